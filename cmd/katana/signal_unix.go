@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// frontierDumpSignals are the signals dumping the frontier of the crawl
+var frontierDumpSignals = []os.Signal{syscall.SIGUSR1}
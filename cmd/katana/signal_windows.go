@@ -0,0 +1,9 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// frontierDumpSignals are the signals dumping the frontier of the crawl,
+// windows has no user signals so it is only dumped on interrupt
+var frontierDumpSignals []os.Signal
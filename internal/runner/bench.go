@@ -0,0 +1,26 @@
+package runner
+
+import (
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/bench"
+	"github.com/projectdiscovery/katana/pkg/types"
+)
+
+// ExecuteBench replays the stored responses of a directory through
+// the crawl pipeline and prints the throughput measurements.
+func ExecuteBench(options *types.Options) error {
+	snapshots, err := bench.LoadStoredResponses(options.Bench)
+	if err != nil {
+		return err
+	}
+	gologger.Info().Msgf("Replaying %d stored responses %d times", len(snapshots), options.BenchIterations)
+
+	result, err := bench.Run(snapshots, bench.Options{Iterations: options.BenchIterations})
+	if err != nil {
+		return err
+	}
+	gologger.Info().Msgf("Processed %d pages in %s (%.1f pages/sec)", result.Pages, result.Duration, result.PagesPerSecond())
+	gologger.Info().Msgf("Extracted %d navigations, %d unique page states", result.Navigations, result.States)
+	gologger.Info().Msgf("Allocated %d bytes in %d allocations (%d allocations/page)", result.AllocatedBytes, result.Allocations, result.AllocationsPerPage())
+	return nil
+}
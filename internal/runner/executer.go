@@ -1,9 +1,15 @@
 package runner
 
 import (
+	"fmt"
+	"net"
 	"strings"
+	"time"
 
 	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/engine/headless"
+	"github.com/projectdiscovery/katana/pkg/importer"
+	"github.com/projectdiscovery/katana/pkg/output"
 	"github.com/projectdiscovery/utils/errkit"
 	urlutil "github.com/projectdiscovery/utils/url"
 	"github.com/remeh/sizedwaitgroup"
@@ -14,20 +20,126 @@ func (r *Runner) ExecuteCrawling() error {
 	if r.crawler == nil {
 		return errkit.New("crawler is not initialized")
 	}
-	inputs := r.parseInputs()
-	if len(inputs) == 0 {
-		return errkit.New("no input provided for crawling")
+	inputs, err := r.crawlInputs()
+	if err != nil {
+		return err
 	}
 
-	for _, input := range inputs {
-		_ = r.state.InFlightUrls.Set(addSchemeIfNotExists(input), struct{}{})
+	defer func() {
+		if err := r.crawler.Close(); err != nil {
+			gologger.Error().Msgf("Error closing crawler: %v\n", err)
+		}
+	}()
+
+	r.crawl(inputs)
+	r.printSessionSummary()
+	r.printBlockSummary()
+	r.printAdaptiveSummary()
+	r.printFormSummary()
+	r.printErrorSummary()
+	r.printDomainInventory()
+	return nil
+}
+
+// printSessionSummary prints the metadata of the run
+func (r *Runner) printSessionSummary() {
+	if r.crawlerOptions.Session == nil {
+		return
+	}
+	gologger.Info().Msgf("Session: %s", r.crawlerOptions.Session)
+}
+
+// printBlockSummary prints the ratio of blocked requests of blocked hosts
+func (r *Runner) printBlockSummary() {
+	if r.crawlerOptions.BlockTracker == nil {
+		return
+	}
+	for _, stats := range r.crawlerOptions.BlockTracker.Stats() {
+		gologger.Info().Msgf("Blocked %d/%d (%.1f%%) requests to %s by %s, %d cooldowns", stats.Blocked, stats.Requests, stats.Ratio()*100, stats.Host, stats.Protection, stats.Cooldowns)
+	}
+}
+
+// printAdaptiveSummary prints the final adaptive concurrency of hosts
+func (r *Runner) printAdaptiveSummary() {
+	if r.crawlerOptions.AdaptiveConcurrency == nil {
+		return
 	}
+	for _, stats := range r.crawlerOptions.AdaptiveConcurrency.Stats() {
+		gologger.Verbose().Msgf("Adaptive concurrency of %s: %d (%d requests, %d errors, %d decreases)", stats.Host, stats.Limit, stats.Requests, stats.Errors, stats.Decreases)
+	}
+}
+
+// printFormSummary prints the auto-filled forms and submissions of hosts
+func (r *Runner) printFormSummary() {
+	for _, stats := range r.crawlerOptions.FormBudget.Stats() {
+		gologger.Info().Msgf("Auto-filled %d forms of %s with %d submissions, %d skipped over the form limits", stats.Forms, stats.Host, stats.Submissions, stats.Skipped)
+	}
+}
+
+// printErrorSummary prints the number of errors of each class
+func (r *Runner) printErrorSummary() {
+	counts := r.crawlerOptions.ErrorStats.Counts()
+	if len(counts) == 0 {
+		return
+	}
+	classes := make([]string, 0, len(counts))
+	for _, count := range counts {
+		classes = append(classes, fmt.Sprintf("%s=%d", count.Class, count.Count))
+	}
+	gologger.Info().Msgf("Errors by class: %s", strings.Join(classes, ", "))
+}
 
+// printDomainInventory prints the third-party domains of the targets
+func (r *Runner) printDomainInventory() {
+	for _, target := range r.crawlerOptions.DomainInventory.Report() {
+		gologger.Info().Msgf("Third-party domains of %s: %d", target.Target, len(target.Domains))
+		for _, domain := range target.Domains {
+			gologger.Info().Msgf("  %s (%s) %d requests", domain.Domain, strings.Join(domain.Kinds, ", "), domain.Requests)
+		}
+	}
+}
+
+// ExecuteReplay replays the actions of a diagnostics directory
+func (r *Runner) ExecuteReplay() error {
+	headlessCrawler, ok := r.crawler.(*headless.Headless)
+	if !ok {
+		return errkit.New("diagnostics replay requires headless mode")
+	}
 	defer func() {
 		if err := r.crawler.Close(); err != nil {
 			gologger.Error().Msgf("Error closing crawler: %v\n", err)
 		}
 	}()
+	return headlessCrawler.Replay(r.options.ReplayDiagnostics, r.options.ReplaySnapshot)
+}
+
+// ExecuteReparse parses the responses of the archive again
+func (r *Runner) ExecuteReparse() error {
+	defer func() {
+		if err := r.crawler.Close(); err != nil {
+			gologger.Error().Msgf("Error closing crawler: %v\n", err)
+		}
+	}()
+	return r.crawler.Crawl(r.options.Reparse)
+}
+
+// crawlInputs returns the inputs to crawl
+func (r *Runner) crawlInputs() ([]string, error) {
+	inputs := r.parseInputs()
+	if len(inputs) == 0 && len(r.crawlerOptions.Seeds) > 0 {
+		inputs = importer.RootURLs(r.crawlerOptions.Seeds)
+	}
+	if len(inputs) == 0 {
+		return nil, errkit.New("no input provided for crawling")
+	}
+	return inputs, nil
+}
+
+// crawl crawls the inputs waiting for all of them to complete
+func (r *Runner) crawl(inputs []string) {
+	for _, input := range inputs {
+		_ = r.state.InFlightUrls.Set(addSchemeIfNotExists(input), struct{}{})
+	}
 
 	wg := sizedwaitgroup.New(r.options.Parallelism)
 	for _, input := range inputs {
@@ -36,23 +148,34 @@ func (r *Runner) ExecuteCrawling() error {
 			continue
 		}
 		wg.Add()
+		if _, expired := r.crawlerOptions.CrawlTimeout(); expired {
+			wg.Done()
+			gologger.Warning().Msgf("Crawl budget of %s exhausted, skipping remaining inputs", r.options.CrawlBudget)
+			break
+		}
 		input = addSchemeIfNotExists(input)
 		go func(input string) {
 			defer wg.Done()
 
-			if err := r.crawler.Crawl(input); err != nil {
+			if err := r.crawler.Crawl(r.crawlerOptions.HostMap.Apply(input)); err != nil {
 				gologger.Warning().Msgf("Could not crawl %s: %s", input, err)
+				_ = r.crawlerOptions.OutputWriter.WriteErr(&output.Error{
+					Timestamp: time.Now(),
+					Endpoint:  input,
+					Error:     err.Error(),
+					Class:     output.ClassifyError(err),
+				})
 			}
 			r.state.InFlightUrls.Delete(input)
 		}(input)
 	}
 	wg.Wait()
-	return nil
 }
 
 // scheme less urls are skipped and are required for headless mode and other purposes
 // this method adds scheme if given input does not have any
 func addSchemeIfNotExists(inputURL string) string {
+	inputURL = bracketIPv6(inputURL)
 	if strings.HasPrefix(inputURL, urlutil.HTTP) || strings.HasPrefix(inputURL, urlutil.HTTPS) {
 		return inputURL
 	}
@@ -67,3 +190,16 @@ func addSchemeIfNotExists(inputURL string) string {
 		return urlutil.HTTPS + urlutil.SchemeSeparator + inputURL
 	}
 }
+
+// bracketIPv6 encloses bare ipv6 literal hosts in brackets
+// so that they are not confused with a port (eg. ::1/path => [::1]/path)
+func bracketIPv6(input string) string {
+	host, rest := input, ""
+	if index := strings.IndexAny(input, "/?#"); index != -1 {
+		host, rest = input[:index], input[index:]
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		return "[" + host + "]" + rest
+	}
+	return input
+}
@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/monitor"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// ExecuteMonitoring crawls the inputs every monitor interval reporting
+// the endpoints and forms which changed since the previous crawl.
+func (r *Runner) ExecuteMonitoring() error {
+	if r.crawler == nil {
+		return errkit.New("crawler is not initialized")
+	}
+	inputs, err := r.crawlInputs()
+	if err != nil {
+		return err
+	}
+
+	var notifier *monitor.Notifier
+	if r.options.MonitorWebhook != "" {
+		notifier, err = monitor.NewNotifier(r.options.MonitorWebhook, r.options.MonitorWebhookFormat)
+		if err != nil {
+			return err
+		}
+	}
+	previous, err := monitor.Load(r.options.MonitorState)
+	if err != nil {
+		return err
+	}
+
+	recorder := monitor.NewRecorder(r.crawlerOptions.OutputWriter)
+	recorder.LengthThreshold = int64(r.options.MonitorLengthThreshold)
+	r.crawlerOptions.OutputWriter = recorder
+
+	defer func() {
+		if err := r.crawler.Close(); err != nil {
+			gologger.Error().Msgf("Error closing crawler: %v\n", err)
+		}
+	}()
+
+	for round := 1; ; round++ {
+		if round > 1 {
+			if err := r.crawlerOptions.ResetUniqueFilter(); err != nil {
+				return err
+			}
+		}
+		recorder.Start(previous)
+		r.crawl(inputs)
+		current := recorder.Snapshot()
+
+		if previous == nil {
+			gologger.Info().Msgf("Monitor baseline recorded with %d endpoints", len(current.Endpoints))
+		} else {
+			changes := monitor.Diff(previous, current, recorder.LengthThreshold)
+			gologger.Info().Msgf("Monitor round %d: %d new, %d changed, %d removed endpoints, %d changed forms", round, len(changes.NewEndpoints), len(changes.ChangedEndpoints), len(changes.RemovedEndpoints), len(changes.ChangedForms))
+			if notifier != nil && !changes.Empty() {
+				if err := notifier.Notify(inputs, changes); err != nil {
+					gologger.Warning().Msgf("Could not send monitor changes: %s", err)
+				}
+			}
+		}
+		if r.options.MonitorState != "" {
+			if err := current.Save(r.options.MonitorState); err != nil {
+				gologger.Warning().Msgf("Could not save monitor state: %s", err)
+			}
+		}
+		previous = current
+
+		gologger.Info().Msgf("Next monitor crawl in %s", r.options.MonitorInterval)
+		time.Sleep(r.options.MonitorInterval)
+	}
+}
@@ -2,13 +2,19 @@ package runner
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/gologger/formatter"
+	"github.com/projectdiscovery/katana/pkg/engine/common"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/graph"
+	"github.com/projectdiscovery/katana/pkg/monitor"
+	"github.com/projectdiscovery/katana/pkg/output"
 	"github.com/projectdiscovery/katana/pkg/types"
 	"github.com/projectdiscovery/katana/pkg/utils"
 	"github.com/projectdiscovery/utils/errkit"
@@ -16,12 +22,15 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// handlerAttributeRegex matches the attribute names usable in selectors
+var handlerAttributeRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
 // validateOptions validates the provided options for crawler
 func validateOptions(options *types.Options) error {
 	if options.MaxDepth <= 0 && options.CrawlDuration.Seconds() <= 0 {
 		return errkit.New("either max-depth or crawl-duration must be specified")
 	}
-	if len(options.URLs) == 0 && !fileutil.HasStdin() {
+	if len(options.URLs) == 0 && !fileutil.HasStdin() && options.ImportFile == "" && options.ReplayDiagnostics == "" && options.Reparse == "" {
 		return errkit.New("no inputs specified for crawler")
 	}
 
@@ -44,6 +53,201 @@ func validateOptions(options *types.Options) error {
 	if (options.HeadlessOptionalArguments != nil || options.HeadlessNoSandbox || options.SystemChromePath != "") && !options.Headless && !options.HeadlessHybrid {
 		return errkit.New("headless mode (-hl) is required if -ho, -nos or -scp are set")
 	}
+	if options.IPVersion != "" && options.IPVersion != "4" && options.IPVersion != "6" {
+		return errkit.New("ip version (-iv) must be either 4 or 6")
+	}
+	if options.ImportHeaders && options.Headless {
+		return errkit.New("flags -import-headers and -hl (headless) are mutually exclusive")
+	}
+	if options.AuthScript != "" && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -auth-script is set")
+	}
+	if options.SessionCheckURL != "" && options.AuthScript == "" {
+		return errkit.New("auth script (-auth-script) is required if -session-check-url is set")
+	}
+	if (options.SessionCheckURL == "") != (options.SessionCheckMarker == "") {
+		return errkit.New("flags -session-check-url and -session-check-marker must be set together")
+	}
+	if (len(options.LoginPageURLs) > 0 || len(options.LoginPageMarkers) > 0) && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -login-page-url or -login-page-marker is set")
+	}
+	if len(options.NucleiTags) > 0 && !options.Nuclei {
+		return errkit.New("nuclei integration (-nuclei) is required if -nuclei-tags is set")
+	}
+	if options.Interactsh && !options.AutomaticFormFill {
+		return errkit.New("automatic form fill (-aff) is required if -interactsh is set")
+	}
+	if options.MaxForms < 0 || options.MaxFormSubmissions < 0 {
+		return errkit.New("form limits (-max-forms, -max-form-submissions) must not be negative")
+	}
+	if (options.MaxForms > 0 || options.MaxFormSubmissions > 0) && !options.AutomaticFormFill {
+		return errkit.New("automatic form fill (-aff) is required if -max-forms or -max-form-submissions is set")
+	}
+	if options.OutputSchemaVersion != 0 && options.OutputSchemaVersion != output.SchemaVersion {
+		return errkit.New(fmt.Sprintf("output schema version (-osv) must be 0 (legacy) or %d", output.SchemaVersion))
+	}
+	if (options.InteractshServer != "" || options.InteractshToken != "") && !options.Interactsh {
+		return errkit.New("interactsh (-interactsh) is required if -interactsh-server or -interactsh-token are set")
+	}
+	if (options.BlockRotateUserAgent || len(options.BlockRotateProxy) > 0) && !options.BlockDetection {
+		return errkit.New("block detection (-block-detection) is required if -block-rotate-ua or -block-rotate-proxy are set")
+	}
+	if options.XhrFuzz && (!options.HeadlessHybrid || !options.XhrExtraction) {
+		return errkit.New("hybrid mode (-hh) and xhr extraction (-xhr) are required if -xhr-fuzz is set")
+	}
+	if (options.ReducedMotion || options.DeterministicSeed != 0) && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -reduced-motion or -deterministic-seed is set")
+	}
+	if len(options.HeadlessActionTimeouts) > 0 && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -action-timeout is set")
+	}
+	if len(options.HeadlessSimilarity) > 0 && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -state-similarity is set")
+	}
+	if options.ClientRedirects && !options.DisableRedirects {
+		return errkit.New("disable redirects (-dr) is required if -client-redirects is set")
+	}
+	if len(options.HeadlessResourceTypes) > 0 && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -resource-type is set")
+	}
+	if len(options.BlockResources) > 0 && !options.Headless && !options.HeadlessHybrid {
+		return errkit.New("headless (-hl) or hybrid (-hh) mode is required if -block-resource is set")
+	}
+	if options.BlockThirdParty && !options.Headless && !options.HeadlessHybrid {
+		return errkit.New("headless (-hl) or hybrid (-hh) mode is required if -block-third-party is set")
+	}
+	if options.HeadlessClientCert != "" && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -headless-client-cert is set")
+	}
+	if options.HeadlessClientKey != "" && options.HeadlessClientCert == "" {
+		return errkit.New("client certificate (-headless-client-cert) is required if -headless-client-key is set")
+	}
+	if options.StateDuration > 0 && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -state-duration is set")
+	}
+	if options.StorageStateDir != "" && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -storage-state-dir is set")
+	}
+	if options.SessionFile != "" && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -session-file is set")
+	}
+	if options.MixedContent && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -mixed-content is set")
+	}
+	if options.HeadlessConcurrency < 0 {
+		return errkit.New("headless concurrency (-headless-concurrency) must not be negative")
+	}
+	if options.HeadlessMinBrowsers < 0 {
+		return errkit.New("headless min browsers (-headless-min-browsers) must not be negative")
+	}
+	if options.HeadlessBrowserIdleTimeout < 0 {
+		return errkit.New("browser idle timeout (-browser-idle-timeout) must not be negative")
+	}
+	if options.DOMSnapshotDir != "" && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -dom-snapshot-dir is set")
+	}
+	if len(options.LanguageSweep) > 0 && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -language-sweep is set")
+	}
+	if options.CrossOriginFrames && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -cross-origin-frames is set")
+	}
+	if options.ScrollBudget < 0 {
+		return errkit.New("scroll budget (-scroll-budget) must not be negative")
+	}
+	if options.ScrollBudget > 0 && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -scroll-budget is set")
+	}
+	if options.ErrorPageActions && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -error-page-actions is set")
+	}
+	if len(options.HeadlessClickSelectors) > 0 && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -click-selector is set")
+	}
+	if len(options.HeadlessNoClickSelectors) > 0 && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -no-click-selector is set")
+	}
+	if len(options.HeadlessLogoutPatterns) > 0 && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -logout-pattern is set")
+	}
+	if options.CrawlGraphDir != "" && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -crawl-graph-dir is set")
+	}
+	if options.CrawlGraphDir != "" && !slices.Contains(graph.ExportFormats, graph.ExportFormat(options.CrawlGraphFormat)) {
+		return errkit.New("crawl graph format (-crawl-graph-format) must be json or graphml")
+	}
+	if options.StatsJSON && options.StatsInterval <= 0 {
+		return errkit.New("stats interval (-stats-interval) must be positive if -stats-json is set")
+	}
+	if options.SpillUniqueActions && options.MaxUniqueActions <= 0 {
+		return errkit.New("max unique actions (-max-unique-actions) is required if -unique-actions-spill is set")
+	}
+	if options.ReplayDiagnostics != "" && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -replay-diagnostics is set")
+	}
+	if options.ReplaySnapshot && options.ReplayDiagnostics == "" {
+		return errkit.New("diagnostics replay (-replay-diagnostics) is required if -replay-snapshot is set")
+	}
+	if options.MonitorInterval > 0 && options.CaptureProxy != "" {
+		return errkit.New("monitor mode (-monitor-interval) cannot be used with -capture-proxy")
+	}
+	if len(options.Roles) > 0 && (options.MonitorInterval > 0 || options.CaptureProxy != "" || options.StorageStateDir != "" || options.SessionFile != "") {
+		return errkit.New("role comparison (-role) cannot be used with -monitor-interval, -capture-proxy, -storage-state-dir or -session-file")
+	}
+	if options.RoleReport != "" && len(options.Roles) == 0 {
+		return errkit.New("roles (-role) are required if -role-report is set")
+	}
+	if (options.MonitorState != "" || options.MonitorWebhook != "") && options.MonitorInterval <= 0 {
+		return errkit.New("monitor mode (-monitor-interval) is required if -monitor-state or -monitor-webhook are set")
+	}
+	if options.MonitorWebhookFormat != "" && options.MonitorWebhookFormat != monitor.FormatJSON && options.MonitorWebhookFormat != monitor.FormatSlack {
+		return errkit.Newf("invalid monitor webhook format %q (json, slack)", options.MonitorWebhookFormat)
+	}
+	if options.MonitorLengthThreshold < 0 {
+		return errkit.New("monitor length threshold (-monitor-length-threshold) must not be negative")
+	}
+	if options.DefectDojoOutput != "" && options.DefectDojoOutput == options.OutputFile {
+		return errkit.New("defectdojo output (-defectdojo-output) must differ from the output file (-output)")
+	}
+	if len(options.EncryptRecipients) > 0 && options.StoreFields != "" {
+		return errkit.New("encrypted output (-encrypt-recipient) cannot be used with -store-field")
+	}
+	if options.SitemapOutput != "" && options.SitemapOutput == options.OutputFile {
+		return errkit.New("sitemap output (-sitemap-output) must differ from the output file (-output)")
+	}
+	if options.ObjectReferenceOutput != "" && options.ObjectReferenceOutput == options.OutputFile {
+		return errkit.New("object reference output (-object-reference-output) must differ from the output file (-output)")
+	}
+	if options.SSHTunnel != "" && options.Proxy != "" {
+		return errkit.New("ssh tunnel (-ssh-tunnel) cannot be used with -proxy")
+	}
+	if options.SSHTunnel != "" && (options.HeadlessHybrid || options.ChromeWSUrl != "") {
+		return errkit.New("ssh tunnel (-ssh-tunnel) is only supported by the standard and headless engines")
+	}
+	if common.IsUnixProxy(options.Proxy) && (options.Headless || options.HeadlessHybrid) {
+		return errkit.New("unix socket proxies (-proxy) are only supported by the standard engine")
+	}
+	if options.Reparse != "" && (options.Headless || options.HeadlessHybrid || options.ImportFile != "" || len(options.Roles) > 0 || options.MonitorInterval > 0) {
+		return errkit.New("reparse (-reparse) cannot be used with headless modes, -import, -role or -monitor-interval")
+	}
+	if options.Reparse != "" && (options.Soft404 || options.MethodDiscovery || options.CORSProbe) {
+		return errkit.New("reparse (-reparse) cannot be used with -soft-404, -method-discovery or -cors-probe as they require network access")
+	}
+	if options.RespectRobots && options.Headless {
+		return errkit.New("robots.txt compliance (-respect-robots) is not supported in headless mode (-hl)")
+	}
+	if options.Soft404 && options.Headless {
+		return errkit.New("soft 404 detection (-soft-404) is not supported in headless mode (-hl)")
+	}
+	if options.MethodDiscovery && options.Headless {
+		return errkit.New("method discovery (-method-discovery) is not supported in headless mode (-hl)")
+	}
+	if options.CORSProbe && options.Headless {
+		return errkit.New("cors probing (-cors-probe) is not supported in headless mode (-hl)")
+	}
+	if options.CaptureProxy != "" && !options.Headless {
+		return errkit.New("headless mode (-hl) is required if -capture-proxy is set")
+	}
 	if options.SystemChromePath != "" {
 		if !fileutil.FileExists(options.SystemChromePath) {
 			return errkit.New("specified system chrome binary does not exist")
@@ -67,7 +271,15 @@ func validateOptions(options *types.Options) error {
 		}
 		options.FilterRegex = append(options.FilterRegex, cr)
 	}
-	if options.KnownFiles != "" && options.MaxDepth < 3 {
+	for i, attribute := range options.HandlerAttributes {
+		// attribute names of parsed html documents are lowercase
+		attribute = strings.ToLower(strings.TrimSpace(attribute))
+		if !handlerAttributeRegex.MatchString(attribute) {
+			return errkit.Newf("invalid handler attribute %q (-handler-attribute)", attribute)
+		}
+		options.HandlerAttributes[i] = attribute
+	}
+	if (options.KnownFiles != "" || len(options.KnownFilesList) > 0) && options.MaxDepth < 3 {
 		gologger.Info().Msgf("Depth automatically set to 3 to accommodate the `--known-files` option (originally set to %d).", options.MaxDepth)
 		options.MaxDepth = 3
 	}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"slices"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/roles"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// ExecuteRoles crawls the inputs as each role reporting the endpoints
+// and page states reachable by some of the roles only.
+func (r *Runner) ExecuteRoles() error {
+	if r.crawler == nil {
+		return errkit.New("crawler is not initialized")
+	}
+	crawlRoles, err := roles.Parse(r.options.Roles)
+	if err != nil {
+		return errkit.Wrap(err, "could not parse roles")
+	}
+	for _, role := range crawlRoles {
+		if role.AuthScript != "" && !r.options.Headless {
+			return errkit.New("headless mode (-hl) is required if a role sets an auth script")
+		}
+	}
+	inputs, err := r.crawlInputs()
+	if err != nil {
+		return err
+	}
+
+	recorder := roles.NewRecorder(r.crawlerOptions.OutputWriter)
+	r.crawlerOptions.OutputWriter = recorder
+	r.crawlerOptions.OnPageState = recorder.RecordState
+	headerRules := r.crawlerOptions.HeaderRules
+	authScript := r.options.AuthScript
+	defer func() {
+		r.options.AuthScript = authScript
+	}()
+
+	defer func() {
+		if err := r.crawler.Close(); err != nil {
+			gologger.Error().Msgf("Error closing crawler: %v\n", err)
+		}
+	}()
+
+	for i, role := range crawlRoles {
+		r.crawlerOptions.HeaderRules = append(slices.Clip(headerRules), role.Rules...)
+		r.options.AuthScript = authScript
+		if role.AuthScript != "" {
+			r.options.AuthScript = role.AuthScript
+		}
+		// every role is crawled with a fresh engine so that cookies
+		// and browser sessions are not shared, the engine of the
+		// first role is recreated to log in with its auth script
+		if i > 0 || role.AuthScript != "" {
+			if err := r.crawler.Close(); err != nil {
+				gologger.Error().Msgf("Error closing crawler: %v\n", err)
+			}
+			if err := r.crawlerOptions.ResetUniqueFilter(); err != nil {
+				return err
+			}
+			crawler, err := newCrawler(r.options, r.crawlerOptions)
+			if err != nil {
+				return errkit.Wrap(err, "could not create crawler")
+			}
+			r.crawler = crawler
+		}
+
+		gologger.Info().Msgf("Crawling as role %s", role.Name)
+		recorder.SetRole(role.Name)
+		r.crawl(inputs)
+	}
+
+	comparison := recorder.Compare()
+	restricted := comparison.Restricted()
+	restrictedStates := comparison.RestrictedStates()
+	for _, name := range comparison.Roles {
+		gologger.Info().Msgf("Role %s reached %d endpoints and %d page states not reachable by every role", name, restricted[name], restrictedStates[name])
+	}
+	if r.options.RoleReport != "" {
+		if err := comparison.Save(r.options.RoleReport); err != nil {
+			return err
+		}
+	}
+	return nil
+}
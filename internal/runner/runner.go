@@ -4,13 +4,18 @@ import (
 	"encoding/json"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/katana/pkg/engine"
+	"github.com/projectdiscovery/katana/pkg/engine/common"
 	"github.com/projectdiscovery/katana/pkg/engine/headless"
 	"github.com/projectdiscovery/katana/pkg/engine/hybrid"
+	"github.com/projectdiscovery/katana/pkg/engine/offline"
 	"github.com/projectdiscovery/katana/pkg/engine/standard"
 	"github.com/projectdiscovery/katana/pkg/types"
+	"github.com/projectdiscovery/katana/pkg/utils/sshtunnel"
 	"github.com/projectdiscovery/mapcidr"
 	"github.com/projectdiscovery/mapcidr/asn"
 	"github.com/projectdiscovery/networkpolicy"
@@ -31,6 +36,7 @@ type Runner struct {
 	options        *types.Options
 	state          *RunnerState
 	networkpolicy  *networkpolicy.NetworkPolicy
+	tunnel         *sshtunnel.Tunnel
 }
 
 type RunnerState struct {
@@ -85,27 +91,32 @@ func New(options *types.Options) (*Runner, error) {
 			return nil, err
 		}
 	}
+	// the engines reach targets behind the jump host through the
+	// local proxy of the tunnel
+	var tunnel *sshtunnel.Tunnel
+	if options.SSHTunnel != "" {
+		var err error
+		tunnel, err = sshtunnel.New(sshtunnel.Options{
+			Target:         options.SSHTunnel,
+			KeyFile:        options.SSHKey,
+			KnownHostsFile: options.SSHKnownHosts,
+			Timeout:        time.Duration(options.Timeout) * time.Second,
+		})
+		if err != nil {
+			return nil, errkit.Wrap(err, "could not create ssh tunnel")
+		}
+		options.Proxy = tunnel.ProxyURL()
+		gologger.Info().Msgf("Tunneling crawl traffic through %s", options.SSHTunnel)
+	}
 	crawlerOptions, err := types.NewCrawlerOptions(options)
 	if err != nil {
+		closeTunnel(tunnel)
 		return nil, errkit.Wrap(err, "could not create crawler options")
 	}
 
-	var crawler engine.Engine
-
-	switch {
-	case options.ChromeWSUrl != "":
-		// When connecting to existing browser via WebSocket URL,
-		// use hybrid engine regardless of other flags
-		// (ChromeWSUrl takes precedence over -headless flag)
-		crawler, err = hybrid.New(crawlerOptions)
-	case options.Headless:
-		crawler, err = headless.New(crawlerOptions)
-	case options.HeadlessHybrid:
-		crawler, err = hybrid.New(crawlerOptions)
-	default:
-		crawler, err = standard.New(crawlerOptions)
-	}
+	crawler, err := newCrawler(options, crawlerOptions)
 	if err != nil {
+		closeTunnel(tunnel)
 		return nil, errkit.Wrap(err, "could not create standard crawler")
 	}
 
@@ -143,17 +154,46 @@ func New(options *types.Options) (*Runner, error) {
 		crawler:        crawler,
 		state:          &RunnerState{InFlightUrls: mapsutil.NewSyncLockMap[string, struct{}]()},
 		networkpolicy:  np,
+		tunnel:         tunnel,
 	}
 
 	return runner, nil
 }
 
+// newCrawler creates the crawling engine selected by the options
+func newCrawler(options *types.Options, crawlerOptions *types.CrawlerOptions) (engine.Engine, error) {
+	switch {
+	case options.ChromeWSUrl != "" && !options.Headless:
+		// When connecting to existing browser via WebSocket URL,
+		// use hybrid engine unless the headless engine drives it
+		return hybrid.New(crawlerOptions)
+	case options.Reparse != "":
+		return offline.New(crawlerOptions)
+	case options.Headless:
+		return headless.New(crawlerOptions)
+	case options.HeadlessHybrid:
+		return hybrid.New(crawlerOptions)
+	default:
+		return standard.New(crawlerOptions)
+	}
+}
+
 // Close closes the runner releasing resources
 func (r *Runner) Close() error {
-	return multierr.Combine(
+	err := multierr.Combine(
 		r.crawler.Close(),
 		r.crawlerOptions.Close(),
 	)
+	if r.tunnel != nil {
+		err = multierr.Append(err, r.tunnel.Close())
+	}
+	return err
+}
+
+func closeTunnel(tunnel *sshtunnel.Tunnel) {
+	if tunnel != nil {
+		_ = tunnel.Close()
+	}
 }
 
 func (r *Runner) SaveState(resumeFilename string) error {
@@ -162,6 +202,45 @@ func (r *Runner) SaveState(resumeFilename string) error {
 	return os.WriteFile(resumeFilename, data, os.ModePerm)
 }
 
+// Frontier returns the requests waiting in the crawl queues of the
+// running crawls, nil if the engine does not expose them
+func (r *Runner) Frontier() []common.FrontierEntry {
+	provider, ok := r.crawler.(engine.FrontierProvider)
+	if !ok {
+		return nil
+	}
+	return provider.Frontier()
+}
+
+// QueueSize returns the number of requests waiting in the
+// crawl queues of the running crawls
+func (r *Runner) QueueSize() int {
+	provider, ok := r.crawler.(engine.FrontierProvider)
+	if !ok {
+		return 0
+	}
+	return provider.QueueSize()
+}
+
+// DumpFrontier writes the requests waiting in the crawl queues
+// of the running crawls to file as json lines
+func (r *Runner) DumpFrontier(file string) (int, error) {
+	entries := r.Frontier()
+	var builder strings.Builder
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return 0, errkit.Wrap(err, "could not marshal frontier entry")
+		}
+		builder.Write(data)
+		builder.WriteByte('\n')
+	}
+	if err := os.WriteFile(file, []byte(builder.String()), 0644); err != nil {
+		return 0, errkit.Wrap(err, "could not write frontier dump")
+	}
+	return len(entries), nil
+}
+
 func expandCIDRInputValue(value string) []string {
 	var ips []string
 	ipsCh, _ := mapcidr.IPAddressesAsStream(value)
@@ -0,0 +1,249 @@
+// Package bench measures the throughput of the crawl pipeline by
+// replaying recorded responses through the response parser, the
+// dom normalizer and the crawl graph without network access.
+package bench
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/normalizer"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/normalizer/simhash"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/graph"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/projectdiscovery/katana/pkg/engine/parser"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// indexFile is the index of a stored responses directory
+const indexFile = "index.txt"
+
+// Snapshot is a recorded response of a page
+type Snapshot struct {
+	URL    string
+	Header http.Header
+	Status int
+	Body   []byte
+}
+
+// LoadStoredResponses loads the responses stored in a directory
+// written by the -store-response option.
+func LoadStoredResponses(directory string) ([]*Snapshot, error) {
+	var snapshots []*Snapshot
+	err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".txt" || entry.Name() == indexFile {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return errkit.Wrap(err, "could not read stored response")
+		}
+		snapshot, err := parseStoredResponse(data)
+		if err != nil {
+			return errkit.Wrap(err, fmt.Sprintf("could not parse stored response %s", path))
+		}
+		snapshots = append(snapshots, snapshot)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, errkit.Newf("no stored responses found in %s", directory)
+	}
+	return snapshots, nil
+}
+
+// parseStoredResponse parses a stored response made of the url,
+// the raw request and the raw response.
+func parseStoredResponse(data []byte) (*Snapshot, error) {
+	content := string(data)
+	URL, rest, found := strings.Cut(content, "\n\n\n")
+	if !found {
+		return nil, errkit.New("missing url header")
+	}
+	// the raw response starts with the status line after the raw request
+	start := strings.Index(rest, "\nHTTP/")
+	if start == -1 {
+		return nil, errkit.New("missing raw response")
+	}
+	raw := rest[start+1:]
+
+	separator := "\r\n\r\n"
+	end := strings.Index(raw, separator)
+	if end == -1 {
+		separator = "\n\n"
+		end = strings.Index(raw, separator)
+	}
+	head, body := raw, ""
+	if end != -1 {
+		head, body = raw[:end], raw[end+len(separator):]
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(strings.NewReader(head+"\r\n\r\n")), nil)
+	if err != nil {
+		return nil, errkit.Wrap(err, "could not read raw response")
+	}
+	_ = resp.Body.Close()
+
+	return &Snapshot{
+		URL:    strings.TrimSpace(URL),
+		Header: resp.Header,
+		Status: resp.StatusCode,
+		Body:   []byte(body),
+	}, nil
+}
+
+// Options contains the configuration of a benchmark
+type Options struct {
+	// Iterations is the number of times the snapshots are replayed
+	Iterations int
+}
+
+// Result contains the measurements of a benchmark
+type Result struct {
+	// Pages is the number of pages processed
+	Pages int
+	// Navigations is the number of navigations extracted
+	Navigations int
+	// States is the number of unique page states of the graph
+	States int
+	// Duration is the wall-clock duration of the benchmark
+	Duration time.Duration
+	// Allocations is the number of heap allocations
+	Allocations uint64
+	// AllocatedBytes is the number of heap bytes allocated
+	AllocatedBytes uint64
+}
+
+// PagesPerSecond returns the throughput of the pipeline
+func (r *Result) PagesPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Pages) / r.Duration.Seconds()
+}
+
+// AllocationsPerPage returns the mean heap allocations per page
+func (r *Result) AllocationsPerPage() uint64 {
+	if r.Pages == 0 {
+		return 0
+	}
+	return r.Allocations / uint64(r.Pages)
+}
+
+// Run replays the snapshots through the crawl pipeline
+func Run(snapshots []*Snapshot, options Options) (*Result, error) {
+	if options.Iterations <= 0 {
+		options.Iterations = 1
+	}
+	domNormalizer, err := normalizer.New()
+	if err != nil {
+		return nil, errkit.Wrap(err, "could not create dom normalizer")
+	}
+	responseParser := parser.NewResponseParser()
+	responseParser.InitWithOptions(&parser.Options{})
+
+	result := &Result{}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	started := time.Now()
+
+	for i := 0; i < options.Iterations; i++ {
+		crawlGraph := graph.NewCrawlGraph()
+		states := make(map[string]string, len(snapshots))
+		links := make(map[string][]string, len(snapshots))
+
+		for _, snapshot := range snapshots {
+			navigations, err := parseSnapshot(responseParser, snapshot)
+			if err != nil {
+				return nil, err
+			}
+			result.Navigations += len(navigations)
+			for _, navigationRequest := range navigations {
+				links[snapshot.URL] = append(links[snapshot.URL], navigationRequest.URL)
+			}
+
+			strippedDOM, err := domNormalizer.Apply(string(snapshot.Body))
+			if err != nil {
+				return nil, errkit.Wrap(err, fmt.Sprintf("could not normalize dom of %s", snapshot.URL))
+			}
+			hash := sha256.Sum256([]byte(strippedDOM))
+			state := types.PageState{
+				UniqueID:    hex.EncodeToString(hash[:]),
+				URL:         snapshot.URL,
+				StrippedDOM: strippedDOM,
+				SimHash:     simhash.Fingerprint(strings.NewReader(strippedDOM), 3),
+			}
+			if err := crawlGraph.AddPageState(state); err != nil {
+				return nil, err
+			}
+			states[snapshot.URL] = state.UniqueID
+			result.Pages++
+		}
+
+		// links between recorded pages become edges of the graph
+		for source, targets := range links {
+			for _, target := range targets {
+				targetState, ok := states[target]
+				if !ok {
+					continue
+				}
+				action := &types.Action{Type: types.ActionTypeLoadURL, Input: target}
+				if err := crawlGraph.AddEdge(states[source], targetState, action); err != nil {
+					return nil, err
+				}
+			}
+		}
+		result.States = len(crawlGraph.GetVertices())
+	}
+
+	result.Duration = time.Since(started)
+	runtime.ReadMemStats(&after)
+	result.Allocations = after.Mallocs - before.Mallocs
+	result.AllocatedBytes = after.TotalAlloc - before.TotalAlloc
+	return result, nil
+}
+
+// parseSnapshot extracts the navigations of a snapshot
+func parseSnapshot(responseParser *parser.Parser, snapshot *Snapshot) ([]*navigation.Request, error) {
+	request, err := http.NewRequest(http.MethodGet, snapshot.URL, nil)
+	if err != nil {
+		return nil, errkit.Wrap(err, fmt.Sprintf("invalid snapshot url %s", snapshot.URL))
+	}
+	document, err := goquery.NewDocumentFromReader(bytes.NewReader(snapshot.Body))
+	if err != nil {
+		return nil, errkit.Wrap(err, fmt.Sprintf("could not parse body of %s", snapshot.URL))
+	}
+	resp := &navigation.Response{
+		Resp: &http.Response{
+			StatusCode: snapshot.Status,
+			Header:     snapshot.Header,
+			Body:       io.NopCloser(bytes.NewReader(snapshot.Body)),
+			Request:    request,
+		},
+		Depth:        1,
+		Reader:       document,
+		StatusCode:   snapshot.Status,
+		Body:         string(snapshot.Body),
+		RootHostname: request.URL.Hostname(),
+	}
+	return responseParser.ParseResponse(resp), nil
+}
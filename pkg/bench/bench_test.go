@@ -0,0 +1,39 @@
+package bench
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeStoredResponse(t *testing.T, directory, name, URL, body string) {
+	t.Helper()
+
+	data := URL + "\n\n\n" +
+		"GET / HTTP/1.1\r\nHost: example.com\r\n\r\n" + "\n\n" +
+		"HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n" + body
+	require.NoError(t, os.MkdirAll(filepath.Join(directory, "example.com"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "example.com", name), []byte(data), 0644))
+}
+
+func TestBench(t *testing.T) {
+	directory := t.TempDir()
+	writeStoredResponse(t, directory, "a.txt", "https://example.com/", `<html><body><a href="/about">about</a></body></html>`)
+	writeStoredResponse(t, directory, "b.txt", "https://example.com/about", `<html><body><form><input name="q"></form><a href="/">home</a></body></html>`)
+	require.NoError(t, os.WriteFile(filepath.Join(directory, indexFile), []byte("ignored"), 0644))
+
+	snapshots, err := LoadStoredResponses(directory)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	require.Equal(t, 200, snapshots[0].Status)
+	require.Equal(t, "text/html", snapshots[0].Header.Get("Content-Type"))
+
+	result, err := Run(snapshots, Options{Iterations: 3})
+	require.NoError(t, err)
+	require.Equal(t, 6, result.Pages)
+	require.Equal(t, 6, result.Navigations)
+	require.Equal(t, 2, result.States)
+	require.Greater(t, result.PagesPerSecond(), float64(0))
+}
@@ -3,9 +3,11 @@ package common
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -16,7 +18,14 @@ import (
 	"github.com/projectdiscovery/katana/pkg/output"
 	"github.com/projectdiscovery/katana/pkg/types"
 	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/katana/pkg/utils/blockdetect"
+	"github.com/projectdiscovery/katana/pkg/utils/debugserver"
+	"github.com/projectdiscovery/katana/pkg/utils/formbudget"
+	"github.com/projectdiscovery/katana/pkg/utils/fuzzlite"
+	"github.com/projectdiscovery/katana/pkg/utils/idn"
 	"github.com/projectdiscovery/katana/pkg/utils/queue"
+	"github.com/projectdiscovery/katana/pkg/utils/robots"
+	"github.com/projectdiscovery/katana/pkg/utils/soft404"
 	"github.com/projectdiscovery/retryablehttp-go"
 	"github.com/projectdiscovery/utils/errkit"
 	httputil "github.com/projectdiscovery/utils/http"
@@ -34,6 +43,18 @@ type Shared struct {
 	Options    *types.CrawlerOptions
 	Jar        *httputil.CookieJar
 	PathTrie   *utils.PathTrie
+	// Robots checks urls against the robots.txt of hosts when set
+	Robots *robots.Checker
+	// Methods discovers the methods of api endpoints when set
+	Methods *MethodProber
+	// CORS probes the cross-origin policy of api endpoints when set
+	CORS *CORSProber
+	// Soft404 detects soft 404 pages of hosts when set
+	Soft404 *soft404.Detector
+
+	// queues are the queues of the running crawl sessions by target
+	queuesMu sync.Mutex
+	queues   map[string]*queue.Queue
 }
 
 // NewShared creates a new Shared instance with the provided crawler options.
@@ -44,12 +65,30 @@ func NewShared(options *types.CrawlerOptions) (*Shared, error) {
 		Headers: options.Options.ParseCustomHeaders(),
 		Options: options,
 	}
-	if options.Options.KnownFiles != "" {
+	knownFiles := options.Options.KnownFiles != "" || len(options.Options.KnownFilesList) > 0
+	if knownFiles || options.Options.RespectRobots || options.Options.Soft404 {
 		httpclient, _, err := BuildHttpClient(options.Dialer, options.Options, nil)
 		if err != nil {
 			return nil, errkit.Wrap(err, "could not create http client")
 		}
-		shared.KnownFiles = files.New(httpclient, options.Options.KnownFiles)
+		// known file probes share the rate limit of the crawl requests
+		httpclient.HTTPClient.Transport = options.RateLimit.Transport(httpclient.HTTPClient.Transport)
+		if knownFiles {
+			shared.KnownFiles = files.New(httpclient, options.Options.KnownFiles, options.Options.KnownFilesList)
+		}
+		if options.Options.RespectRobots {
+			shared.Robots = robots.NewChecker(httpclient)
+		}
+		if options.Options.Soft404 {
+			shared.Soft404 = soft404.New(shared.newSoft404Probe(httpclient))
+		}
+	}
+
+	if options.Options.MethodDiscovery {
+		shared.Methods = NewMethodProber()
+	}
+	if options.Options.CORSProbe {
+		shared.CORS = NewCORSProber()
 	}
 
 	// create an empty cookie jar, this is used to store cookies during the crawl
@@ -82,14 +121,17 @@ func NewShared(options *types.CrawlerOptions) (*Shared, error) {
 // Out-of-scope URLs are sent to output if DisplayOutScope is enabled.
 func (s *Shared) Enqueue(queue *queue.Queue, navigationRequests ...*navigation.Request) {
 	for _, nr := range navigationRequests {
+		nr.URL = s.Options.HostMap.Apply(nr.URL)
 		if nr.URL == "" || !utils.IsURL(nr.URL) {
 			if s.Options.Options.OnSkipURL != nil {
 				s.Options.Options.OnSkipURL(nr.URL)
 			}
 			continue
 		}
+		s.Options.DomainInventory.Record(nr.RootHostname, nr.URL, nr.Tag)
 
-		reqUrl := nr.RequestURL()
+		// internationalized hosts are deduplicated in their punycode form
+		reqUrl := idn.ASCIIURL(nr.RequestURL())
 		if s.Options.Options.IgnoreQueryParams {
 			reqUrl = utils.ReplaceAllQueryParam(reqUrl, "")
 		}
@@ -123,6 +165,10 @@ func (s *Shared) Enqueue(queue *queue.Queue, navigationRequests ...*navigation.R
 			}
 			continue
 		}
+		// auto-filled forms are only submitted within the form budget
+		if nr.Tag == "form" && !s.Options.FormBudget.Allow(requestHost(nr.URL), formbudget.Key(nr.Method, nr.URL)) {
+			continue
+		}
 
 		queue.Push(nr, nr.Depth)
 
@@ -156,6 +202,7 @@ func (s *Shared) Enqueue(queue *queue.Queue, navigationRequests ...*navigation.R
 					RootHostname: nr.RootHostname,
 					Source:       nr.Source,
 					Tag:          "path-climb",
+					SourceChain:  nr.SourceChain,
 				}
 				queue.Push(parentReq, parentDepth)
 			}
@@ -176,6 +223,21 @@ func (s *Shared) ValidateScope(URL string, root string) bool {
 	return err == nil && scopeValidated
 }
 
+// ValidateHost checks whether the host of a URL is within the host scope
+// of the root hostname, the url patterns of the scope are not matched.
+// URLs without a host are always valid.
+func (s *Shared) ValidateHost(URL string, root string) bool {
+	parsed, err := urlutil.Parse(URL)
+	if err != nil {
+		return false
+	}
+	if parsed.Hostname() == "" {
+		return true
+	}
+	validated, err := s.Options.ScopeManager.ValidateHost(parsed.Hostname(), root)
+	return err == nil && validated
+}
+
 // Output writes a crawl result to the configured output writer.
 // It creates a Result object containing the navigation request, response (if any),
 // and error information (if any), then writes it to the output writer.
@@ -211,11 +273,13 @@ type CrawlSession struct {
 	Queue      *queue.Queue
 	HttpClient *retryablehttp.Client
 	Browser    *rod.Browser
+	// XhrFuzzer permutes the parameters of captured xhr endpoints
+	XhrFuzzer *fuzzlite.Fuzzer
 }
 
 // NewCrawlSessionWithURL creates and initializes a new crawl session for the specified URL.
 // It performs the following initialization steps:
-//  1. Creates a context with optional timeout based on CrawlDuration setting
+//  1. Creates a context with optional timeout based on CrawlDuration and CrawlBudget settings
 //  2. Parses the target URL and extracts the hostname
 //  3. Initializes the request queue with the configured strategy
 //  4. Enqueues the initial URL and any known files for the target
@@ -223,10 +287,14 @@ type CrawlSession struct {
 //
 // Returns the initialized CrawlSession or an error if initialization fails.
 func (s *Shared) NewCrawlSessionWithURL(URL string) (*CrawlSession, error) {
+	timeout, expired := s.Options.CrawlTimeout()
+	if expired {
+		return nil, ErrCrawlBudgetExhausted
+	}
 	ctx, cancel := context.WithCancel(context.Background())
-	if s.Options.Options.CrawlDuration.Seconds() > 0 {
+	if timeout > 0 {
 		//nolint
-		ctx, cancel = context.WithTimeout(ctx, s.Options.Options.CrawlDuration)
+		ctx, cancel = context.WithTimeout(ctx, timeout)
 	}
 
 	parsed, err := urlutil.Parse(URL)
@@ -243,6 +311,18 @@ func (s *Shared) NewCrawlSessionWithURL(URL string) (*CrawlSession, error) {
 	}
 	queue.Push(&navigation.Request{Method: http.MethodGet, URL: URL, Depth: 0, SkipValidation: true}, 0)
 
+	// imported requests are enqueued for every target they are in scope for.
+	// Scope is checked before enqueueing so that seeds of other targets are
+	// not marked as seen by the unique filter of this session.
+	for _, seed := range s.Options.Seeds {
+		if !s.ValidateScope(seed.URL, hostname) {
+			continue
+		}
+		seedRequest := *seed
+		seedRequest.RootHostname = hostname
+		s.Enqueue(queue, &seedRequest)
+	}
+
 	if s.KnownFiles != nil {
 		navigationRequests, err := s.KnownFiles.Request(URL)
 		if err != nil {
@@ -268,6 +348,7 @@ func (s *Shared) NewCrawlSessionWithURL(URL string) (*CrawlSession, error) {
 			StatusCode:    resp.StatusCode,
 			Headers:       utils.FlattenHeaders(resp.Header),
 			KnowledgeBase: s.Options.ClassifyPage(string(body)),
+			KeepFragment:  s.Options.Options.KeepFragments,
 		}
 		navigationRequests := s.Options.Parser.ParseResponse(navigationResponse)
 		s.Enqueue(queue, navigationRequests...)
@@ -276,9 +357,19 @@ func (s *Shared) NewCrawlSessionWithURL(URL string) (*CrawlSession, error) {
 		cancel()
 		return nil, errkit.Wrap(err, "could not create http client")
 	}
+	// blocked hosts are rotated through the configured proxies
+	if s.Options.BlockTracker != nil {
+		if transport, ok := httpclient.HTTPClient.Transport.(*http.Transport); ok {
+			transport.Proxy = s.Options.BlockTracker.Proxy(transport.Proxy)
+		}
+	}
+	untrack := s.trackQueue(URL, queue)
 	crawlSession := &CrawlSession{
-		Ctx:        ctx,
-		CancelFunc: cancel,
+		Ctx: ctx,
+		CancelFunc: func() {
+			untrack()
+			cancel()
+		},
 		URL:        parsed.URL,
 		Hostname:   hostname,
 		Queue:      queue,
@@ -287,12 +378,65 @@ func (s *Shared) NewCrawlSessionWithURL(URL string) (*CrawlSession, error) {
 	return crawlSession, nil
 }
 
+// recordBlock records whether the response is a block page cooling
+// the host down when it was blocked too many times in a row
+func (s *Shared) recordBlock(host string, req *navigation.Request, resp *navigation.Response) {
+	protection, blocked := blockdetect.Detect(resp.StatusCode, resp.Headers, resp.Body)
+	if blocked {
+		_ = s.Options.OutputWriter.WriteErr(&output.Error{
+			Timestamp: time.Now(),
+			Endpoint:  req.RequestURL(),
+			Source:    req.Source,
+			Error:     fmt.Sprintf("request blocked by %s", protection),
+			Class:     output.ErrorClassBlocked,
+		})
+	}
+	if s.Options.BlockTracker.Record(host, protection, blocked) {
+		gologger.Warning().Msgf("Host %s is blocked by %s, cooling down before resuming", host, protection)
+	}
+}
+
+// RobotsAllowed returns true if the robots.txt of the host allows the
+// request waiting for its crawl-delay. Disallowed requests are written
+// to output without being requested.
+func (s *Shared) RobotsAllowed(crawlSession *CrawlSession, req *navigation.Request) bool {
+	if s.Robots == nil {
+		return true
+	}
+	if !s.Robots.Allowed(req.URL) {
+		gologger.Debug().Msgf("`%v` disallowed by robots.txt. skipping", req.URL)
+		s.Output(req, nil, ErrRobotsDisallowed)
+		return false
+	}
+	return s.Robots.Wait(crawlSession.Ctx, req.URL) == nil
+}
+
+func requestHost(URL string) string {
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
 // DoRequestFunc is a function type for executing navigation requests.
 // Implementations should perform the actual HTTP request or browser navigation
 // and return the response or an error. This allows different crawling strategies
 // (standard HTTP vs. headless browser) to provide their own request logic.
 type DoRequestFunc func(crawlSession *CrawlSession, req *navigation.Request) (*navigation.Response, error)
 
+// isFailedResponse returns true if the request failed or the server
+// signaled that it is overloaded
+func isFailedResponse(resp *navigation.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if resp == nil || resp.Resp == nil {
+		return false
+	}
+	return resp.Resp.StatusCode == http.StatusTooManyRequests || resp.Resp.StatusCode >= http.StatusInternalServerError
+}
+
 // Do executes the main crawling loop for the given crawl session.
 // It processes items from the queue concurrently (respecting the Concurrency limit),
 // validates each request (URL format, path filters, scope), applies rate limiting
@@ -329,6 +473,13 @@ func (s *Shared) Do(crawlSession *CrawlSession, doRequest DoRequestFunc) error {
 		inScope, scopeErr := s.Options.ValidateScope(req.URL, crawlSession.Hostname)
 		if scopeErr != nil {
 			gologger.Debug().Msgf("Error validating scope for `%v`: %v. skipping", req.URL, scopeErr)
+			_ = s.Options.OutputWriter.WriteErr(&output.Error{
+				Timestamp: time.Now(),
+				Endpoint:  req.RequestURL(),
+				Source:    req.Source,
+				Error:     scopeErr.Error(),
+				Class:     output.ErrorClassScope,
+			})
 			continue
 		}
 		if !req.SkipValidation && !inScope {
@@ -341,16 +492,48 @@ func (s *Shared) Do(crawlSession *CrawlSession, doRequest DoRequestFunc) error {
 		go func() {
 			defer wg.Done()
 
-			s.Options.RateLimit.Take()
+			if !s.RobotsAllowed(crawlSession, req) {
+				return
+			}
+
+			s.Options.RateLimit.TakeURL(req.URL)
 
 			// Delay if the user has asked for it
 			if s.Options.Options.Delay > 0 {
 				time.Sleep(time.Duration(s.Options.Options.Delay) * time.Second)
 			}
 
+			host := requestHost(req.URL)
+			if s.Options.BlockTracker != nil {
+				if err := s.Options.BlockTracker.Wait(crawlSession.Ctx, host); err != nil {
+					return
+				}
+			}
+
+			if s.Options.AdaptiveConcurrency != nil {
+				if err := s.Options.AdaptiveConcurrency.Acquire(crawlSession.Ctx, host); err != nil {
+					return
+				}
+			}
+
+			debugserver.Requests.Add(1)
+			debugserver.InFlight.Add(1)
+			started := time.Now()
 			resp, err := doRequest(crawlSession, req)
+			debugserver.InFlight.Add(-1)
+			if s.Options.AdaptiveConcurrency != nil {
+				s.Options.AdaptiveConcurrency.Release(host, time.Since(started), isFailedResponse(resp, err))
+			}
+			if s.Options.BlockTracker != nil && err == nil && resp != nil && resp.Resp != nil {
+				s.recordBlock(host, req, resp)
+			}
 
 			if inScope {
+				if err == nil {
+					s.DiscoverMethods(crawlSession, req, resp)
+					s.ProbeCORS(crawlSession, req, resp)
+					s.MarkSoft404(req, resp)
+				}
 				s.Output(req, resp, err)
 			}
 
@@ -361,6 +544,7 @@ func (s *Shared) Do(crawlSession *CrawlSession, doRequest DoRequestFunc) error {
 					Endpoint:  req.RequestURL(),
 					Source:    req.Source,
 					Error:     err.Error(),
+					Class:     output.ClassifyError(err),
 				}
 				_ = s.Options.OutputWriter.WriteErr(outputError)
 				return
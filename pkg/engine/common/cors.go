@@ -0,0 +1,111 @@
+package common
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+// CORSProbeOrigin is the crafted origin sent to api endpoints to
+// check whether they reflect arbitrary origins
+const CORSProbeOrigin = "https://katana-cors-probe.com"
+
+// CORSProber probes the cross-origin policy of api endpoints
+type CORSProber struct {
+	mu        sync.Mutex
+	endpoints map[string]*navigation.CORSPolicy
+}
+
+// NewCORSProber creates a new cors prober
+func NewCORSProber() *CORSProber {
+	return &CORSProber{endpoints: make(map[string]*navigation.CORSPolicy)}
+}
+
+// ProbeCORS records the cross-origin policy of the endpoint of an api
+// response, the endpoint is requested with a crafted Origin once per path.
+func (s *Shared) ProbeCORS(crawlSession *CrawlSession, req *navigation.Request, resp *navigation.Response) {
+	if s.CORS == nil || resp == nil || resp.Resp == nil || !isAPIEndpoint(req, resp) {
+		return
+	}
+	endpoint := methodsEndpoint(req.URL)
+	if endpoint == "" {
+		return
+	}
+
+	s.CORS.mu.Lock()
+	policy, probed := s.CORS.endpoints[endpoint]
+	if !probed {
+		s.CORS.endpoints[endpoint] = nil
+	}
+	s.CORS.mu.Unlock()
+	if !probed {
+		policy = s.probeCORS(crawlSession, endpoint)
+		s.CORS.mu.Lock()
+		s.CORS.endpoints[endpoint] = policy
+		s.CORS.mu.Unlock()
+	}
+	resp.CORS = policy
+}
+
+// probeCORS requests the endpoint with the crafted origin, servers which
+// only answer preflight requests with cors headers are requested again
+// with an OPTIONS preflight.
+func (s *Shared) probeCORS(crawlSession *CrawlSession, endpoint string) *navigation.CORSPolicy {
+	for _, method := range []string{http.MethodGet, http.MethodOptions} {
+		header, err := s.requestCORS(crawlSession, method, endpoint)
+		if err != nil {
+			gologger.Debug().Msgf("Could not request cors policy of %s: %s", endpoint, err)
+			return nil
+		}
+		if policy := ParseCORSPolicy(header, CORSProbeOrigin); policy != nil {
+			if policy.Reflected || policy.Wildcard {
+				gologger.Debug().Msgf("Permissive cors policy on %s: %s (credentials: %t)", endpoint, policy.AllowOrigin, policy.AllowCredentials)
+			}
+			return policy
+		}
+	}
+	return nil
+}
+
+func (s *Shared) requestCORS(crawlSession *CrawlSession, method, endpoint string) (http.Header, error) {
+	req, err := retryablehttp.NewRequestWithContext(crawlSession.Ctx, method, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", utils.WebUserAgent())
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Origin", CORSProbeOrigin)
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	}
+
+	s.Options.RateLimit.TakeURL(endpoint)
+	resp, err := crawlSession.HttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	return resp.Header, nil
+}
+
+// ParseCORSPolicy returns the cross-origin policy of a response to a
+// request sent with origin, or nil if the response has no cors headers
+func ParseCORSPolicy(header http.Header, origin string) *navigation.CORSPolicy {
+	allowOrigin := strings.TrimSpace(header.Get("Access-Control-Allow-Origin"))
+	if allowOrigin == "" {
+		return nil
+	}
+	return &navigation.CORSPolicy{
+		AllowOrigin:      allowOrigin,
+		AllowCredentials: strings.EqualFold(strings.TrimSpace(header.Get("Access-Control-Allow-Credentials")), "true"),
+		Reflected:        strings.EqualFold(allowOrigin, origin),
+		Wildcard:         allowOrigin == "*",
+	}
+}
@@ -0,0 +1,30 @@
+package common
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCORSPolicy(t *testing.T) {
+	header := http.Header{}
+	header.Set("Access-Control-Allow-Origin", CORSProbeOrigin)
+	header.Set("Access-Control-Allow-Credentials", "true")
+	policy := ParseCORSPolicy(header, CORSProbeOrigin)
+	require.NotNil(t, policy)
+	require.True(t, policy.Reflected, "crafted origin should be reported as reflected")
+	require.True(t, policy.AllowCredentials)
+	require.False(t, policy.Wildcard)
+
+	policy = ParseCORSPolicy(http.Header{"Access-Control-Allow-Origin": []string{"*"}}, CORSProbeOrigin)
+	require.NotNil(t, policy)
+	require.True(t, policy.Wildcard)
+	require.False(t, policy.Reflected)
+
+	policy = ParseCORSPolicy(http.Header{"Access-Control-Allow-Origin": []string{"https://app.example.com"}}, CORSProbeOrigin)
+	require.NotNil(t, policy)
+	require.False(t, policy.Reflected || policy.Wildcard, "fixed origins are not permissive")
+
+	require.Nil(t, ParseCORSPolicy(http.Header{}, CORSProbeOrigin))
+}
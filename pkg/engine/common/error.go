@@ -3,3 +3,7 @@ package common
 import "errors"
 
 var ErrOutOfScope = errors.New("out of scope")
+
+var ErrRobotsDisallowed = errors.New("robots-disallowed")
+
+var ErrCrawlBudgetExhausted = errors.New("crawl budget exhausted")
@@ -0,0 +1,88 @@
+package common
+
+import (
+	"sort"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/utils/queue"
+)
+
+// FrontierEntry is a request waiting in the crawl queue of a target
+type FrontierEntry struct {
+	Target string `json:"target"`
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+	Depth  int    `json:"depth"`
+	// Source is the url the request was discovered on
+	Source    string `json:"source,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
+}
+
+// trackQueue records the queue of a running crawl session of target
+// returning a function removing it once the session is done
+func (s *Shared) trackQueue(target string, crawlQueue *queue.Queue) func() {
+	s.queuesMu.Lock()
+	if s.queues == nil {
+		s.queues = make(map[string]*queue.Queue)
+	}
+	s.queues[target] = crawlQueue
+	s.queuesMu.Unlock()
+
+	return func() {
+		s.queuesMu.Lock()
+		if s.queues[target] == crawlQueue {
+			delete(s.queues, target)
+		}
+		s.queuesMu.Unlock()
+	}
+}
+
+// Frontier returns the requests waiting in the queues of the running
+// crawl sessions, per target in the order they will be requested.
+func (s *Shared) Frontier() []FrontierEntry {
+	s.queuesMu.Lock()
+	targets := make([]string, 0, len(s.queues))
+	queues := make(map[string]*queue.Queue, len(s.queues))
+	for target, crawlQueue := range s.queues {
+		targets = append(targets, target)
+		queues[target] = crawlQueue
+	}
+	s.queuesMu.Unlock()
+	sort.Strings(targets)
+
+	var entries []FrontierEntry
+	for _, target := range targets {
+		for _, item := range queues[target].Snapshot() {
+			req, ok := item.(*navigation.Request)
+			if !ok {
+				continue
+			}
+			entries = append(entries, FrontierEntry{
+				Target:    target,
+				Method:    req.Method,
+				URL:       req.URL,
+				Body:      req.Body,
+				Depth:     req.Depth,
+				Source:    req.Source,
+				Tag:       req.Tag,
+				Attribute: req.Attribute,
+			})
+		}
+	}
+	return entries
+}
+
+// QueueSize returns the number of requests waiting in the
+// queues of the running crawl sessions
+func (s *Shared) QueueSize() int {
+	s.queuesMu.Lock()
+	defer s.queuesMu.Unlock()
+
+	var size int
+	for _, crawlQueue := range s.queues {
+		size += crawlQueue.Len()
+	}
+	return size
+}
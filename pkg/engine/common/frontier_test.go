@@ -0,0 +1,37 @@
+package common
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/utils/queue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrontier(t *testing.T) {
+	shared := &Shared{}
+	crawlQueue, err := queue.New("breadth-first", 1)
+	require.NoError(t, err)
+	crawlQueue.Push(&navigation.Request{Method: http.MethodGet, URL: "https://example.com/deep", Depth: 2, Source: "https://example.com/a"}, 2)
+	crawlQueue.Push(&navigation.Request{Method: http.MethodGet, URL: "https://example.com/a", Depth: 1, Source: "https://example.com/", Tag: "a", Attribute: "href"}, 1)
+
+	untrack := shared.trackQueue("https://example.com", crawlQueue)
+	entries := shared.Frontier()
+	require.Len(t, entries, 2)
+	require.Equal(t, FrontierEntry{
+		Target:    "https://example.com",
+		Method:    http.MethodGet,
+		URL:       "https://example.com/a",
+		Depth:     1,
+		Source:    "https://example.com/",
+		Tag:       "a",
+		Attribute: "href",
+	}, entries[0], "entries should be listed in the order they are requested")
+	require.Equal(t, 2, crawlQueue.Len(), "listing the frontier should not dequeue requests")
+	require.Equal(t, 2, shared.QueueSize())
+
+	untrack()
+	require.Empty(t, shared.Frontier(), "finished sessions should not be listed")
+	require.Zero(t, shared.QueueSize(), "finished sessions should not be counted")
+}
@@ -3,9 +3,10 @@ package common
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"net"
 	"net/http"
-	"net/url"
+	"strings"
 	"time"
 
 	"github.com/projectdiscovery/fastdialer/fastdialer"
@@ -14,7 +15,6 @@ import (
 	"github.com/projectdiscovery/katana/pkg/types"
 	"github.com/projectdiscovery/retryablehttp-go"
 	"github.com/projectdiscovery/utils/errkit"
-	proxyutil "github.com/projectdiscovery/utils/proxy"
 )
 
 type RedirectCallback func(resp *http.Response, depth int)
@@ -42,34 +42,107 @@ func BuildHttpClient(dialer *fastdialer.Dialer, options *types.Options, redirect
 		DisableKeepAlives: false,
 	}
 
-	// Attempts to overwrite the dial function with the socks proxied version
-	if proxyURL, err := url.Parse(options.Proxy); options.Proxy != "" && err == nil {
-		if ok, err := proxyutil.IsBurp(options.Proxy); err == nil && ok {
-			transport.TLSClientConfig.MaxVersion = tls.VersionTLS12
+	// dual-stack hosts are connected to with the preferred ip version first
+	if options.IPVersion != "" {
+		transport.DialContext = familyDial(dialer, options.IPVersion, func(ctx context.Context, network, addr, _ string) (net.Conn, error) {
+			return dialer.Dial(ctx, network, addr)
+		})
+		transport.DialTLSContext = familyDial(dialer, options.IPVersion, func(ctx context.Context, network, addr, serverName string) (net.Conn, error) {
+			config := &tls.Config{ServerName: serverName, InsecureSkipVerify: true, MinVersion: tls.VersionTLS10}
+			if options.TlsImpersonate {
+				return dialer.DialTLSWithConfigImpersonate(ctx, network, addr, config, impersonate.Random, nil)
+			}
+			return dialer.DialTLSWithConfig(ctx, network, addr, config)
+		})
+	}
+
+	// Attempts to overwrite the dial function with the proxied version
+	if options.Proxy != "" {
+		if err := configureProxy(transport, options.Proxy); err != nil {
+			return nil, nil, err
 		}
-		transport.Proxy = http.ProxyURL(proxyURL)
 	}
 
 	client := retryablehttp.NewWithHTTPClient(&http.Client{
-		Transport: transport,
-		Timeout:   time.Duration(options.Timeout) * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if options.DisableRedirects {
-				return http.ErrUseLastResponse
-			}
-			if len(via) == 10 {
-				return errkit.New("stopped after 10 redirects")
-			}
-			depth, ok := req.Context().Value(navigation.Depth{}).(int)
-			if !ok {
-				depth = 2
-			}
-			if redirectCallback != nil {
-				redirectCallback(req.Response, depth)
-			}
-			return nil
-		},
+		Transport:     transport,
+		Timeout:       time.Duration(options.Timeout) * time.Second,
+		CheckRedirect: checkRedirect(options, redirectCallback),
 	}, retryablehttpOptions)
 	client.CheckRetry = retryablehttp.HostSprayRetryPolicy()
 	return client, dialer, nil
 }
+
+// checkRedirect returns the redirect policy of the http client
+func checkRedirect(options *types.Options, redirectCallback RedirectCallback) func(req *http.Request, via []*http.Request) error {
+	maxRedirects := options.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = 10
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if options.DisableRedirects {
+			return http.ErrUseLastResponse
+		}
+		// cross-host redirects are returned so that their location is
+		// enqueued as a discovered url and validated against the scope
+		if options.RedirectScope && !strings.EqualFold(req.URL.Hostname(), via[0].URL.Hostname()) {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= maxRedirects {
+			return errkit.New(fmt.Sprintf("stopped after %d redirects", maxRedirects))
+		}
+		depth, ok := req.Context().Value(navigation.Depth{}).(int)
+		if !ok {
+			depth = 2
+		}
+		if redirectCallback != nil {
+			redirectCallback(req.Response, depth)
+		}
+		return nil
+	}
+}
+
+// hostDialFunc dials an address keeping the original hostname for tls verification
+type hostDialFunc func(ctx context.Context, network, addr, serverName string) (net.Conn, error)
+
+// familyDial returns a dial function connecting to the resolved addresses
+// of the preferred ip version first, falling back to the other version.
+func familyDial(dialer *fastdialer.Dialer, ipVersion string, dial hostDialFunc) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr, host)
+		}
+		dnsData, err := dialer.GetDNSData(host)
+		if err != nil || dnsData == nil {
+			return dial(ctx, network, addr, host)
+		}
+		addresses := orderByFamily(dnsData.A, dnsData.AAAA, ipVersion)
+		if len(addresses) == 0 {
+			return dial(ctx, network, addr, host)
+		}
+
+		var lastErr error
+		for _, address := range addresses {
+			conn, err := dial(ctx, network, net.JoinHostPort(address, port), host)
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, lastErr
+	}
+}
+
+// orderByFamily returns the addresses of the preferred ip version first
+func orderByFamily(v4, v6 []string, ipVersion string) []string {
+	preferred, fallback := v4, v6
+	if ipVersion == "6" {
+		preferred, fallback = v6, v4
+	}
+	addresses := make([]string, 0, len(preferred)+len(fallback))
+	addresses = append(addresses, preferred...)
+	return append(addresses, fallback...)
+}
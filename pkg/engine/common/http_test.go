@@ -0,0 +1,73 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRedirect(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("other"))
+	}))
+	defer other.Close()
+	// the other server is reached through another hostname
+	otherURL := strings.Replace(other.URL, "127.0.0.1", "localhost", 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusFound)
+		case "/external":
+			http.Redirect(w, r, otherURL, http.StatusFound)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	get := func(t *testing.T, options *types.Options, path string) (*http.Response, error) {
+		client := &http.Client{CheckRedirect: checkRedirect(options, nil)}
+		resp, err := client.Get(server.URL + path)
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	t.Run("chain", func(t *testing.T) {
+		resp, err := get(t, &types.Options{}, "/a")
+		require.NoError(t, err)
+		require.Equal(t, []navigation.RedirectHop{
+			{URL: server.URL + "/a", StatusCode: http.StatusMovedPermanently},
+			{URL: server.URL + "/b", StatusCode: http.StatusFound},
+			{URL: server.URL + "/c", StatusCode: http.StatusOK},
+		}, navigation.NewRedirectChain(resp))
+
+		resp, err = get(t, &types.Options{}, "/c")
+		require.NoError(t, err)
+		require.Nil(t, navigation.NewRedirectChain(resp), "responses without redirects should have no chain")
+	})
+
+	t.Run("max-redirects", func(t *testing.T) {
+		_, err := get(t, &types.Options{MaxRedirects: 1}, "/a")
+		require.ErrorContains(t, err, "stopped after 1 redirects")
+	})
+
+	t.Run("redirect-scope", func(t *testing.T) {
+		resp, err := get(t, &types.Options{RedirectScope: true}, "/external")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusFound, resp.StatusCode, "cross-host redirects should not be followed")
+
+		resp, err = get(t, &types.Options{RedirectScope: true}, "/a")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode, "same host redirects should be followed")
+	})
+}
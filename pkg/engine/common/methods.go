@@ -0,0 +1,133 @@
+package common
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+// versionSegment matches api version path segments (eg. v1)
+var versionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// MethodProber discovers the http methods supported by api endpoints
+type MethodProber struct {
+	mu        sync.Mutex
+	endpoints map[string][]string
+}
+
+// NewMethodProber creates a new method prober
+func NewMethodProber() *MethodProber {
+	return &MethodProber{endpoints: make(map[string][]string)}
+}
+
+// DiscoverMethods records the methods supported by the endpoint of an
+// api response, the endpoint is requested with OPTIONS once per path.
+func (s *Shared) DiscoverMethods(crawlSession *CrawlSession, req *navigation.Request, resp *navigation.Response) {
+	if s.Methods == nil || resp == nil || resp.Resp == nil || !isAPIEndpoint(req, resp) {
+		return
+	}
+	endpoint := methodsEndpoint(req.URL)
+	if endpoint == "" {
+		return
+	}
+
+	s.Methods.mu.Lock()
+	methods, probed := s.Methods.endpoints[endpoint]
+	if !probed {
+		s.Methods.endpoints[endpoint] = nil
+	}
+	s.Methods.mu.Unlock()
+	if !probed {
+		methods = s.probeMethods(crawlSession, endpoint)
+		s.Methods.mu.Lock()
+		s.Methods.endpoints[endpoint] = methods
+		s.Methods.mu.Unlock()
+	}
+	resp.AllowedMethods = methods
+}
+
+// probeMethods requests the endpoint with OPTIONS returning its allowed methods
+func (s *Shared) probeMethods(crawlSession *CrawlSession, endpoint string) []string {
+	req, err := retryablehttp.NewRequestWithContext(crawlSession.Ctx, http.MethodOptions, endpoint, nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("User-Agent", utils.WebUserAgent())
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	// cors preflight headers make servers list the methods of the endpoint
+	// in Access-Control-Allow-Methods when they do not send Allow
+	if parsed, err := url.Parse(endpoint); err == nil {
+		req.Header.Set("Origin", parsed.Scheme+"://"+parsed.Host)
+	}
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+	s.Options.RateLimit.TakeURL(endpoint)
+	resp, err := crawlSession.HttpClient.Do(req)
+	if err != nil {
+		gologger.Debug().Msgf("Could not request methods of %s: %s", endpoint, err)
+		return nil
+	}
+	_ = resp.Body.Close()
+	return ParseAllowedMethods(resp.Header)
+}
+
+// ParseAllowedMethods returns the sorted methods of the Allow and
+// Access-Control-Allow-Methods headers of a response
+func ParseAllowedMethods(header http.Header) []string {
+	var methods []string
+	for _, name := range []string{"Allow", "Access-Control-Allow-Methods"} {
+		for _, value := range header.Values(name) {
+			for _, method := range strings.Split(value, ",") {
+				method = strings.ToUpper(strings.TrimSpace(method))
+				if method == "" || method == "*" || slices.Contains(methods, method) {
+					continue
+				}
+				methods = append(methods, method)
+			}
+		}
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+// isAPIEndpoint returns true if the response is served by an api endpoint,
+// it has a json content type or an api path segment (eg. /api/, /v2/).
+func isAPIEndpoint(req *navigation.Request, resp *navigation.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(resp.Resp.Header.Get("Content-Type"))
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		return true
+	}
+	parsed, err := url.Parse(req.URL)
+	if err != nil {
+		return false
+	}
+	for _, segment := range strings.Split(strings.ToLower(parsed.Path), "/") {
+		switch {
+		case segment == "api", segment == "rest", segment == "graphql", versionSegment.MatchString(segment):
+			return true
+		}
+	}
+	return false
+}
+
+// methodsEndpoint returns the url of the endpoint without query and fragment
+func methodsEndpoint(requestURL string) string {
+	parsed, err := url.Parse(requestURL)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return parsed.String()
+}
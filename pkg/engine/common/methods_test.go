@@ -0,0 +1,40 @@
+package common
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAllowedMethods(t *testing.T) {
+	header := http.Header{}
+	header.Add("Allow", "GET, HEAD,post")
+	header.Add("Access-Control-Allow-Methods", "PUT, GET, *")
+	require.Equal(t, []string{"GET", "HEAD", "POST", "PUT"}, ParseAllowedMethods(header))
+	require.Nil(t, ParseAllowedMethods(http.Header{}))
+}
+
+func TestIsAPIEndpoint(t *testing.T) {
+	response := func(contentType string) *navigation.Response {
+		return &navigation.Response{Resp: &http.Response{Header: http.Header{"Content-Type": []string{contentType}}}}
+	}
+	tests := []struct {
+		url         string
+		contentType string
+		want        bool
+	}{
+		{"https://example.com/users", "application/json; charset=utf-8", true},
+		{"https://example.com/users", "application/problem+json", true},
+		{"https://example.com/api/users", "text/html", true},
+		{"https://example.com/v2/users", "", true},
+		{"https://example.com/graphql", "", true},
+		{"https://example.com/about", "text/html", false},
+		{"https://example.com/apiary", "text/html", false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, isAPIEndpoint(&navigation.Request{URL: tt.url}, response(tt.contentType)), tt.url)
+	}
+	require.Equal(t, "https://example.com/api/users", methodsEndpoint("https://example.com/api/users?id=1#top"))
+}
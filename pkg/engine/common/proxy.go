@@ -0,0 +1,78 @@
+package common
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/projectdiscovery/utils/errkit"
+	proxyutil "github.com/projectdiscovery/utils/proxy"
+	"golang.org/x/net/proxy"
+)
+
+const (
+	// unixProxyScheme is an http proxy listening on a unix domain socket
+	// (eg. unix:///tmp/proxy.sock)
+	unixProxyScheme = "unix"
+	// socksUnixProxyScheme is a socks5 proxy listening on a unix domain socket
+	// (eg. socks5+unix://user:pass@/tmp/proxy.sock)
+	socksUnixProxyScheme = "socks5+unix"
+)
+
+// IsUnixProxy returns true if the proxy is reached through a unix domain socket
+func IsUnixProxy(proxyURL string) bool {
+	return strings.HasPrefix(proxyURL, unixProxyScheme+"://") || strings.HasPrefix(proxyURL, socksUnixProxyScheme+"://")
+}
+
+// configureProxy configures the transport to send requests through the proxy.
+//
+// http, https, socks5 and socks5h proxies are handled by the transport while
+// proxies listening on unix domain sockets replace the transport dialers.
+func configureProxy(transport *http.Transport, proxyURL string) error {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return errkit.Wrap(err, "could not parse proxy")
+	}
+
+	switch parsed.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		if ok, err := proxyutil.IsBurp(proxyURL); err == nil && ok {
+			transport.TLSClientConfig.MaxVersion = tls.VersionTLS12
+		}
+		transport.Proxy = http.ProxyURL(parsed)
+	case unixProxyScheme:
+		socketPath := parsed.Path
+		// the proxy host is only used to build the CONNECT and
+		// Proxy-Authorization headers, connections go to the socket
+		transport.Proxy = http.ProxyURL(&url.URL{Scheme: "http", Host: "localhost", User: parsed.User})
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	case socksUnixProxyScheme:
+		var auth *proxy.Auth
+		if parsed.User != nil {
+			password, _ := parsed.User.Password()
+			auth = &proxy.Auth{User: parsed.User.Username(), Password: password}
+		}
+		dialer, err := proxy.SOCKS5("unix", parsed.Path, auth, &net.Dialer{})
+		if err != nil {
+			return errkit.Wrap(err, "could not create socks5 dialer")
+		}
+		contextDialer, ok := dialer.(proxy.ContextDialer)
+		if !ok {
+			return errkit.New("socks5 dialer does not support contexts")
+		}
+		transport.Proxy = nil
+		transport.DialContext = contextDialer.DialContext
+		// tls is negotiated by the transport over the tunneled connection
+		transport.DialTLSContext = nil
+	default:
+		return errkit.New(fmt.Sprintf("unsupported proxy scheme %q", parsed.Scheme))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package common
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigureProxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("target"))
+	}))
+	defer target.Close()
+
+	get := func(t *testing.T, proxyURL string) string {
+		transport := &http.Transport{}
+		require.NoError(t, configureProxy(transport, proxyURL))
+		resp, err := (&http.Client{Transport: transport}).Get(target.URL)
+		require.NoError(t, err)
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	t.Run("unix", func(t *testing.T) {
+		socketPath := filepath.Join(t.TempDir(), "proxy.sock")
+		listener, err := net.Listen("unix", socketPath)
+		require.NoError(t, err)
+		defer func() {
+			_ = listener.Close()
+		}()
+
+		requests := make(chan *http.Request, 1)
+		go func() {
+			_ = http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests <- r
+				_, _ = w.Write([]byte("proxy"))
+			}))
+		}()
+
+		require.Equal(t, "proxy", get(t, "unix://user:pass@"+socketPath))
+		proxied := <-requests
+		require.Equal(t, target.URL+"/", proxied.URL.String())
+		require.Equal(t, "Basic dXNlcjpwYXNz", proxied.Header.Get("Proxy-Authorization"))
+	})
+	t.Run("socks5+unix", func(t *testing.T) {
+		socketPath := filepath.Join(t.TempDir(), "socks.sock")
+		listener, err := net.Listen("unix", socketPath)
+		require.NoError(t, err)
+		defer func() {
+			_ = listener.Close()
+		}()
+
+		credentials := make(chan string, 1)
+		go serveSocks5(listener, credentials)
+
+		require.Equal(t, "target", get(t, "socks5+unix://user:pass@"+socketPath))
+		require.Equal(t, "user:pass", <-credentials)
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		require.Error(t, configureProxy(&http.Transport{}, "ftp://127.0.0.1:21"))
+	})
+}
+
+// serveSocks5 serves a minimal socks5 proxy with username/password authentication
+func serveSocks5(listener net.Listener, credentials chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	buf := make([]byte, 512)
+	// greeting: version, methods
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+		return
+	}
+	_, _ = conn.Write([]byte{5, 2})
+
+	// username/password negotiation
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+	user := make([]byte, buf[1])
+	_, _ = io.ReadFull(conn, user)
+	_, _ = io.ReadFull(conn, buf[:1])
+	pass := make([]byte, buf[0])
+	_, _ = io.ReadFull(conn, pass)
+	credentials <- string(user) + ":" + string(pass)
+	_, _ = conn.Write([]byte{1, 0})
+
+	// connect request with an ipv4 or domain address
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+		return
+	}
+	var host string
+	switch buf[3] {
+	case 1:
+		_, _ = io.ReadFull(conn, buf[:4])
+		host = net.IP(buf[:4]).String()
+	case 3:
+		_, _ = io.ReadFull(conn, buf[:1])
+		name := make([]byte, buf[0])
+		_, _ = io.ReadFull(conn, name)
+		host = string(name)
+	default:
+		return
+	}
+	_, _ = io.ReadFull(conn, buf[:2])
+	port := binary.BigEndian.Uint16(buf[:2])
+
+	upstream, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = upstream.Close()
+	}()
+	_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go func() {
+		_, _ = io.Copy(upstream, conn)
+	}()
+	_, _ = io.Copy(conn, upstream)
+}
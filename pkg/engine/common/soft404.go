@@ -0,0 +1,52 @@
+package common
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/katana/pkg/utils/soft404"
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+// newSoft404Probe returns a probe requesting paths with the http client
+func (s *Shared) newSoft404Probe(httpclient *retryablehttp.Client) soft404.ProbeFunc {
+	return func(origin, path string) (soft404.Fingerprint, error) {
+		req, err := retryablehttp.NewRequest(http.MethodGet, origin+path, nil)
+		if err != nil {
+			return soft404.Fingerprint{}, err
+		}
+		req.Header.Set("User-Agent", utils.WebUserAgent())
+		for k, v := range s.Headers {
+			req.Header.Set(k, v)
+		}
+		for k, v := range s.Options.HeaderRules.Headers(req.Request.URL) {
+			req.Header.Set(k, v)
+		}
+		resp, err := httpclient.Do(req)
+		if err != nil {
+			return soft404.Fingerprint{}, err
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, int64(s.Options.Options.BodyReadSize)))
+		return soft404.NewFingerprint(resp.StatusCode, resp.Header.Get("Location"), string(body), path), nil
+	}
+}
+
+// MarkSoft404 marks the response as a soft 404 page if it matches
+// the response of its host to a non-existent path
+func (s *Shared) MarkSoft404(req *navigation.Request, resp *navigation.Response) {
+	if s.Soft404 == nil || resp == nil || resp.Resp == nil {
+		return
+	}
+	parsed, err := url.Parse(req.URL)
+	if err != nil || parsed.Host == "" {
+		return
+	}
+	fingerprint := soft404.NewFingerprint(resp.Resp.StatusCode, resp.Resp.Header.Get("Location"), resp.Body, parsed.Path)
+	resp.Soft404 = s.Soft404.IsSoft404(parsed.Scheme+"://"+parsed.Host, fingerprint)
+}
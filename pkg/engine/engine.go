@@ -1,6 +1,15 @@
 package engine
 
+import "github.com/projectdiscovery/katana/pkg/engine/common"
+
 type Engine interface {
 	Crawl(string) error
 	Close() error
 }
+
+// FrontierProvider is implemented by engines exposing the
+// requests waiting in the crawl queues of running crawls
+type FrontierProvider interface {
+	Frontier() []common.FrontierEntry
+	QueueSize() int
+}
@@ -0,0 +1,83 @@
+// Package authscript translates recorded browser automation scripts
+// (Selenium IDE .side projects and Playwright scripts) and yaml login
+// sequences into headless crawler actions used to authenticate before
+// a crawl starts.
+//
+// Only navigation, fill, click and wait steps are supported, other
+// steps are skipped.
+package authscript
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// ParseFile parses an authentication script returning the actions to execute.
+// Files with the .side extension are parsed as Selenium IDE projects,
+// files with the .yaml or .yml extension as yaml login sequences and
+// everything else is parsed as a Playwright script.
+func ParseFile(file string) ([]*types.Action, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errkit.Wrap(err, "authscript: could not read file")
+	}
+
+	var actions []*types.Action
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".side":
+		actions, err = parseSide(data)
+	case ".yaml", ".yml":
+		actions, err = parseYAML(data)
+	default:
+		actions, err = parsePlaywright(string(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(actions) == 0 {
+		return nil, errkit.New("authscript: no supported steps found")
+	}
+	return actions, nil
+}
+
+// elementFromSelector returns an element matching the selector.
+// Selectors prefixed with xpath= or starting with / are treated as xpath
+// expressions, everything else as css selectors.
+func elementFromSelector(selector string) *types.HTMLElement {
+	switch {
+	case strings.HasPrefix(selector, "xpath="):
+		return &types.HTMLElement{XPath: strings.TrimPrefix(selector, "xpath=")}
+	case strings.HasPrefix(selector, "/"), strings.HasPrefix(selector, "(/"):
+		return &types.HTMLElement{XPath: selector}
+	case strings.HasPrefix(selector, "css="):
+		return &types.HTMLElement{CSSSelector: strings.TrimPrefix(selector, "css=")}
+	case strings.HasPrefix(selector, "text="):
+		return textElement(strings.Trim(strings.TrimPrefix(selector, "text="), `"'`))
+	default:
+		return &types.HTMLElement{CSSSelector: selector}
+	}
+}
+
+// textElement returns an element matching clickable elements by text
+func textElement(text string) *types.HTMLElement {
+	return &types.HTMLElement{
+		XPath: fmt.Sprintf(`//*[self::a or self::button or self::input or self::label or @role="button"][normalize-space(.)=%s or @value=%s]`, xpathLiteral(text), xpathLiteral(text)),
+	}
+}
+
+// xpathLiteral quotes a string for use in an xpath expression
+func xpathLiteral(value string) string {
+	if !strings.Contains(value, `"`) {
+		return `"` + value + `"`
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	parts := strings.Split(value, `"`)
+	return `concat("` + strings.Join(parts, `", '"', "`) + `")`
+}
@@ -0,0 +1,165 @@
+package authscript
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSide(t *testing.T) {
+	project := `{
+  "url": "https://example.com",
+  "tests": [{
+    "name": "login",
+    "commands": [
+      {"command": "open", "target": "/login", "value": ""},
+      {"command": "setWindowSize", "target": "1280x720", "value": ""},
+      {"command": "type", "target": "id=username", "value": "admin"},
+      {"command": "type", "target": "name=password", "value": "secret"},
+      {"command": "click", "target": "css=button[type=submit]", "value": ""},
+      {"command": "waitForElementVisible", "target": "linkText=Logout", "value": "5000"}
+    ]
+  }]
+}`
+	file := filepath.Join(t.TempDir(), "login.side")
+	require.NoError(t, os.WriteFile(file, []byte(project), 0644))
+
+	actions, err := ParseFile(file)
+	require.NoError(t, err)
+	require.Len(t, actions, 5)
+
+	require.Equal(t, types.ActionTypeLoadURL, actions[0].Type)
+	require.Equal(t, "https://example.com/login", actions[0].Input)
+	require.Equal(t, types.ActionTypeSendKeys, actions[1].Type)
+	require.Equal(t, `[id="username"]`, actions[1].Element.CSSSelector)
+	require.Equal(t, "admin", actions[1].Input)
+	require.Equal(t, `[name="password"]`, actions[2].Element.CSSSelector)
+	require.Equal(t, types.ActionTypeLeftClick, actions[3].Type)
+	require.Equal(t, "button[type=submit]", actions[3].Element.CSSSelector)
+	require.Equal(t, types.ActionTypeWait, actions[4].Type)
+	require.Equal(t, `//a[normalize-space(.)="Logout"]`, actions[4].Element.XPath)
+}
+
+func TestParsePlaywright(t *testing.T) {
+	script := `import { test } from '@playwright/test';
+
+test('login', async ({ page }) => {
+  await page.goto('https://example.com/login');
+  await page.fill('#username', "admin");
+  await page.locator('input[name="password"]').fill('s3cr3t');
+  await page.getByText('Sign in').click();
+  await page.waitForSelector('xpath=//nav');
+  await page.waitForTimeout(500);
+  await page.screenshot({ path: 'out.png' });
+});`
+
+	actions, err := parsePlaywright(script)
+	require.NoError(t, err)
+	require.Len(t, actions, 6)
+
+	require.Equal(t, types.ActionTypeLoadURL, actions[0].Type)
+	require.Equal(t, "https://example.com/login", actions[0].Input)
+	require.Equal(t, "#username", actions[1].Element.CSSSelector)
+	require.Equal(t, "admin", actions[1].Input)
+	require.Equal(t, `input[name="password"]`, actions[2].Element.CSSSelector)
+	require.Equal(t, "s3cr3t", actions[2].Input)
+	require.Equal(t, types.ActionTypeLeftClick, actions[3].Type)
+	require.Contains(t, actions[3].Element.XPath, `"Sign in"`)
+	require.Equal(t, "//nav", actions[4].Element.XPath)
+	require.Equal(t, types.ActionTypeWait, actions[5].Type)
+	require.Equal(t, "500", actions[5].Input)
+}
+
+func TestParsePlaywrightCodegen(t *testing.T) {
+	script := `await page.goto('https://example.com/login');
+  await page.getByRole('textbox', { name: 'Username' }).fill('admin');
+  await page.getByLabel('Password').fill('s3cr3t');
+  await page.getByRole('button', { name: 'Sign in' }).click();
+  await page.waitForLoadState('networkidle');
+  await page.screenshot({ path: 'out.png' });`
+
+	actions, skipped := parsePlaywrightSteps(script)
+	require.Len(t, actions, 4)
+	require.Equal(t, []string{"screenshot"}, skipped)
+
+	require.Equal(t, types.ActionTypeSendKeys, actions[1].Type)
+	require.Contains(t, actions[1].Element.XPath, `self::textarea`)
+	require.Contains(t, actions[1].Element.XPath, `"Username"`)
+	require.Equal(t, "admin", actions[1].Input)
+	require.Equal(t, types.ActionTypeLeftClick, actions[3].Type)
+	require.Contains(t, actions[3].Element.XPath, `self::button`)
+	require.Contains(t, actions[3].Element.XPath, `"Sign in"`)
+}
+
+func TestParsePlaywrightPython(t *testing.T) {
+	script := `page.goto("https://example.com/login")
+    page.get_by_placeholder("Email").fill("admin@example.com")
+    page.get_by_role("button", name="Log in").click()
+    page.wait_for_selector("#dashboard")
+    page.wait_for_url("https://example.com/home")`
+
+	actions, skipped := parsePlaywrightSteps(script)
+	require.Empty(t, skipped)
+	require.Len(t, actions, 4)
+	require.Equal(t, `//*[@placeholder="Email"]`, actions[1].Element.XPath)
+	require.Equal(t, "admin@example.com", actions[1].Input)
+	require.Contains(t, actions[2].Element.XPath, `"Log in"`)
+	require.Equal(t, types.ActionTypeWait, actions[3].Type)
+	require.Equal(t, "#dashboard", actions[3].Element.CSSSelector)
+}
+
+func TestXPathLiteral(t *testing.T) {
+	require.Equal(t, `"it's"`, xpathLiteral(`it's`))
+	require.Equal(t, `'say "hi"'`, xpathLiteral(`say "hi"`))
+	require.Equal(t, `concat("a", '"', "b'c")`, xpathLiteral(`a"b'c`))
+}
+
+func TestParseYAML(t *testing.T) {
+	t.Setenv("KATANA_TEST_PASSWORD", "s3cr3t")
+	steps := `
+- navigate: https://example.com/login
+- fill: "#username"
+  value: admin
+- fill: "#password"
+  secret: env:KATANA_TEST_PASSWORD
+- click: text=Sign in
+- wait-for: xpath=//nav
+`
+	file := filepath.Join(t.TempDir(), "login.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(steps), 0644))
+
+	actions, err := ParseFile(file)
+	require.NoError(t, err)
+	require.Len(t, actions, 5)
+
+	require.Equal(t, types.ActionTypeLoadURL, actions[0].Type)
+	require.Equal(t, "https://example.com/login", actions[0].Input)
+	require.Equal(t, types.ActionTypeSendKeys, actions[1].Type)
+	require.Equal(t, "#username", actions[1].Element.CSSSelector)
+	require.Equal(t, "admin", actions[1].Input)
+	require.Equal(t, "s3cr3t", actions[2].Input)
+	require.Equal(t, types.ActionTypeLeftClick, actions[3].Type)
+	require.Contains(t, actions[3].Element.XPath, `"Sign in"`)
+	require.Equal(t, types.ActionTypeWait, actions[4].Type)
+	require.Equal(t, "//nav", actions[4].Element.XPath)
+
+	secretFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("hunter2\n"), 0600))
+	actions, err = parseYAML([]byte("- fill: '#password'\n  secret: file:" + secretFile))
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", actions[0].Input)
+
+	for _, invalid := range []string{
+		"- fill: '#password'\n  secret: env:KATANA_TEST_MISSING",
+		"- fill: '#password'\n  secret: vault:password",
+		"- navigate: https://example.com\n  click: '#login'",
+		"- click: '#login'\n  value: admin",
+		"- submit: '#login'",
+	} {
+		_, err := parseYAML([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,187 @@
+package authscript
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// stringArg matches a single, double or backtick quoted string argument
+const stringArg = `(?:'((?:[^'\\]|\\.)*)'|"((?:[^"\\]|\\.)*)"|` + "`([^`]*)`" + `)`
+
+var (
+	// pageCall matches the start of every step executed on the page
+	pageCall = regexp.MustCompile(`\bpage\s*\.\s*(\w+)\s*\(`)
+
+	playwrightStep = regexp.MustCompile(`^page\s*\.\s*(\w+)\s*\(\s*(?:` + stringArg + `|(\d+))(?:\s*,\s*` + stringArg + `)?`)
+	locatorStep    = regexp.MustCompile(`^page\s*\.\s*(\w+)\s*\(\s*` + stringArg + `[^)]*\)\s*\.\s*(\w+)\s*\(\s*(?:` + stringArg + `)?`)
+	roleStep       = regexp.MustCompile(`^page\s*\.\s*(?:getByRole|get_by_role)\s*\(\s*` + stringArg + `\s*(?:,\s*\{?\s*name\s*[:=]\s*` + stringArg + `[^)]*)?\)\s*\.\s*(\w+)\s*\(\s*(?:` + stringArg + `)?`)
+)
+
+// ignoredSteps are page steps which need no action as the
+// crawler already waits for pages to load after each action.
+var ignoredSteps = map[string]struct{}{
+	"waitForLoadState":  {},
+	"waitForURL":        {},
+	"waitForNavigation": {},
+	"close":             {},
+}
+
+// parsePlaywright parses the supported steps of a recorded Playwright
+// script (javascript, typescript or python) in source order. Steps
+// which are not supported are reported as warnings.
+func parsePlaywright(script string) ([]*types.Action, error) {
+	actions, skipped := parsePlaywrightSteps(script)
+	for _, step := range skipped {
+		gologger.Warning().Msgf("authscript: skipping unsupported playwright step page.%s", step)
+	}
+	return actions, nil
+}
+
+// parsePlaywrightSteps returns the actions of the supported steps
+// and the names of the steps which could not be translated.
+func parsePlaywrightSteps(script string) ([]*types.Action, []string) {
+	var (
+		actions []*types.Action
+		skipped []string
+	)
+	for _, loc := range pageCall.FindAllStringSubmatchIndex(script, -1) {
+		remaining := script[loc[0]:]
+		method := camelCase(script[loc[2]:loc[3]])
+		if _, ok := ignoredSteps[method]; ok {
+			continue
+		}
+
+		action := parsePlaywrightStep(remaining)
+		if action == nil {
+			skipped = append(skipped, method)
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions, skipped
+}
+
+// parsePlaywrightStep translates the page step at the start of script
+func parsePlaywrightStep(script string) *types.Action {
+	if match := roleStep.FindStringSubmatch(script); match != nil {
+		return roleAction(match)
+	}
+	if match := locatorStep.FindStringSubmatch(script); match != nil {
+		if action := locatorAction(match); action != nil {
+			return action
+		}
+	}
+	if match := playwrightStep.FindStringSubmatch(script); match != nil {
+		return pageAction(match)
+	}
+	return nil
+}
+
+func pageAction(match []string) *types.Action {
+	first := firstNonEmpty(match[2], match[3], match[4], match[5])
+	second := firstNonEmpty(match[6], match[7], match[8])
+
+	switch camelCase(match[1]) {
+	case "goto":
+		return &types.Action{Type: types.ActionTypeLoadURL, Input: first}
+	case "fill", "type":
+		return &types.Action{Type: types.ActionTypeSendKeys, Element: elementFromSelector(first), Input: second}
+	case "click":
+		return &types.Action{Type: types.ActionTypeLeftClick, Element: elementFromSelector(first)}
+	case "waitForSelector":
+		return &types.Action{Type: types.ActionTypeWait, Element: elementFromSelector(first)}
+	case "waitForTimeout":
+		return &types.Action{Type: types.ActionTypeWait, Input: first}
+	}
+	return nil
+}
+
+func locatorAction(match []string) *types.Action {
+	selector := firstNonEmpty(match[2], match[3], match[4])
+	value := firstNonEmpty(match[6], match[7], match[8])
+
+	var element *types.HTMLElement
+	switch camelCase(match[1]) {
+	case "locator":
+		element = elementFromSelector(selector)
+	case "getByText":
+		element = textElement(selector)
+	case "getByPlaceholder":
+		element = &types.HTMLElement{XPath: `//*[@placeholder=` + xpathLiteral(selector) + `]`}
+	case "getByLabel":
+		element = &types.HTMLElement{XPath: `//*[@aria-label=` + xpathLiteral(selector) + ` or @id=//label[normalize-space(.)=` + xpathLiteral(selector) + `]/@for]`}
+	default:
+		return nil
+	}
+	return elementAction(match[5], element, value)
+}
+
+func roleAction(match []string) *types.Action {
+	role := firstNonEmpty(match[1], match[2], match[3])
+	name := firstNonEmpty(match[4], match[5], match[6])
+	value := firstNonEmpty(match[8], match[9], match[10])
+	return elementAction(match[7], roleElement(role, name), value)
+}
+
+func elementAction(step string, element *types.HTMLElement, value string) *types.Action {
+	switch camelCase(step) {
+	case "fill", "type", "pressSequentially":
+		return &types.Action{Type: types.ActionTypeSendKeys, Element: element, Input: value}
+	case "click", "check":
+		return &types.Action{Type: types.ActionTypeLeftClick, Element: element}
+	case "waitFor":
+		return &types.Action{Type: types.ActionTypeWait, Element: element}
+	}
+	return nil
+}
+
+// roleTags are the elements having an implicit aria role
+var roleTags = map[string]string{
+	"button":   `self::button or self::input[@type="submit" or @type="button" or @type="reset"]`,
+	"link":     `self::a[@href]`,
+	"textbox":  `self::textarea or self::input[not(@type) or @type="text" or @type="email" or @type="password" or @type="search" or @type="tel" or @type="url"]`,
+	"checkbox": `self::input[@type="checkbox"]`,
+	"radio":    `self::input[@type="radio"]`,
+	"combobox": `self::select`,
+}
+
+// roleElement returns an element matching the aria role and the
+// accessible name (text, aria-label, value, placeholder or label).
+func roleElement(role, name string) *types.HTMLElement {
+	match := `@role=` + xpathLiteral(role)
+	if tags, ok := roleTags[role]; ok {
+		match += ` or ` + tags
+	}
+	xpath := `//*[` + match + `]`
+	if name != "" {
+		literal := xpathLiteral(name)
+		xpath += `[normalize-space(.)=` + literal + ` or @aria-label=` + literal + ` or @value=` + literal +
+			` or @placeholder=` + literal + ` or @id=//label[normalize-space(.)=` + literal + `]/@for]`
+	}
+	return &types.HTMLElement{XPath: xpath}
+}
+
+// camelCase converts python snake_case step names to their javascript names
+func camelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	name = strings.Join(parts, "")
+	// python uses wait_for_url for waitForURL
+	return strings.Replace(name, "ForUrl", "ForURL", 1)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package authscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+type sideProject struct {
+	URL   string     `json:"url"`
+	Tests []sideTest `json:"tests"`
+}
+
+type sideTest struct {
+	Name     string        `json:"name"`
+	Commands []sideCommand `json:"commands"`
+}
+
+type sideCommand struct {
+	Command string `json:"command"`
+	Target  string `json:"target"`
+	Value   string `json:"value"`
+}
+
+// parseSide parses the first test of a Selenium IDE project
+func parseSide(data []byte) ([]*types.Action, error) {
+	var project sideProject
+	if err := json.Unmarshal(data, &project); err != nil {
+		return nil, errkit.Wrap(err, "authscript: could not decode side project")
+	}
+	if len(project.Tests) == 0 {
+		return nil, errkit.New("authscript: side project has no tests")
+	}
+
+	var actions []*types.Action
+	for _, command := range project.Tests[0].Commands {
+		switch command.Command {
+		case "open":
+			actions = append(actions, &types.Action{
+				Type:  types.ActionTypeLoadURL,
+				Input: resolveSideURL(project.URL, command.Target),
+			})
+		case "type", "sendKeys":
+			actions = append(actions, &types.Action{
+				Type:    types.ActionTypeSendKeys,
+				Element: sideElement(command.Target),
+				Input:   command.Value,
+			})
+		case "click", "clickAt", "submit":
+			actions = append(actions, &types.Action{
+				Type:    types.ActionTypeLeftClick,
+				Element: sideElement(command.Target),
+			})
+		case "waitForElementPresent", "waitForElementVisible":
+			actions = append(actions, &types.Action{
+				Type:    types.ActionTypeWait,
+				Element: sideElement(command.Target),
+			})
+		case "pause":
+			actions = append(actions, &types.Action{
+				Type:  types.ActionTypeWait,
+				Input: command.Target,
+			})
+		}
+	}
+	return actions, nil
+}
+
+// sideElement converts a Selenium IDE locator to an element
+func sideElement(locator string) *types.HTMLElement {
+	strategy, value, found := strings.Cut(locator, "=")
+	if !found {
+		return elementFromSelector(locator)
+	}
+	switch strategy {
+	case "id":
+		return &types.HTMLElement{CSSSelector: fmt.Sprintf(`[id=%q]`, value)}
+	case "name":
+		return &types.HTMLElement{CSSSelector: fmt.Sprintf(`[name=%q]`, value)}
+	case "css":
+		return &types.HTMLElement{CSSSelector: value}
+	case "xpath":
+		return &types.HTMLElement{XPath: value}
+	case "linkText":
+		return &types.HTMLElement{XPath: fmt.Sprintf(`//a[normalize-space(.)=%s]`, xpathLiteral(value))}
+	case "partialLinkText":
+		return &types.HTMLElement{XPath: fmt.Sprintf(`//a[contains(normalize-space(.), %s)]`, xpathLiteral(value))}
+	default:
+		return elementFromSelector(locator)
+	}
+}
+
+// resolveSideURL resolves an open target against the project base url
+func resolveSideURL(base, target string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil || base == "" {
+		return target
+	}
+	resolved, err := baseURL.Parse(target)
+	if err != nil {
+		return target
+	}
+	return resolved.String()
+}
@@ -0,0 +1,109 @@
+package authscript
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/projectdiscovery/utils/errkit"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlStep is a step of a yaml login sequence, each step sets one of
+// navigate, fill, click or wait-for. Fill steps type value or the
+// secret referenced by secret (env:NAME or file:PATH), eg.
+// `{fill: "#password", secret: "env:KATANA_PASSWORD"}`.
+type yamlStep struct {
+	Navigate string `yaml:"navigate"`
+	Fill     string `yaml:"fill"`
+	Value    string `yaml:"value"`
+	Secret   string `yaml:"secret"`
+	Click    string `yaml:"click"`
+	WaitFor  string `yaml:"wait-for"`
+}
+
+// parseYAML parses a yaml list of login steps
+func parseYAML(data []byte) ([]*types.Action, error) {
+	var steps []yamlStep
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&steps); err != nil && !errors.Is(err, io.EOF) {
+		return nil, errkit.Wrap(err, "authscript: could not decode yaml steps")
+	}
+
+	actions := make([]*types.Action, 0, len(steps))
+	for i, step := range steps {
+		action, err := step.action()
+		if err != nil {
+			return nil, errkit.Wrap(err, fmt.Sprintf("authscript: invalid step %d", i+1))
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+func (s yamlStep) action() (*types.Action, error) {
+	set := 0
+	for _, value := range []string{s.Navigate, s.Fill, s.Click, s.WaitFor} {
+		if value != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, errkit.New("exactly one of navigate, fill, click or wait-for must be set")
+	}
+	if s.Fill == "" && (s.Value != "" || s.Secret != "") {
+		return nil, errkit.New("value and secret are only supported by fill steps")
+	}
+
+	switch {
+	case s.Navigate != "":
+		return &types.Action{Type: types.ActionTypeLoadURL, Input: s.Navigate}, nil
+	case s.Click != "":
+		return &types.Action{Type: types.ActionTypeLeftClick, Element: elementFromSelector(s.Click)}, nil
+	case s.WaitFor != "":
+		return &types.Action{Type: types.ActionTypeWait, Element: elementFromSelector(s.WaitFor)}, nil
+	}
+
+	if s.Value != "" && s.Secret != "" {
+		return nil, errkit.New("fill steps set either value or secret")
+	}
+	value := s.Value
+	if s.Secret != "" {
+		secret, err := resolveSecret(s.Secret)
+		if err != nil {
+			return nil, err
+		}
+		value = secret
+	}
+	return &types.Action{Type: types.ActionTypeSendKeys, Element: elementFromSelector(s.Fill), Input: value}, nil
+}
+
+// resolveSecret returns the value of a secret reference, the value of an
+// environment variable (env:NAME) or the contents of a file (file:PATH)
+func resolveSecret(ref string) (string, error) {
+	scheme, name, _ := strings.Cut(ref, ":")
+	if name == "" {
+		return "", errkit.New(fmt.Sprintf("invalid secret reference %q, expected env:NAME or file:PATH", ref))
+	}
+	switch scheme {
+	case "env":
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", errkit.New(fmt.Sprintf("secret environment variable %s is not set", name))
+		}
+		return value, nil
+	case "file":
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return "", errkit.Wrap(err, "could not read secret file")
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		return "", errkit.New(fmt.Sprintf("invalid secret reference %q, expected env:NAME or file:PATH", ref))
+	}
+}
@@ -3,6 +3,7 @@ package browser
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -14,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -29,19 +31,34 @@ import (
 	"github.com/projectdiscovery/katana/pkg/navigation"
 	"github.com/projectdiscovery/katana/pkg/output"
 	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/katana/pkg/utils/headerrules"
+	"github.com/projectdiscovery/katana/pkg/utils/hostmap"
+	"github.com/projectdiscovery/katana/pkg/utils/resourceblock"
 	"github.com/rs/xid"
 )
 
 // Launcher is a high level controller to launch browsers
 // and do the execution on them.
 type Launcher struct {
-	browserPool rod.Pool[BrowserPage]
+	browserPool *pagePool
 
 	opts LauncherOptions
+	// clientCertHosts are the hosts probed for client certificate
+	// requests, nil if they are not detected
+	clientCertHosts *clientCertificateHosts
+	// clientCertClient performs the requests to the hosts requiring
+	// the configured client certificate
+	clientCertClient *http.Client
+	// remote is the connection to the remote browser, if controlled
+	remote       *rod.Browser
+	remoteCancel context.CancelFunc
 }
 
 // LauncherOptions contains options for the launcher
 type LauncherOptions struct {
+	// ControlURL is the devtools url (ws:// or http://) of a remote browser
+	// the pages are opened in instead of launching local browsers
+	ControlURL          string
 	ChromiumPath        string
 	MaxBrowsers         int
 	PageMaxTimeout      time.Duration
@@ -52,9 +69,45 @@ type LauncherOptions struct {
 	Trace               bool
 	CookieConsentBypass bool
 	ChromeUser          *user.User // optional chrome user to use
+	// ReducedMotion emulates prefers-reduced-motion and suppresses animations
+	ReducedMotion bool
+	// DeterministicSeed seeds page randomness and clock when not zero
+	DeterministicSeed int
+	// HeaderRules are headers added to the requests matching their patterns
+	HeaderRules headerrules.Rules
+	// HostMap rewrites the requests to the mapped hosts to their target,
+	// navigations are redirected so that pages load from the target
+	HostMap hostmap.Map
+	// BlockedResources are the resource types of the requests failed
+	// before being sent
+	BlockedResources resourceblock.Types
+	// ResourceTypes are the resource types of intercepted requests
+	// reported to the request callback, empty reports all of them
+	ResourceTypes ResourceTypes
+	// CrossOriginFrames walks cross-origin frames for navigations,
+	// same-origin frames are always walked
+	CrossOriginFrames bool
+	// ClientCertificate is presented to the servers requesting one,
+	// their requests fail fast instead of prompting when nil
+	ClientCertificate *tls.Certificate
+	// ClickSelectors are css or xpath selectors of elements clicked
+	// in addition to the ones found by the built-in heuristics
+	ClickSelectors []string
+	// NoClickSelectors are css or xpath selectors of elements never
+	// clicked, along with the elements inside them
+	NoClickSelectors []string
+	// MinBrowsers are kept open when idle, the pool grows up to
+	// MaxBrowsers as the actions back up (1 by default)
+	MinBrowsers int
+	// BrowserIdleTimeout is the time after which the idle browsers
+	// above MinBrowsers are closed (30s by default)
+	BrowserIdleTimeout time.Duration
 
 	ScopeValidator  ScopeValidator
 	RequestCallback func(*output.Result)
+	// HostValidator blocks the requests to the hosts it rejects when
+	// set, they are reported to RequestCallback as third-party requests
+	HostValidator ScopeValidator
 }
 
 type ScopeValidator func(string) bool
@@ -63,7 +116,24 @@ type ScopeValidator func(string) bool
 func NewLauncher(opts LauncherOptions) (*Launcher, error) {
 	l := &Launcher{
 		opts:        opts,
-		browserPool: rod.NewPool[BrowserPage](opts.MaxBrowsers),
+		browserPool: newPagePool(opts.MinBrowsers, opts.MaxBrowsers, opts.BrowserIdleTimeout),
+	}
+	if opts.ClientCertificate != nil {
+		client, err := newClientCertificateClient(opts.ClientCertificate, opts.Proxy)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create client certificate client")
+		}
+		l.clientCertClient = client
+	}
+	probe, err := newClientCertificateProber(opts.Proxy)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create client certificate probe")
+	}
+	l.clientCertHosts = newClientCertificateHosts(probe)
+	if opts.ControlURL != "" {
+		if err := l.connectRemote(opts.ControlURL); err != nil {
+			return nil, err
+		}
 	}
 
 	return l, nil
@@ -99,7 +169,10 @@ func (l *Launcher) launchBrowserWithDataDir(userDataDir string) (*rod.Browser, e
 	}
 
 	if l.opts.Proxy != "" {
-		chromeLauncher = chromeLauncher.Proxy(l.opts.Proxy)
+		// chrome bypasses proxies for loopback addresses by default which
+		// would skip the proxy for localhost targets behind an ssh tunnel
+		chromeLauncher = chromeLauncher.Proxy(l.opts.Proxy).
+			Set("proxy-bypass-list", "<-loopback>")
 	}
 
 	if l.opts.NoSandbox {
@@ -141,11 +214,8 @@ func (l *Launcher) launchBrowserWithDataDir(userDataDir string) (*rod.Browser, e
 
 // Close closes the launcher
 func (l *Launcher) Close() {
-	l.browserPool.Cleanup(func(b *BrowserPage) {
-		b.cancel()
-		b.CloseBrowserPage()
-	})
-	close(l.browserPool)
+	l.browserPool.closePages(l.browserPool.close())
+	l.closeRemote()
 }
 
 // BrowserPage is a combination of a browser and a page
@@ -154,6 +224,21 @@ type BrowserPage struct {
 	Browser     *rod.Browser
 	cancel      context.CancelFunc
 	userDataDir string
+	// websockets records the websocket traffic of the page, if reported
+	websockets *webSocketTracker
+	// popups records the pages opened by the page
+	popups *popupTracker
+	// waited is the time spent waiting for the page to load
+	// since it was last taken with TakeWaited
+	waited time.Duration
+	// documentStatus is the response status of the last document
+	// loaded in the main frame of the page
+	documentStatus atomic.Int64
+	// crashed is set when the page or its browser crashed
+	crashed atomic.Bool
+	// mutationOrigin is the id of the state the mutations
+	// of the document are observed since, if observed
+	mutationOrigin atomic.Value
 
 	launcher *Launcher
 }
@@ -189,7 +274,21 @@ var defaultWaitOptions = WaitOptions{
 //
 // This keeps fast pages fast while still succeeding on noisy, long-running SPAs.
 func (b *BrowserPage) WaitPageLoadHeurisitics() error {
+	return b.WaitPageLoadWithin(defaultWaitOptions.MaxTimeout)
+}
+
+// WaitPageLoadWithin waits for the page to load using the load heuristics
+// bounding the whole wait by maxTimeout instead of the default upper bound.
+func (b *BrowserPage) WaitPageLoadWithin(maxTimeout time.Duration) error {
+	defer b.trackWait(time.Now())
+
 	opts := defaultWaitOptions
+	if maxTimeout > 0 {
+		opts.MaxTimeout = maxTimeout
+		if opts.URLPollTimeout > maxTimeout {
+			opts.URLPollTimeout = maxTimeout
+		}
+	}
 
 	chained := b.Timeout(opts.MaxTimeout)
 
@@ -225,24 +324,43 @@ func (b *BrowserPage) WaitPageLoadHeurisitics() error {
 
 	// 4b. URL didn't change – fall back to broader heuristics.
 	_ = chained.WaitIdle(opts.IdleWait)
-	_ = b.WaitNewStable(opts.DOMStableWait)
+	_ = b.waitNewStable(opts.DOMStableWait)
 
 	return nil
 }
 
 // WaitPageLoadHeuristicsFallback provides the enhanced timeouts for complex navigation
 func (b *BrowserPage) WaitPageLoadHeuristicsFallback() error {
+	defer b.trackWait(time.Now())
+
 	chainedTimeout := b.Timeout(20 * time.Second)
 
 	_ = chainedTimeout.WaitLoad()
 	_ = chainedTimeout.WaitIdle(4 * time.Second)
-	_ = b.WaitNewStable(2 * time.Second)
+	_ = b.waitNewStable(2 * time.Second)
 
 	return nil
 }
 
 // WaitStable waits until the page is stable for d duration.
 func (p *BrowserPage) WaitNewStable(d time.Duration) error {
+	defer p.trackWait(time.Now())
+	return p.waitNewStable(d)
+}
+
+// TakeWaited returns the time spent waiting for the page to load
+// since the last call and resets it
+func (b *BrowserPage) TakeWaited() time.Duration {
+	waited := b.waited
+	b.waited = 0
+	return waited
+}
+
+func (b *BrowserPage) trackWait(start time.Time) {
+	b.waited += time.Since(start)
+}
+
+func (p *BrowserPage) waitNewStable(d time.Duration) error {
 	// Enforce an upper-bound on how long we will wait for the page to become
 	// stable. We simply reuse the heuristic window (d) and give the combined
 	// operation 2× that duration. This guarantees that callers will be
@@ -268,6 +386,14 @@ func (p *BrowserPage) WaitNewStable(d time.Duration) error {
 }
 
 func (l *Launcher) createBrowserPageFunc() (*BrowserPage, error) {
+	if l.remote != nil {
+		remoteContext, err := l.newRemoteContext()
+		if err != nil {
+			return nil, err
+		}
+		return l.newBrowserPage(remoteContext, "")
+	}
+
 	// Create unique temp userDataDir for this browser instance
 	var tempDir string
 	shouldCleanup := true
@@ -309,9 +435,22 @@ func (l *Launcher) createBrowserPageFunc() (*BrowserPage, error) {
 	if err != nil {
 		return nil, err
 	}
+	browserPage, err := l.newBrowserPage(browser, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success - cancel the deferred cleanup
+	shouldCleanup = false
+	return browserPage, nil
+}
 
+// newBrowserPage opens the pooled page in browser, the browser
+// is closed if the page could not be set up
+func (l *Launcher) newBrowserPage(browser *rod.Browser, userDataDir string) (*BrowserPage, error) {
 	page, err := browser.Page(proto.TargetCreateTarget{})
 	if err != nil {
+		_ = browser.Close()
 		return nil, errors.Wrap(err, "could not create new page")
 	}
 
@@ -337,11 +476,12 @@ func (l *Launcher) createBrowserPageFunc() (*BrowserPage, error) {
 		Browser:     browser,
 		launcher:    l,
 		cancel:      cancel,
-		userDataDir: tempDir,
+		userDataDir: userDataDir,
 	}
 	if err := browserPage.handlePageDialogBoxes(); err != nil {
 		return nil, err
 	}
+	browserPage.trackPopups(cancelCtx)
 
 	// Add stealth evasion JS
 	_, err = page.EvalOnNewDocument(stealth.JS)
@@ -352,22 +492,48 @@ func (l *Launcher) createBrowserPageFunc() (*BrowserPage, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "could not initialize javascript env")
 	}
+	if err := setupMotionEmulation(page, l.opts.ReducedMotion, l.opts.DeterministicSeed); err != nil {
+		return nil, err
+	}
+	// local browsers are launched ignoring certificate errors
+	if l.remote != nil {
+		if err := (proto.SecuritySetIgnoreCertificateErrors{Ignore: true}).Call(page); err != nil {
+			return nil, errors.Wrap(err, "could not ignore certificate errors")
+		}
+	}
 
-	// Success - cancel the deferred cleanup
 	successfulPageCreation = true
-	shouldCleanup = false
 	return browserPage, nil
 }
 
 // GetPageFromPool returns a page from the pool
 func (l *Launcher) GetPageFromPool() (*BrowserPage, error) {
-	browserPage, err := l.browserPool.Get(l.createBrowserPageFunc)
-	if err != nil {
-		return nil, err
+	// idle browsers may have crashed since they were put back, they
+	// are replaced by new browsers until every one was replaced once
+	for attempt := 0; attempt <= l.browserPool.snapshot().Max; attempt++ {
+		browserPage, err := l.browserPool.get(l.createBrowserPageFunc)
+		if err != nil {
+			return nil, err
+		}
+		if browserPage.healthy() {
+			return browserPage, nil
+		}
+		slog.Debug("Replacing crashed browser")
+		l.discardPage(browserPage)
 	}
-	// TODO: should we check if the browser is alive because sometimes it
-	// might die?
-	return browserPage, nil
+	return nil, errors.Wrap(ErrBrowserCrashed, "could not replace crashed browsers")
+}
+
+// PageAvailable returns true if a page can be taken from the pool
+// without waiting for a busy one. Browsers are only opened once the
+// queued actions outnumber the open browsers.
+func (l *Launcher) PageAvailable(queued int) bool {
+	return l.browserPool.available(queued)
+}
+
+// PoolStats returns the utilization of the browser pool
+func (l *Launcher) PoolStats() PoolStats {
+	return l.browserPool.snapshot()
 }
 
 // backoffCountSleeper returns a sleeper that uses backoff strategy but stops after max attempts.
@@ -380,19 +546,60 @@ func backoffCountSleeper(initInterval, maxInterval time.Duration, maxAttempts in
 }
 
 func (b *BrowserPage) handlePageDialogBoxes() error {
-	err := proto.FetchEnable{
-		Patterns: []*proto.FetchRequestPattern{
-			{
-				URLPattern:   "*",
-				RequestStage: proto.FetchRequestStageResponse,
-			},
+	patterns := []*proto.FetchRequestPattern{
+		{
+			URLPattern:   "*",
+			RequestStage: proto.FetchRequestStageResponse,
 		},
-	}.Call(b.Page)
+	}
+	// requests are only paused before being sent to add the headers of
+	// header rules, to rewrite the requests to mapped hosts or to block
+	// the requests to third-party hosts
+	headerRules := b.launcher.opts.HeaderRules
+	hostMap := b.launcher.opts.HostMap
+	pauseRequests := len(headerRules) > 0 || len(hostMap) > 0 || b.launcher.opts.HostValidator != nil
+	if pauseRequests {
+		patterns = append(patterns, &proto.FetchRequestPattern{
+			URLPattern:   "*",
+			RequestStage: proto.FetchRequestStageRequest,
+		})
+	}
+	// requests to servers requiring a client certificate are paused before
+	// being sent, as the browser would otherwise prompt for the certificate
+	if !pauseRequests && b.launcher.clientCertHosts != nil {
+		pattern := &proto.FetchRequestPattern{
+			URLPattern:   "https://*",
+			RequestStage: proto.FetchRequestStageRequest,
+		}
+		if b.launcher.clientCertClient == nil {
+			// only navigations are failed fast without a certificate
+			pattern.ResourceType = proto.NetworkResourceTypeDocument
+		}
+		patterns = append(patterns, pattern)
+	}
+	// blocked resources are paused before being sent to be failed
+	blockedResources := b.launcher.opts.BlockedResources
+	if !pauseRequests {
+		for _, resourceType := range blockedResources.List() {
+			patterns = append(patterns, &proto.FetchRequestPattern{
+				URLPattern:   "*",
+				ResourceType: proto.NetworkResourceType(resourceType),
+				RequestStage: proto.FetchRequestStageRequest,
+			})
+		}
+	}
+	err := proto.FetchEnable{Patterns: patterns}.Call(b.Page)
 	if err != nil {
 		return errors.Wrap(err, "could not enable fetch domain")
 	}
 
-	go b.EachEvent(
+	crashHandler, err := b.enableCrashDetection()
+	if err != nil {
+		return err
+	}
+
+	handlers := []any{
+		crashHandler,
 		func(e *proto.PageJavascriptDialogOpening) {
 			_ = proto.PageHandleJavaScriptDialog{
 				Accept:     true,
@@ -401,6 +608,17 @@ func (b *BrowserPage) handlePageDialogBoxes() error {
 		},
 
 		func(e *proto.FetchRequestPaused) {
+			if e.ResponseStatusCode == nil && e.ResponseErrorReason == "" && b.blocksThirdParty(e) {
+				b.blockThirdPartyRequest(e)
+				return
+			}
+			if e.ResponseStatusCode == nil && e.ResponseErrorReason == "" && blockedResources.Blocks(string(e.ResourceType)) {
+				_ = proto.FetchFailRequest{
+					RequestID:   e.RequestID,
+					ErrorReason: proto.NetworkErrorReasonBlockedByClient,
+				}.Call(b.Page)
+				return
+			}
 			if b.launcher.opts.CookieConsentBypass {
 				// Check if request should be blocked by cookie consent rules
 				var originStr string
@@ -416,7 +634,27 @@ func (b *BrowserPage) handlePageDialogBoxes() error {
 				}
 			}
 
-			if e.ResponseStatusCode == nil || e.ResponseErrorReason != "" || (*e.ResponseStatusCode >= 301 && *e.ResponseStatusCode <= 308) {
+			if e.ResponseStatusCode == nil && e.ResponseErrorReason == "" {
+				if rewritten, ok := hostMap.Rewrite(e.Request.URL); ok {
+					b.handleHostMapRequest(e, rewritten, headerRules.HeadersString(rewritten))
+					return
+				}
+				headers := headerRules.HeadersString(e.Request.URL)
+				if b.launcher.clientCertHosts != nil && b.launcher.clientCertHosts.Requires(e.Request.URL) {
+					b.handleClientCertificateRequest(e, headers)
+					return
+				}
+				if err := fetchContinueRequestWithHeaders(b.Page, e, headers); err != nil {
+					slog.Warn("fetchContinueRequestWithHeaders failed", "error", err)
+				}
+				return
+			}
+
+			if e.ResponseStatusCode != nil && e.ResourceType == proto.NetworkResourceTypeDocument && e.FrameID == b.FrameID {
+				b.documentStatus.Store(int64(*e.ResponseStatusCode))
+			}
+			if e.ResponseStatusCode == nil || e.ResponseErrorReason != "" || (*e.ResponseStatusCode >= 301 && *e.ResponseStatusCode <= 308) ||
+				!b.launcher.opts.ResourceTypes.Allows(e.ResourceType) {
 				if err := fetchContinueRequest(b.Page, e); err != nil {
 					slog.Warn("fetchContinueRequest failed", "error", err)
 				}
@@ -434,49 +672,62 @@ func (b *BrowserPage) handlePageDialogBoxes() error {
 				slog.Warn("fetchContinueRequest failed", "error", err)
 			}
 
-			httpreq, err := netHTTPRequestFromProto(e.Request)
-			if err != nil {
-				return
-			}
+			b.reportResponse(e, netHTTPResponseFromProto(e, body), body)
+		},
+	}
+	// websocket traffic never reaches the fetch domain so the
+	// handshakes and frames are recorded from the network events
+	if b.launcher.opts.RequestCallback != nil && b.launcher.opts.ResourceTypes.Allows(proto.NetworkResourceTypeWebSocket) {
+		b.websockets = newWebSocketTracker(b.launcher.opts.RequestCallback)
+		handlers = append(handlers, b.websockets.handlers()...)
+	}
+	go b.EachEvent(handlers...)()
+	return nil
+}
+
+// reportResponse reports the response of a paused request to the request callback
+func (b *BrowserPage) reportResponse(e *proto.FetchRequestPaused, httpresp *http.Response, body []byte) {
+	if b.launcher.opts.RequestCallback == nil {
+		return
+	}
+	httpreq, err := netHTTPRequestFromProto(e.Request)
+	if err != nil {
+		return
+	}
 
-			rawBytesRequest, _ := httputil.DumpRequestOut(httpreq, true)
+	rawBytesRequest, _ := httputil.DumpRequestOut(httpreq, true)
 
-			req := navigation.Request{
-				Method:  httpreq.Method,
-				URL:     httpreq.URL.String(),
-				Body:    e.Request.PostData,
-				Headers: utils.FlattenHeaders(httpreq.Header),
-				Raw:     string(rawBytesRequest),
-			}
+	req := navigation.Request{
+		Method:  httpreq.Method,
+		URL:     httpreq.URL.String(),
+		Body:    e.Request.PostData,
+		Headers: utils.FlattenHeaders(httpreq.Header),
+		Tag:     strings.ToLower(string(e.ResourceType)),
+		Raw:     string(rawBytesRequest),
+	}
 
-			httpresp := netHTTPResponseFromProto(e, body)
-			httpresp.Request = httpreq
+	httpresp.Request = httpreq
 
-			rawBytesResponse, _ := httputil.DumpResponse(httpresp, true)
+	rawBytesResponse, _ := httputil.DumpResponse(httpresp, true)
 
-			doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
-			if err != nil {
-				slog.Warn("could not parse response body", "error", err)
-			}
-			resp := &navigation.Response{
-				Body:          string(body),
-				StatusCode:    httpresp.StatusCode,
-				Headers:       utils.FlattenHeaders(httpresp.Header),
-				Raw:           string(rawBytesResponse),
-				ContentLength: httpresp.ContentLength,
-				Resp:          httpresp,
-				Reader:        doc,
-			}
-			if b.launcher.opts.RequestCallback != nil {
-				b.launcher.opts.RequestCallback(&output.Result{
-					Timestamp: time.Now(),
-					Request:   &req,
-					Response:  resp,
-				})
-			}
-		},
-	)()
-	return nil
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		slog.Warn("could not parse response body", "error", err)
+	}
+	resp := &navigation.Response{
+		Body:          string(body),
+		StatusCode:    httpresp.StatusCode,
+		Headers:       utils.FlattenHeaders(httpresp.Header),
+		Raw:           string(rawBytesResponse),
+		ContentLength: httpresp.ContentLength,
+		Resp:          httpresp,
+		Reader:        doc,
+	}
+	b.launcher.opts.RequestCallback(&output.Result{
+		Timestamp: time.Now(),
+		Request:   &req,
+		Response:  resp,
+	})
 }
 
 func fetchContinueRequest(page *rod.Page, e *proto.FetchRequestPaused) error {
@@ -485,6 +736,30 @@ func fetchContinueRequest(page *rod.Page, e *proto.FetchRequestPaused) error {
 	}.Call(page)
 }
 
+// fetchContinueRequestWithHeaders continues a paused request adding the headers
+func fetchContinueRequestWithHeaders(page *rod.Page, e *proto.FetchRequestPaused, headers map[string]string) error {
+	if len(headers) == 0 {
+		return fetchContinueRequest(page, e)
+	}
+	return proto.FetchContinueRequest{
+		RequestID: e.RequestID,
+		Headers:   fetchHeaderEntries(e, headers),
+	}.Call(page)
+}
+
+// fetchHeaderEntries returns the headers of a paused request with the headers added
+func fetchHeaderEntries(e *proto.FetchRequestPaused, headers map[string]string) []*proto.FetchHeaderEntry {
+	existing := make(map[string]string, len(e.Request.Headers))
+	for name, value := range e.Request.Headers {
+		existing[name] = value.Str()
+	}
+	var entries []*proto.FetchHeaderEntry
+	for name, value := range headerrules.Apply(existing, headers) {
+		entries = append(entries, &proto.FetchHeaderEntry{Name: name, Value: value})
+	}
+	return entries
+}
+
 // fetchGetResponseBody get request body.
 func fetchGetResponseBody(page *rod.Page, e *proto.FetchRequestPaused) ([]byte, error) {
 	m := proto.FetchGetResponseBody{
@@ -506,6 +781,12 @@ func fetchGetResponseBody(page *rod.Page, e *proto.FetchRequestPaused) ([]byte,
 	return bs, nil
 }
 
+// DocumentStatus returns the response status of the document loaded in
+// the main frame of the page, or 0 if it is not known.
+func (b *BrowserPage) DocumentStatus() int {
+	return int(b.documentStatus.Load())
+}
+
 func netHTTPRequestFromProto(e *proto.NetworkRequest) (*http.Request, error) {
 	req, err := http.NewRequest(e.Method, e.URL, nil)
 	if err != nil {
@@ -542,45 +823,46 @@ func (l *Launcher) PutBrowserToPool(browser *BrowserPage) {
 	// Discard pages that hit a deadline or were cancelled to avoid immediately
 	// returning a poisoned page that will fail every subsequent call.
 	if cerr := browser.Page.GetContext().Err(); cerr != nil {
-		browser.cancel()
-		browser.CloseBrowserPage()
+		l.discardPage(browser)
 		return
 	}
-	// If the browser is not connected, close it
-	if !isBrowserConnected(browser.Browser) {
-		browser.cancel()
-		browser.CloseBrowserPage()
+	// Crashed pages and pages whose browser is not connected are replaced
+	if !browser.healthy() {
+		l.discardPage(browser)
 		return
 	}
 
-	pages, err := browser.Browser.Pages()
+	targets, err := pageTargets(browser.Browser)
 	if err != nil {
-		browser.cancel()
-		browser.CloseBrowserPage()
+		browser.crashed.Store(true)
+		l.discardPage(browser)
 		return
 	}
 
 	currentPageID := browser.TargetID
-	for _, page := range pages {
-		if page.TargetID != currentPageID {
-			_ = page.Close()
+	for _, targetID := range targets {
+		if targetID != currentPageID {
+			_ = proto.TargetCloseTarget{TargetID: targetID}.Call(browser.Browser)
 		}
 	}
-	l.browserPool.Put(browser)
+	if browser.popups != nil {
+		// popups left open were closed above
+		browser.popups.take()
+	}
+	l.browserPool.put(browser)
 }
 
-func isBrowserConnected(browser *rod.Browser) bool {
-	getVersionResult, err := proto.BrowserGetVersion{}.Call(browser)
-	if err != nil {
-		return false
-	}
-	if getVersionResult == nil || getVersionResult.Product == "" {
-		return false
-	}
-	return true
+// discardPage closes a page taken from the pool, a new
+// browser is opened in its place when needed
+func (l *Launcher) discardPage(browser *BrowserPage) {
+	closeBrowserPage(browser)
+	l.browserPool.discard()
 }
 
 func (b *BrowserPage) CloseBrowserPage() {
+	if b.websockets != nil {
+		b.websockets.Flush()
+	}
 	_ = b.Close()
 	_ = b.Browser.Close()
 	if b.userDataDir != "" {
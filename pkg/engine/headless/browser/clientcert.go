@@ -0,0 +1,340 @@
+package browser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"golang.org/x/net/proxy"
+)
+
+const (
+	// ClientCertificateTag is the tag of the requests failed because
+	// their server requires a client certificate and none is configured
+	ClientCertificateTag = "client-certificate"
+	// clientCertificateProbeTimeout is the timeout of the handshakes
+	// probing whether a server requests a client certificate
+	clientCertificateProbeTimeout = 5 * time.Second
+	// maxClientCertificateBody is the maximum size of the responses
+	// fetched with the client certificate
+	maxClientCertificateBody = 10 << 20
+)
+
+// ErrClientCertificateRequired is reported for the requests to servers
+// requiring a client certificate when none is configured
+var ErrClientCertificateRequired = errors.New("server requires a client certificate")
+
+// clientCertificateHosts records the hosts whose servers request a client
+// certificate. Browsers prompt for the certificate to present to them, so
+// their requests are performed by the crawler instead or failed fast.
+type clientCertificateHosts struct {
+	probe func(addr string) bool
+
+	mu    sync.Mutex
+	hosts map[string]*clientCertificateProbe
+}
+
+type clientCertificateProbe struct {
+	once     sync.Once
+	required bool
+}
+
+func newClientCertificateHosts(probe func(addr string) bool) *clientCertificateHosts {
+	return &clientCertificateHosts{probe: probe, hosts: make(map[string]*clientCertificateProbe)}
+}
+
+// Requires returns true if the server of the https url requests a client
+// certificate. Each host is probed once, concurrent callers wait for it.
+func (c *clientCertificateHosts) Requires(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return false
+	}
+	addr := parsed.Host
+	if parsed.Port() == "" {
+		addr = net.JoinHostPort(parsed.Hostname(), "443")
+	}
+
+	c.mu.Lock()
+	probe, ok := c.hosts[addr]
+	if !ok {
+		probe = &clientCertificateProbe{}
+		c.hosts[addr] = probe
+	}
+	c.mu.Unlock()
+
+	probe.once.Do(func() {
+		probe.required = c.probe(addr)
+	})
+	return probe.required
+}
+
+// probeDialer opens the connections of the client certificate probes
+type probeDialer func(ctx context.Context, addr string) (net.Conn, error)
+
+// newClientCertificateProber returns the function probing whether servers
+// request a client certificate. The handshakes go through the proxy of the
+// browsers so that hosts only reachable through it (eg. behind an ssh
+// tunnel) are probed the way the browsers reach them.
+func newClientCertificateProber(proxyURL string) (func(addr string) bool, error) {
+	dial, err := newProbeDialer(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return func(addr string) bool {
+		return probeClientCertificate(dial, addr)
+	}, nil
+}
+
+// newProbeDialer returns a dialer connecting directly or through the
+// http, https, socks5 or socks5h proxy.
+func newProbeDialer(proxyURL string) (probeDialer, error) {
+	dialer := &net.Dialer{Timeout: clientCertificateProbeTimeout}
+	if proxyURL == "" {
+		return func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", addr)
+		}, nil
+	}
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse proxy url")
+	}
+
+	switch parsed.Scheme {
+	case "http", "https":
+		return func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialConnectProxy(ctx, dialer, parsed, addr)
+		}, nil
+	case "socks5", "socks5h":
+		socksDialer, err := proxy.FromURL(parsed, dialer)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create socks5 dialer")
+		}
+		contextDialer, ok := socksDialer.(proxy.ContextDialer)
+		if !ok {
+			return nil, errors.New("socks5 dialer does not support contexts")
+		}
+		return func(ctx context.Context, addr string) (net.Conn, error) {
+			return contextDialer.DialContext(ctx, "tcp", addr)
+		}, nil
+	}
+	return nil, errors.Errorf("client certificates can not be probed through %s proxies", parsed.Scheme)
+}
+
+// dialConnectProxy opens a tunnel to addr with a CONNECT request
+// to the http or https proxy.
+func dialConnectProxy(ctx context.Context, dialer *net.Dialer, proxyURL *url.URL, addr string) (net.Conn, error) {
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not connect to proxy")
+	}
+	if proxyURL.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname(), InsecureSkipVerify: true})
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrap(err, "could not write proxy connect request")
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrap(err, "could not read proxy connect response")
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, errors.Errorf("proxy refused to connect to %s: %s", addr, resp.Status)
+	}
+	return conn, nil
+}
+
+// probeClientCertificate returns true if the server at addr requests a
+// client certificate during the handshake. Unreachable servers are left
+// to the browser.
+func probeClientCertificate(dial probeDialer, addr string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), clientCertificateProbeTimeout)
+	defer cancel()
+
+	conn, err := dial(ctx, addr)
+	if err != nil {
+		return false
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	var requested bool
+	host, _, _ := net.SplitHostPort(addr)
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			requested = true
+			return &tls.Certificate{}, nil
+		},
+	})
+	_ = tlsConn.HandshakeContext(ctx)
+	return requested
+}
+
+// newClientCertificateClient returns the client performing the requests
+// to the servers requiring the certificate. Redirects are returned to
+// the browser which follows them itself.
+func newClientCertificateClient(certificate *tls.Certificate, proxy string) (*http.Client, error) {
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			Certificates:       []tls.Certificate{*certificate},
+			InsecureSkipVerify: true,
+		},
+		Proxy: http.ProxyFromEnvironment,
+	}
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse proxy url")
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}, nil
+}
+
+// handleClientCertificateRequest performs a paused request to a server
+// requiring a client certificate with the configured certificate, or
+// fails it reporting a tagged result if none is configured.
+func (b *BrowserPage) handleClientCertificateRequest(e *proto.FetchRequestPaused, headers map[string]string) {
+	if b.launcher.clientCertClient == nil {
+		if err := (proto.FetchFailRequest{
+			RequestID:   e.RequestID,
+			ErrorReason: proto.NetworkErrorReasonAccessDenied,
+		}).Call(b.Page); err != nil {
+			slog.Warn("fetchFailRequest failed", "error", err)
+		}
+		if b.launcher.opts.RequestCallback != nil {
+			b.launcher.opts.RequestCallback(&output.Result{
+				Timestamp: time.Now(),
+				Request: &navigation.Request{
+					Method: e.Request.Method,
+					URL:    e.Request.URL,
+					Tag:    ClientCertificateTag,
+				},
+				Error: ErrClientCertificateRequired.Error(),
+			})
+		}
+		return
+	}
+
+	httpresp, body, err := b.fetchWithClientCertificate(e, headers)
+	if err != nil {
+		slog.Debug("could not fetch request with client certificate", "url", e.Request.URL, "error", err)
+		if err := (proto.FetchFailRequest{
+			RequestID:   e.RequestID,
+			ErrorReason: proto.NetworkErrorReasonFailed,
+		}).Call(b.Page); err != nil {
+			slog.Warn("fetchFailRequest failed", "error", err)
+		}
+		return
+	}
+
+	responseHeaders := make([]*proto.FetchHeaderEntry, 0, len(httpresp.Header))
+	for name, values := range httpresp.Header {
+		for _, value := range values {
+			responseHeaders = append(responseHeaders, &proto.FetchHeaderEntry{Name: name, Value: value})
+		}
+	}
+	if err := (proto.FetchFulfillRequest{
+		RequestID:       e.RequestID,
+		ResponseCode:    httpresp.StatusCode,
+		ResponseHeaders: responseHeaders,
+		Body:            body,
+	}).Call(b.Page); err != nil {
+		slog.Warn("fetchFulfillRequest failed", "error", err)
+		return
+	}
+
+	// fulfilled requests are not paused again once responded to
+	if e.ResourceType == proto.NetworkResourceTypeDocument && e.FrameID == b.FrameID {
+		b.documentStatus.Store(int64(httpresp.StatusCode))
+	}
+	if httpresp.StatusCode >= 301 && httpresp.StatusCode <= 308 || !b.launcher.opts.ResourceTypes.Allows(e.ResourceType) {
+		return
+	}
+	httpresp.Body = io.NopCloser(bytes.NewReader(body))
+	httpresp.ContentLength = int64(len(body))
+	b.reportResponse(e, httpresp, body)
+}
+
+// fetchWithClientCertificate performs the paused request with the
+// cookies of the browser and the extra headers
+func (b *BrowserPage) fetchWithClientCertificate(e *proto.FetchRequestPaused, headers map[string]string) (*http.Response, []byte, error) {
+	httpreq, err := netHTTPRequestFromProto(e.Request)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, value := range headers {
+		httpreq.Header.Set(name, value)
+	}
+	// the body is fulfilled decoded so the encoding is left to the client
+	httpreq.Header.Del("Accept-Encoding")
+	// the cookies are only added by the browser once the request is sent
+	cookies, err := proto.NetworkGetCookies{Urls: []string{e.Request.URL}}.Call(b.Page)
+	if err == nil {
+		for _, cookie := range cookies.Cookies {
+			httpreq.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+	httpreq = httpreq.WithContext(b.GetContext())
+
+	httpresp, err := b.launcher.clientCertClient.Do(httpreq)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not perform request")
+	}
+	defer func() {
+		_ = httpresp.Body.Close()
+	}()
+	body, err := io.ReadAll(io.LimitReader(httpresp.Body, maxClientCertificateBody))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not read response body")
+	}
+	return httpresp, body, nil
+}
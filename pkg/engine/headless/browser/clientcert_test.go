@@ -0,0 +1,65 @@
+package browser
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCertificateHosts(t *testing.T) {
+	var probed []string
+	hosts := newClientCertificateHosts(func(addr string) bool {
+		probed = append(probed, addr)
+		return addr == "mtls.example.com:443"
+	})
+	require.True(t, hosts.Requires("https://mtls.example.com/login"))
+	require.True(t, hosts.Requires("https://mtls.example.com/admin"))
+	require.False(t, hosts.Requires("https://example.com:8443/"))
+	require.False(t, hosts.Requires("http://mtls.example.com/"), "plain http requests should not be probed")
+	require.Equal(t, []string{"mtls.example.com:443", "example.com:8443"}, probed, "hosts should be probed once")
+}
+
+func TestProbeClientCertificate(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	plain := httptest.NewTLSServer(handler)
+	defer plain.Close()
+	mtls := httptest.NewUnstartedServer(handler)
+	mtls.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	mtls.StartTLS()
+	defer mtls.Close()
+
+	var tunneled []string
+	connectProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodConnect, r.Method)
+		tunneled = append(tunneled, r.Host)
+		upstream, err := net.Dial("tcp", r.Host)
+		require.NoError(t, err)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go func() {
+			_, _ = io.Copy(upstream, conn)
+			_ = upstream.Close()
+		}()
+		_, _ = io.Copy(conn, upstream)
+		_ = conn.Close()
+	}))
+	defer connectProxy.Close()
+
+	for _, proxyURL := range []string{"", connectProxy.URL} {
+		probe, err := newClientCertificateProber(proxyURL)
+		require.NoError(t, err)
+		require.False(t, probe(strings.TrimPrefix(plain.URL, "https://")))
+		require.True(t, probe(strings.TrimPrefix(mtls.URL, "https://")))
+	}
+	require.Equal(t, []string{strings.TrimPrefix(plain.URL, "https://"), strings.TrimPrefix(mtls.URL, "https://")}, tunneled, "hosts should be probed through the proxy")
+
+	_, err := newClientCertificateProber("ftp://127.0.0.1:21")
+	require.Error(t, err, "unsupported proxies should fail fast")
+}
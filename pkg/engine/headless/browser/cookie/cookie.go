@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/go-rod/rod/lib/proto"
 )
@@ -31,9 +32,14 @@ type Condition struct {
 //go:embed rules.json
 var rules []byte
 
-var cookieConsentBlockRequests []CookieConsentBlockRequest
+var (
+	cookieConsentBlockRequests []CookieConsentBlockRequest
+	loadRulesOnce              sync.Once
+)
 
-func init() {
+// loadRules parses the embedded rules on first use so that importing
+// the package has no startup cost for crawls without headless mode
+func loadRules() {
 	err := json.Unmarshal(rules, &cookieConsentBlockRequests)
 	if err != nil {
 		panic(err)
@@ -46,6 +52,7 @@ func init() {
 
 // ShouldBlockRequest determines if a request should be blocked based on cookie consent rules
 func ShouldBlockRequest(url string, resourceType proto.NetworkResourceType, initiatorDomain string) bool {
+	loadRulesOnce.Do(loadRules)
 	resourceTypeStr := getResourceType(resourceType)
 	for _, rule := range cookieConsentBlockRequests {
 		if matchesRule(rule, url, resourceTypeStr, initiatorDomain) {
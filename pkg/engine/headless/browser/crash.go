@@ -0,0 +1,60 @@
+package browser
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// ErrBrowserCrashed is returned when crashed browsers could not be replaced
+var ErrBrowserCrashed = errors.New("browser crashed")
+
+// healthCheckTimeout bounds the health check of a browser, a browser
+// whose devtools socket stopped responding is considered crashed
+const healthCheckTimeout = 5 * time.Second
+
+// Crashed returns true if the page or its browser crashed, crashed
+// pages are closed and replaced when they are put back to the pool
+func (b *BrowserPage) Crashed() bool {
+	return b.crashed.Load()
+}
+
+// healthy returns true if the page did not crash and its browser responds
+func (b *BrowserPage) healthy() bool {
+	if b.crashed.Load() {
+		return false
+	}
+	if !isBrowserConnected(b.Browser) {
+		b.crashed.Store(true)
+		return false
+	}
+	return true
+}
+
+// enableCrashDetection marks the page as crashed when its renderer crashes
+func (b *BrowserPage) enableCrashDetection() (func(*proto.InspectorTargetCrashed), error) {
+	if err := (proto.InspectorEnable{}).Call(b.Page); err != nil {
+		return nil, errors.Wrap(err, "could not enable inspector domain")
+	}
+	return func(*proto.InspectorTargetCrashed) {
+		slog.Debug("Browser page crashed", slog.String("target", string(b.TargetID)))
+		b.crashed.Store(true)
+	}, nil
+}
+
+func isBrowserConnected(browser *rod.Browser) bool {
+	browser = browser.Timeout(healthCheckTimeout)
+	defer browser.CancelTimeout()
+
+	getVersionResult, err := proto.BrowserGetVersion{}.Call(browser)
+	if err != nil {
+		return false
+	}
+	if getVersionResult == nil || getVersionResult.Product == "" {
+		return false
+	}
+	return true
+}
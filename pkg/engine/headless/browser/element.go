@@ -7,6 +7,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -52,20 +53,136 @@ func isElementDisabled(element *types.HTMLElement) bool {
 //  1. Forms
 //  2. Buttons
 //  3. Links
-//  4. Elements with event listeners
+//  4. Elements matching the configured click selectors
+//  5. Elements with event listeners
+//  6. Routes declared in client-side router tables
+//  7. The navigations of the above kinds inside frames
+//
+// Elements matching the configured no click selectors are skipped.
 //
 // The navigations found are unique across the page. The caller
 // needs to ensure they are unique globally before doing further actions with details.
+//
+// The elements are extracted from the page concurrently and merged
+// in the above order.
 func (b *BrowserPage) FindNavigations() ([]*types.Action, error) {
+	return b.findNavigations(0)
+}
+
+// findNavigations finds the navigations of the document of the page
+// which is a frame nested frameDepth levels deep if not zero.
+func (b *BrowserPage) findNavigations(frameDepth int) ([]*types.Action, error) {
+	var (
+		forms          []*types.HTMLForm
+		buttons        []*types.HTMLElement
+		links          []*types.HTMLElement
+		clickables     []*types.HTMLElement
+		pageURL        string
+		baseURL        string
+		eventListeners []*types.EventListener
+		routes         []*RouterRoute
+		group          errgroup.Group
+	)
+	if err := b.applyNoClickSelectors(); err != nil {
+		return nil, errors.Wrap(err, "could not apply no click selectors")
+	}
+	group.Go(func() (err error) {
+		forms, err = b.GetAllForms()
+		return errors.Wrap(err, "could not get forms")
+	})
+	group.Go(func() (err error) {
+		buttons, err = b.GetAllElements(buttonsCSSSelector)
+		return errors.Wrap(err, "could not get buttons")
+	})
+	group.Go(func() (err error) {
+		links, err = b.GetAllElements(linksCSSSelector)
+		return errors.Wrap(err, "could not get links")
+	})
+	if selectors := b.launcher.opts.ClickSelectors; len(selectors) > 0 {
+		group.Go(func() (err error) {
+			clickables, err = b.GetElementsBySelectors(selectors)
+			return errors.Wrap(err, "could not get click selector elements")
+		})
+	}
+	group.Go(func() (err error) {
+		pageURL, err = b.documentURL(frameDepth > 0)
+		return errors.Wrap(err, "could not get page info")
+	})
+	group.Go(func() (err error) {
+		baseURL, err = b.documentBaseURL()
+		return errors.Wrap(err, "could not get base url")
+	})
+	group.Go(func() (err error) {
+		eventListeners, err = b.GetEventListeners()
+		return errors.Wrap(err, "could not get event listeners")
+	})
+	group.Go(func() (err error) {
+		routes, err = b.GetRouterRoutes()
+		return errors.Wrap(err, "could not get router routes")
+	})
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
 	unique := make(map[string]struct{})
+	navigations := b.elementNavigations(pageElements{
+		forms:          forms,
+		buttons:        buttons,
+		links:          links,
+		clickables:     clickables,
+		eventListeners: eventListeners,
+	}, baseURL, unique)
 
-	navigations := make([]*types.Action, 0)
+	scopeValidator := b.launcher.ScopeValidator()
+	for _, route := range routes {
+		routeURL, ok := resolveRoute(pageURL, route.Path)
+		if !ok || !scopeValidator(routeURL) {
+			continue
+		}
+		action := &types.Action{
+			Type:  types.ActionTypeLoadURL,
+			Input: routeURL,
+		}
+		hash := action.Hash()
+		if _, found := unique[hash]; found {
+			continue
+		}
+		unique[hash] = struct{}{}
+		navigations = append(navigations, action)
+	}
 
-	forms, err := b.GetAllForms()
-	if err != nil {
-		return nil, errors.Wrap(err, "could not get forms")
+	if frameDepth < maxFrameDepth {
+		for _, action := range b.findFrameNavigations(pageURL, frameDepth) {
+			hash := action.Hash()
+			if _, found := unique[hash]; found {
+				continue
+			}
+			unique[hash] = struct{}{}
+			navigations = append(navigations, action)
+		}
 	}
-	for _, form := range forms {
+	return navigations, nil
+}
+
+// pageElements are the elements of a document navigations are found from
+type pageElements struct {
+	forms          []*types.HTMLForm
+	buttons        []*types.HTMLElement
+	links          []*types.HTMLElement
+	eventListeners []*types.EventListener
+	// clickables are the elements matching the configured click selectors
+	clickables []*types.HTMLElement
+}
+
+// elementNavigations returns the navigations of the elements which are
+// not in unique, resolving links against baseURL, and adds them to it.
+func (b *BrowserPage) elementNavigations(elements pageElements, baseURL string, unique map[string]struct{}) []*types.Action {
+	navigations := make([]*types.Action, 0)
+
+	for _, form := range elements.forms {
+		if isNoClickForm(form) {
+			continue
+		}
 		for _, element := range form.Elements {
 			if element.TagName != "BUTTON" {
 				continue
@@ -86,12 +203,8 @@ func (b *BrowserPage) FindNavigations() ([]*types.Action, error) {
 		})
 	}
 
-	buttons, err := b.GetAllElements(buttonsCSSSelector)
-	if err != nil {
-		return nil, errors.Wrap(err, "could not get buttons")
-	}
-	for _, button := range buttons {
-		if isElementDisabled(button) {
+	for _, button := range elements.buttons {
+		if button.NoClick || isElementDisabled(button) {
 			continue
 		}
 
@@ -109,21 +222,13 @@ func (b *BrowserPage) FindNavigations() ([]*types.Action, error) {
 	}
 
 	scopeValidator := b.launcher.ScopeValidator()
-	links, err := b.GetAllElements(linksCSSSelector)
-	if err != nil {
-		return nil, errors.Wrap(err, "could not get links")
-	}
-	info, err := b.Info()
-	if err != nil {
-		return nil, errors.Wrap(err, "could not get page info")
-	}
-	for _, link := range links {
+	for _, link := range elements.links {
 		href := link.Attributes["href"]
-		if href == "" {
+		if href == "" || link.NoClick {
 			continue
 		}
 
-		resolvedHref, err := resolveURL(info.URL, href)
+		resolvedHref, err := resolveURL(baseURL, href)
 		if err != nil {
 			continue
 		}
@@ -152,27 +257,44 @@ func (b *BrowserPage) FindNavigations() ([]*types.Action, error) {
 		})
 	}
 
-	eventListeners, err := b.GetEventListeners()
-	if err != nil {
-		return nil, errors.Wrap(err, "could not get event listeners")
-	}
-	for _, listener := range eventListeners {
-		if _, found := relevantEventListeners[listener.Type]; !found {
+	// clickables are clicked even when disabled, the
+	// selectors are trusted to match what has to be clicked
+	for _, clickable := range elements.clickables {
+		if clickable.NoClick {
 			continue
 		}
-		if listener.Element == nil {
-			continue
-		}
-		hash := listener.Element.Hash()
-		listener.Element.MD5Hash = hash
+
+		hash := clickable.Hash()
+		clickable.MD5Hash = hash
+
 		if _, found := unique[hash]; found {
 			continue
 		}
 		unique[hash] = struct{}{}
-		navigations = append(navigations, types.ActionFromEventListener(listener))
+		navigations = append(navigations, &types.Action{
+			Type:    types.ActionTypeLeftClick,
+			Element: clickable,
+		})
 	}
 
-	return navigations, nil
+	for _, listener := range elements.eventListeners {
+		if _, found := relevantEventListeners[listener.Type]; !found {
+			continue
+		}
+		if listener.Element == nil || listener.Element.NoClick {
+			continue
+		}
+		listener.Element.MD5Hash = listener.Element.Hash()
+		for _, action := range types.ActionsFromEventListener(listener) {
+			hash := action.Hash()
+			if _, found := unique[hash]; found {
+				continue
+			}
+			unique[hash] = struct{}{}
+			navigations = append(navigations, action)
+		}
+	}
+	return navigations
 }
 
 func (b *BrowserPage) GetAllElements(selector string) ([]*types.HTMLElement, error) {
@@ -224,13 +346,7 @@ func (b *BrowserPage) GetEventListeners() ([]*types.EventListener, error) {
 	}
 
 	// Also get inline event listeners
-	var inlineEventListeners []struct {
-		Element   *types.HTMLElement `json:"element"`
-		Listeners []struct {
-			Type     string `json:"type"`
-			Listener string `json:"listener"`
-		} `json:"listeners"`
-	}
+	var inlineEventListeners []*inlineEventListener
 	inlineListeners, err := b.Eval(`() => window.getAllElementsWithEventListeners()`)
 	if err != nil {
 		return nil, err
@@ -240,18 +356,39 @@ func (b *BrowserPage) GetEventListeners() ([]*types.EventListener, error) {
 	}
 
 	for _, inlineListener := range inlineEventListeners {
-		for _, listener := range inlineListener.Listeners {
-			listenerType := strings.TrimPrefix(listener.Type, "on")
-			listeners = append(listeners, &types.EventListener{
-				Type:     listenerType,
-				Listener: listener.Listener,
-				Element:  inlineListener.Element,
-			})
-		}
+		listeners = append(listeners, inlineListener.eventListeners()...)
+	}
+
+	// Also get the double click and context menu listeners from the debugger
+	debuggerListeners, err := b.getDebuggerEventListeners()
+	if err == nil {
+		listeners = append(listeners, debuggerListeners...)
 	}
 	return listeners, nil
 }
 
+// inlineEventListener is an element with the inline
+// event handlers (eg. onclick) set on it
+type inlineEventListener struct {
+	Element   *types.HTMLElement `json:"element"`
+	Listeners []struct {
+		Type     string `json:"type"`
+		Listener string `json:"listener"`
+	} `json:"listeners"`
+}
+
+func (i *inlineEventListener) eventListeners() []*types.EventListener {
+	listeners := make([]*types.EventListener, 0, len(i.Listeners))
+	for _, listener := range i.Listeners {
+		listeners = append(listeners, &types.EventListener{
+			Type:     strings.TrimPrefix(listener.Type, "on"),
+			Listener: listener.Listener,
+			Element:  i.Element,
+		})
+	}
+	return listeners
+}
+
 // NavigatedLink is a link navigated collected from one of the
 // navigation hooks.
 type NavigatedLink struct {
@@ -273,6 +410,68 @@ func (b *BrowserPage) GetNavigatedLinks() ([]*NavigatedLink, error) {
 	return listeners, nil
 }
 
+// RouterRoute is a route declared in a client-side router table
+type RouterRoute struct {
+	Framework string `json:"framework"`
+	Path      string `json:"path"`
+}
+
+// GetRouterRoutes returns the routes declared in the react, vue
+// and angular router tables of the page
+func (b *BrowserPage) GetRouterRoutes() ([]*RouterRoute, error) {
+	object, err := b.Eval(`() => window.getRouterRoutes()`)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]*RouterRoute, 0)
+	if err := object.Value.Unmarshal(&routes); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// resolveRoute resolves a route path against the page url, filling
+// route parameters with a placeholder value. Routes of hash routers
+// are resolved in the fragment of the page url.
+func resolveRoute(pageURL, routePath string) (string, bool) {
+	segments := strings.Split(routePath, "/")
+	resolved := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		switch {
+		case segment == "":
+			continue
+		case strings.Contains(segment, "*"):
+			// wildcard routes match any remaining path
+			return "", false
+		case strings.HasPrefix(segment, ":"),
+			strings.HasPrefix(segment, "[") && strings.HasSuffix(segment, "]"),
+			strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			resolved = append(resolved, routeParameterValue)
+		default:
+			resolved = append(resolved, segment)
+		}
+	}
+	path := "/" + strings.Join(resolved, "/")
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", false
+	}
+	if strings.HasPrefix(base.Fragment, "/") {
+		base.Fragment = path
+		return base.String(), true
+	}
+	base.Fragment = ""
+	base.RawQuery = ""
+	base.Path = path
+	base.RawPath = ""
+	return base.String(), true
+}
+
+// routeParameterValue is the value of route parameters
+const routeParameterValue = "1"
+
 // Define the map to hold event types
 var relevantEventListeners = map[string]struct{}{
 	// Focus and Blur events
@@ -0,0 +1,28 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRoute(t *testing.T) {
+	tests := []struct {
+		pageURL  string
+		route    string
+		expected string
+		ok       bool
+	}{
+		{"https://example.com/home?tab=1", "/users", "https://example.com/users", true},
+		{"https://example.com/", "/users/:id/posts", "https://example.com/users/1/posts", true},
+		{"https://example.com/", "/blog/[slug]", "https://example.com/blog/1", true},
+		{"https://example.com/#/home", "/settings", "https://example.com/#/settings", true},
+		{"https://example.com/", "/*", "", false},
+		{"https://example.com/", "**", "", false},
+	}
+	for _, test := range tests {
+		resolved, ok := resolveRoute(test.pageURL, test.route)
+		require.Equal(t, test.ok, ok, test.route)
+		require.Equal(t, test.expected, resolved, test.route)
+	}
+}
@@ -0,0 +1,133 @@
+package browser
+
+import (
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/js"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+const (
+	// framesCSSSelector is the css selector for all frames
+	framesCSSSelector = "iframe, frame"
+	// maxFrameDepth is the maximum nesting of the walked frames
+	maxFrameDepth = 3
+)
+
+// FrameAt returns the page of the frame at path, the xpaths of the
+// frame elements starting from the top document. The page itself is
+// returned for an empty path.
+func (b *BrowserPage) FrameAt(path []string) (*BrowserPage, error) {
+	current := b
+	for _, xpath := range path {
+		element, err := current.ElementX(xpath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not find frame %s", xpath)
+		}
+		frame, err := element.Frame()
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get frame %s", xpath)
+		}
+		current = &BrowserPage{Page: frame, Browser: b.Browser, launcher: b.launcher}
+	}
+	return current, nil
+}
+
+// documentURL returns the url of the document of the page
+func (b *BrowserPage) documentURL(frame bool) (string, error) {
+	if !frame {
+		info, err := b.Info()
+		if err != nil {
+			return "", err
+		}
+		return info.URL, nil
+	}
+	result, err := b.Eval(`() => window.location.href`)
+	if err != nil {
+		return "", err
+	}
+	return result.Value.Str(), nil
+}
+
+// documentBaseURL returns the url relative links of the document of the
+// page are resolved against, which is declared by its base element if any
+func (b *BrowserPage) documentBaseURL() (string, error) {
+	result, err := b.Eval(`() => document.baseURI`)
+	if err != nil {
+		return "", err
+	}
+	return result.Value.Str(), nil
+}
+
+// findFrameNavigations returns the navigations of the frames of the
+// document at pageURL. Cross-origin frames are only walked if enabled.
+func (b *BrowserPage) findFrameNavigations(pageURL string, frameDepth int) []*types.Action {
+	frames, err := b.GetAllElements(framesCSSSelector)
+	if err != nil {
+		return nil
+	}
+	var navigations []*types.Action
+	for _, frameElement := range frames {
+		if frameElement.XPath == "" {
+			continue
+		}
+		if !b.launcher.opts.CrossOriginFrames && !isSameOriginFrame(pageURL, frameElement.Attributes["src"]) {
+			continue
+		}
+		frame, err := b.FrameAt([]string{frameElement.XPath})
+		if err != nil {
+			slog.Debug("Could not enter frame", slog.String("xpath", frameElement.XPath), slog.String("error", err.Error()))
+			continue
+		}
+		if err := js.EnsureJavascriptEnv(frame.Page); err != nil {
+			slog.Debug("Could not initialize frame", slog.String("xpath", frameElement.XPath), slog.String("error", err.Error()))
+			continue
+		}
+		frameNavigations, err := frame.findNavigations(frameDepth + 1)
+		if err != nil {
+			slog.Debug("Could not find frame navigations", slog.String("xpath", frameElement.XPath), slog.String("error", err.Error()))
+			continue
+		}
+		for _, action := range frameNavigations {
+			inFrame(action, frameElement.XPath)
+		}
+		navigations = append(navigations, frameNavigations...)
+	}
+	return navigations
+}
+
+// inFrame prefixes the frame paths of the elements of the action with
+// the xpath of the frame element they were found in
+func inFrame(action *types.Action, xpath string) {
+	if action.Element != nil {
+		action.Element.FramePath = append([]string{xpath}, action.Element.FramePath...)
+		action.Element.MD5Hash = action.Element.Hash()
+	}
+	if action.Form != nil {
+		action.Form.FramePath = append([]string{xpath}, action.Form.FramePath...)
+		for _, element := range action.Form.Elements {
+			element.FramePath = append([]string{xpath}, element.FramePath...)
+		}
+	}
+}
+
+// isSameOriginFrame returns true if the frame with src is loaded from
+// the origin of the page. Frames without a source inherit the origin.
+func isSameOriginFrame(pageURL, src string) bool {
+	src = strings.TrimSpace(src)
+	if src == "" || strings.HasPrefix(src, "about:") || strings.HasPrefix(strings.ToLower(src), "javascript:") {
+		return true
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return false
+	}
+	frameURL, err := base.Parse(src)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(frameURL.Scheme, base.Scheme) && strings.EqualFold(frameURL.Host, base.Host)
+}
@@ -0,0 +1,41 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSameOriginFrame(t *testing.T) {
+	pageURL := "https://example.com/app/index.html"
+
+	require.True(t, isSameOriginFrame(pageURL, ""), "frames without source should inherit the origin")
+	require.True(t, isSameOriginFrame(pageURL, "about:blank"))
+	require.True(t, isSameOriginFrame(pageURL, "javascript:void(0)"))
+	require.True(t, isSameOriginFrame(pageURL, "/embed/widget"))
+	require.True(t, isSameOriginFrame(pageURL, "frame.html"))
+	require.True(t, isSameOriginFrame(pageURL, "https://EXAMPLE.com/embed"))
+
+	require.False(t, isSameOriginFrame(pageURL, "https://ads.example.net/frame"))
+	require.False(t, isSameOriginFrame(pageURL, "http://example.com/embed"), "scheme should be part of the origin")
+	require.False(t, isSameOriginFrame(pageURL, "//example.com:8443/embed"), "port should be part of the origin")
+}
+
+func TestInFrame(t *testing.T) {
+	element := &types.HTMLElement{TagName: "A", XPath: "/html/body/a"}
+	element.MD5Hash = element.Hash()
+	original := element.MD5Hash
+	action := &types.Action{Type: types.ActionTypeLeftClick, Element: element}
+
+	inFrame(action, "/html/body/iframe[2]")
+	inFrame(action, "/html/body/iframe[1]")
+	require.Equal(t, []string{"/html/body/iframe[1]", "/html/body/iframe[2]"}, element.FramePath, "outer frames should come first")
+	require.NotEqual(t, original, element.MD5Hash, "elements in frames should not be deduplicated with the top document")
+
+	field := &types.HTMLElement{TagName: "INPUT", XPath: "/html/body/form/input"}
+	form := &types.Action{Type: types.ActionTypeFillForm, Form: &types.HTMLForm{Elements: []*types.HTMLElement{field}}}
+	inFrame(form, "/html/body/iframe")
+	require.Equal(t, []string{"/html/body/iframe"}, form.Form.FramePath)
+	require.Equal(t, []string{"/html/body/iframe"}, field.FramePath)
+}
@@ -0,0 +1,36 @@
+package browser
+
+// HistoryNavigation is a route change of the document made
+// with the history api (pushState, replaceState, popstate)
+type HistoryNavigation struct {
+	URL    string `json:"url"`
+	Source string `json:"source"`
+}
+
+// TakeHistoryNavigations returns the route changes of the document
+// since they were last taken
+func (b *BrowserPage) TakeHistoryNavigations() ([]*HistoryNavigation, error) {
+	result, err := b.Eval(`() => {
+		const navigations = window.__historyNavigations || [];
+		window.__historyNavigations = [];
+		return navigations;
+	}`)
+	if err != nil {
+		return nil, err
+	}
+	navigations := make([]*HistoryNavigation, 0)
+	if err := result.Value.Unmarshal(&navigations); err != nil {
+		return nil, err
+	}
+	return navigations, nil
+}
+
+// HistoryRoute returns the source of the last route change of the
+// document, or an empty string if its url is the one it was loaded with
+func (b *BrowserPage) HistoryRoute() (string, error) {
+	result, err := b.Eval(`() => window.__historyRoute || ""`)
+	if err != nil {
+		return "", err
+	}
+	return result.Value.Str(), nil
+}
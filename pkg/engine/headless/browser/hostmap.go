@@ -0,0 +1,36 @@
+package browser
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// handleHostMapRequest rewrites a paused request to a mapped host.
+// Navigations are redirected to the target so that pages are loaded
+// from it, other requests are sent to it without the page observing it.
+func (b *BrowserPage) handleHostMapRequest(e *proto.FetchRequestPaused, rewritten string, headers map[string]string) {
+	if e.ResourceType == proto.NetworkResourceTypeDocument {
+		// a temporary redirect keeps the method and body of form submissions
+		if err := (proto.FetchFulfillRequest{
+			RequestID:       e.RequestID,
+			ResponseCode:    http.StatusTemporaryRedirect,
+			ResponseHeaders: []*proto.FetchHeaderEntry{{Name: "Location", Value: rewritten}},
+		}).Call(b.Page); err != nil {
+			slog.Warn("fetchFulfillRequest failed", "error", err)
+		}
+		return
+	}
+
+	request := proto.FetchContinueRequest{
+		RequestID: e.RequestID,
+		URL:       rewritten,
+	}
+	if len(headers) > 0 {
+		request.Headers = fetchHeaderEntries(e, headers)
+	}
+	if err := request.Call(b.Page); err != nil {
+		slog.Warn("fetchContinueRequest failed", "error", err)
+	}
+}
@@ -0,0 +1,82 @@
+package browser
+
+import (
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// debuggerListenerTypes are the event listener types read from the
+// debugger. The addEventListener hook only captures listeners when the
+// page hooks are enabled, while the debugger reports all of them.
+var debuggerListenerTypes = map[string]struct{}{
+	"dblclick":    {},
+	"contextmenu": {},
+}
+
+// elementDataScript returns the data of an element, or null for nodes
+// which are not elements (eg. the document)
+const elementDataScript = `() => this.nodeType === Node.ELEMENT_NODE ? window._elementDataFromElement(this) : null`
+
+// getDebuggerEventListeners returns the listeners of debuggerListenerTypes
+// registered on the elements of the page, including the elements inside
+// shadow roots, as reported by DOMDebugger.getEventListeners.
+func (b *BrowserPage) getDebuggerEventListeners() ([]*types.EventListener, error) {
+	document, err := b.Evaluate(rod.Eval(`() => document`).ByObject())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get document")
+	}
+	depth := -1
+	result, err := proto.DOMDebuggerGetEventListeners{
+		ObjectID: document.ObjectID,
+		Depth:    &depth,
+		Pierce:   true,
+	}.Call(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get event listeners")
+	}
+
+	var listeners []*types.EventListener
+	elements := make(map[proto.DOMBackendNodeID]*types.HTMLElement)
+	for _, listener := range result.Listeners {
+		if _, ok := debuggerListenerTypes[listener.Type]; !ok || listener.BackendNodeID == 0 {
+			continue
+		}
+		element, ok := elements[listener.BackendNodeID]
+		if !ok {
+			element = b.elementFromBackendNode(listener.BackendNodeID)
+			elements[listener.BackendNodeID] = element
+		}
+		if element == nil {
+			continue
+		}
+		listeners = append(listeners, &types.EventListener{
+			Type:    listener.Type,
+			Element: element,
+		})
+	}
+	return listeners, nil
+}
+
+// elementFromBackendNode returns the data of the element of a backend
+// node or nil if it is not an element
+func (b *BrowserPage) elementFromBackendNode(id proto.DOMBackendNodeID) *types.HTMLElement {
+	node, err := proto.DOMResolveNode{BackendNodeID: id}.Call(b)
+	if err != nil || node.Object == nil {
+		return nil
+	}
+	element, err := b.ElementFromObject(node.Object)
+	if err != nil {
+		return nil
+	}
+	data, err := element.Eval(elementDataScript)
+	if err != nil || data.Value.Nil() {
+		return nil
+	}
+	htmlElement := &types.HTMLElement{}
+	if err := data.Value.Unmarshal(htmlElement); err != nil {
+		return nil
+	}
+	return htmlElement
+}
@@ -0,0 +1,79 @@
+package browser
+
+import (
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// deterministicEpoch is the time the page clock starts at in
+// deterministic mode (2024-01-01T00:00:00Z in milliseconds)
+const deterministicEpoch int64 = 1704067200000
+
+// suppressAnimationsJS disables css animations, transitions and smooth
+// scrolling so that the page settles as soon as it is rendered.
+const suppressAnimationsJS = `(() => {
+	const css = "*, *::before, *::after { animation-delay: 0s !important; animation-duration: 0s !important; animation-iteration-count: 1 !important; transition: none !important; scroll-behavior: auto !important; }";
+	const inject = () => {
+		const style = document.createElement("style");
+		style.textContent = css;
+		(document.head || document.documentElement).appendChild(style);
+	};
+	if (document.documentElement) {
+		inject();
+	} else {
+		document.addEventListener("readystatechange", inject, { once: true });
+	}
+})()`
+
+// deterministicJS seeds Math.random and starts the page clock at a
+// fixed time so that repeated visits render the same content.
+const deterministicJS = `(() => {
+	let seed = %d >>> 0;
+	Math.random = function () {
+		seed = (seed + 0x6D2B79F5) >>> 0;
+		let t = seed;
+		t = Math.imul(t ^ (t >>> 15), t | 1);
+		t ^= t + Math.imul(t ^ (t >>> 7), t | 61);
+		return ((t ^ (t >>> 14)) >>> 0) / 4294967296;
+	};
+	const RealDate = Date;
+	const started = performance.now();
+	const now = () => %d + Math.floor(performance.now() - started);
+	function FixedDate(...args) {
+		if (!new.target) {
+			return new RealDate(now()).toString();
+		}
+		return args.length ? new RealDate(...args) : new RealDate(now());
+	}
+	FixedDate.prototype = RealDate.prototype;
+	FixedDate.now = now;
+	FixedDate.parse = RealDate.parse;
+	FixedDate.UTC = RealDate.UTC;
+	window.Date = FixedDate;
+})()`
+
+// setupMotionEmulation emulates prefers-reduced-motion and suppresses
+// animations when reducedMotion is set, and makes the page clock and
+// random numbers deterministic when seed is not zero.
+func setupMotionEmulation(page *rod.Page, reducedMotion bool, seed int) error {
+	if reducedMotion {
+		err := proto.EmulationSetEmulatedMedia{
+			Features: []*proto.EmulationMediaFeature{{Name: "prefers-reduced-motion", Value: "reduce"}},
+		}.Call(page)
+		if err != nil {
+			return errors.Wrap(err, "could not emulate reduced motion")
+		}
+		if _, err := page.EvalOnNewDocument(suppressAnimationsJS); err != nil {
+			return errors.Wrap(err, "could not suppress animations")
+		}
+	}
+	if seed != 0 {
+		if _, err := page.EvalOnNewDocument(fmt.Sprintf(deterministicJS, uint32(seed), deterministicEpoch)); err != nil {
+			return errors.Wrap(err, "could not seed page randomness")
+		}
+	}
+	return nil
+}
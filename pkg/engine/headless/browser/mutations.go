@@ -0,0 +1,67 @@
+package browser
+
+import (
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// mutatedElements are the navigable elements added
+// to the document since they were last taken
+type mutatedElements struct {
+	Forms     []*types.HTMLForm      `json:"forms"`
+	Buttons   []*types.HTMLElement   `json:"buttons"`
+	Links     []*types.HTMLElement   `json:"links"`
+	Listeners []*inlineEventListener `json:"listeners"`
+}
+
+// ObserveMutations starts recording the navigable elements added to the
+// document of the page once it was scanned in the state originID, so
+// that they can be taken with TakeMutationNavigations.
+func (b *BrowserPage) ObserveMutations(originID string) error {
+	b.mutationOrigin.Store(originID)
+	_, err := b.Eval(`() => {
+		if (window.__mutatedNodes) {
+			window.__mutatedNodes.clear();
+		}
+		window.__observingMutations = true;
+	}`)
+	return err
+}
+
+// TakeMutationNavigations returns the navigations of the elements added
+// to the document since the mutations were observed or last taken, and
+// the id of the state the page was scanned in. Nothing is returned if
+// the page left the document it was observing.
+func (b *BrowserPage) TakeMutationNavigations() (string, []*types.Action, error) {
+	originID, _ := b.mutationOrigin.Swap("").(string)
+	if originID == "" {
+		return "", nil, nil
+	}
+	result, err := b.Eval(`() => window.__observingMutations === true ? window.getMutatedElements() : null`)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "could not get mutated elements")
+	}
+	if result.Value.Nil() {
+		return "", nil, nil
+	}
+	var elements mutatedElements
+	if err := result.Value.Unmarshal(&elements); err != nil {
+		return "", nil, errors.Wrap(err, "could not unmarshal mutated elements")
+	}
+	baseURL, err := b.documentBaseURL()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "could not get base url")
+	}
+
+	var listeners []*types.EventListener
+	for _, listener := range elements.Listeners {
+		listeners = append(listeners, listener.eventListeners()...)
+	}
+	navigations := b.elementNavigations(pageElements{
+		forms:          elements.Forms,
+		buttons:        elements.Buttons,
+		links:          elements.Links,
+		eventListeners: listeners,
+	}, baseURL, make(map[string]struct{}))
+	return originID, navigations, nil
+}
@@ -0,0 +1,233 @@
+package browser
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/utils/debugserver"
+)
+
+// ErrPoolClosed is returned when a page is taken from a closed pool
+var ErrPoolClosed = errors.New("browser pool is closed")
+
+const (
+	// defaultMinBrowsers is the number of browsers kept open when idle
+	defaultMinBrowsers = 1
+	// defaultBrowserIdleTimeout is the time after which the idle
+	// browsers above the minimum are closed
+	defaultBrowserIdleTimeout = 30 * time.Second
+)
+
+// PoolStats is the utilization of the browser pool of a launcher
+type PoolStats struct {
+	// Open is the number of open browsers
+	Open int `json:"open"`
+	// Busy is the number of browsers taken from the pool
+	Busy int `json:"busy"`
+	// Max is the maximum number of open browsers
+	Max int `json:"max"`
+	// Peak is the highest number of browsers open at once
+	Peak int `json:"peak"`
+	// Opened and Closed are the number of browsers opened and closed
+	Opened int `json:"opened"`
+	Closed int `json:"closed"`
+	// Waits is the number of times a page was awaited as all
+	// the browsers were busy
+	Waits int `json:"waits"`
+}
+
+// pagePool is the pool of pages of a launcher, each in its own browser.
+// Browsers are opened on demand up to max and the ones idle for longer
+// than idleTimeout are closed down to min.
+type pagePool struct {
+	min         int
+	idleTimeout time.Duration
+	// closePage closes the pages removed from the pool
+	closePage func(*BrowserPage)
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// idle are the pages put back, the most recently used last
+	idle   []idlePage
+	closed bool
+	stats  PoolStats
+}
+
+type idlePage struct {
+	page  *BrowserPage
+	since time.Time
+}
+
+func newPagePool(minBrowsers, maxBrowsers int, idleTimeout time.Duration) *pagePool {
+	maxBrowsers = max(maxBrowsers, 1)
+	if minBrowsers <= 0 {
+		minBrowsers = defaultMinBrowsers
+	}
+	if idleTimeout <= 0 {
+		idleTimeout = defaultBrowserIdleTimeout
+	}
+	p := &pagePool{
+		min:         min(minBrowsers, maxBrowsers),
+		idleTimeout: idleTimeout,
+		closePage:   closeBrowserPage,
+		stats:       PoolStats{Max: maxBrowsers},
+	}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// get returns an idle page or a page opened with create, it
+// waits for a page to be released when all browsers are busy
+func (p *pagePool) get(create func() (*BrowserPage, error)) (*BrowserPage, error) {
+	p.mu.Lock()
+	expired := p.expire(time.Now())
+	waited := false
+	for {
+		if p.closed {
+			p.mu.Unlock()
+			p.closePages(expired)
+			return nil, ErrPoolClosed
+		}
+		// the most recently used page is reused so that the
+		// pages which are not needed expire
+		if n := len(p.idle); n > 0 {
+			page := p.idle[n-1].page
+			p.idle = p.idle[:n-1]
+			p.take()
+			p.mu.Unlock()
+			p.closePages(expired)
+			return page, nil
+		}
+		if p.stats.Open < p.stats.Max {
+			break
+		}
+		if !waited {
+			waited = true
+			p.stats.Waits++
+		}
+		p.cond.Wait()
+	}
+	// the browser is accounted while it is opened
+	p.stats.Open++
+	p.take()
+	p.mu.Unlock()
+	p.closePages(expired)
+
+	page, err := create()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.stats.Open--
+		p.release()
+		return nil, err
+	}
+	p.stats.Opened++
+	p.stats.Peak = max(p.stats.Peak, p.stats.Open)
+	debugserver.Browsers.Add(1)
+	return page, nil
+}
+
+// put puts a page back into the pool
+func (p *pagePool) put(page *BrowserPage) {
+	p.mu.Lock()
+	p.release()
+	if p.closed {
+		p.closeLocked()
+		p.mu.Unlock()
+		p.closePage(page)
+		return
+	}
+	now := time.Now()
+	p.idle = append(p.idle, idlePage{page: page, since: now})
+	expired := p.expire(now)
+	p.mu.Unlock()
+	p.closePages(expired)
+}
+
+// discard releases a page taken from the pool which was closed
+func (p *pagePool) discard() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.release()
+	p.closeLocked()
+}
+
+// available returns true if a page can be taken without waiting,
+// browsers are only opened when the queued actions outnumber them
+func (p *pagePool) available(queued int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle) > 0 {
+		return true
+	}
+	if p.stats.Open >= p.stats.Max {
+		return false
+	}
+	return p.stats.Open < p.min || queued > p.stats.Open
+}
+
+// close closes the pool returning its idle pages to be closed,
+// the busy pages are closed when they are put back
+func (p *pagePool) close() []*BrowserPage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	pages := make([]*BrowserPage, 0, len(p.idle))
+	for _, idle := range p.idle {
+		pages = append(pages, idle.page)
+		p.closeLocked()
+	}
+	p.idle = nil
+	p.cond.Broadcast()
+	return pages
+}
+
+// snapshot returns the utilization of the pool
+func (p *pagePool) snapshot() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+// expire removes the pages idle for longer than the idle timeout while
+// more than min browsers are open, the removed pages must be closed
+func (p *pagePool) expire(now time.Time) []*BrowserPage {
+	var expired []*BrowserPage
+	for len(p.idle) > 0 && p.stats.Open > p.min && now.Sub(p.idle[0].since) >= p.idleTimeout {
+		expired = append(expired, p.idle[0].page)
+		p.idle = p.idle[1:]
+		p.closeLocked()
+	}
+	return expired
+}
+
+func (p *pagePool) take() {
+	p.stats.Busy++
+	debugserver.BrowsersBusy.Add(1)
+}
+
+func (p *pagePool) release() {
+	p.stats.Busy--
+	debugserver.BrowsersBusy.Add(-1)
+	p.cond.Signal()
+}
+
+func (p *pagePool) closeLocked() {
+	p.stats.Open--
+	p.stats.Closed++
+	debugserver.Browsers.Add(-1)
+	p.cond.Signal()
+}
+
+func (p *pagePool) closePages(pages []*BrowserPage) {
+	for _, page := range pages {
+		p.closePage(page)
+	}
+}
+
+func closeBrowserPage(page *BrowserPage) {
+	page.cancel()
+	page.CloseBrowserPage()
+}
@@ -0,0 +1,99 @@
+package browser
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPagePool(minBrowsers, maxBrowsers int, idleTimeout time.Duration) (*pagePool, *[]*BrowserPage) {
+	pool := newPagePool(minBrowsers, maxBrowsers, idleTimeout)
+	closed := &[]*BrowserPage{}
+	pool.closePage = func(page *BrowserPage) {
+		*closed = append(*closed, page)
+	}
+	return pool, closed
+}
+
+func createPage() (*BrowserPage, error) {
+	return &BrowserPage{}, nil
+}
+
+func TestPagePoolGrowth(t *testing.T) {
+	pool, _ := newTestPagePool(1, 3, time.Minute)
+
+	require.True(t, pool.available(0), "the first browser should always be opened")
+	first, err := pool.get(createPage)
+	require.NoError(t, err)
+
+	require.False(t, pool.available(1), "a shallow queue should not open another browser")
+	require.True(t, pool.available(2), "a backed up queue should open another browser")
+	second, err := pool.get(createPage)
+	require.NoError(t, err)
+	require.NotSame(t, first, second)
+
+	pool.put(first)
+	require.True(t, pool.available(0), "idle pages should be available")
+	reused, err := pool.get(createPage)
+	require.NoError(t, err)
+	require.Same(t, first, reused, "idle pages should be reused before opening browsers")
+
+	stats := pool.snapshot()
+	require.Equal(t, PoolStats{Open: 2, Busy: 2, Max: 3, Peak: 2, Opened: 2}, stats)
+
+	_, err = pool.get(func() (*BrowserPage, error) { return nil, errors.New("launch failed") })
+	require.Error(t, err)
+	require.Equal(t, 2, pool.snapshot().Open, "failed browsers should not be accounted")
+}
+
+func TestPagePoolWait(t *testing.T) {
+	pool, _ := newTestPagePool(1, 1, time.Minute)
+	page, err := pool.get(createPage)
+	require.NoError(t, err)
+	require.False(t, pool.available(10), "the pool should not grow beyond its maximum")
+
+	done := make(chan *BrowserPage)
+	go func() {
+		waited, _ := pool.get(createPage)
+		done <- waited
+	}()
+	time.Sleep(10 * time.Millisecond)
+	pool.put(page)
+	require.Same(t, page, <-done, "waiting gets should receive the released page")
+	require.Equal(t, 1, pool.snapshot().Waits)
+
+	// discarded pages free their browser slot
+	pool.discard()
+	replaced, err := pool.get(createPage)
+	require.NoError(t, err)
+	require.NotSame(t, page, replaced)
+	require.Equal(t, PoolStats{Open: 1, Busy: 1, Max: 1, Peak: 1, Opened: 2, Closed: 1, Waits: 1}, pool.snapshot())
+}
+
+func TestPagePoolShrink(t *testing.T) {
+	pool, closed := newTestPagePool(1, 3, 20*time.Millisecond)
+	pages := make([]*BrowserPage, 0, 3)
+	for range 3 {
+		page, err := pool.get(createPage)
+		require.NoError(t, err)
+		pages = append(pages, page)
+	}
+	for _, page := range pages {
+		pool.put(page)
+	}
+	require.Empty(t, *closed)
+
+	time.Sleep(30 * time.Millisecond)
+	page, err := pool.get(createPage)
+	require.NoError(t, err)
+	require.Equal(t, pages[:2], *closed, "the least recently used idle browsers should be closed")
+	require.Same(t, pages[2], page, "the minimum of browsers should be kept open")
+	require.Equal(t, 1, pool.snapshot().Open)
+
+	pool.put(page)
+	require.Equal(t, []*BrowserPage{page}, pool.close())
+	_, err = pool.get(createPage)
+	require.ErrorIs(t, err, ErrPoolClosed)
+}
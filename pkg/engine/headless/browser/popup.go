@@ -0,0 +1,82 @@
+package browser
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// popupTracker records the pages opened by a page through window.open
+// or links targeting a new window. Every pooled page runs in its own
+// browser (or context of a remote browser) so any other page target of
+// the browser is one of its popups.
+type popupTracker struct {
+	owner proto.TargetTargetID
+	// browserContext is the remote browser context of the page, if any
+	browserContext proto.BrowserBrowserContextID
+
+	mu      sync.Mutex
+	targets []proto.TargetTargetID
+}
+
+func newPopupTracker(owner proto.TargetTargetID, browserContext proto.BrowserBrowserContextID) *popupTracker {
+	return &popupTracker{owner: owner, browserContext: browserContext}
+}
+
+// handler returns the CDP event handler of the tracker
+func (t *popupTracker) handler() func(*proto.TargetTargetCreated) {
+	return func(e *proto.TargetTargetCreated) {
+		if e.TargetInfo == nil || e.TargetInfo.Type != proto.TargetTargetInfoTypePage || e.TargetInfo.TargetID == t.owner {
+			return
+		}
+		if t.browserContext != "" && e.TargetInfo.BrowserContextID != t.browserContext {
+			return
+		}
+		t.mu.Lock()
+		t.targets = append(t.targets, e.TargetInfo.TargetID)
+		t.mu.Unlock()
+	}
+}
+
+// take returns the popups opened since the last call
+func (t *popupTracker) take() []proto.TargetTargetID {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	targets := t.targets
+	t.targets = nil
+	return targets
+}
+
+// trackPopups records the popups of the page until ctx is done
+func (b *BrowserPage) trackPopups(ctx context.Context) {
+	b.popups = newPopupTracker(b.TargetID, b.Browser.BrowserContextID)
+	go b.Browser.Context(ctx).EachEvent(b.popups.handler())()
+}
+
+// TakePopups returns the pages opened by the page since the last call.
+// Popups which were closed in the meantime are skipped. The returned
+// pages must be closed with ClosePopup.
+func (b *BrowserPage) TakePopups() []*BrowserPage {
+	if b.popups == nil {
+		return nil
+	}
+	var popups []*BrowserPage
+	for _, targetID := range b.popups.take() {
+		page, err := b.Browser.PageFromTarget(targetID)
+		if err != nil {
+			continue
+		}
+		popups = append(popups, &BrowserPage{
+			Page:     page.Context(b.GetContext()),
+			Browser:  b.Browser,
+			launcher: b.launcher,
+		})
+	}
+	return popups
+}
+
+// ClosePopup closes a popup returned by TakePopups
+func (b *BrowserPage) ClosePopup() {
+	_ = b.Close()
+}
@@ -0,0 +1,31 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPopupTracker(t *testing.T) {
+	tracker := newPopupTracker("owner", "")
+	handler := tracker.handler()
+
+	handler(&proto.TargetTargetCreated{TargetInfo: &proto.TargetTargetInfo{TargetID: "owner", Type: proto.TargetTargetInfoTypePage}})
+	handler(&proto.TargetTargetCreated{TargetInfo: &proto.TargetTargetInfo{TargetID: "worker", Type: proto.TargetTargetInfoTypeServiceWorker}})
+	handler(&proto.TargetTargetCreated{TargetInfo: &proto.TargetTargetInfo{TargetID: "popup", Type: proto.TargetTargetInfoTypePage, OpenerID: "owner"}})
+	handler(&proto.TargetTargetCreated{})
+
+	require.Equal(t, []proto.TargetTargetID{"popup"}, tracker.take())
+	require.Empty(t, tracker.take(), "popups should only be returned once")
+}
+
+func TestPopupTrackerRemoteContext(t *testing.T) {
+	tracker := newPopupTracker("owner", "context")
+	handler := tracker.handler()
+
+	handler(&proto.TargetTargetCreated{TargetInfo: &proto.TargetTargetInfo{TargetID: "popup", Type: proto.TargetTargetInfoTypePage, BrowserContextID: "context"}})
+	handler(&proto.TargetTargetCreated{TargetInfo: &proto.TargetTargetInfo{TargetID: "other", Type: proto.TargetTargetInfoTypePage, BrowserContextID: "other-context"}})
+
+	require.Equal(t, []proto.TargetTargetID{"popup"}, tracker.take(), "pages of other contexts of a remote browser should be ignored")
+}
@@ -0,0 +1,88 @@
+package browser
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// connectRemote connects to the remote browser controlled at controlURL,
+// websocket urls are used as is (eg. browserless urls with a token) and
+// the websocket url of http urls is resolved from their devtools endpoint.
+func (l *Launcher) connectRemote(controlURL string) error {
+	if !strings.HasPrefix(controlURL, "ws://") && !strings.HasPrefix(controlURL, "wss://") {
+		resolved, err := launcher.ResolveURL(controlURL)
+		if err != nil {
+			return errors.Wrap(err, "could not resolve remote browser url")
+		}
+		controlURL = resolved
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	browser := rod.New().
+		Context(ctx).
+		ControlURL(controlURL)
+	if l.opts.Trace {
+		browser = browser.Trace(true)
+	}
+	if l.opts.SlowMotion {
+		browser = browser.SlowMotion(1 * time.Second)
+	}
+	if err := browser.Connect(); err != nil {
+		cancel()
+		return errors.Wrap(err, "could not connect to remote browser")
+	}
+	l.remote = browser
+	l.remoteCancel = cancel
+	return nil
+}
+
+// newRemoteContext returns a new browser context of the remote browser,
+// pooled pages share the remote browser so each of them runs in its own
+// context isolating their cookies and storage.
+func (l *Launcher) newRemoteContext() (*rod.Browser, error) {
+	createContext := proto.TargetCreateBrowserContext{}
+	if l.opts.Proxy != "" {
+		createContext.ProxyServer = l.opts.Proxy
+		createContext.ProxyBypassList = "<-loopback>"
+	}
+	res, err := createContext.Call(l.remote)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create remote browser context")
+	}
+	remoteContext := *l.remote
+	remoteContext.BrowserContextID = res.BrowserContextID
+	return &remoteContext, nil
+}
+
+// closeRemote disconnects from the remote browser leaving it running
+func (l *Launcher) closeRemote() {
+	if l.remoteCancel != nil {
+		l.remoteCancel()
+	}
+}
+
+// pageTargets returns the page targets of the browser, only the ones of
+// its context when it is a context of a remote browser
+func pageTargets(browser *rod.Browser) ([]proto.TargetTargetID, error) {
+	list, err := proto.TargetGetTargets{}.Call(browser)
+	if err != nil {
+		return nil, err
+	}
+	var targets []proto.TargetTargetID
+	for _, target := range list.TargetInfos {
+		if target.Type != proto.TargetTargetInfoTypePage {
+			continue
+		}
+		if browser.BrowserContextID != "" && target.BrowserContextID != browser.BrowserContextID {
+			continue
+		}
+		targets = append(targets, target.TargetID)
+	}
+	return targets, nil
+}
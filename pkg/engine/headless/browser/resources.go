@@ -0,0 +1,83 @@
+package browser
+
+import (
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// resourceTypes are the resource types of intercepted browser requests
+var resourceTypes = []proto.NetworkResourceType{
+	proto.NetworkResourceTypeDocument,
+	proto.NetworkResourceTypeStylesheet,
+	proto.NetworkResourceTypeImage,
+	proto.NetworkResourceTypeMedia,
+	proto.NetworkResourceTypeFont,
+	proto.NetworkResourceTypeScript,
+	proto.NetworkResourceTypeTextTrack,
+	proto.NetworkResourceTypeXHR,
+	proto.NetworkResourceTypeFetch,
+	proto.NetworkResourceTypePrefetch,
+	proto.NetworkResourceTypeEventSource,
+	proto.NetworkResourceTypeWebSocket,
+	proto.NetworkResourceTypeManifest,
+	proto.NetworkResourceTypeSignedExchange,
+	proto.NetworkResourceTypePing,
+	proto.NetworkResourceTypeCSPViolationReport,
+	proto.NetworkResourceTypePreflight,
+	proto.NetworkResourceTypeOther,
+}
+
+// ResourceTypes are the resource types of intercepted browser requests
+// reported to the request callback, empty reports every resource type.
+type ResourceTypes map[proto.NetworkResourceType]struct{}
+
+// ParseResourceTypes parses resource type names (eg. xhr,fetch,document).
+// The api shorthand selects xhr, fetch and document requests and all
+// selects every resource type.
+func ParseResourceTypes(values []string) (ResourceTypes, error) {
+	types := make(ResourceTypes)
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		switch value {
+		case "":
+			continue
+		case "all":
+			return nil, nil
+		case "api":
+			types[proto.NetworkResourceTypeXHR] = struct{}{}
+			types[proto.NetworkResourceTypeFetch] = struct{}{}
+			types[proto.NetworkResourceTypeDocument] = struct{}{}
+			continue
+		}
+		resourceType, ok := lookupResourceType(value)
+		if !ok {
+			return nil, errors.Errorf("unknown resource type %q (eg. api, all, document, xhr, fetch, script, stylesheet, image, font, media)", value)
+		}
+		types[resourceType] = struct{}{}
+	}
+	if len(types) == 0 {
+		return nil, nil
+	}
+	return types, nil
+}
+
+// lookupResourceType returns the resource type with the lowercase name
+func lookupResourceType(name string) (proto.NetworkResourceType, bool) {
+	for _, resourceType := range resourceTypes {
+		if strings.ToLower(string(resourceType)) == name {
+			return resourceType, true
+		}
+	}
+	return "", false
+}
+
+// Allows returns true if requests of the resource type are reported
+func (r ResourceTypes) Allows(resourceType proto.NetworkResourceType) bool {
+	if len(r) == 0 {
+		return true
+	}
+	_, ok := r[resourceType]
+	return ok
+}
@@ -0,0 +1,39 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResourceTypes(t *testing.T) {
+	types, err := ParseResourceTypes(nil)
+	require.NoError(t, err)
+	require.True(t, types.Allows(proto.NetworkResourceTypeImage), "no resource types should report everything")
+
+	types, err = ParseResourceTypes([]string{"xhr", "all"})
+	require.NoError(t, err)
+	require.True(t, types.Allows(proto.NetworkResourceTypeFont))
+
+	types, err = ParseResourceTypes([]string{"api", " Script "})
+	require.NoError(t, err)
+	for _, allowed := range []proto.NetworkResourceType{
+		proto.NetworkResourceTypeXHR,
+		proto.NetworkResourceTypeFetch,
+		proto.NetworkResourceTypeDocument,
+		proto.NetworkResourceTypeScript,
+	} {
+		require.True(t, types.Allows(allowed), allowed)
+	}
+	for _, denied := range []proto.NetworkResourceType{
+		proto.NetworkResourceTypeImage,
+		proto.NetworkResourceTypeFont,
+		proto.NetworkResourceTypePing,
+	} {
+		require.False(t, types.Allows(denied), denied)
+	}
+
+	_, err = ParseResourceTypes([]string{"images"})
+	require.Error(t, err)
+}
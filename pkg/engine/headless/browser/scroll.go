@@ -0,0 +1,46 @@
+package browser
+
+import "github.com/pkg/errors"
+
+// scrollViewportScript scrolls the page down by the height of the viewport
+const scrollViewportScript = `() => {
+	const el = document.scrollingElement || document.documentElement;
+	window.scrollBy(0, window.innerHeight);
+	return {top: el.scrollTop, height: el.scrollHeight};
+}`
+
+// scrollPositionScript returns the scroll position of the page
+const scrollPositionScript = `() => {
+	const el = document.scrollingElement || document.documentElement;
+	return {top: el.scrollTop, height: el.scrollHeight};
+}`
+
+// ScrollPosition is the vertical scroll offset and the scrollable
+// height of a page
+type ScrollPosition struct {
+	Top    float64 `json:"top"`
+	Height float64 `json:"height"`
+}
+
+// ScrollViewport scrolls the page down by one viewport and returns
+// the position after the scroll
+func (b *BrowserPage) ScrollViewport() (*ScrollPosition, error) {
+	return b.evalScrollPosition(scrollViewportScript)
+}
+
+// GetScrollPosition returns the current scroll position of the page
+func (b *BrowserPage) GetScrollPosition() (*ScrollPosition, error) {
+	return b.evalScrollPosition(scrollPositionScript)
+}
+
+func (b *BrowserPage) evalScrollPosition(script string) (*ScrollPosition, error) {
+	result, err := b.Eval(script)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get scroll position")
+	}
+	position := &ScrollPosition{}
+	if err := result.Value.Unmarshal(position); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal scroll position")
+	}
+	return position, nil
+}
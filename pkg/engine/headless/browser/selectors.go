@@ -0,0 +1,46 @@
+package browser
+
+import (
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// applyNoClickSelectors declares the selectors of the elements never to
+// click in the document of the page, so that the elements matching them
+// or inside them are marked when they are collected.
+func (b *BrowserPage) applyNoClickSelectors() error {
+	selectors := b.launcher.opts.NoClickSelectors
+	if len(selectors) == 0 {
+		return nil
+	}
+	_, err := b.Eval(`(selectors) => { window.__katanaNoClickSelectors = selectors; }`, selectors)
+	return err
+}
+
+// GetElementsBySelectors returns the elements of the page
+// matching any of the css or xpath selectors
+func (b *BrowserPage) GetElementsBySelectors(selectors []string) ([]*types.HTMLElement, error) {
+	objects, err := b.Eval(`(selectors) => window.getElementsBySelectors(selectors)`, selectors)
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]*types.HTMLElement, 0)
+	if err := objects.Value.Unmarshal(&elements); err != nil {
+		return nil, err
+	}
+	return elements, nil
+}
+
+// isNoClickForm returns true if the form or the
+// buttons submitting it are never to be clicked
+func isNoClickForm(form *types.HTMLForm) bool {
+	if form.NoClick {
+		return true
+	}
+	for _, element := range form.Elements {
+		if element.NoClick && (element.TagName == "BUTTON" || element.Type == "submit") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElementNavigationsSelectors(t *testing.T) {
+	page := &BrowserPage{launcher: &Launcher{opts: LauncherOptions{
+		ScopeValidator: func(string) bool { return true },
+	}}}
+
+	logout := &types.HTMLElement{TagName: "A", ID: "logout", Attributes: map[string]string{"href": "/logout"}, NoClick: true}
+	profile := &types.HTMLElement{TagName: "A", ID: "profile", Attributes: map[string]string{"href": "/profile"}}
+	deleteButton := &types.HTMLElement{TagName: "BUTTON", ID: "delete", NoClick: true}
+	card := &types.HTMLElement{TagName: "DIV", Classes: "card", Attributes: map[string]string{"class": "card cursor-not-allowed"}}
+	deleteForm := &types.HTMLForm{TagName: "FORM", ID: "delete-account", Elements: []*types.HTMLElement{
+		{TagName: "INPUT", Type: "submit", NoClick: true},
+	}}
+	searchForm := &types.HTMLForm{TagName: "FORM", ID: "search", Elements: []*types.HTMLElement{
+		{TagName: "INPUT", Type: "text", NoClick: true},
+	}}
+
+	navigations := page.elementNavigations(pageElements{
+		forms:      []*types.HTMLForm{deleteForm, searchForm},
+		buttons:    []*types.HTMLElement{deleteButton},
+		links:      []*types.HTMLElement{logout, profile},
+		clickables: []*types.HTMLElement{card, {TagName: "SPAN", ID: "menu", NoClick: true}},
+		eventListeners: []*types.EventListener{
+			{Type: "click", Element: &types.HTMLElement{TagName: "LI", ID: "unsubscribe", NoClick: true}},
+		},
+	}, "https://example.com/", make(map[string]struct{}))
+
+	require.Len(t, navigations, 3)
+	require.Equal(t, types.ActionTypeFillForm, navigations[0].Type)
+	require.Equal(t, searchForm, navigations[0].Form, "forms should only be skipped if their submission is never clicked")
+	require.Equal(t, profile, navigations[1].Element)
+	require.Equal(t, card, navigations[2].Element, "clickables should be clicked even if they look disabled")
+	require.Equal(t, card.Hash(), card.MD5Hash)
+}
@@ -0,0 +1,39 @@
+package browser
+
+import (
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+)
+
+// ShadowSelectorSeparator separates the css selectors of the shadow
+// hosts and of the element in a selector piercing shadow roots
+// (eg. "APP-ROOT >>> NAV-BAR >>> A.login")
+const ShadowSelectorSeparator = " >>> "
+
+// IsShadowSelector returns true if the css selector pierces shadow roots
+func IsShadowSelector(selector string) bool {
+	return strings.Contains(selector, ShadowSelectorSeparator)
+}
+
+// ShadowElement returns the element at a css selector piercing shadow
+// roots. Each selector is matched in the shadow root of the previous
+// host, starting from the document of the page.
+func ShadowElement(page *rod.Page, selector string) (*rod.Element, error) {
+	parts := strings.Split(selector, ShadowSelectorSeparator)
+	element, err := page.Element(parts[0])
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find shadow host %s", parts[0])
+	}
+	for _, part := range parts[1:] {
+		root, err := element.ShadowRoot()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get shadow root")
+		}
+		if element, err = root.Element(part); err != nil {
+			return nil, errors.Wrapf(err, "could not find shadow element %s", part)
+		}
+	}
+	return element, nil
+}
@@ -0,0 +1,13 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsShadowSelector(t *testing.T) {
+	require.True(t, IsShadowSelector("HTML > BODY > APP-ROOT >>> NAV-BAR >>> A.login"))
+	require.False(t, IsShadowSelector("HTML > BODY > DIV > A"))
+	require.False(t, IsShadowSelector(""))
+}
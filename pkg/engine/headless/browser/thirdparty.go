@@ -0,0 +1,41 @@
+package browser
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/katana/pkg/utils/scope"
+)
+
+// blocksThirdParty returns true if the paused request is sent to a host
+// rejected by the host validator, nothing is blocked without a validator
+func (b *BrowserPage) blocksThirdParty(e *proto.FetchRequestPaused) bool {
+	validator := b.launcher.opts.HostValidator
+	return validator != nil && !validator(e.Request.URL)
+}
+
+// blockThirdPartyRequest fails a paused request to a third-party host
+// reporting a tagged result so that the blocked url is still recorded
+func (b *BrowserPage) blockThirdPartyRequest(e *proto.FetchRequestPaused) {
+	if err := (proto.FetchFailRequest{
+		RequestID:   e.RequestID,
+		ErrorReason: proto.NetworkErrorReasonBlockedByClient,
+	}).Call(b.Page); err != nil {
+		slog.Warn("fetchFailRequest failed", "error", err)
+	}
+	if b.launcher.opts.RequestCallback == nil {
+		return
+	}
+	b.launcher.opts.RequestCallback(&output.Result{
+		Timestamp: time.Now(),
+		Request: &navigation.Request{
+			Method: e.Request.Method,
+			URL:    e.Request.URL,
+			Tag:    scope.ThirdPartyTag,
+		},
+		Error: scope.ErrThirdPartyBlocked.Error(),
+	})
+}
@@ -0,0 +1,157 @@
+package browser
+
+import (
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+)
+
+const (
+	// WebSocketTag is the tag of websocket connections opened by pages
+	WebSocketTag = "websocket"
+	// MaxWebSocketFrames is the maximum number of frames recorded per connection
+	MaxWebSocketFrames = 100
+	// MaxWebSocketPayload is the maximum length of a recorded frame payload
+	MaxWebSocketPayload = 4096
+)
+
+// webSocketConnection is a websocket connection opened by a page
+type webSocketConnection struct {
+	url             string
+	status          int
+	requestHeaders  map[string]string
+	responseHeaders map[string]string
+	traffic         navigation.WebSocket
+}
+
+// webSocketTracker records the handshakes and frames of the websocket
+// connections of a page. Connections are reported once they are closed
+// or when the page is closed.
+type webSocketTracker struct {
+	report func(*output.Result)
+
+	mu          sync.Mutex
+	connections map[proto.NetworkRequestID]*webSocketConnection
+}
+
+func newWebSocketTracker(report func(*output.Result)) *webSocketTracker {
+	return &webSocketTracker{report: report, connections: make(map[proto.NetworkRequestID]*webSocketConnection)}
+}
+
+// handlers returns the CDP event handlers of the tracker
+func (t *webSocketTracker) handlers() []any {
+	return []any{
+		func(e *proto.NetworkWebSocketCreated) {
+			t.mu.Lock()
+			t.connections[e.RequestID] = &webSocketConnection{url: e.URL}
+			t.mu.Unlock()
+		},
+		func(e *proto.NetworkWebSocketHandshakeResponseReceived) {
+			if e.Response == nil {
+				return
+			}
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if connection, ok := t.connections[e.RequestID]; ok {
+				connection.status = e.Response.Status
+				connection.requestHeaders = flattenNetworkHeaders(e.Response.RequestHeaders)
+				connection.responseHeaders = flattenNetworkHeaders(e.Response.Headers)
+			}
+		},
+		func(e *proto.NetworkWebSocketFrameSent) {
+			t.recordFrame(e.RequestID, "sent", e.Response)
+		},
+		func(e *proto.NetworkWebSocketFrameReceived) {
+			t.recordFrame(e.RequestID, "received", e.Response)
+		},
+		func(e *proto.NetworkWebSocketClosed) {
+			t.mu.Lock()
+			connection, ok := t.connections[e.RequestID]
+			delete(t.connections, e.RequestID)
+			t.mu.Unlock()
+			if ok {
+				t.emit(connection)
+			}
+		},
+	}
+}
+
+func (t *webSocketTracker) recordFrame(requestID proto.NetworkRequestID, direction string, frame *proto.NetworkWebSocketFrame) {
+	if frame == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	connection, ok := t.connections[requestID]
+	if !ok {
+		return
+	}
+	if len(connection.traffic.Frames) >= MaxWebSocketFrames {
+		connection.traffic.DroppedFrames++
+		return
+	}
+	recorded := navigation.WebSocketFrame{
+		Direction: direction,
+		Opcode:    int(frame.Opcode),
+		Payload:   frame.PayloadData,
+	}
+	if len(recorded.Payload) > MaxWebSocketPayload {
+		end := MaxWebSocketPayload
+		for end > 0 && !utf8.RuneStart(recorded.Payload[end]) {
+			end--
+		}
+		recorded.Payload = recorded.Payload[:end]
+		recorded.Truncated = true
+	}
+	connection.traffic.Frames = append(connection.traffic.Frames, recorded)
+}
+
+// Flush reports the connections which are still open
+func (t *webSocketTracker) Flush() {
+	t.mu.Lock()
+	connections := t.connections
+	t.connections = make(map[proto.NetworkRequestID]*webSocketConnection)
+	t.mu.Unlock()
+
+	for _, connection := range connections {
+		t.emit(connection)
+	}
+}
+
+func (t *webSocketTracker) emit(connection *webSocketConnection) {
+	if t.report == nil {
+		return
+	}
+	traffic := connection.traffic
+	t.report(&output.Result{
+		Timestamp: time.Now(),
+		Request: &navigation.Request{
+			Method:  http.MethodGet,
+			URL:     connection.url,
+			Headers: connection.requestHeaders,
+			Tag:     WebSocketTag,
+		},
+		Response: &navigation.Response{
+			StatusCode: connection.status,
+			Headers:    connection.responseHeaders,
+			WebSocket:  &traffic,
+		},
+	})
+}
+
+func flattenNetworkHeaders(headers proto.NetworkHeaders) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	flattened := make(map[string]string, len(headers))
+	for name, value := range headers {
+		flattened[name] = value.Str()
+	}
+	return flattened
+}
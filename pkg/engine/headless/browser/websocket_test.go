@@ -0,0 +1,62 @@
+package browser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/stretchr/testify/require"
+	"github.com/ysmood/gson"
+)
+
+func TestWebSocketTracker(t *testing.T) {
+	var results []*output.Result
+	tracker := newWebSocketTracker(func(result *output.Result) {
+		results = append(results, result)
+	})
+	handlers := tracker.handlers()
+	created := handlers[0].(func(*proto.NetworkWebSocketCreated))
+	handshake := handlers[1].(func(*proto.NetworkWebSocketHandshakeResponseReceived))
+	sent := handlers[2].(func(*proto.NetworkWebSocketFrameSent))
+	received := handlers[3].(func(*proto.NetworkWebSocketFrameReceived))
+	closed := handlers[4].(func(*proto.NetworkWebSocketClosed))
+
+	created(&proto.NetworkWebSocketCreated{RequestID: "1", URL: "wss://example.com/live"})
+	handshake(&proto.NetworkWebSocketHandshakeResponseReceived{RequestID: "1", Response: &proto.NetworkWebSocketResponse{
+		Status:         101,
+		Headers:        proto.NetworkHeaders{"Upgrade": gson.New("websocket")},
+		RequestHeaders: proto.NetworkHeaders{"Origin": gson.New("https://example.com")},
+	}})
+	sent(&proto.NetworkWebSocketFrameSent{RequestID: "1", Response: &proto.NetworkWebSocketFrame{Opcode: 1, PayloadData: `{"op":"subscribe","path":"/api/orders"}`}})
+	received(&proto.NetworkWebSocketFrameReceived{RequestID: "1", Response: &proto.NetworkWebSocketFrame{Opcode: 1, PayloadData: strings.Repeat("é", MaxWebSocketPayload)}})
+	received(&proto.NetworkWebSocketFrameReceived{RequestID: "unknown", Response: &proto.NetworkWebSocketFrame{Opcode: 1}})
+	require.Empty(t, results, "open connections should not be reported")
+
+	closed(&proto.NetworkWebSocketClosed{RequestID: "1"})
+	require.Len(t, results, 1)
+	result := results[0]
+	require.Equal(t, "wss://example.com/live", result.Request.URL)
+	require.Equal(t, WebSocketTag, result.Request.Tag)
+	require.Equal(t, "https://example.com", result.Request.Headers["Origin"])
+	require.Equal(t, 101, result.Response.StatusCode)
+	require.Equal(t, "websocket", result.Response.Headers["Upgrade"])
+
+	frames := result.Response.WebSocket.Frames
+	require.Len(t, frames, 2)
+	require.Equal(t, "sent", frames[0].Direction)
+	require.Equal(t, `{"op":"subscribe","path":"/api/orders"}`, frames[0].Payload)
+	require.Equal(t, "received", frames[1].Direction)
+	require.True(t, frames[1].Truncated)
+	require.LessOrEqual(t, len(frames[1].Payload), MaxWebSocketPayload)
+	require.True(t, strings.HasSuffix(frames[1].Payload, "é"), "payloads should be truncated on rune boundaries")
+
+	created(&proto.NetworkWebSocketCreated{RequestID: "2", URL: "wss://example.com/chat"})
+	for i := 0; i < MaxWebSocketFrames+5; i++ {
+		sent(&proto.NetworkWebSocketFrameSent{RequestID: "2", Response: &proto.NetworkWebSocketFrame{Opcode: 1, PayloadData: "ping"}})
+	}
+	tracker.Flush()
+	require.Len(t, results, 2, "open connections should be reported on flush")
+	require.Len(t, results[1].Response.WebSocket.Frames, MaxWebSocketFrames)
+	require.Equal(t, 5, results[1].Response.WebSocket.DroppedFrames)
+}
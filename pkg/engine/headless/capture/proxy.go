@@ -0,0 +1,284 @@
+// Package capture implements an intercepting proxy which records
+// the requests made during manual browsing so they can be fed
+// into the headless crawler as additional crawl seeds.
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/utils"
+)
+
+// Options contains the configuration for the capture proxy
+type Options struct {
+	// ListenAddress is the address the proxy listens on (eg. 127.0.0.1:8081)
+	ListenAddress string
+	// Authority is used to intercept CONNECT tunnels. When nil,
+	// HTTPS traffic is tunnelled through without being recorded.
+	Authority *Authority
+	// UpstreamProxy is an optional proxy to forward requests to
+	UpstreamProxy string
+	// OnRequest is called for every navigation or form submission
+	// observed through the proxy.
+	OnRequest func(*navigation.Request)
+	Logger    *slog.Logger
+}
+
+// Proxy is an intercepting HTTP(S) proxy recording observed requests
+type Proxy struct {
+	options   Options
+	listener  net.Listener
+	server    *http.Server
+	transport *http.Transport
+}
+
+// New creates a new capture proxy instance
+func New(options Options) (*Proxy, error) {
+	if options.Logger == nil {
+		options.Logger = slog.Default()
+	}
+	transport := &http.Transport{
+		TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     30 * time.Second,
+	}
+	if options.UpstreamProxy != "" {
+		proxyURL, err := url.Parse(options.UpstreamProxy)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse upstream proxy")
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	p := &Proxy{
+		options:   options,
+		transport: transport,
+	}
+	p.server = &http.Server{
+		Handler:           p,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return p, nil
+}
+
+// Start starts listening for proxy connections in the background
+func (p *Proxy) Start() error {
+	listener, err := net.Listen("tcp", p.options.ListenAddress)
+	if err != nil {
+		return errors.Wrap(err, "could not listen on capture proxy address")
+	}
+	p.listener = listener
+
+	go func() {
+		if err := p.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			p.options.Logger.Warn("Capture proxy stopped", slog.String("error", err.Error()))
+		}
+	}()
+	return nil
+}
+
+// Addr returns the address the proxy is listening on
+func (p *Proxy) Addr() string {
+	if p.listener == nil {
+		return p.options.ListenAddress
+	}
+	return p.listener.Addr().String()
+}
+
+// Close stops the proxy
+func (p *Proxy) Close() error {
+	p.transport.CloseIdleConnections()
+	return p.server.Close()
+}
+
+// ServeHTTP implements the http.Handler interface
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.handleConnect(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "katana capture proxy: absolute url required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := p.forward(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	for k, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// handleConnect handles CONNECT tunnels, intercepting them when
+// a certificate authority is configured.
+func (p *Proxy) handleConnect(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "katana capture proxy: hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = clientConn.Close()
+	}()
+
+	if _, err := clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		return
+	}
+
+	if p.options.Authority == nil {
+		p.tunnel(clientConn, r.Host)
+		return
+	}
+
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		// hosts without a port keep the brackets of ipv6 literals
+		host = strings.Trim(r.Host, "[]")
+	}
+	tlsConn := tls.Server(clientConn, &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			name := hello.ServerName
+			if name == "" {
+				name = host
+			}
+			return p.options.Authority.CertificateFor(name)
+		},
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		p.options.Logger.Debug("Capture proxy tls handshake failed",
+			slog.String("host", r.Host),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	reader := bufio.NewReader(tlsConn)
+	for {
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+		req.URL.Scheme = "https"
+		req.URL.Host = r.Host
+
+		resp, err := p.forward(req)
+		if err != nil {
+			resp = &http.Response{
+				StatusCode: http.StatusBadGateway,
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(err.Error())),
+			}
+		}
+		writeErr := resp.Write(tlsConn)
+		_ = resp.Body.Close()
+		if writeErr != nil || req.Close || resp.Close {
+			return
+		}
+	}
+}
+
+func (p *Proxy) tunnel(clientConn net.Conn, address string) {
+	targetConn, err := net.DialTimeout("tcp", address, 10*time.Second)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = targetConn.Close()
+	}()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(targetConn, clientConn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(clientConn, targetConn)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// forward records the request if it is a navigation and sends
+// it to the upstream server returning the response.
+func (p *Proxy) forward(r *http.Request) (*http.Response, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read request body")
+		}
+		_ = r.Body.Close()
+	}
+
+	outReq := r.Clone(r.Context())
+	outReq.RequestURI = ""
+	outReq.Body = io.NopCloser(bytes.NewReader(body))
+	outReq.ContentLength = int64(len(body))
+	for _, header := range hopHeaders {
+		outReq.Header.Del(header)
+	}
+
+	if p.options.OnRequest != nil && isNavigationRequest(r) {
+		p.options.OnRequest(&navigation.Request{
+			Method:  r.Method,
+			URL:     outReq.URL.String(),
+			Body:    string(body),
+			Headers: utils.FlattenHeaders(outReq.Header),
+			Source:  r.Header.Get("Referer"),
+			Tag:     "proxy",
+		})
+	}
+	return p.transport.RoundTrip(outReq)
+}
+
+var hopHeaders = []string{
+	"Proxy-Connection",
+	"Proxy-Authorization",
+	"Proxy-Authenticate",
+	"Connection",
+	"Keep-Alive",
+	"Te",
+	"Trailer",
+	"Upgrade",
+}
+
+// isNavigationRequest returns true if the request is a top level
+// document navigation or a form submission made by the user.
+func isNavigationRequest(r *http.Request) bool {
+	if r.Header.Get("Sec-Fetch-Mode") == "navigate" {
+		return true
+	}
+	contentType := r.Header.Get("Content-Type")
+	if r.Method == http.MethodPost && (strings.HasPrefix(contentType, "application/x-www-form-urlencoded") || strings.HasPrefix(contentType, "multipart/form-data")) {
+		return true
+	}
+	return r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html")
+}
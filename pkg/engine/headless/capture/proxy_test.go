@@ -0,0 +1,91 @@
+package capture
+
+import (
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyCapturesNavigations(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>ok</html>"))
+	}))
+	defer ts.Close()
+
+	var (
+		mu       sync.Mutex
+		captured []*navigation.Request
+	)
+	proxy, err := New(Options{
+		ListenAddress: "127.0.0.1:0",
+		OnRequest: func(req *navigation.Request) {
+			mu.Lock()
+			captured = append(captured, req)
+			mu.Unlock()
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, proxy.Start())
+	defer func() {
+		_ = proxy.Close()
+	}()
+
+	proxyURL, _ := url.Parse("http://" + proxy.Addr())
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/page", nil)
+	req.Header.Set("Accept", "text/html")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	require.Equal(t, "<html>ok</html>", string(body))
+
+	req, _ = http.NewRequest(http.MethodPost, ts.URL+"/login", strings.NewReader("user=a&pass=b"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	// sub-resource requests are not recorded
+	resp, err = client.Get(ts.URL + "/app.js")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, captured, 2)
+	require.Equal(t, ts.URL+"/page", captured[0].URL)
+	require.Equal(t, http.MethodPost, captured[1].Method)
+	require.Equal(t, "user=a&pass=b", captured[1].Body)
+}
+
+func TestAuthorityCertificateFor(t *testing.T) {
+	dir := t.TempDir()
+	authority, err := LoadOrCreateAuthority(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"))
+	require.NoError(t, err)
+
+	cert, err := authority.CertificateFor("example.com")
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.cert)
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: roots})
+	require.NoError(t, err)
+
+	reloaded, err := LoadOrCreateAuthority(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"))
+	require.NoError(t, err)
+	require.True(t, reloaded.cert.Equal(authority.cert))
+}
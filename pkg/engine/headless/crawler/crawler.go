@@ -2,19 +2,20 @@ package crawler
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/user"
 	"path/filepath"
-	"regexp"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/adrianbrad/queue"
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
-	"github.com/go-rod/rod/lib/utils"
+	rodutils "github.com/go-rod/rod/lib/utils"
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
@@ -25,22 +26,66 @@ import (
 	"github.com/projectdiscovery/katana/pkg/engine/headless/graph"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
 	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/katana/pkg/utils/formbudget"
+	"github.com/projectdiscovery/katana/pkg/utils/headerrules"
+	"github.com/projectdiscovery/katana/pkg/utils/hostmap"
+	"github.com/projectdiscovery/katana/pkg/utils/resourceblock"
+	"github.com/projectdiscovery/katana/pkg/utils/throttle"
 )
 
 type Crawler struct {
 	logger        *slog.Logger
 	launcher      *browser.Launcher
 	options       Options
-	crawlQueue    queue.Queue[*types.Action]
+	crawlQueue    *actionQueue
 	crawlGraph    *graph.CrawlGraph
 	simhashOracle *simhash.Oracle
-	uniqueActions map[string]struct{}
+	// stateHasher decides which page states are the same state
+	stateHasher   StateHasher
+	uniqueActions *actionSet
 	diagnostics   diagnostics.Writer
+	// timings are the phase timings of the processed actions
+	timings phaseTimings
+	// politeness paces the actions executed on each host
+	politeness *politeness
+	// snapshots archives the DOM of unique page states when set
+	snapshots *snapshotArchive
+	// localStorage and sessionStorage are the storage items of the
+	// crawled origins persisted with the storage state of the target
+	// along with the session cookies set by the auth actions
+	storageMu       sync.Mutex
+	authCookies     []*proto.NetworkCookieParam
+	localStorage    map[string]map[string]string
+	sessionStorage  map[string]map[string]string
+	storageRestored map[*browser.BrowserPage]struct{}
+
+	// mixedContentSeen are the reported mixed content findings
+	mixedContentMu   sync.Mutex
+	mixedContentSeen map[string]struct{}
+
+	// reauthPending is true when an action was kicked back to the login
+	// page, reauthAttempts are the re-authentications since an action
+	// last reached another page and loginRetried the hashes of the
+	// actions retried after a re-authentication
+	reauthMu       sync.Mutex
+	reauthPending  bool
+	reauthAttempts int
+	loginRetried   map[string]struct{}
+
+	// crashRetried are the hashes of the actions retried after a browser
+	// crash, they are only accessed by the crawl loop
+	crashRetried map[string]struct{}
 }
 
 type Options struct {
+	// ControlURL is the devtools url of a remote browser the crawl
+	// is driven in instead of local browsers, if set
+	ControlURL          string
 	ChromiumPath        string
 	MaxBrowsers         int
+	MinBrowsers         int
+	BrowserIdleTimeout  time.Duration
 	MaxDepth            int
 	PageMaxTimeout      time.Duration
 	NoSandbox           bool
@@ -51,6 +96,8 @@ type Options struct {
 	Trace               bool
 	CookieConsentBypass bool
 	AutomaticFormFill   bool
+	// FormMarkers injects interaction markers into submitted forms
+	FormMarkers *utils.FormMarkers
 
 	// EnableDiagnostics enables the diagnostics mode
 	// which writes diagnostic information to a directory
@@ -62,46 +109,189 @@ type Options struct {
 	Logger          *slog.Logger
 	ScopeValidator  browser.ScopeValidator
 	RequestCallback func(*output.Result)
-	ChromeUser      *user.User
-	CaptchaHandler  *captcha.Handler
-}
+	// HostValidator blocks the browser requests to the hosts it rejects
+	// when set, eg. analytics and cdn hosts out of the host scope
+	HostValidator browser.ScopeValidator
+	// ErrorCallback is called with the actions that failed
+	ErrorCallback func(*types.Action, error)
+	// StateCallback is called with the in scope page states reached
+	StateCallback  func(*types.PageState)
+	ChromeUser     *user.User
+	CaptchaHandler *captcha.Handler
 
-var domNormalizer *normalizer.Normalizer
-var initOnce sync.Once
-var initError error
+	// AuthActions are executed before the crawl starts to
+	// authenticate the browser session.
+	AuthActions []*types.Action
+	// SessionCheck periodically verifies the authenticated session
+	// and re-runs the auth actions on logout when set
+	SessionCheck *SessionCheck
+	// LoginPage detects the actions kicked back to the login page, the
+	// auth actions are re-run and the actions retried when it is set
+	LoginPage *LoginPage
+	// LogoutPatterns match the elements which are not interacted with as
+	// they would end the session, nil uses DefaultLogoutPatterns and an
+	// empty list disables the detection.
+	LogoutPatterns LogoutPatterns
 
-func init() {
-	initOnce.Do(func() {
-		var err error
-		domNormalizer, err = normalizer.New()
-		if err != nil {
-			initError = errors.Wrap(err, "failed to create domnormalizer")
-		}
-	})
+	// SeedURLs are additional urls loaded at the start of the crawl
+	SeedURLs []string
+
+	// ExternalActions receives actions discovered outside of the
+	// crawler (eg. through the capture proxy). When set, the crawl
+	// keeps waiting for new actions once the queue is exhausted.
+	ExternalActions <-chan *types.Action
+	// ExternalIdleTimeout stops the crawl when no external action
+	// is received for the duration once the queue is exhausted.
+	ExternalIdleTimeout time.Duration
+
+	// Debugger receives the live crawl state when set
+	Debugger Debugger
+	// ActionScorer scores the queued actions to decide the order they
+	// are crawled in, DefaultActionScorer is used when nil
+	ActionScorer ActionScorer
+
+	// MaxUniqueActions is the maximum number of action hashes kept
+	// in memory for deduplication. Zero keeps all of them.
+	MaxUniqueActions int
+	// SpillUniqueActions writes evicted action hashes to disk
+	SpillUniqueActions bool
+
+	// ReducedMotion emulates prefers-reduced-motion and suppresses
+	// animations so that pages settle faster.
+	ReducedMotion bool
+	// DeterministicSeed seeds page randomness and clock when not zero
+	// so that page state hashes are stable across visits.
+	DeterministicSeed int
+
+	// HeaderRules are headers added to the requests matching their patterns
+	HeaderRules headerrules.Rules
+	// HostMap rewrites the requests to the mapped hosts to their target
+	HostMap hostmap.Map
+	// BlockedResources are the resource types of the requests failed
+	// before being sent, eg. images and fonts not needed by the crawl
+	BlockedResources resourceblock.Types
+	// FormBudget limits the auto-filled forms per host and their submissions
+	FormBudget *formbudget.Budget
+	// RateLimit limits the actions executed per host with the
+	// rate limit shared by the engines when set
+	RateLimit *throttle.Limiter
+	// Delay is the minimum delay between the actions on a host
+	Delay time.Duration
+	// ResourceTypes are the resource types of intercepted requests
+	// passed to RequestCallback, empty passes all of them
+	ResourceTypes browser.ResourceTypes
+	// CrossOriginFrames collects the navigations of cross-origin frames
+	CrossOriginFrames bool
+	// ScrollBudget is the maximum number of viewport scrolls per page
+	// state to load infinite feeds and lazy content, zero disables it
+	ScrollBudget int
+	// ErrorPageActions queues the navigations of page states served
+	// with an error status, they are only recorded in the graph otherwise
+	ErrorPageActions bool
+	// ClientCertificate is presented to the servers requesting one,
+	// their navigations fail fast when nil
+	ClientCertificate *tls.Certificate
+	// ClickSelectors are css or xpath selectors of elements clicked
+	// in addition to the ones found by the built-in heuristics
+	ClickSelectors []string
+	// NoClickSelectors are css or xpath selectors of elements never clicked
+	NoClickSelectors []string
+	// Normalizer normalizes the DOM of page states into their unique
+	// ids, a default normalizer is created for the crawler when nil
+	Normalizer *normalizer.Normalizer
+	// Similarity configures how near-duplicate page states are
+	// merged, the zero value uses DefaultSimilarity
+	Similarity Similarity
+	// StateHasher decides which page states are the same state, the
+	// DOM normalized with Normalizer and compared with Similarity is
+	// hashed when nil
+	StateHasher StateHasher
+
+	// ActionTimeouts are the timeouts of the different kinds of
+	// actions, unset ones fall back to PageMaxTimeout.
+	ActionTimeouts ActionTimeouts
+	// MaxStateDuration is the maximum duration of processing a single
+	// action and the page state it reaches, zero for no limit
+	MaxStateDuration time.Duration
+
+	// StorageStatePath is the file the cookies and local storage of
+	// the target are restored from and saved to. A restored session
+	// skips the auth actions.
+	StorageStatePath string
+	// SessionFile is the file the cookies, local and session storage
+	// are loaded from before the crawl and saved to after it in the
+	// Playwright storage state format. A loaded session skips the
+	// auth actions.
+	SessionFile string
+
+	// MixedContent reports the http subresources and form
+	// actions of https pages as findings
+	MixedContent bool
+
+	// SnapshotDir is the directory the raw and normalized DOM of
+	// unique page states are archived to when set
+	SnapshotDir string
+
+	// AcceptLanguages are the Accept-Language values the key pages are
+	// loaded with to discover locale specific routes, hreflang alternates
+	// of crawled pages are followed when set
+	AcceptLanguages []string
+
+	// GraphExportPath is the file the crawl graph is exported to
+	// in GraphExportFormat after the crawl when set
+	GraphExportPath   string
+	GraphExportFormat graph.ExportFormat
 }
 
 func New(opts Options) (*Crawler, error) {
-	if initError != nil {
-		return nil, initError
-	}
-
 	if opts.Logger == nil {
 		opts.Logger = slog.Default()
 	}
+	opts.ActionTimeouts = opts.ActionTimeouts.withDefaults(opts.PageMaxTimeout)
+	opts.Similarity = opts.Similarity.withDefaults()
+	if opts.LogoutPatterns == nil {
+		opts.LogoutPatterns = DefaultLogoutPatterns()
+	}
+
+	stateHasher := opts.StateHasher
+	if stateHasher == nil {
+		domNormalizer := opts.Normalizer
+		if domNormalizer == nil {
+			var err error
+			if domNormalizer, err = normalizer.New(); err != nil {
+				return nil, errors.Wrap(err, "failed to create domnormalizer")
+			}
+		}
+		stateHasher = &domStateHasher{normalizer: domNormalizer, similarity: opts.Similarity}
+	}
 
 	launcher, err := browser.NewLauncher(browser.LauncherOptions{
+		ControlURL:          opts.ControlURL,
 		ChromiumPath:        opts.ChromiumPath,
 		MaxBrowsers:         opts.MaxBrowsers,
+		MinBrowsers:         opts.MinBrowsers,
+		BrowserIdleTimeout:  opts.BrowserIdleTimeout,
 		PageMaxTimeout:      opts.PageMaxTimeout,
 		ShowBrowser:         opts.ShowBrowser,
 		RequestCallback:     opts.RequestCallback,
 		SlowMotion:          opts.SlowMotion,
 		ScopeValidator:      opts.ScopeValidator,
+		HostValidator:       opts.HostValidator,
 		ChromeUser:          opts.ChromeUser,
 		Trace:               opts.Trace,
 		CookieConsentBypass: opts.CookieConsentBypass,
 		NoSandbox:           opts.NoSandbox,
 		Proxy:               opts.Proxy,
+		ReducedMotion:       opts.ReducedMotion,
+		DeterministicSeed:   opts.DeterministicSeed,
+		HeaderRules:         opts.HeaderRules,
+		HostMap:             opts.HostMap,
+		BlockedResources:    opts.BlockedResources,
+		ResourceTypes:       opts.ResourceTypes,
+		CrossOriginFrames:   opts.CrossOriginFrames,
+		ClientCertificate:   opts.ClientCertificate,
+		ClickSelectors:      opts.ClickSelectors,
+		NoClickSelectors:    opts.NoClickSelectors,
 	})
 	if err != nil {
 		return nil, err
@@ -124,32 +314,79 @@ func New(opts Options) (*Crawler, error) {
 		opts.Logger.Info("Diagnostics enabled", slog.String("directory", directory))
 	}
 
+	uniqueActions, err := newActionSet(opts.MaxUniqueActions, opts.SpillUniqueActions)
+	if err != nil {
+		launcher.Close()
+		return nil, err
+	}
+
+	var snapshots *snapshotArchive
+	if opts.SnapshotDir != "" {
+		snapshots, err = newSnapshotArchive(opts.SnapshotDir)
+		if err != nil {
+			launcher.Close()
+			uniqueActions.Close()
+			return nil, err
+		}
+	}
+
 	crawler := &Crawler{
 		launcher:      launcher,
 		options:       opts,
 		logger:        opts.Logger,
-		uniqueActions: make(map[string]struct{}),
+		uniqueActions: uniqueActions,
 		diagnostics:   diagnosticsWriter,
+		snapshots:     snapshots,
 		simhashOracle: simhash.NewOracle(),
+		stateHasher:   stateHasher,
+		politeness:    newPoliteness(opts.RateLimit, opts.Delay),
+
+		localStorage:     make(map[string]map[string]string),
+		sessionStorage:   make(map[string]map[string]string),
+		storageRestored:  make(map[*browser.BrowserPage]struct{}),
+		mixedContentSeen: make(map[string]struct{}),
+		loginRetried:     make(map[string]struct{}),
+		crashRetried:     make(map[string]struct{}),
 	}
 	return crawler, nil
 }
 
 func (c *Crawler) Close() {
 	c.launcher.Close()
+	c.uniqueActions.Close()
 	if c.diagnostics != nil {
 		if err := c.diagnostics.Close(); err != nil {
 			c.logger.Warn("Failed to close diagnostics", slog.String("error", err.Error()))
 		}
 	}
+	if c.snapshots != nil {
+		if err := c.snapshots.Close(); err != nil {
+			c.logger.Warn("Failed to close snapshot archive", slog.String("error", err.Error()))
+		}
+	}
 }
 
 func (c *Crawler) GetCrawlGraph() *graph.CrawlGraph {
 	return c.crawlGraph
 }
 
+// UniqueActionStats returns the statistics of the action deduplication
+func (c *Crawler) UniqueActionStats() UniqueActionStats {
+	return c.uniqueActions.Stats()
+}
+
+// PoolStats returns the utilization of the browser pool
+func (c *Crawler) PoolStats() browser.PoolStats {
+	return c.launcher.PoolStats()
+}
+
 func (c *Crawler) Crawl(URL string) error {
 	defer func() {
+		if c.options.GraphExportPath != "" {
+			if err := c.crawlGraph.ExportFile(c.options.GraphExportPath, c.options.GraphExportFormat); err != nil {
+				c.logger.Error("Failed to export crawl graph", slog.String("error", err.Error()))
+			}
+		}
 		if c.diagnostics == nil {
 			return
 		}
@@ -165,8 +402,24 @@ func (c *Crawler) Crawl(URL string) error {
 		Depth:    0,
 		OriginID: emptyPageHash,
 	}}
+	for _, seedURL := range c.options.SeedURLs {
+		if seedURL == URL {
+			continue
+		}
+		// Seeds have no origin as they can be loaded from any state
+		actions = append(actions, &types.Action{
+			Type:  types.ActionTypeLoadURL,
+			Input: seedURL,
+			Depth: 0,
+		})
+	}
+	actions = append(actions, languageSweepActions(actions, c.options.AcceptLanguages)...)
 
-	crawlQueue := queue.NewLinked(actions)
+	scorer := c.options.ActionScorer
+	if scorer == nil {
+		scorer = DefaultActionScorer()
+	}
+	crawlQueue := newActionQueue(actions, scorer)
 	c.crawlQueue = crawlQueue
 
 	crawlGraph := graph.NewCrawlGraph()
@@ -202,108 +455,309 @@ func (c *Crawler) Crawl(URL string) error {
 		crawlTimeout = time.After(c.options.MaxCrawlDuration)
 	}
 
+	restored := false
+	if c.options.StorageStatePath != "" {
+		restored = c.restoreStorageState(c.options.StorageStatePath, LoadStorageState)
+		defer c.saveStorageState(c.options.StorageStatePath, (*StorageState).Save)
+	}
+	if c.options.SessionFile != "" {
+		restored = c.restoreStorageState(c.options.SessionFile, LoadSessionFile) || restored
+		defer c.saveStorageState(c.options.SessionFile, (*StorageState).SaveSessionFile)
+	}
+
+	if len(c.options.AuthActions) > 0 && !restored {
+		if err := c.executeAuthActions(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Actions are executed concurrently by one worker per browser of
+	// the pool, which grows with the queued actions. Finished workers
+	// report back to this loop which owns the failure accounting and
+	// decides when the crawl is done.
+	workers := max(c.options.MaxBrowsers, 1)
+	results := make(chan actionResult, workers)
+	inFlight := 0
+	defer func() {
+		// in-flight actions are cancelled and awaited before returning
+		cancel()
+		for ; inFlight > 0; inFlight-- {
+			<-results
+		}
+	}()
+
 	consecutiveFailures := 0
+	lastSessionCheck := time.Now()
 
 	for {
 		select {
 		case <-crawlTimeout:
 			c.logger.Debug("Max crawl duration reached, stopping crawl")
 			return nil
+		case result := <-results:
+			inFlight--
+			consecutiveFailures = c.handleActionResult(result, consecutiveFailures)
+			continue
 		default:
-			// Check for too many failures
-			if c.options.MaxFailureCount > 0 && consecutiveFailures >= c.options.MaxFailureCount {
-				c.logger.Warn("Too many consecutive failures, stopping crawl",
-					slog.Int("failures", consecutiveFailures),
-					slog.Int("max_allowed", c.options.MaxFailureCount),
-					slog.Int("remaining_actions", c.crawlQueue.Size()),
-				)
-				return nil
+		}
+
+		// Check for too many failures
+		if c.options.MaxFailureCount > 0 && consecutiveFailures >= c.options.MaxFailureCount {
+			c.logger.Warn("Too many consecutive failures, stopping crawl",
+				slog.Int("failures", consecutiveFailures),
+				slog.Int("max_allowed", c.options.MaxFailureCount),
+				slog.Int("remaining_actions", c.crawlQueue.Size()),
+			)
+			return nil
+		}
+
+		if c.options.SessionCheck != nil && len(c.options.AuthActions) > 0 && c.options.SessionCheck.due(lastSessionCheck) {
+			if err := c.ensureSession(ctx); err != nil {
+				return err
 			}
+			lastSessionCheck = time.Now()
+		}
+		if err := c.reauthenticate(ctx); err != nil {
+			return err
+		}
+
+		c.drainExternalActions()
+		c.debugQueue()
 
-			action, err := crawlQueue.Get()
-			if err == queue.ErrNoElementsAvailable {
+		// all browsers are busy or the queue is too shallow to open
+		// another browser, wait for an action to finish
+		if inFlight == workers || (inFlight > 0 && !c.launcher.PageAvailable(crawlQueue.Size())) {
+			consecutiveFailures = c.handleActionResult(<-results, consecutiveFailures)
+			inFlight--
+			continue
+		}
+
+		action, err := crawlQueue.Get()
+		if err == queue.ErrNoElementsAvailable && inFlight > 0 {
+			// running actions may still discover new actions
+			consecutiveFailures = c.handleActionResult(<-results, consecutiveFailures)
+			inFlight--
+			continue
+		}
+		if err == queue.ErrNoElementsAvailable {
+			if c.options.ExternalActions == nil {
 				c.logger.Debug("No more actions to process")
 				return nil
 			}
-			if err != nil {
-				return err
+			c.logger.Debug("No more actions to process, waiting for external actions")
+			var idleTimeout <-chan time.Time
+			if c.options.ExternalIdleTimeout > 0 {
+				idleTimeout = time.After(c.options.ExternalIdleTimeout)
 			}
-
-			if c.options.MaxDepth > 0 && action.Depth > c.options.MaxDepth {
-				continue
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-idleTimeout:
+				c.logger.Debug("No external actions received, stopping crawl")
+				return nil
+			case external, ok := <-c.options.ExternalActions:
+				if !ok {
+					return nil
+				}
+				action, err = external, nil
 			}
+		}
+		if err != nil {
+			return err
+		}
 
-			page, err := c.launcher.GetPageFromPool()
-			if err != nil {
-				return err
-			}
+		if c.options.MaxDepth > 0 && action.Depth > c.options.MaxDepth {
+			continue
+		}
+
+		page, err := c.launcher.GetPageFromPool()
+		if err != nil {
+			return err
+		}
 
-			page.Page = page.Context(ctx)
+		inFlight++
+		go func() {
+			results <- c.executeAction(ctx, action, page)
+		}()
+	}
+}
 
-			c.logger.Debug("Processing action",
-				slog.String("action", action.String()),
-			)
+// actionResult is the outcome of an action executed by a worker
+type actionResult struct {
+	action *types.Action
+	err    error
+	// stateExpired is true if the page state budget was exceeded
+	stateExpired bool
+	// crashed is true if the browser crashed during the action
+	crashed bool
+}
 
-			if err := c.crawlFn(ctx, action, page); err != nil {
-				if err == ErrNoCrawlingAction {
-					return nil
-				}
-				if errors.Is(err, ErrElementNotVisible) {
-					consecutiveFailures++
-					continue
-				}
-				var npe *rod.NoPointerEventsError
-				var ish *rod.InvisibleShapeError
-				if errors.As(err, &npe) || errors.As(err, &ish) {
-					c.logger.Debug("Skipping action as it is not visible",
-						slog.String("action", action.String()),
-						slog.String("error", err.Error()),
-					)
-					consecutiveFailures++
-					continue
-				}
-				var ne *rod.NavigationError
-				if errors.As(err, &ne) {
-					c.logger.Debug("Skipping action as navigation failed",
-						slog.String("action", action.String()),
-						slog.String("error", err.Error()),
-					)
-					consecutiveFailures++
-					continue
-				}
-				if errors.Is(err, ErrNoNavigationPossible) {
-					c.logger.Debug("Skipping action as no navigation possible", slog.String("action", action.String()))
-					consecutiveFailures++
-					continue
-				}
-				var msce *utils.MaxSleepCountError
-				if errors.As(err, &msce) {
-					c.logger.Debug("Skipping action as it is taking too long", slog.String("action", action.String()))
-					consecutiveFailures++
-					continue
-				}
+// executeAction executes a crawl action on a page of the pool
+func (c *Crawler) executeAction(ctx context.Context, action *types.Action, page *browser.BrowserPage) actionResult {
+	// the politeness wait is not accounted to the page state budget
+	page.Page = page.Context(ctx)
+	if err := c.politeness.wait(ctx, actionHost(action, page)); err != nil {
+		c.launcher.PutBrowserToPool(page)
+		return actionResult{action: action, err: err}
+	}
 
-				c.logger.Debug("Skipping action due to site-specific error",
-					slog.String("error", err.Error()),
-					slog.String("action", action.String()),
-				)
-				consecutiveFailures++
-				continue
-			}
+	// a page state stuck in endless scripts only consumes its own budget
+	stateCtx, stateCancel := ctx, context.CancelFunc(func() {})
+	if c.options.MaxStateDuration > 0 {
+		stateCtx, stateCancel = context.WithTimeout(ctx, c.options.MaxStateDuration)
+	}
+	defer stateCancel()
+
+	page.Page = page.Context(stateCtx)
+	if err := c.restoreAuthSession(page); err != nil {
+		c.logger.Debug("Could not restore auth session", slog.String("error", err.Error()))
+	}
 
-			consecutiveFailures = 0
+	c.logger.Debug("Processing action",
+		slog.String("action", action.String()),
+	)
+
+	started := time.Now()
+	err := c.crawlFn(stateCtx, action, page)
+	stateExpired := ctx.Err() == nil && errors.Is(stateCtx.Err(), context.DeadlineExceeded)
+	if c.options.Debugger != nil {
+		var actionErr error
+		if err != ErrNoCrawlingAction {
+			actionErr = err
 		}
+		c.options.Debugger.OnAction(action, time.Since(started), actionErr)
+	}
+	// crashed pages were replaced when they were put back to the pool
+	crashed := err != nil && err != ErrNoCrawlingAction && page.Crashed()
+	if crashed {
+		err = errors.Wrap(err, "browser crashed")
+	}
+	return actionResult{action: action, err: err, stateExpired: stateExpired, crashed: crashed}
+}
+
+// handleActionResult reports the error of a finished action returning
+// the updated number of consecutive failures of the crawl.
+func (c *Crawler) handleActionResult(result actionResult, consecutiveFailures int) int {
+	action, err := result.action, result.err
+	// no new actions from this state, the crawl ends once the
+	// queue is exhausted and no other action is running
+	if err == nil || err == ErrNoCrawlingAction {
+		return 0
+	}
+	// the action is retried once the session is re-authenticated
+	if errors.Is(err, ErrLoginPage) {
+		return consecutiveFailures
+	}
+	if result.crashed && c.retryCrashedAction(action) {
+		return consecutiveFailures
+	}
+	if c.diagnostics != nil {
+		if logErr := c.diagnostics.LogError(action, err); logErr != nil {
+			c.logger.Warn("Failed to log action error", slog.String("error", logErr.Error()))
+		}
+	}
+	if errors.Is(err, ErrElementNotVisible) {
+		return consecutiveFailures + 1
+	}
+	var npe *rod.NoPointerEventsError
+	var ish *rod.InvisibleShapeError
+	if errors.As(err, &npe) || errors.As(err, &ish) {
+		c.logger.Debug("Skipping action as it is not visible",
+			slog.String("action", action.String()),
+			slog.String("error", err.Error()),
+		)
+		return consecutiveFailures + 1
+	}
+	// actions on invisible elements are expected to fail and are not reported
+	if c.options.ErrorCallback != nil {
+		c.options.ErrorCallback(action, err)
+	}
+	if result.stateExpired {
+		c.logger.Debug("Skipping action as page state budget was exceeded",
+			slog.String("action", action.String()),
+			slog.Duration("budget", c.options.MaxStateDuration),
+		)
+		return consecutiveFailures + 1
+	}
+	var ne *rod.NavigationError
+	if errors.As(err, &ne) {
+		c.logger.Debug("Skipping action as navigation failed",
+			slog.String("action", action.String()),
+			slog.String("error", err.Error()),
+		)
+		return consecutiveFailures + 1
+	}
+	if errors.Is(err, ErrNoNavigationPossible) {
+		c.logger.Debug("Skipping action as no navigation possible", slog.String("action", action.String()))
+		return consecutiveFailures + 1
+	}
+	var msce *rodutils.MaxSleepCountError
+	if errors.As(err, &msce) {
+		c.logger.Debug("Skipping action as it is taking too long", slog.String("action", action.String()))
+		return consecutiveFailures + 1
 	}
+
+	c.logger.Debug("Skipping action due to site-specific error",
+		slog.String("error", err.Error()),
+		slog.String("action", action.String()),
+	)
+	return consecutiveFailures + 1
 }
 
 var ErrNoCrawlingAction = errors.New("no more actions to crawl")
 
+// retryCrashedAction requeues an action interrupted by a browser crash
+// to be retried on a new browser, actions are only retried once.
+func (c *Crawler) retryCrashedAction(action *types.Action) bool {
+	actionHash := action.Hash()
+	if _, ok := c.crashRetried[actionHash]; ok {
+		return false
+	}
+	c.crashRetried[actionHash] = struct{}{}
+	if err := c.crawlQueue.Offer(action); err != nil {
+		c.logger.Debug("Could not requeue crashed action", slog.String("error", err.Error()))
+		return false
+	}
+	c.logger.Warn("Browser crashed, retrying action on a new browser",
+		slog.String("action", action.String()),
+	)
+	return true
+}
+
+// drainExternalActions moves all pending external actions into the crawl queue
+func (c *Crawler) drainExternalActions() {
+	if c.options.ExternalActions == nil {
+		return
+	}
+	for {
+		select {
+		case action, ok := <-c.options.ExternalActions:
+			if !ok {
+				return
+			}
+			if err := c.crawlQueue.Offer(action); err != nil {
+				c.logger.Debug("Could not queue external action", slog.String("error", err.Error()))
+			}
+		default:
+			return
+		}
+	}
+}
+
 func (c *Crawler) crawlFn(ctx context.Context, action *types.Action, page *browser.BrowserPage) error {
 	defer func() {
 		c.launcher.PutBrowserToPool(page)
 	}()
+	timings := make(diagnostics.ActionTimings)
+	defer c.recordTimings(action, timings)
+
+	// the elements added to the state the page was left in
+	// are collected before the action moves it elsewhere
+	c.offerMutationNavigations(page)
 
-	currentPageHash, _, err := getPageHash(page)
+	hashingStarted := time.Now()
+	currentPageHash, _, err := c.getPageHash(page)
+	timings.Since(diagnostics.HashingPhase, hashingStarted)
 	if err != nil {
 		return err
 	}
@@ -319,7 +773,9 @@ func (c *Crawler) crawlFn(ctx context.Context, action *types.Action, page *brows
 			slog.String("from", currentPageHash),
 			slog.String("to", action.OriginID),
 		)
+		navigateBackStarted := time.Now()
 		newPageHash, err := c.navigateBackToStateOrigin(action, page, currentPageHash)
+		timings.Since(diagnostics.NavigateBackPhase, navigateBackStarted)
 		if err != nil {
 			return err
 		}
@@ -336,9 +792,28 @@ func (c *Crawler) crawlFn(ctx context.Context, action *types.Action, page *brows
 			return err
 		}
 	}
-	if err := c.executeCrawlStateAction(action, page); err != nil {
+	// only the route changes made by the action are collected
+	if _, err := page.TakeHistoryNavigations(); err != nil {
+		c.logger.Debug("Could not reset history navigations", slog.String("error", err.Error()))
+	}
+	var screenshotBefore []byte
+	if c.diagnostics != nil {
+		screenshotBefore = c.actionScreenshot(page, timings)
+	}
+	// the waits of earlier phases are accounted to them
+	page.TakeWaited()
+	executeStarted := time.Now()
+	err = c.executeCrawlStateAction(action, page)
+	waited := page.TakeWaited()
+	timings[diagnostics.WaitPhase] += waited
+	timings[diagnostics.ExecutePhase] += time.Since(executeStarted) - waited
+	if err != nil {
 		return err
 	}
+	c.adoptPopups(page, action, currentPageHash)
+	if screenshotBefore != nil {
+		c.logActionScreenshots(page, action, screenshotBefore, timings)
+	}
 
 	// Check for captcha pages after navigation and attempt to solve them.
 	// On success, wait for the page to settle and re-enter crawlFn so navigation
@@ -361,16 +836,41 @@ func (c *Crawler) crawlFn(ctx context.Context, action *types.Action, page *brows
 		}
 	}
 
-	pageState, err := newPageState(page, action)
+	hashingStarted = time.Now()
+	pageState, err := c.newPageState(page, action)
+	timings.Since(diagnostics.HashingPhase, hashingStarted)
 	if err != nil {
 		return err
 	}
+	pageState.StatusCode = page.DocumentStatus()
+	// the reached state is recorded to verify replays of the action
+	action.ResultID = pageState.UniqueID
+	if err := c.checkLoginPage(action, pageState); err != nil {
+		return err
+	}
+	if c.isTranslatedDuplicate(action, pageState) {
+		c.logger.Debug("Skipping translated duplicate page state",
+			slog.String("url", pageState.URL),
+			slog.String("language", action.Language),
+		)
+		if c.crawlQueue.Size() == 0 {
+			return ErrNoCrawlingAction
+		}
+		return nil
+	}
 	if c.diagnostics != nil {
 		if err := c.diagnostics.LogPageState(pageState, diagnostics.PostActionPageState); err != nil {
 			return err
 		}
 	}
 	pageState.OriginID = currentPageHash
+	c.debugPageState(page, pageState)
+	if c.persistsStorage() {
+		c.captureStorage(page)
+	}
+	if c.options.MixedContent {
+		c.detectMixedContent(page, pageState)
+	}
 
 	if c.options.ScopeValidator != nil {
 		if !c.options.ScopeValidator(pageState.URL) {
@@ -384,19 +884,76 @@ func (c *Crawler) crawlFn(ctx context.Context, action *types.Action, page *brows
 		}
 	}
 
-	navigations, err := page.FindNavigations()
-	if err != nil {
-		return err
+	if c.options.StateCallback != nil {
+		c.options.StateCallback(pageState)
 	}
 
-	// Log navigations for diagnostics
-	if c.diagnostics != nil {
-		screenshotState, err := page.Screenshot(false, &proto.PageCaptureScreenshot{
+	// Error pages are recorded in the graph, their links are collected
+	// from the response body but their elements are not interacted with
+	if pageState.IsErrorPage() && !c.options.ErrorPageActions {
+		c.logger.Debug("Skipping navigation collection - current page is an error page",
+			slog.String("url", pageState.URL),
+			slog.Int("status_code", pageState.StatusCode),
+		)
+		if err := c.crawlGraph.AddPageState(*pageState); err != nil {
+			return err
+		}
+		if c.crawlQueue.Size() == 0 {
+			return ErrNoCrawlingAction
+		}
+		return nil
+	}
+
+	// The diagnostics screenshot is taken while the navigations are extracted
+	var (
+		screenshotState    []byte
+		screenshotDuration time.Duration
+		screenshotDone     = make(chan struct{})
+	)
+	extractionStarted := time.Now()
+	go func() {
+		defer close(screenshotDone)
+		if c.diagnostics == nil {
+			return
+		}
+		screenshotStarted := time.Now()
+		screenshot, err := page.Screenshot(false, &proto.PageCaptureScreenshot{
 			Format: proto.PageCaptureScreenshotFormatPng,
 		})
+		screenshotDuration = time.Since(screenshotStarted)
 		if err != nil {
 			c.logger.Error("Failed to take screenshot", slog.String("error", err.Error()))
 		}
+		screenshotState = screenshot
+	}()
+	// elements added once the document was scanned are collected
+	// later without scanning it again
+	if err := page.ObserveMutations(pageState.UniqueID); err != nil {
+		c.logger.Debug("Could not observe mutations", slog.String("error", err.Error()))
+	}
+	navigations, err := page.FindNavigations()
+	<-screenshotDone
+	timings[diagnostics.ScreenshotPhase] += screenshotDuration
+	if err != nil {
+		timings.Since(diagnostics.FindNavigationsPhase, extractionStarted)
+		return err
+	}
+	if c.options.ScrollBudget > 0 {
+		navigations = c.scrollNavigations(page, navigations)
+	}
+	timings.Since(diagnostics.FindNavigationsPhase, extractionStarted)
+	c.logger.Debug("Extracted navigations",
+		slog.Int("count", len(navigations)),
+		slog.Duration("duration", time.Since(extractionStarted)),
+	)
+
+	if len(c.options.AcceptLanguages) > 0 {
+		navigations = append(navigations, c.hreflangActions(page, pageState)...)
+	}
+	navigations = append(navigations, c.historyRouteActions(page, pageState)...)
+
+	// Log navigations for diagnostics
+	if c.diagnostics != nil {
 		if err := c.diagnostics.LogPageStateScreenshot(pageState.UniqueID, screenshotState); err != nil {
 			c.logger.Error("Failed to log page state screenshot", slog.String("error", err.Error()))
 		}
@@ -405,21 +962,50 @@ func (c *Crawler) crawlFn(ctx context.Context, action *types.Action, page *brows
 		}
 	}
 
+	if err := c.offerNavigations(navigations, pageState.UniqueID); err != nil {
+		return err
+	}
+
+	err = c.crawlGraph.AddPageState(*pageState)
+	if err != nil {
+		return err
+	}
+	if c.snapshots != nil {
+		if err := c.snapshots.Store(pageState); err != nil {
+			c.logger.Warn("Failed to archive page state", slog.String("error", err.Error()))
+		}
+	}
+
+	if len(navigations) == 0 && c.crawlQueue.Size() == 0 {
+		return ErrNoCrawlingAction
+	}
+	return nil
+}
+
+// offerNavigations queues the navigations which were not seen before
+// to be performed from the state originID
+func (c *Crawler) offerNavigations(navigations []*types.Action, originID string) error {
 	for _, nav := range navigations {
 		actionHash := nav.Hash()
-		if _, ok := c.uniqueActions[actionHash]; ok {
+		if c.uniqueActions.Seen(actionHash) {
 			continue
 		}
-		c.uniqueActions[actionHash] = struct{}{}
 
-		// Check if the element we have is a logout page
-		if nav.Element != nil && isLogoutPage(nav.Element) {
-			c.logger.Debug("Skipping Found logout page",
+		// elements ending the session are not interacted with
+		if pattern, ok := c.options.LogoutPatterns.match(nav.Element); ok {
+			c.logger.Debug("Skipping logout element",
 				slog.String("url", nav.Element.Attributes["href"]),
+				slog.String("pattern", pattern),
 			)
+			if c.options.ErrorCallback != nil {
+				c.options.ErrorCallback(nav, errors.Wrapf(ErrLogoutElement, "matching %q", pattern))
+			}
 			continue
 		}
-		nav.OriginID = pageState.UniqueID
+		// urls can be loaded from any state without navigating back
+		if nav.Type != types.ActionTypeLoadURL {
+			nav.OriginID = originID
+		}
 
 		c.logger.Debug("Got new navigation",
 			slog.Any("navigation", nav),
@@ -428,49 +1014,60 @@ func (c *Crawler) crawlFn(ctx context.Context, action *types.Action, page *brows
 			return err
 		}
 	}
+	return nil
+}
 
-	err = c.crawlGraph.AddPageState(*pageState)
-	if err != nil {
-		return err
-	}
-
-	// TODO: Check if the page opened new sub pages and if so capture their
-	// navigation as well as close them so the state change can work.
+var ErrElementNotVisible = errors.New("element not visible")
 
-	if len(navigations) == 0 && c.crawlQueue.Size() == 0 {
-		return ErrNoCrawlingAction
+// clickInput returns the mouse button and the click count of a click action
+func clickInput(actionType types.ActionType) (proto.InputMouseButton, int) {
+	switch actionType {
+	case types.ActionTypeDoubleClick:
+		return proto.InputMouseButtonLeft, 2
+	case types.ActionTypeRightClick:
+		return proto.InputMouseButtonRight, 1
+	default:
+		return proto.InputMouseButtonLeft, 1
 	}
-	return nil
 }
 
-var ErrElementNotVisible = errors.New("element not visible")
+// hoverSettleWait is the DOM idle window waited for after hovering
+// an element so that the menus it opens can render
+const hoverSettleWait = time.Second
 
 func (c *Crawler) executeCrawlStateAction(action *types.Action, page *browser.BrowserPage) error {
 	var err error
 	switch action.Type {
 	case types.ActionTypeLoadURL:
 		// Apply a timeout to every critical Rod call.
-		pTimeout := page.Timeout(c.options.PageMaxTimeout)
+		pTimeout := page.Timeout(c.options.ActionTimeouts.Navigation)
 
-		if err := pTimeout.Navigate(action.Input); err != nil {
+		if action.Language != "" {
+			cleanup, err := page.SetExtraHeaders([]string{"Accept-Language", action.Language})
+			if err != nil {
+				return err
+			}
+			// Keep the header until the navigation has loaded
+			defer cleanup()
+		}
+		// error pages without a body are reported as failed navigations
+		if err := pTimeout.Navigate(action.Input); err != nil && !isErrorPageNavigation(err, page.DocumentStatus()) {
 			return err
 		}
-		if err = page.WaitPageLoadHeurisitics(); err != nil {
+		if err = page.WaitPageLoadWithin(c.options.ActionTimeouts.Navigation); err != nil {
 			return err
 		}
 	case types.ActionTypeFillForm:
-		if err := c.processForm(page, action.Form); err != nil {
+		if err := c.processForm(page, action.Form, action.OriginID); err != nil {
 			return err
 		}
-	case types.ActionTypeLeftClick, types.ActionTypeLeftClickDown:
-		pTimeout := page.Timeout(c.options.PageMaxTimeout)
-		element, err := pTimeout.ElementX(action.Element.XPath)
+	case types.ActionTypeLeftClick, types.ActionTypeLeftClickDown, types.ActionTypeDoubleClick, types.ActionTypeRightClick:
+		element, err := c.findElement(page, action.Element)
 		if err != nil {
 			return err
 		}
 
-		elementTimeout := element.Timeout(c.options.PageMaxTimeout)
-		if err := elementTimeout.ScrollIntoView(); err != nil {
+		if err := element.Timeout(c.options.ActionTimeouts.Scroll).ScrollIntoView(); err != nil {
 			return err
 		}
 		visible, err := element.Visible()
@@ -494,21 +1091,153 @@ func (c *Crawler) executeCrawlStateAction(action *types.Action, page *browser.Br
 			return ErrElementNotVisible
 		}
 
-		if err := element.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		button, clickCount := clickInput(action.Type)
+		if err := element.Timeout(c.options.ActionTimeouts.ClickSettle).Click(button, clickCount); err != nil {
+			return err
+		}
+		if err = page.WaitPageLoadWithin(c.options.ActionTimeouts.ClickSettle); err != nil {
+			return err
+		}
+	case types.ActionTypeHover:
+		element, err := c.findElement(page, action.Element)
+		if err != nil {
+			return err
+		}
+		if err := element.Timeout(c.options.ActionTimeouts.Scroll).ScrollIntoView(); err != nil {
+			return err
+		}
+		visible, err := element.Visible()
+		if err != nil {
+			return err
+		}
+		if !visible {
+			return ErrElementNotVisible
+		}
+		if err := element.Timeout(c.options.ActionTimeouts.ClickSettle).Hover(); err != nil {
 			return err
 		}
-		if err = page.WaitPageLoadHeurisitics(); err != nil {
+		// menus render their links without navigating
+		_ = page.WaitNewStable(hoverSettleWait)
+	case types.ActionTypePressKey:
+		key, ok := keyboardKeys[action.Input]
+		if !ok {
+			return fmt.Errorf("unknown key: %v", action.Input)
+		}
+		if action.Element == nil {
+			if err := page.Keyboard.Type(key); err != nil {
+				return err
+			}
+		} else {
+			element, err := c.findElement(page, action.Element)
+			if err != nil {
+				return err
+			}
+			if err := element.Timeout(c.options.ActionTimeouts.Scroll).ScrollIntoView(); err != nil {
+				return err
+			}
+			visible, err := element.Visible()
+			if err != nil {
+				return err
+			}
+			if !visible {
+				return ErrElementNotVisible
+			}
+			// the element is focused before the key is sent
+			if err := element.Timeout(c.options.ActionTimeouts.ClickSettle).Type(key); err != nil {
+				return err
+			}
+		}
+		// keys may submit forms as well as open or close widgets
+		if err = page.WaitPageLoadWithin(c.options.ActionTimeouts.ClickSettle); err != nil {
 			return err
 		}
+	case types.ActionTypeSendKeys:
+		element, err := c.findElement(page, action.Element)
+		if err != nil {
+			return err
+		}
+		if err := element.Timeout(c.options.ActionTimeouts.Scroll).ScrollIntoView(); err != nil {
+			return err
+		}
+		elementTimeout := element.Timeout(c.options.PageMaxTimeout)
+		if err := elementTimeout.SelectAllText(); err != nil {
+			c.logger.Debug("Could not select element text", slog.String("error", err.Error()))
+		}
+		if err := elementTimeout.Input(action.Input); err != nil {
+			return err
+		}
+	case types.ActionTypeWait:
+		if action.Element != nil {
+			element, err := c.findElement(page, action.Element)
+			if err != nil {
+				return err
+			}
+			if err := element.Timeout(c.options.PageMaxTimeout).WaitVisible(); err != nil {
+				return err
+			}
+			return nil
+		}
+		waitMillis, err := strconv.Atoi(action.Input)
+		if err != nil {
+			return errors.Wrap(err, "invalid wait duration")
+		}
+		ctx := page.GetContext()
+		select {
+		case <-time.After(time.Duration(waitMillis) * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	default:
 		return fmt.Errorf("unknown action type: %v", action.Type)
 	}
 	return nil
 }
 
-var logoutPattern = regexp.MustCompile(`(?i)(log[\s-]?out|sign[\s-]?out|signout|deconnexion|cerrar[\s-]?sesion|sair|abmelden|uitloggen|ausloggen|exit|disconnect|terminate|end[\s-]?session|salir|desconectar|afmelden|wyloguj|logout|sign[\s-]?off)`)
+// executeAuthActions executes the authentication actions on a
+// browser page before the crawl starts.
+func (c *Crawler) executeAuthActions(ctx context.Context) error {
+	page, err := c.launcher.GetPageFromPool()
+	if err != nil {
+		return err
+	}
+	defer c.launcher.PutBrowserToPool(page)
+
+	page.Page = page.Context(ctx)
+	for _, action := range c.options.AuthActions {
+		c.logger.Debug("Executing auth action", slog.String("action", action.String()))
+
+		if err := c.executeCrawlStateAction(action, page); err != nil {
+			return errors.Wrapf(err, "could not execute auth action %s", action)
+		}
+	}
+
+	// Every pooled page runs in its own browser, so the session cookies
+	// are kept and restored on the pages used for crawling.
+	cookies, err := page.Browser.GetCookies()
+	if err != nil {
+		return errors.Wrap(err, "could not get auth session cookies")
+	}
+	c.storageMu.Lock()
+	c.authCookies = proto.CookiesToParams(cookies)
+	c.storageMu.Unlock()
 
-func isLogoutPage(element *types.HTMLElement) bool {
-	return logoutPattern.MatchString(element.TextContent) ||
-		logoutPattern.MatchString(element.Attributes["href"])
+	// Leave the page on the empty state crawl actions originate from
+	if err := page.Timeout(c.options.PageMaxTimeout).Navigate("about:blank"); err != nil {
+		return errors.Wrap(err, "could not reset page after authentication")
+	}
+	return nil
+}
+
+// restoreAuthSession sets the authenticated session cookies on the page browser
+func (c *Crawler) restoreAuthSession(page *browser.BrowserPage) error {
+	if err := c.restoreStorage(page); err != nil {
+		return err
+	}
+	c.storageMu.Lock()
+	cookies := c.authCookies
+	c.storageMu.Unlock()
+	if len(cookies) == 0 {
+		return nil
+	}
+	return page.Browser.SetCookies(cookies)
 }
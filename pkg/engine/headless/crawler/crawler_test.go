@@ -0,0 +1,29 @@
+package crawler
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleActionResultCrashed(t *testing.T) {
+	c := &Crawler{
+		logger:       slog.Default(),
+		crawlQueue:   newActionQueue(nil, nil),
+		crashRetried: make(map[string]struct{}),
+	}
+	action := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/"}
+	crashed := actionResult{action: action, err: errors.New("browser crashed: websocket closed"), crashed: true}
+
+	require.Equal(t, 2, c.handleActionResult(crashed, 2), "crashed actions should not count as failures when retried")
+	require.Equal(t, 1, c.crawlQueue.Size(), "crashed actions should be requeued")
+	requeued, err := c.crawlQueue.Get()
+	require.NoError(t, err)
+	require.Same(t, action, requeued)
+
+	require.Equal(t, 3, c.handleActionResult(crashed, 2), "crashed actions should only be retried once")
+	require.Zero(t, c.crawlQueue.Size())
+}
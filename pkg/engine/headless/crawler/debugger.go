@@ -0,0 +1,102 @@
+package crawler
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// Debugger receives the live state of a crawl and can
+// skip or prioritize the actions waiting in its queue.
+type Debugger interface {
+	// OnQueue is called with the actions waiting in the queue
+	OnQueue(actions []*types.Action)
+	// OnAction is called once an action was executed
+	OnAction(action *types.Action, duration time.Duration, err error)
+	// OnPageState is called with every reached page state and its screenshot
+	OnPageState(state *types.PageState, screenshot []byte)
+	// Commands returns the queue commands issued since the last call
+	Commands() []DebugCommand
+}
+
+// DebugCommandType is the type of a queue command
+type DebugCommandType string
+
+const (
+	// DebugCommandSkip removes the action from the queue
+	DebugCommandSkip DebugCommandType = "skip"
+	// DebugCommandPrioritize moves the action to the front of the queue
+	DebugCommandPrioritize DebugCommandType = "prioritize"
+)
+
+// DebugCommand is a command on a queued action identified by its hash
+type DebugCommand struct {
+	Type       DebugCommandType
+	ActionHash string
+}
+
+// debugQueue applies the pending debugger commands to the crawl
+// queue and reports the queued actions to the debugger.
+func (c *Crawler) debugQueue() {
+	if c.options.Debugger == nil {
+		return
+	}
+	// The queue has no random access, so it is drained and
+	// refilled in the new order from the crawl loop.
+	actions := c.crawlQueue.Clear()
+	for _, command := range c.options.Debugger.Commands() {
+		actions = applyDebugCommand(actions, command)
+		if command.Type != DebugCommandPrioritize {
+			continue
+		}
+		// prioritized actions lead the queue whatever their score
+		for i := len(actions) - 1; i >= 0; i-- {
+			if actions[i].Hash() == command.ActionHash {
+				c.crawlQueue.promote(actions[i])
+			}
+		}
+	}
+	for _, action := range actions {
+		if err := c.crawlQueue.Offer(action); err != nil {
+			c.logger.Debug("Could not requeue action", slog.String("error", err.Error()))
+		}
+	}
+	c.options.Debugger.OnQueue(actions)
+}
+
+func applyDebugCommand(actions []*types.Action, command DebugCommand) []*types.Action {
+	var (
+		matched   []*types.Action
+		remaining = make([]*types.Action, 0, len(actions))
+	)
+	for _, action := range actions {
+		if action.Hash() == command.ActionHash {
+			matched = append(matched, action)
+			continue
+		}
+		remaining = append(remaining, action)
+	}
+	if command.Type == DebugCommandPrioritize {
+		return append(matched, remaining...)
+	}
+	return remaining
+}
+
+// debugPageState reports the page state and its screenshot to the debugger
+func (c *Crawler) debugPageState(page *browser.BrowserPage, state *types.PageState) {
+	if c.options.Debugger == nil {
+		return
+	}
+	quality := 50
+	screenshot, err := page.Screenshot(false, &proto.PageCaptureScreenshot{
+		Format:  proto.PageCaptureScreenshotFormatJpeg,
+		Quality: &quality,
+	})
+	if err != nil {
+		c.logger.Debug("Could not take debugger screenshot", slog.String("error", err.Error()))
+	}
+	c.options.Debugger.OnPageState(state, screenshot)
+}
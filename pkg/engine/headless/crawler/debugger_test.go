@@ -0,0 +1,24 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDebugCommand(t *testing.T) {
+	first := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/a"}
+	second := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/b"}
+	third := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/c"}
+	actions := []*types.Action{first, second, third}
+
+	prioritized := applyDebugCommand(actions, DebugCommand{Type: DebugCommandPrioritize, ActionHash: third.Hash()})
+	require.Equal(t, []*types.Action{third, first, second}, prioritized)
+
+	skipped := applyDebugCommand(actions, DebugCommand{Type: DebugCommandSkip, ActionHash: second.Hash()})
+	require.Equal(t, []*types.Action{first, third}, skipped)
+
+	unknown := applyDebugCommand(actions, DebugCommand{Type: DebugCommandSkip, ActionHash: "missing"})
+	require.Equal(t, actions, unknown)
+}
@@ -19,6 +19,75 @@ type Writer interface {
 	LogPageState(state *types.PageState, stateType PageStateType) error
 	LogNavigations(pageStateID string, navigations []*types.Action) error
 	LogPageStateScreenshot(pageStateID string, screenshot []byte) error
+	LogError(action *types.Action, err error) error
+	LogTimings(action *types.Action, timings ActionTimings) error
+	LogActionScreenshots(action *types.Action, before, after []byte) error
+}
+
+// EventType is the type of a diagnostics event
+type EventType string
+
+var (
+	ActionEvent     EventType = "action"
+	PageStateEvent  EventType = "page-state"
+	NavigationEvent EventType = "navigation"
+	ErrorEvent      EventType = "error"
+	ScreenshotEvent EventType = "screenshot"
+	TimingEvent     EventType = "timing"
+	// ActionScreenshotEvent are the screenshots taken before and after an action
+	ActionScreenshotEvent EventType = "action-screenshot"
+)
+
+// Phase is a phase of processing an action
+type Phase string
+
+var (
+	// NavigateBackPhase navigates back to the origin state of the action
+	NavigateBackPhase Phase = "navigate-back"
+	// ExecutePhase executes the action excluding the page load waits
+	ExecutePhase Phase = "execute"
+	// WaitPhase waits for the page to load after the action
+	WaitPhase Phase = "wait"
+	// FindNavigationsPhase extracts the navigations of the reached state
+	FindNavigationsPhase Phase = "find-navigations"
+	// HashingPhase normalizes and hashes the DOM of the page states
+	HashingPhase Phase = "hashing"
+	// ScreenshotPhase takes the diagnostics screenshot of the reached state
+	ScreenshotPhase Phase = "screenshot"
+)
+
+// Phases are the phases of processing an action in the order they run
+var Phases = []Phase{NavigateBackPhase, ExecutePhase, WaitPhase, FindNavigationsPhase, HashingPhase, ScreenshotPhase}
+
+// ActionTimings are the durations of the phases of processing an action
+type ActionTimings map[Phase]time.Duration
+
+// Since adds the duration since start to the phase
+func (t ActionTimings) Since(phase Phase, start time.Time) {
+	t[phase] += time.Since(start)
+}
+
+// Event is an entry of the events.jsonl diagnostics stream
+type Event struct {
+	Timestamp   time.Time       `json:"timestamp"`
+	Type        EventType       `json:"type"`
+	PageStateID string          `json:"page_state_id,omitempty"`
+	URL         string          `json:"url,omitempty"`
+	Title       string          `json:"title,omitempty"`
+	StateType   PageStateType   `json:"state_type,omitempty"`
+	Action      *types.Action   `json:"action,omitempty"`
+	Navigations []*types.Action `json:"navigations,omitempty"`
+	// Screenshot is the path of the screenshot relative to the directory
+	Screenshot string `json:"screenshot,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Timings are the durations of the phases of the action in milliseconds
+	Timings map[Phase]float64 `json:"timings_ms,omitempty"`
+	// ScreenshotAfter is the path of the screenshot taken after the action
+	ScreenshotAfter string `json:"screenshot_after,omitempty"`
+	// DiffScore is the fraction of the pixels changed by the action
+	DiffScore *float64 `json:"diff_score,omitempty"`
+	// NoEffect is true if the action did not visibly change the page
+	NoEffect bool `json:"no_effect,omitempty"`
 }
 
 type PageStateType string
@@ -33,6 +102,12 @@ type diskWriter struct {
 	actions   []*types.Action
 	mu        sync.Mutex
 	directory string
+
+	events   *os.File
+	eventsMu sync.Mutex
+
+	// noEffectActions are the actions which did not visibly change the page
+	noEffectActions []*noEffectAction
 }
 
 type stateMetadata struct {
@@ -43,6 +118,16 @@ type stateMetadata struct {
 	Type      string `json:"type"`
 }
 
+// noEffectAction is an action whose screenshots before and after it was
+// performed are the same, such as a click on an element which is not
+// clickable, written to no-effect-actions.json to tune the discovery
+type noEffectAction struct {
+	Action    *types.Action `json:"action"`
+	DiffScore float64       `json:"diff_score"`
+	// Screenshots is the directory of the screenshots of the action
+	Screenshots string `json:"screenshots"`
+}
+
 type navigationEntry struct {
 	PageStateID     string          `json:"page_state_id"`
 	URL             string          `json:"url"`
@@ -56,19 +141,44 @@ func NewWriter(directory string) (Writer, error) {
 	if err := os.MkdirAll(directory, 0755); err != nil {
 		return nil, err
 	}
+	events, err := os.Create(filepath.Join(directory, "events.jsonl"))
+	if err != nil {
+		return nil, err
+	}
 
 	return &diskWriter{
 		directory: directory,
 		index:     mapsutil.NewOrderedMap[string, *stateMetadata](),
 		actions:   make([]*types.Action, 0),
 		mu:        sync.Mutex{},
+		events:    events,
 	}, nil
 }
 
+// writeEvent appends an event to the events stream
+func (w *diskWriter) writeEvent(event *Event) error {
+	event.Timestamp = time.Now()
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	w.eventsMu.Lock()
+	defer w.eventsMu.Unlock()
+	_, err = w.events.Write(append(data, '\n'))
+	return err
+}
+
 func (w *diskWriter) Close() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	w.eventsMu.Lock()
+	if err := w.events.Close(); err != nil {
+		w.eventsMu.Unlock()
+		return err
+	}
+	w.eventsMu.Unlock()
+
 	actionsList := w.actions
 	marshallIndented, err := json.MarshalIndent(actionsList, "", "  ")
 	if err != nil {
@@ -78,6 +188,16 @@ func (w *diskWriter) Close() error {
 		return err
 	}
 
+	if len(w.noEffectActions) > 0 {
+		marshallIndented, err = json.MarshalIndent(w.noEffectActions, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(w.directory, "no-effect-actions.json"), marshallIndented, 0644); err != nil {
+			return err
+		}
+	}
+
 	// Write index to a separate file
 	var data []*stateMetadata
 	w.index.Iterate(func(key string, value *stateMetadata) bool {
@@ -97,10 +217,32 @@ func (w *diskWriter) LogAction(action *types.Action) error {
 	defer w.mu.Unlock()
 
 	w.actions = append(w.actions, action)
-	return nil
+	return w.writeEvent(&Event{Type: ActionEvent, Action: action})
+}
+
+func (w *diskWriter) LogError(action *types.Action, err error) error {
+	return w.writeEvent(&Event{Type: ErrorEvent, Action: action, Error: err.Error()})
+}
+
+func (w *diskWriter) LogTimings(action *types.Action, timings ActionTimings) error {
+	milliseconds := make(map[Phase]float64, len(timings))
+	for phase, duration := range timings {
+		milliseconds[phase] = float64(duration) / float64(time.Millisecond)
+	}
+	return w.writeEvent(&Event{Type: TimingEvent, Action: action, Timings: milliseconds})
 }
 
 func (w *diskWriter) LogPageState(state *types.PageState, stateType PageStateType) error {
+	if err := w.writeEvent(&Event{
+		Type:        PageStateEvent,
+		PageStateID: state.UniqueID,
+		URL:         state.URL,
+		Title:       state.Title,
+		StateType:   stateType,
+	}); err != nil {
+		return err
+	}
+
 	w.mu.Lock()
 	val, ok := w.index.Get(state.UniqueID)
 	if ok && val != nil {
@@ -152,6 +294,15 @@ func (w *diskWriter) LogNavigations(pageStateID string, navigations []*types.Act
 		return err
 	}
 
+	if err := w.writeEvent(&Event{
+		Type:        NavigationEvent,
+		PageStateID: pageStateID,
+		URL:         url,
+		Navigations: navigations,
+	}); err != nil {
+		return err
+	}
+
 	navigationsFile := filepath.Join(dir, "navigations.json")
 
 	var entry navigationEntry
@@ -190,5 +341,86 @@ func (w *diskWriter) LogPageStateScreenshot(pageStateID string, screenshot []byt
 		return err
 	}
 	screenshotFile := filepath.Join(dir, "screenshot.png")
-	return os.WriteFile(screenshotFile, screenshot, 0644)
+	if err := os.WriteFile(screenshotFile, screenshot, 0644); err != nil {
+		return err
+	}
+	return w.writeEvent(&Event{
+		Type:        ScreenshotEvent,
+		PageStateID: pageStateID,
+		Screenshot:  filepath.Join(pageStateID, "screenshot.png"),
+	})
+}
+
+// LogActionScreenshots writes the screenshots taken before and after an
+// action and their diff score, flagging the action if it had no effect.
+func (w *diskWriter) LogActionScreenshots(action *types.Action, before, after []byte) error {
+	score, err := ScreenshotDiff(before, after)
+	if err != nil {
+		return err
+	}
+	noEffect := score < NoEffectThreshold
+
+	dir := filepath.Join("actions", action.Hash())
+	if err := os.MkdirAll(filepath.Join(w.directory, dir), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(w.directory, dir, "before.png"), before, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(w.directory, dir, "after.png"), after, 0644); err != nil {
+		return err
+	}
+
+	if noEffect {
+		w.mu.Lock()
+		w.noEffectActions = append(w.noEffectActions, &noEffectAction{Action: action, DiffScore: score, Screenshots: dir})
+		w.mu.Unlock()
+	}
+	return w.writeEvent(&Event{
+		Type:            ActionScreenshotEvent,
+		Action:          action,
+		Screenshot:      filepath.Join(dir, "before.png"),
+		ScreenshotAfter: filepath.Join(dir, "after.png"),
+		DiffScore:       &score,
+		NoEffect:        noEffect,
+	})
+}
+
+// LoadActions loads the actions logged to a diagnostics directory
+// in the order they were executed.
+func LoadActions(directory string) ([]*types.Action, error) {
+	data, err := os.ReadFile(filepath.Join(directory, "actions.json"))
+	if err != nil {
+		return nil, err
+	}
+	var actions []*types.Action
+	if err := json.Unmarshal(data, &actions); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// LoadPageStateSnapshot loads the url and the dom of a page
+// state logged to a diagnostics directory.
+func LoadPageStateSnapshot(directory, pageStateID string) (string, string, error) {
+	data, err := os.ReadFile(filepath.Join(directory, "index.json"))
+	if err != nil {
+		return "", "", err
+	}
+	var index []*stateMetadata
+	if err := json.Unmarshal(data, &index); err != nil {
+		return "", "", err
+	}
+	var url string
+	for _, state := range index {
+		if state.UniqueID == pageStateID {
+			url = state.URL
+			break
+		}
+	}
+	dom, err := os.ReadFile(filepath.Join(directory, pageStateID, "dom.html"))
+	if err != nil {
+		return "", "", err
+	}
+	return url, string(dom), nil
 }
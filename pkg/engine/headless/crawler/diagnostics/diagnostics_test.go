@@ -0,0 +1,135 @@
+package diagnostics
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStream(t *testing.T) {
+	directory := t.TempDir()
+	writer, err := NewWriter(directory)
+	require.NoError(t, err)
+
+	action := &types.Action{Type: types.ActionTypeLeftClick, OriginID: "state"}
+	require.NoError(t, writer.LogAction(action))
+	require.NoError(t, writer.LogPageState(&types.PageState{UniqueID: "state", URL: "https://example.com"}, PreActionPageState))
+	require.NoError(t, writer.LogNavigations("state", []*types.Action{action}))
+	require.NoError(t, writer.LogPageStateScreenshot("state", []byte("png")))
+	require.NoError(t, writer.LogError(action, errors.New("element not visible")))
+	require.NoError(t, writer.Close())
+
+	file, err := os.Open(filepath.Join(directory, "events.jsonl"))
+	require.NoError(t, err)
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var events []*Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		event := &Event{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), event))
+		require.False(t, event.Timestamp.IsZero())
+		events = append(events, event)
+	}
+	require.Len(t, events, 5)
+	require.Equal(t, ActionEvent, events[0].Type)
+	require.Equal(t, PageStateEvent, events[1].Type)
+	require.Equal(t, "https://example.com", events[1].URL)
+	require.Equal(t, NavigationEvent, events[2].Type)
+	require.Len(t, events[2].Navigations, 1)
+	require.Equal(t, ScreenshotEvent, events[3].Type)
+	require.Equal(t, filepath.Join("state", "screenshot.png"), events[3].Screenshot)
+	require.Equal(t, ErrorEvent, events[4].Type)
+	require.Equal(t, "element not visible", events[4].Error)
+}
+
+func TestLoadRecordedActions(t *testing.T) {
+	directory := t.TempDir()
+	writer, err := NewWriter(directory)
+	require.NoError(t, err)
+
+	action := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com", ResultID: "state"}
+	require.NoError(t, writer.LogAction(action))
+	require.NoError(t, writer.LogPageState(&types.PageState{
+		UniqueID: "state",
+		URL:      "https://example.com",
+		DOM:      "<html><head></head><body>example</body></html>",
+	}, PostActionPageState))
+	require.NoError(t, writer.Close())
+
+	actions, err := LoadActions(directory)
+	require.NoError(t, err)
+	require.Equal(t, []*types.Action{action}, actions)
+
+	url, dom, err := LoadPageStateSnapshot(directory, "state")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com", url)
+	require.Equal(t, "<html><head></head><body>example</body></html>", dom)
+
+	_, _, err = LoadPageStateSnapshot(directory, "missing")
+	require.Error(t, err)
+}
+
+func TestTimingEvent(t *testing.T) {
+	directory := t.TempDir()
+	writer, err := NewWriter(directory)
+	require.NoError(t, err)
+
+	action := &types.Action{Type: types.ActionTypeLeftClick, OriginID: "state"}
+	timings := make(ActionTimings)
+	timings[WaitPhase] += 1500 * time.Millisecond
+	timings[ExecutePhase] += 250 * time.Microsecond
+	require.NoError(t, writer.LogTimings(action, timings))
+	require.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(filepath.Join(directory, "events.jsonl"))
+	require.NoError(t, err)
+	event := &Event{}
+	require.NoError(t, json.Unmarshal(data, event))
+	require.Equal(t, TimingEvent, event.Type)
+	require.Equal(t, map[Phase]float64{WaitPhase: 1500, ExecutePhase: 0.25}, event.Timings)
+}
+
+func TestActionScreenshots(t *testing.T) {
+	directory := t.TempDir()
+	writer, err := NewWriter(directory)
+	require.NoError(t, err)
+
+	click := &types.Action{Type: types.ActionTypeLeftClick, Element: &types.HTMLElement{TagName: "DIV", CSSSelector: "div.card"}}
+	load := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com"}
+	require.NoError(t, writer.LogActionScreenshots(click, testScreenshot(t, 10, 10, 0), testScreenshot(t, 10, 10, 0)))
+	require.NoError(t, writer.LogActionScreenshots(load, testScreenshot(t, 10, 10, 0), testScreenshot(t, 10, 10, 10)))
+	require.NoError(t, writer.Close())
+
+	require.FileExists(t, filepath.Join(directory, "actions", click.Hash(), "before.png"))
+	require.FileExists(t, filepath.Join(directory, "actions", click.Hash(), "after.png"))
+
+	data, err := os.ReadFile(filepath.Join(directory, "no-effect-actions.json"))
+	require.NoError(t, err)
+	var noEffect []*noEffectAction
+	require.NoError(t, json.Unmarshal(data, &noEffect))
+	require.Len(t, noEffect, 1, "only actions without a visible effect should be flagged")
+	require.Equal(t, click.Hash(), noEffect[0].Action.Hash())
+	require.Equal(t, filepath.Join("actions", click.Hash()), noEffect[0].Screenshots)
+
+	data, err = os.ReadFile(filepath.Join(directory, "events.jsonl"))
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	require.Len(t, lines, 2)
+	event := &Event{}
+	require.NoError(t, json.Unmarshal(lines[1], event))
+	require.Equal(t, ActionScreenshotEvent, event.Type)
+	require.False(t, event.NoEffect)
+	require.NotNil(t, event.DiffScore)
+	require.InDelta(t, 0.1, *event.DiffScore, 1e-9)
+}
@@ -0,0 +1,68 @@
+package diagnostics
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// NoEffectThreshold is the screenshot diff score under which
+	// an action is considered to have had no visible effect
+	NoEffectThreshold = 0.001
+	// pixelTolerance is the per channel difference (16 bit) under which
+	// pixels are considered equal, absorbing antialiasing and compression
+	pixelTolerance = 8 << 8
+)
+
+// ScreenshotDiff returns the fraction of the pixels which differ between
+// two png screenshots, from 0 for identical screenshots to 1. Pixels
+// outside of the overlap of screenshots of different sizes differ.
+func ScreenshotDiff(before, after []byte) (float64, error) {
+	beforeImage, err := png.Decode(bytes.NewReader(before))
+	if err != nil {
+		return 0, errors.Wrap(err, "could not decode before screenshot")
+	}
+	afterImage, err := png.Decode(bytes.NewReader(after))
+	if err != nil {
+		return 0, errors.Wrap(err, "could not decode after screenshot")
+	}
+	return imageDiff(beforeImage, afterImage), nil
+}
+
+func imageDiff(before, after image.Image) float64 {
+	beforeBounds, afterBounds := before.Bounds(), after.Bounds()
+	total := max(beforeBounds.Dx()*beforeBounds.Dy(), afterBounds.Dx()*afterBounds.Dy())
+	if total == 0 {
+		return 0
+	}
+	width := min(beforeBounds.Dx(), afterBounds.Dx())
+	height := min(beforeBounds.Dy(), afterBounds.Dy())
+
+	differing := total - width*height
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !similarPixels(before.At(beforeBounds.Min.X+x, beforeBounds.Min.Y+y), after.At(afterBounds.Min.X+x, afterBounds.Min.Y+y)) {
+				differing++
+			}
+		}
+	}
+	return float64(differing) / float64(total)
+}
+
+func similarPixels(a, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return channelDiff(ar, br) <= pixelTolerance && channelDiff(ag, bg) <= pixelTolerance &&
+		channelDiff(ab, bb) <= pixelTolerance && channelDiff(aa, ba) <= pixelTolerance
+}
+
+func channelDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,45 @@
+package diagnostics
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testScreenshot returns a white png screenshot with the
+// first changed pixels of its first row painted black
+func testScreenshot(t *testing.T, width, height, changed int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+	for x := 0; x < changed; x++ {
+		img.Set(x, 0, color.Black)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestScreenshotDiff(t *testing.T) {
+	score, err := ScreenshotDiff(testScreenshot(t, 10, 10, 0), testScreenshot(t, 10, 10, 0))
+	require.NoError(t, err)
+	require.Zero(t, score)
+
+	score, err = ScreenshotDiff(testScreenshot(t, 10, 10, 0), testScreenshot(t, 10, 10, 5))
+	require.NoError(t, err)
+	require.InDelta(t, 0.05, score, 1e-9)
+
+	score, err = ScreenshotDiff(testScreenshot(t, 10, 10, 0), testScreenshot(t, 10, 20, 0))
+	require.NoError(t, err)
+	require.InDelta(t, 0.5, score, 1e-9, "pixels outside of the overlap should differ")
+
+	_, err = ScreenshotDiff([]byte("png"), testScreenshot(t, 1, 1, 0))
+	require.Error(t, err)
+}
@@ -0,0 +1,21 @@
+package crawler
+
+import (
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+)
+
+// httpResponseCodeFailure is the navigation error chrome reports
+// for error responses served without a body
+const httpResponseCodeFailure = "net::ERR_HTTP_RESPONSE_CODE_FAILURE"
+
+// isErrorPageNavigation returns true if a navigation failed only because
+// the document was served with an error status. Such navigations still
+// reach a page state which is recorded like any other.
+func isErrorPageNavigation(err error, status int) bool {
+	var ne *rod.NavigationError
+	if !errors.As(err, &ne) || ne.Reason != httpResponseCodeFailure {
+		return false
+	}
+	return status >= 400
+}
@@ -0,0 +1,18 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsErrorPageNavigation(t *testing.T) {
+	failure := &rod.NavigationError{Reason: httpResponseCodeFailure}
+	require.True(t, isErrorPageNavigation(failure, 404))
+	require.True(t, isErrorPageNavigation(errors.Wrap(failure, "could not navigate"), 500), "wrapped errors should be unwrapped")
+	require.False(t, isErrorPageNavigation(failure, 0), "failures without a recorded status should be reported")
+	require.False(t, isErrorPageNavigation(&rod.NavigationError{Reason: "net::ERR_NAME_NOT_RESOLVED"}, 404))
+	require.False(t, isErrorPageNavigation(errors.New("timeout"), 404))
+}
@@ -3,12 +3,16 @@ package crawler
 import (
 	"fmt"
 	"log/slog"
+	"net/url"
+	"strings"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/projectdiscovery/katana/pkg/navigation"
 	utilsformfill "github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/katana/pkg/utils/formbudget"
 	mapsutil "github.com/projectdiscovery/utils/maps"
 )
 
@@ -57,24 +61,76 @@ func convertHTMLElementToFormSelect(element *types.HTMLElement) utilsformfill.Fo
 	}
 }
 
-func (c *Crawler) processForm(page *browser.BrowserPage, form *types.HTMLForm) error {
+// formHost returns the host the form is submitted to
+func formHost(form *types.HTMLForm) string {
+	parsed, err := url.Parse(form.Action)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// formSubmission describes the submission of a form found in the page
+// state originID, the source chain is the action path to the state.
+func (c *Crawler) formSubmission(form *types.HTMLForm, originID string) *utilsformfill.FormSubmission {
+	method := strings.ToUpper(form.Method)
+	if method == "" {
+		method = "GET"
+	}
+	submission := &utilsformfill.FormSubmission{Action: form.Action, Method: method}
+	if state, err := c.crawlGraph.GetPageState(originID); err == nil {
+		submission.Source = state.URL
+	}
+	if actions, err := c.crawlGraph.ShortestPath(emptyPageHash, originID); err == nil {
+		for _, action := range actions {
+			link := navigation.SourceLink{Tag: string(action.Type), Attribute: action.Input}
+			if action.Element != nil {
+				link.Attribute = action.Element.String()
+			}
+			if origin, err := c.crawlGraph.GetPageState(action.OriginID); err == nil {
+				link.URL = origin.URL
+			}
+			submission.SourceChain = append(submission.SourceChain, link)
+		}
+	}
+	submission.SourceChain = append(submission.SourceChain, navigation.SourceLink{URL: submission.Source, Tag: "form", Attribute: "action"})
+	return submission
+}
+
+func (c *Crawler) processForm(page *browser.BrowserPage, form *types.HTMLForm, originID string) error {
 	if !c.options.AutomaticFormFill {
 		return nil
 	}
+	if !c.options.FormBudget.Allow(formHost(form), formbudget.Key(form.Method, form.Action)) {
+		c.logger.Debug("Skipping form over the form budget", slog.String("action", form.Action))
+		return nil
+	}
+	page, err := page.FrameAt(form.FramePath)
+	if err != nil {
+		return err
+	}
 
 	var formFields []interface{}
 	var submitButton *rod.Element
 	elementMap := make(map[string]*rod.Element)
 
 	for _, field := range form.Elements {
-		if field.XPath == "" {
+		var (
+			element *rod.Element
+			err     error
+		)
+		switch {
+		case field.XPath != "":
+			element, err = page.ElementX(field.XPath)
+		case browser.IsShadowSelector(field.CSSSelector):
+			element, err = browser.ShadowElement(page.Page, field.CSSSelector)
+		default:
 			continue
 		}
-
-		element, err := page.ElementX(field.XPath)
 		if err != nil {
 			c.logger.Debug("Could not find form element",
 				slog.String("xpath", field.XPath),
+				slog.String("selector", field.CSSSelector),
 				slog.String("error", err.Error()),
 			)
 			continue
@@ -118,14 +174,31 @@ func (c *Crawler) processForm(page *browser.BrowserPage, form *types.HTMLForm) e
 		}
 	}
 
+	var submission *utilsformfill.FormSubmission
+	if c.options.FormMarkers != nil {
+		submission = c.formSubmission(form, originID)
+	}
 	fillSuggestions := utilsformfill.FormFillSuggestions(formFields)
+	c.options.FormMarkers.InjectFields(&fillSuggestions, submission)
 
 	if err := c.applyFormSuggestions(fillSuggestions, elementMap); err != nil {
 		c.logger.Debug("Error applying form suggestions", slog.String("error", err.Error()))
 	}
 
 	if submitButton != nil {
-		if err := submitButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		if headers := c.options.FormMarkers.HeaderValues(submission); len(headers) > 0 {
+			cleanup, err := page.SetExtraHeaders(flattenHeaders(headers))
+			if err != nil {
+				c.logger.Debug("Failed to set marker headers", slog.String("error", err.Error()))
+			} else {
+				// Keep the headers until the submission navigation has loaded
+				defer cleanup()
+			}
+		}
+		if err := submitButton.Timeout(c.options.ActionTimeouts.FormSubmit).Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return err
+		}
+		if err := page.WaitPageLoadWithin(c.options.ActionTimeouts.FormSubmit); err != nil {
 			return err
 		}
 	}
@@ -238,3 +311,12 @@ func (c *Crawler) applyFormSuggestions(suggestions mapsutil.OrderedMap[string, s
 
 	return nil
 }
+
+// flattenHeaders converts headers to the key value pairs used by rod
+func flattenHeaders(headers map[string]string) []string {
+	dict := make([]string, 0, len(headers)*2)
+	for k, v := range headers {
+		dict = append(dict, k, v)
+	}
+	return dict
+}
@@ -0,0 +1,40 @@
+package crawler
+
+import (
+	"log/slog"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// historyRouteActions returns load url actions for the routes an action
+// passed through with the history api before settling on the route of
+// the state, so that each of them is crawled as its own state.
+func (c *Crawler) historyRouteActions(page *browser.BrowserPage, state *types.PageState) []*types.Action {
+	navigations, err := page.TakeHistoryNavigations()
+	if err != nil {
+		c.logger.Debug("Could not collect history navigations", slog.String("error", err.Error()))
+		return nil
+	}
+	return intermediateRouteActions(navigations, state, c.options.ScopeValidator)
+}
+
+func intermediateRouteActions(navigations []*browser.HistoryNavigation, state *types.PageState, scopeValidator browser.ScopeValidator) []*types.Action {
+	seen := map[string]struct{}{state.URL: {}}
+	var actions []*types.Action
+	for _, navigation := range navigations {
+		if _, ok := seen[navigation.URL]; ok || navigation.URL == "" {
+			continue
+		}
+		seen[navigation.URL] = struct{}{}
+		if scopeValidator != nil && !scopeValidator(navigation.URL) {
+			continue
+		}
+		actions = append(actions, &types.Action{
+			Type:  types.ActionTypeLoadURL,
+			Input: navigation.URL,
+			Depth: state.Depth,
+		})
+	}
+	return actions
+}
@@ -0,0 +1,29 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntermediateRouteActions(t *testing.T) {
+	state := &types.PageState{URL: "https://example.com/app/orders/1", Depth: 2}
+	navigations := []*browser.HistoryNavigation{
+		{URL: "https://example.com/app/orders", Source: "pushState"},
+		{URL: "https://other.com/callback", Source: "pushState"},
+		{URL: "https://example.com/app/orders", Source: "popstate"},
+		{URL: "https://example.com/app/orders/1", Source: "replaceState"},
+	}
+	inScope := func(url string) bool { return strings.HasPrefix(url, "https://example.com/") }
+
+	actions := intermediateRouteActions(navigations, state, inScope)
+	require.Len(t, actions, 1, "routes should be unique, in scope and distinct from the state route")
+	require.Equal(t, types.ActionTypeLoadURL, actions[0].Type)
+	require.Equal(t, "https://example.com/app/orders", actions[0].Input)
+	require.Equal(t, state.Depth, actions[0].Depth)
+
+	require.Empty(t, intermediateRouteActions(nil, state, inScope))
+}
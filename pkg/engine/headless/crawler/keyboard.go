@@ -0,0 +1,17 @@
+package crawler
+
+import (
+	"github.com/go-rod/rod/lib/input"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// keyboardKeys are the keys press key actions can send
+var keyboardKeys = map[string]input.Key{
+	types.KeyEnter:      input.Enter,
+	types.KeyEscape:     input.Escape,
+	types.KeyTab:        input.Tab,
+	types.KeyArrowDown:  input.ArrowDown,
+	types.KeyArrowUp:    input.ArrowUp,
+	types.KeyArrowLeft:  input.ArrowLeft,
+	types.KeyArrowRight: input.ArrowRight,
+}
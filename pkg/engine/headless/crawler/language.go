@@ -0,0 +1,86 @@
+package crawler
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// hreflangScript collects the hreflang alternates of a page
+const hreflangScript = `() => {
+	const alternates = [];
+	for (const link of document.querySelectorAll('link[rel~="alternate"][hreflang][href]')) {
+		alternates.push({url: link.href, language: link.hreflang});
+	}
+	return alternates;
+}`
+
+type hreflangAlternate struct {
+	URL      string `json:"url"`
+	Language string `json:"language"`
+}
+
+// languageSweepActions returns copies of the load url actions of the
+// key pages which load them with each of the accept languages.
+func languageSweepActions(actions []*types.Action, languages []string) []*types.Action {
+	var sweep []*types.Action
+	for _, action := range actions {
+		if action.Type != types.ActionTypeLoadURL || action.Language != "" {
+			continue
+		}
+		for _, language := range languages {
+			localized := *action
+			localized.Language = language
+			sweep = append(sweep, &localized)
+		}
+	}
+	return sweep
+}
+
+// hreflangActions returns load url actions for the in-scope hreflang
+// alternates of the page, loaded with their language so that servers
+// negotiating the locale serve the alternate.
+func (c *Crawler) hreflangActions(page *browser.BrowserPage, state *types.PageState) []*types.Action {
+	result, err := page.Eval(hreflangScript)
+	if err != nil {
+		c.logger.Debug("Could not collect hreflang alternates", slog.String("error", err.Error()))
+		return nil
+	}
+	var alternates []hreflangAlternate
+	if err := result.Value.Unmarshal(&alternates); err != nil {
+		return nil
+	}
+
+	var actions []*types.Action
+	for _, alternate := range alternates {
+		if alternate.URL == "" || alternate.URL == state.URL {
+			continue
+		}
+		if c.options.ScopeValidator != nil && !c.options.ScopeValidator(alternate.URL) {
+			continue
+		}
+		action := &types.Action{
+			Type:  types.ActionTypeLoadURL,
+			Input: alternate.URL,
+			Depth: state.Depth,
+		}
+		if language := strings.TrimSpace(alternate.Language); !strings.EqualFold(language, "x-default") {
+			action.Language = language
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// isTranslatedDuplicate returns true if a page loaded with another
+// language normalizes to an already crawled state. Text content is
+// stripped by the normalizer so translations of a page share a state.
+func (c *Crawler) isTranslatedDuplicate(action *types.Action, state *types.PageState) bool {
+	if action == nil || action.Language == "" {
+		return false
+	}
+	existing, err := c.crawlGraph.GetPageState(state.UniqueID)
+	return err == nil && existing != nil
+}
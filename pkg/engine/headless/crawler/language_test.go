@@ -0,0 +1,27 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguageSweepActions(t *testing.T) {
+	root := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com", OriginID: emptyPageHash}
+	click := &types.Action{Type: types.ActionTypeLeftClick, Element: &types.HTMLElement{TagName: "A"}}
+	localized := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/de", Language: "de"}
+
+	sweep := languageSweepActions([]*types.Action{root, click, localized}, []string{"de", "fr-FR"})
+	require.Len(t, sweep, 2, "only unlocalized load url actions should be swept")
+	require.Equal(t, "de", sweep[0].Language)
+	require.Equal(t, "fr-FR", sweep[1].Language)
+	require.Equal(t, root.Input, sweep[1].Input)
+	require.Equal(t, emptyPageHash, sweep[1].OriginID)
+	require.Empty(t, root.Language, "the original action should not be modified")
+
+	require.NotEqual(t, root.Hash(), sweep[0].Hash(), "localized loads should not be deduplicated with the original")
+	require.NotEqual(t, sweep[0].Hash(), sweep[1].Hash())
+
+	require.Empty(t, languageSweepActions([]*types.Action{root}, nil))
+}
@@ -0,0 +1,89 @@
+package crawler
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// ErrLogoutElement is reported for the elements skipped as they
+// would end the session
+var ErrLogoutElement = errors.New("skipped logout element")
+
+// defaultLogoutPattern matches the logout elements of common languages
+const defaultLogoutPattern = `(?i)(log[\s-]?out|sign[\s-]?out|signout|deconnexion|cerrar[\s-]?sesion|sair|abmelden|uitloggen|ausloggen|exit|disconnect|terminate|end[\s-]?session|salir|desconectar|afmelden|wyloguj|logout|sign[\s-]?off)`
+
+// noLogoutPatterns disables the logout detection
+const noLogoutPatterns = "none"
+
+// LogoutPatterns match the text or the href of the elements
+// which end the session when they are interacted with
+type LogoutPatterns []*regexp.Regexp
+
+// DefaultLogoutPatterns returns the default logout patterns
+func DefaultLogoutPatterns() LogoutPatterns {
+	return LogoutPatterns{regexp.MustCompile(defaultLogoutPattern)}
+}
+
+var defaultLogoutPatterns = DefaultLogoutPatterns()
+
+// ParseLogoutPatterns parses the logout patterns, patterns prefixed
+// with + are added to the default ones which are replaced by the
+// others, and none disables the logout detection.
+func ParseLogoutPatterns(values []string) (LogoutPatterns, error) {
+	var (
+		added    LogoutPatterns
+		replaced LogoutPatterns
+		disabled bool
+	)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if strings.EqualFold(value, noLogoutPatterns) {
+			disabled = true
+			continue
+		}
+		pattern, add := strings.CutPrefix(value, "+")
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid logout pattern %q", value)
+		}
+		if add {
+			added = append(added, compiled)
+		} else {
+			replaced = append(replaced, compiled)
+		}
+	}
+
+	switch {
+	case disabled && (len(added) > 0 || len(replaced) > 0):
+		return nil, errors.Errorf("logout detection can not be disabled with %s and given patterns", noLogoutPatterns)
+	case disabled:
+		return LogoutPatterns{}, nil
+	case len(replaced) > 0:
+		return append(replaced, added...), nil
+	default:
+		return append(DefaultLogoutPatterns(), added...), nil
+	}
+}
+
+// match returns the pattern matching a logout element, if any,
+// nil patterns match with the default ones
+func (l LogoutPatterns) match(element *types.HTMLElement) (string, bool) {
+	if element == nil {
+		return "", false
+	}
+	if l == nil {
+		l = defaultLogoutPatterns
+	}
+	for _, pattern := range l {
+		if pattern.MatchString(element.TextContent) || pattern.MatchString(element.Attributes["href"]) {
+			return pattern.String(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,51 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogoutPatterns(t *testing.T) {
+	logout := &types.HTMLElement{TextContent: "Sign out"}
+	exit := &types.HTMLElement{TextContent: "Exit fullscreen"}
+	quit := &types.HTMLElement{Attributes: map[string]string{"href": "/account/quit"}}
+
+	patterns, err := ParseLogoutPatterns(nil)
+	require.NoError(t, err)
+	_, ok := patterns.match(logout)
+	require.True(t, ok, "default patterns should match logout elements")
+	_, ok = patterns.match(exit)
+	require.True(t, ok)
+	_, ok = patterns.match(nil)
+	require.False(t, ok)
+	_, ok = LogoutPatterns(nil).match(logout)
+	require.True(t, ok, "nil patterns should match with the defaults")
+
+	patterns, err = ParseLogoutPatterns([]string{"+/quit$"})
+	require.NoError(t, err)
+	pattern, ok := patterns.match(quit)
+	require.True(t, ok, "added patterns should match")
+	require.Equal(t, "/quit$", pattern)
+	_, ok = patterns.match(logout)
+	require.True(t, ok, "added patterns should keep the defaults")
+
+	patterns, err = ParseLogoutPatterns([]string{`(?i)sign\s?out`})
+	require.NoError(t, err)
+	_, ok = patterns.match(logout)
+	require.True(t, ok)
+	_, ok = patterns.match(exit)
+	require.False(t, ok, "replaced patterns should not match the defaults")
+
+	patterns, err = ParseLogoutPatterns([]string{"none"})
+	require.NoError(t, err)
+	require.NotNil(t, patterns)
+	_, ok = patterns.match(logout)
+	require.False(t, ok, "disabled patterns should match nothing")
+
+	_, err = ParseLogoutPatterns([]string{"none", "+quit"})
+	require.Error(t, err)
+	_, err = ParseLogoutPatterns([]string{"+("})
+	require.Error(t, err)
+}
@@ -0,0 +1,152 @@
+package crawler
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+)
+
+const (
+	// MixedContentTag is the tag of http subresources of https pages
+	MixedContentTag = "mixed-content"
+	// InsecureFormTag is the tag of http form actions of https pages
+	InsecureFormTag = "insecure-form"
+)
+
+// mixedContentScript collects the http subresources and form actions of
+// an https page. Blocked active content never reaches the resource
+// timing entries so the subresource attributes of the DOM are collected too.
+const mixedContentScript = `() => {
+	if (location.protocol !== 'https:') {
+		return null;
+	}
+	const resources = [];
+	for (const entry of performance.getEntriesByType('resource')) {
+		if (entry.name.startsWith('http:')) {
+			resources.push({url: entry.name, kind: entry.initiatorType});
+		}
+	}
+	for (const element of document.querySelectorAll('script[src], img[src], iframe[src], frame[src], audio[src], video[src], source[src], track[src], embed[src], object[data], link[rel~="stylesheet"][href]')) {
+		const url = element.src || element.data || element.href;
+		if (typeof url === 'string' && url.startsWith('http:')) {
+			resources.push({url: url, kind: element.tagName.toLowerCase()});
+		}
+	}
+	const forms = [];
+	for (const form of document.forms) {
+		if (typeof form.action === 'string' && form.action.startsWith('http:')) {
+			forms.push({url: form.action, kind: (form.method || 'get').toUpperCase()});
+		}
+	}
+	return {page: location.href, resources: resources, forms: forms};
+}`
+
+type mixedContentResource struct {
+	URL  string `json:"url"`
+	Kind string `json:"kind"`
+}
+
+type mixedContentReport struct {
+	Page      string                 `json:"page"`
+	Resources []mixedContentResource `json:"resources"`
+	Forms     []mixedContentResource `json:"forms"`
+}
+
+// passiveMixedContent are the kinds of subresources which browsers
+// display without executing them (passive or optionally-blockable content)
+var passiveMixedContent = map[string]struct{}{
+	"img":    {},
+	"image":  {},
+	"audio":  {},
+	"video":  {},
+	"source": {},
+	"track":  {},
+	"css":    {},
+}
+
+// detectMixedContent reports the http subresources and form
+// actions of the current https page of the browser
+func (c *Crawler) detectMixedContent(page *browser.BrowserPage, state *types.PageState) {
+	if c.options.RequestCallback == nil {
+		return
+	}
+	object, err := page.Eval(mixedContentScript)
+	if err != nil || object.Value.Nil() {
+		return
+	}
+	report := &mixedContentReport{}
+	if err := object.Value.Unmarshal(report); err != nil {
+		c.logger.Debug("Could not decode mixed content report", slog.String("error", err.Error()))
+		return
+	}
+	for _, result := range c.newMixedContentResults(report, state) {
+		c.options.RequestCallback(result)
+	}
+}
+
+// newMixedContentResults returns the findings of the report which were
+// not reported yet for the page
+func (c *Crawler) newMixedContentResults(report *mixedContentReport, state *types.PageState) []*output.Result {
+	c.mixedContentMu.Lock()
+	defer c.mixedContentMu.Unlock()
+
+	var results []*output.Result
+	add := func(tag string, resource mixedContentResource) {
+		key := tag + "|" + report.Page + "|" + resource.URL
+		if _, ok := c.mixedContentSeen[key]; ok {
+			return
+		}
+		c.mixedContentSeen[key] = struct{}{}
+		results = append(results, mixedContentResult(report.Page, tag, resource, state))
+	}
+	for _, resource := range report.Resources {
+		add(MixedContentTag, resource)
+	}
+	for _, form := range report.Forms {
+		add(InsecureFormTag, form)
+	}
+	return results
+}
+
+// mixedContentResult returns the finding of an http resource or
+// form action loaded by the https page
+func mixedContentResult(pageURL, tag string, resource mixedContentResource, state *types.PageState) *output.Result {
+	request := &navigation.Request{
+		Method:    http.MethodGet,
+		URL:       resource.URL,
+		Source:    pageURL,
+		Tag:       tag,
+		Attribute: strings.ToLower(resource.Kind),
+	}
+	if state != nil {
+		request.CustomFields = map[string][]string{"page_state": {state.UniqueID}}
+	}
+	finding := &output.Finding{
+		TemplateID: "mixed-content",
+		Name:       "HTTP " + request.Attribute + " loaded by HTTPS page",
+		Severity:   "medium",
+		Type:       MixedContentTag,
+		MatchedAt:  pageURL,
+	}
+	if _, ok := passiveMixedContent[request.Attribute]; ok {
+		finding.Severity = "low"
+	}
+	if tag == InsecureFormTag {
+		request.Method = strings.ToUpper(resource.Kind)
+		request.Attribute = "action"
+		finding.TemplateID = "insecure-form-action"
+		finding.Name = "Form of HTTPS page submitted over HTTP"
+		finding.Severity = "medium"
+	}
+	return &output.Result{
+		Timestamp: time.Now(),
+		Request:   request,
+		Finding:   finding,
+	}
+}
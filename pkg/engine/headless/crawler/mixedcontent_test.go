@@ -0,0 +1,39 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMixedContentResults(t *testing.T) {
+	c := &Crawler{mixedContentSeen: make(map[string]struct{})}
+	report := &mixedContentReport{
+		Page: "https://example.com/",
+		Resources: []mixedContentResource{
+			{URL: "http://cdn.example.com/app.js", Kind: "script"},
+			{URL: "http://cdn.example.com/logo.png", Kind: "img"},
+			{URL: "http://cdn.example.com/app.js", Kind: "script"},
+		},
+		Forms: []mixedContentResource{{URL: "http://example.com/login", Kind: "post"}},
+	}
+	state := &types.PageState{UniqueID: "state-1"}
+
+	results := c.newMixedContentResults(report, state)
+	require.Len(t, results, 3, "duplicate resources should be reported once")
+
+	require.Equal(t, MixedContentTag, results[0].Request.Tag)
+	require.Equal(t, "script", results[0].Request.Attribute)
+	require.Equal(t, "medium", results[0].Finding.Severity, "scripts are active mixed content")
+	require.Equal(t, "https://example.com/", results[0].Finding.MatchedAt)
+	require.Equal(t, []string{"state-1"}, results[0].Request.CustomFields["page_state"])
+
+	require.Equal(t, "low", results[1].Finding.Severity, "images are passive mixed content")
+
+	require.Equal(t, InsecureFormTag, results[2].Request.Tag)
+	require.Equal(t, "POST", results[2].Request.Method)
+	require.Equal(t, "insecure-form-action", results[2].Finding.TemplateID)
+
+	require.Empty(t, c.newMixedContentResults(report, state), "findings should be reported once per page")
+}
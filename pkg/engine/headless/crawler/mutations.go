@@ -0,0 +1,32 @@
+package crawler
+
+import (
+	"log/slog"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+)
+
+// offerMutationNavigations queues the navigations of the elements added
+// to the state the page was left in once it was scanned, such as the
+// content rendered by delayed requests, from that state.
+func (c *Crawler) offerMutationNavigations(page *browser.BrowserPage) {
+	originID, navigations, err := page.TakeMutationNavigations()
+	if err != nil {
+		c.logger.Debug("Could not collect mutation navigations", slog.String("error", err.Error()))
+		return
+	}
+	if len(navigations) == 0 {
+		return
+	}
+	// states whose crawl failed once they were observed are not in the graph
+	if state, err := c.crawlGraph.GetPageState(originID); err != nil || state == nil {
+		return
+	}
+	c.logger.Debug("Collected mutation navigations",
+		slog.String("origin_id", originID),
+		slog.Int("count", len(navigations)),
+	)
+	if err := c.offerNavigations(navigations, originID); err != nil {
+		c.logger.Debug("Could not queue mutation navigations", slog.String("error", err.Error()))
+	}
+}
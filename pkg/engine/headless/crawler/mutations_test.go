@@ -0,0 +1,29 @@
+package crawler
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfferNavigations(t *testing.T) {
+	uniqueActions, err := newActionSet(0, false)
+	require.NoError(t, err)
+	crawlQueue := newActionQueue(nil, nil)
+	c := &Crawler{logger: slog.Default(), crawlQueue: crawlQueue, uniqueActions: uniqueActions}
+
+	click := &types.Action{Type: types.ActionTypeLeftClick, Element: &types.HTMLElement{TagName: "A", Attributes: map[string]string{"href": "/orders"}}}
+	load := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/settings"}
+	logout := &types.Action{Type: types.ActionTypeLeftClick, Element: &types.HTMLElement{TagName: "A", Attributes: map[string]string{"href": "/logout"}}}
+	require.NoError(t, c.offerNavigations([]*types.Action{click, load, logout}, "state"))
+	require.Equal(t, 2, crawlQueue.Size(), "logout links should not be queued")
+	require.Equal(t, "state", click.OriginID)
+	require.Empty(t, load.OriginID, "urls should be loadable from any state")
+
+	// elements collected again from mutations of the state are not queued twice
+	again := &types.Action{Type: types.ActionTypeLeftClick, Element: &types.HTMLElement{TagName: "A", Attributes: map[string]string{"href": "/orders"}}}
+	require.NoError(t, c.offerNavigations([]*types.Action{again}, "state"))
+	require.Equal(t, 2, crawlQueue.Size())
+}
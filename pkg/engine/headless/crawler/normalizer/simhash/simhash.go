@@ -31,15 +31,41 @@ var bufferPool = sync.Pool{
 	},
 }
 
-func fingerprintOptimized(r io.Reader, shingle int) uint64 {
+// Weights are the weights of the kinds of features of a document in
+// its fingerprint, the features of a kind weighted zero are ignored.
+type Weights struct {
+	// Tags weights the start, end and self-closing tags and the doctype
+	Tags int
+	// Attributes weights the attribute names, along with
+	// the values of the class, name and rel attributes
+	Attributes int
+	// Text weights the text content
+	Text int
+	// Comments weights the comments
+	Comments int
+}
+
+// DefaultWeights weights all the kinds of features equally
+var DefaultWeights = Weights{Tags: 1, Attributes: 1, Text: 1, Comments: 1}
+
+// feature is a feature of a document with the weight of its kind
+type feature struct {
+	value  string
+	weight int
+}
+
+func fingerprintOptimized(r io.Reader, shingle int, weights Weights) uint64 {
 	if shingle < 1 {
 		shingle = 1
 	}
+	if weights == (Weights{}) {
+		weights = DefaultWeights
+	}
 
 	v := simhash.Vector{}
 	z := html.NewTokenizer(r)
 
-	features := make([]string, 0, featuresBufSize)
+	features := make([]feature, 0, featuresBufSize)
 	window := make([][]byte, shingle)
 	windowIndex := 0
 
@@ -52,7 +78,7 @@ func fingerprintOptimized(r io.Reader, shingle int) uint64 {
 		t := z.Token()
 		count++
 
-		extractFeatures(&t, &features)
+		extractFeatures(&t, weights, &features)
 	}
 
 	// Process features with shingling
@@ -63,7 +89,7 @@ func fingerprintOptimized(r io.Reader, shingle int) uint64 {
 	}()
 
 	for _, f := range features {
-		window[windowIndex%shingle] = []byte(f)
+		window[windowIndex%shingle] = []byte(f.value)
 		windowIndex++
 
 		buf.Reset()
@@ -76,11 +102,12 @@ func fingerprintOptimized(r io.Reader, shingle int) uint64 {
 
 		sum := simhash.NewFeature(buf.Bytes()).Sum()
 
+		// shingles are weighted as the feature ending them
 		for i := uint8(0); i < 64; i++ {
 			if (sum>>i)&1 == 1 {
-				v[i]++
+				v[i] += f.weight
 			} else {
-				v[i]--
+				v[i] -= f.weight
 			}
 		}
 	}
@@ -88,10 +115,12 @@ func fingerprintOptimized(r io.Reader, shingle int) uint64 {
 	return simhash.Fingerprint(v)
 }
 
-// extractFeatures extracts features from HTML token and appends to slice
-func extractFeatures(t *html.Token, features *[]string) {
+// extractFeatures extracts features from HTML token and appends to slice,
+// skipping the features of the kinds weighted zero
+func extractFeatures(t *html.Token, weights Weights, features *[]feature) {
 	// Pre-allocate string builder for efficiency
 	var s string
+	weight := weights.Tags
 
 	switch t.Type {
 	case html.StartTagToken:
@@ -104,15 +133,22 @@ func extractFeatures(t *html.Token, features *[]string) {
 		s = "D:" + string(t.Data)
 	case html.CommentToken:
 		s = "E:" + string(t.Data)
+		weight = weights.Comments
 	case html.TextToken:
 		s = "F:" + string(t.Data)
+		weight = weights.Text
 	case html.ErrorToken:
 		s = "Z:" + string(t.Data)
 	default:
 		return
 	}
 
-	*features = append(*features, s)
+	if weight > 0 {
+		*features = append(*features, feature{value: s, weight: weight})
+	}
+	if weights.Attributes <= 0 {
+		return
+	}
 
 	// Process attributes
 	for _, attr := range t.Attr {
@@ -122,13 +158,19 @@ func extractFeatures(t *html.Token, features *[]string) {
 		default:
 			s = fmt.Sprintf("G:%s:%s", t.DataAtom.String(), attr.Key)
 		}
-		*features = append(*features, s)
+		*features = append(*features, feature{value: s, weight: weights.Attributes})
 	}
 }
 
 // Fingerprint is the original function signature for compatibility
 func Fingerprint(r io.Reader, shingle int) uint64 {
-	return fingerprintOptimized(r, shingle)
+	return fingerprintOptimized(r, shingle, DefaultWeights)
+}
+
+// FingerprintWeighted returns the fingerprint of the document with its
+// kinds of features weighted, zero weights use DefaultWeights
+func FingerprintWeighted(r io.Reader, shingle int, weights Weights) uint64 {
+	return fingerprintOptimized(r, shingle, weights)
 }
 
 type Oracle struct {
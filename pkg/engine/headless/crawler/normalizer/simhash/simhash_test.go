@@ -70,3 +70,20 @@ func TestOracle(t *testing.T) {
 		t.Fatalf("oracle should recognise fingerprint within distance %d", r)
 	}
 }
+
+// TestFingerprintWeights checks that ignoring a kind of features makes documents
+// differing only by these features identical, and that default weights are used
+// when none are set.
+func TestFingerprintWeights(t *testing.T) {
+	noText := Weights{Tags: 1, Attributes: 1, Comments: 1}
+	fpA := FingerprintWeighted(strings.NewReader(htmlA), 3, noText)
+	fpB := FingerprintWeighted(strings.NewReader(htmlB), 3, noText)
+	if fpA != fpB {
+		t.Fatalf("expected identical fingerprints when text is ignored, got distance %d", Distance(fpA, fpB))
+	}
+
+	fpDefault := Fingerprint(strings.NewReader(htmlA), 3)
+	if fp := FingerprintWeighted(strings.NewReader(htmlA), 3, Weights{}); fp != fpDefault {
+		t.Fatalf("expected unset weights to match the default fingerprint, got %d and %d", fp, fpDefault)
+	}
+}
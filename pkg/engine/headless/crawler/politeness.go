@@ -0,0 +1,80 @@
+package crawler
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/projectdiscovery/katana/pkg/utils/throttle"
+)
+
+// politeness paces the actions executed on each host with the rate
+// limit shared by the engines and a minimum delay between them
+type politeness struct {
+	limiter *throttle.Limiter
+	delay   time.Duration
+
+	mu sync.Mutex
+	// next is the earliest time of the next action on each host
+	next map[string]time.Time
+}
+
+func newPoliteness(limiter *throttle.Limiter, delay time.Duration) *politeness {
+	return &politeness{
+		limiter: limiter,
+		delay:   delay,
+		next:    make(map[string]time.Time),
+	}
+}
+
+// wait blocks until an action on the host is allowed by the rate limit
+// and the delay since the previous action on the host has elapsed
+func (p *politeness) wait(ctx context.Context, host string) error {
+	p.limiter.Take(host)
+	if p.delay <= 0 {
+		return nil
+	}
+	host = strings.ToLower(host)
+
+	// concurrent actions on the host reserve consecutive slots
+	p.mu.Lock()
+	now := time.Now()
+	slot := now
+	if next, ok := p.next[host]; ok && next.After(now) {
+		slot = next
+	}
+	p.next[host] = slot.Add(p.delay)
+	p.mu.Unlock()
+
+	if !slot.After(now) {
+		return nil
+	}
+	timer := time.NewTimer(slot.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// actionHost returns the host an action is executed on, the host of
+// the url loaded or of the page the action is performed on
+func actionHost(action *types.Action, page *browser.BrowserPage) string {
+	rawURL := ""
+	if action.Type == types.ActionTypeLoadURL {
+		rawURL = action.Input
+	} else if info, err := page.Info(); err == nil {
+		rawURL = info.URL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
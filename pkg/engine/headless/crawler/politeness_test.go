@@ -0,0 +1,28 @@
+package crawler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolitenessWait(t *testing.T) {
+	delay := 50 * time.Millisecond
+	polite := newPoliteness(nil, delay)
+
+	started := time.Now()
+	require.NoError(t, polite.wait(context.Background(), "example.com"))
+	require.NoError(t, polite.wait(context.Background(), "other.example.com"))
+	require.Less(t, time.Since(started), delay, "first actions on hosts should not wait")
+
+	require.NoError(t, polite.wait(context.Background(), "EXAMPLE.com"))
+	require.GreaterOrEqual(t, time.Since(started), delay, "actions on a host should be spaced by the delay")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, polite.wait(ctx, "example.com"), context.Canceled)
+
+	require.NoError(t, newPoliteness(nil, 0).wait(context.Background(), "example.com"))
+}
@@ -0,0 +1,66 @@
+package crawler
+
+import (
+	"log/slog"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// adoptPopups records the pages opened by the action in new windows
+// and closes them so that the crawl continues on the pooled page.
+func (c *Crawler) adoptPopups(page *browser.BrowserPage, action *types.Action, originID string) {
+	for _, popup := range page.TakePopups() {
+		if err := c.adoptPopup(popup, action, originID); err != nil {
+			c.logger.Debug("Could not adopt popup", slog.String("error", err.Error()))
+		}
+		popup.ClosePopup()
+	}
+}
+
+// adoptPopup adds the state of the popup to the crawl graph and queues
+// loading its url. The popup state is reached from the origin by the
+// load so that the navigations discovered on it can be replayed on a
+// pooled page.
+func (c *Crawler) adoptPopup(popup *browser.BrowserPage, action *types.Action, originID string) error {
+	if err := popup.WaitPageLoadWithin(c.options.ActionTimeouts.Navigation); err != nil {
+		return err
+	}
+	info, err := popup.Info()
+	if err != nil {
+		return err
+	}
+	if info.URL == "" || info.URL == "about:blank" {
+		return nil
+	}
+	if c.options.ScopeValidator != nil && !c.options.ScopeValidator(info.URL) {
+		c.logger.Debug("Skipping out of scope popup", slog.String("url", info.URL))
+		return nil
+	}
+
+	load := &types.Action{
+		Type:  types.ActionTypeLoadURL,
+		Input: info.URL,
+		Depth: action.Depth + 1,
+	}
+	state, err := c.newPageState(popup, load)
+	if err == ErrEmptyPage {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	state.OriginID = originID
+	if err := c.crawlGraph.AddPageState(*state); err != nil {
+		return err
+	}
+	c.logger.Debug("Adopted popup",
+		slog.String("url", info.URL),
+		slog.String("action", action.String()),
+	)
+
+	if c.uniqueActions.Seen(load.Hash()) {
+		return nil
+	}
+	return c.crawlQueue.Offer(load)
+}
@@ -0,0 +1,203 @@
+package crawler
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/adrianbrad/queue"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// ActionScorer scores the actions offered to the crawl queue, actions
+// with higher scores are crawled first and actions with the same score
+// in the order they were offered. It is called concurrently.
+type ActionScorer func(action *types.Action) int
+
+const (
+	scoreNewHost     = 40
+	scoreNewSegment  = 10
+	scoreForm        = 20
+	scoreDepthFactor = 5
+	// maxScoredSegments caps the unseen path segments scored per action
+	maxScoredSegments = 3
+)
+
+// actionScorer is the default action scorer, favoring the actions reaching
+// new hosts and unseen path segments, forms and shallow actions so that
+// interesting deep actions don't starve behind repeated ones (eg. the
+// pagination clicks of a listing).
+type actionScorer struct {
+	mu       sync.Mutex
+	hosts    map[string]struct{}
+	segments map[string]struct{}
+}
+
+// DefaultActionScorer returns a new default action scorer
+func DefaultActionScorer() ActionScorer {
+	scorer := &actionScorer{
+		hosts:    make(map[string]struct{}),
+		segments: make(map[string]struct{}),
+	}
+	return scorer.score
+}
+
+func (s *actionScorer) score(action *types.Action) int {
+	score := -action.Depth * scoreDepthFactor
+	if action.Form != nil {
+		score += scoreForm
+	}
+	parsed, err := url.Parse(actionURL(action))
+	if err != nil {
+		return score
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if host := strings.ToLower(parsed.Hostname()); host != "" {
+		if _, ok := s.hosts[host]; !ok {
+			s.hosts[host] = struct{}{}
+			score += scoreNewHost
+		}
+	}
+	newSegments := 0
+	for _, segment := range strings.Split(parsed.Path, "/") {
+		if segment == "" {
+			continue
+		}
+		// numeric segments (eg. ids and page numbers) are not new content
+		if strings.Trim(segment, "0123456789") == "" {
+			segment = "{n}"
+		}
+		if _, ok := s.segments[segment]; ok {
+			continue
+		}
+		s.segments[segment] = struct{}{}
+		newSegments++
+	}
+	return score + min(newSegments, maxScoredSegments)*scoreNewSegment
+}
+
+// actionURL returns the url an action navigates to when known
+func actionURL(action *types.Action) string {
+	switch {
+	case action.Type == types.ActionTypeLoadURL:
+		return action.Input
+	case action.Form != nil:
+		return action.Form.Action
+	case action.Element != nil:
+		return action.Element.Attributes["href"]
+	}
+	return ""
+}
+
+// actionPriority is the priority of a queued action
+type actionPriority struct {
+	score int
+	// order is the order the action was offered in
+	order int
+}
+
+// actionQueue is the crawl queue ordering the actions by their scores
+type actionQueue struct {
+	scorer ActionScorer
+
+	mu         sync.Mutex
+	priorities map[*types.Action]actionPriority
+	offered    int
+
+	queue *queue.Priority[*types.Action]
+}
+
+// newActionQueue returns a crawl queue of the actions scored by scorer,
+// a nil scorer crawls the actions in the order they are offered.
+func newActionQueue(actions []*types.Action, scorer ActionScorer) *actionQueue {
+	q := &actionQueue{
+		scorer:     scorer,
+		priorities: make(map[*types.Action]actionPriority),
+	}
+	for _, action := range actions {
+		q.prioritize(action)
+	}
+	q.queue = queue.NewPriority(actions, q.less)
+	return q
+}
+
+// prioritize scores an action the first time it is offered, requeued
+// actions keep their priority
+func (q *actionQueue) prioritize(action *types.Action) {
+	q.mu.Lock()
+	_, ok := q.priorities[action]
+	q.mu.Unlock()
+	if ok {
+		return
+	}
+
+	var score int
+	if q.scorer != nil {
+		score = q.scorer(action)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.priorities[action] = actionPriority{score: score, order: q.offered}
+	q.offered++
+}
+
+// promote moves an action ahead of all the queued actions,
+// it must not be queued when it is promoted
+func (q *actionQueue) promote(action *types.Action) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.priorities[action] = actionPriority{score: math.MaxInt, order: -q.offered}
+	q.offered++
+}
+
+func (q *actionQueue) less(action, other *types.Action) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	first, second := q.priorities[action], q.priorities[other]
+	if first.score != second.score {
+		return first.score > second.score
+	}
+	return first.order < second.order
+}
+
+// Offer queues an action
+func (q *actionQueue) Offer(action *types.Action) error {
+	q.prioritize(action)
+	return q.queue.Offer(action)
+}
+
+// Get returns the queued action with the highest priority
+func (q *actionQueue) Get() (*types.Action, error) {
+	action, err := q.queue.Get()
+	if err != nil {
+		return nil, err
+	}
+	q.mu.Lock()
+	delete(q.priorities, action)
+	q.mu.Unlock()
+	return action, nil
+}
+
+// Size returns the number of queued actions
+func (q *actionQueue) Size() int {
+	return q.queue.Size()
+}
+
+// Clear removes all the queued actions and returns them in their order,
+// they keep their priority when they are offered again.
+func (q *actionQueue) Clear() []*types.Action {
+	actions := q.queue.Clear()
+	sort.SliceStable(actions, func(i, j int) bool {
+		return q.less(actions[i], actions[j])
+	})
+	return actions
+}
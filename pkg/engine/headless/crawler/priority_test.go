@@ -0,0 +1,50 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/adrianbrad/queue"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultActionScorer(t *testing.T) {
+	score := DefaultActionScorer()
+
+	load := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/products/42"}
+	require.Equal(t, scoreNewHost+2*scoreNewSegment, score(load))
+
+	page := &types.Action{Type: types.ActionTypeLeftClick, Depth: 3, Element: &types.HTMLElement{Attributes: map[string]string{"href": "/products/43"}}}
+	require.Equal(t, -3*scoreDepthFactor, score(page), "numeric segments of seen paths should not be new")
+
+	form := &types.Action{Type: types.ActionTypeFillForm, Depth: 1, Form: &types.HTMLForm{Action: "https://example.com/search"}}
+	require.Equal(t, scoreForm+scoreNewSegment-scoreDepthFactor, score(form))
+}
+
+func TestActionQueue(t *testing.T) {
+	first := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/a"}
+	second := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/b"}
+	third := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/c"}
+	scores := map[*types.Action]int{first: 1, second: 5, third: 1}
+
+	crawlQueue := newActionQueue([]*types.Action{first}, func(action *types.Action) int {
+		return scores[action]
+	})
+	require.NoError(t, crawlQueue.Offer(second))
+	require.NoError(t, crawlQueue.Offer(third))
+	require.Equal(t, []*types.Action{second, first, third}, crawlQueue.Clear(), "actions should be ordered by score then offer order")
+
+	// requeued actions keep their priority
+	scores[first] = 10
+	crawlQueue.promote(third)
+	for _, action := range []*types.Action{third, first, second} {
+		require.NoError(t, crawlQueue.Offer(action))
+	}
+	for _, expected := range []*types.Action{third, second, first} {
+		action, err := crawlQueue.Get()
+		require.NoError(t, err)
+		require.Equal(t, expected, action)
+	}
+	_, err := crawlQueue.Get()
+	require.ErrorIs(t, err, queue.ErrNoElementsAvailable)
+}
@@ -0,0 +1,82 @@
+package crawler
+
+import (
+	"html"
+	"log/slog"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/diagnostics"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// ReplayStep is the outcome of a replayed action
+type ReplayStep struct {
+	Index  int
+	Action *types.Action
+	// StateID is the page state reached by replaying the action
+	StateID string
+	// Reproduced is true if the page state is the recorded one
+	Reproduced bool
+	Error      error
+}
+
+// Replay executes the actions recorded in a diagnostics directory in
+// order, reporting each step to onStep. When snapshot is true, the
+// recorded dom of the origin state is loaded before each action instead
+// of reaching the origin state on the live target.
+func (c *Crawler) Replay(directory string, snapshot bool, onStep func(*ReplayStep)) error {
+	actions, err := diagnostics.LoadActions(directory)
+	if err != nil {
+		return errors.Wrap(err, "could not load diagnostics actions")
+	}
+
+	page, err := c.launcher.GetPageFromPool()
+	if err != nil {
+		return err
+	}
+	defer c.launcher.PutBrowserToPool(page)
+
+	for i, action := range actions {
+		step := &ReplayStep{Index: i, Action: action}
+		if snapshot && action.OriginID != "" && action.OriginID != emptyPageHash {
+			if err := loadSnapshot(page, directory, action.OriginID); err != nil {
+				c.logger.Debug("Could not load page state snapshot",
+					slog.String("state", action.OriginID),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+
+		if err := c.executeCrawlStateAction(action, page); err != nil {
+			step.Error = err
+			onStep(step)
+			continue
+		}
+		step.StateID, _, step.Error = c.getPageHash(page)
+		step.Reproduced = step.Error == nil && step.StateID == action.ResultID
+		onStep(step)
+	}
+	return nil
+}
+
+var headTag = regexp.MustCompile(`(?i)<head[^>]*>`)
+
+// loadSnapshot loads the recorded dom of a page state, resolving
+// its relative urls against the recorded page url.
+func loadSnapshot(page *browser.BrowserPage, directory, pageStateID string) error {
+	URL, dom, err := diagnostics.LoadPageStateSnapshot(directory, pageStateID)
+	if err != nil {
+		return err
+	}
+	if URL != "" {
+		base := `<base href="` + html.EscapeString(URL) + `">`
+		if loc := headTag.FindStringIndex(dom); loc != nil {
+			dom = dom[:loc[1]] + base + dom[loc[1]:]
+		} else {
+			dom = base + dom
+		}
+	}
+	return page.SetDocumentContent(dom)
+}
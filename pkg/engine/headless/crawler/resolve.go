@@ -0,0 +1,182 @@
+package crawler
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// minResolveScore is the minimum score a candidate needs to
+// be used in place of an element whose xpath went stale.
+const minResolveScore = 3
+
+// identityAttributes are the attributes which usually survive
+// re-renders and identify what an element does on the page.
+var identityAttributes = []string{"href", "name", "aria-label", "role", "action", "formaction", "data-testid"}
+
+// findElement finds the element of an action on the page.
+//
+// Actions store the xpath of an element captured on an earlier page
+// state which often goes stale once the DOM shifts after re-navigation.
+// The element is looked up in the following order -
+//  1. The stored xpath if it still points to the same element
+//  2. The stored css selector if it points to the same element
+//  3. The best same-tag candidate matched on id, text, role and
+//     identity attributes, nearest to the original location
+//  4. The stored xpath or css selector, waiting for it to appear
+//
+// Elements found inside frames are looked up in the document of
+// their frame. Elements inside shadow roots have no xpath and are
+// looked up by their css selector piercing the shadow roots.
+func (c *Crawler) findElement(page *browser.BrowserPage, element *types.HTMLElement) (*rod.Element, error) {
+	if element == nil {
+		return nil, errors.New("action has no element")
+	}
+	if element.XPath == "" && element.CSSSelector == "" {
+		return nil, errors.New("element has no selector")
+	}
+	page, err := page.FrameAt(element.FramePath)
+	if err != nil {
+		return nil, err
+	}
+	if browser.IsShadowSelector(element.CSSSelector) {
+		return browser.ShadowElement(page.Timeout(c.options.PageMaxTimeout), element.CSSSelector)
+	}
+
+	if element.XPath != "" {
+		if found, ok := c.matchingElement(page, element, element.XPath, true); ok {
+			return found, nil
+		}
+	}
+	if element.CSSSelector != "" {
+		if found, ok := c.matchingElement(page, element, element.CSSSelector, false); ok {
+			return found, nil
+		}
+	}
+	if element.TagName != "" {
+		candidates, _ := page.GetAllElements(strings.ToLower(element.TagName))
+		if resolved := resolveElement(candidates, element); resolved != nil {
+			c.logger.Debug("Re-resolved stale element",
+				slog.String("element", element.String()),
+				slog.String("xpath", element.XPath),
+				slog.String("resolved", resolved.XPath),
+			)
+			if found, err := page.Timeout(c.options.PageMaxTimeout).ElementX(resolved.XPath); err == nil {
+				return found, nil
+			}
+		}
+	}
+
+	pTimeout := page.Timeout(c.options.PageMaxTimeout)
+	if element.XPath != "" {
+		return pTimeout.ElementX(element.XPath)
+	}
+	return pTimeout.Element(element.CSSSelector)
+}
+
+// matchingElement returns the element at selector without waiting
+// if it is still the same element as the target.
+func (c *Crawler) matchingElement(page *browser.BrowserPage, target *types.HTMLElement, selector string, xpath bool) (*rod.Element, bool) {
+	var (
+		found   bool
+		el      *rod.Element
+		current *types.HTMLElement
+		err     error
+	)
+	if xpath {
+		if found, el, err = page.HasX(selector); err != nil || !found {
+			return nil, false
+		}
+		current, err = page.GetElementFromXpath(selector)
+	} else {
+		if found, el, err = page.Has(selector); err != nil || !found {
+			return nil, false
+		}
+		var matches []*types.HTMLElement
+		if matches, err = page.GetAllElements(selector); err == nil && len(matches) > 0 {
+			current = matches[0]
+		}
+	}
+	if err != nil || !isElementMatch(current, target) {
+		return nil, false
+	}
+	return el, true
+}
+
+// resolveElement returns the candidate which is most likely the
+// target element after the DOM shifted, or nil if none is a
+// confident match. Equally scored candidates are disambiguated by
+// their distance to the original xpath of the target.
+func resolveElement(candidates []*types.HTMLElement, target *types.HTMLElement) *types.HTMLElement {
+	var (
+		best          *types.HTMLElement
+		bestScore     int
+		bestProximity int
+		ambiguous     bool
+	)
+	for _, candidate := range candidates {
+		if candidate == nil || candidate.XPath == "" {
+			continue
+		}
+		score := resolveScore(candidate, target)
+		if score < minResolveScore {
+			continue
+		}
+		proximity := xpathProximity(candidate.XPath, target.XPath)
+		switch {
+		case best == nil || score > bestScore || (score == bestScore && proximity > bestProximity):
+			best, bestScore, bestProximity, ambiguous = candidate, score, proximity, false
+		case score == bestScore && proximity == bestProximity:
+			ambiguous = true
+		}
+	}
+	if ambiguous {
+		return nil
+	}
+	return best
+}
+
+// resolveScore scores how well a candidate matches the target element
+func resolveScore(candidate, target *types.HTMLElement) int {
+	if !strings.EqualFold(candidate.TagName, target.TagName) {
+		return 0
+	}
+	var score int
+	if target.ID != "" && candidate.ID == target.ID {
+		score += 4
+	}
+	if target.TextContent != "" && candidate.TextContent == target.TextContent {
+		score += 3
+	}
+	for _, attribute := range identityAttributes {
+		if value := target.Attributes[attribute]; value != "" && candidate.Attributes[attribute] == value {
+			score += 3
+		}
+	}
+	if target.Type != "" && candidate.Type == target.Type {
+		score++
+	}
+	if target.Classes != "" && candidate.Classes == target.Classes {
+		score++
+	}
+	if target.CSSSelector != "" && candidate.CSSSelector == target.CSSSelector {
+		score++
+	}
+	return score
+}
+
+// xpathProximity returns the number of leading xpath steps shared
+// by both paths which is higher for elements closer in the tree.
+func xpathProximity(a, b string) int {
+	stepsA := strings.Split(strings.Trim(a, "/"), "/")
+	stepsB := strings.Split(strings.Trim(b, "/"), "/")
+	var shared int
+	for shared < len(stepsA) && shared < len(stepsB) && stepsA[shared] == stepsB[shared] {
+		shared++
+	}
+	return shared
+}
@@ -0,0 +1,53 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveElement(t *testing.T) {
+	target := &types.HTMLElement{
+		TagName:     "A",
+		TextContent: "Settings",
+		Attributes:  map[string]string{"href": "/settings"},
+		XPath:       "/html/body/div[1]/nav/a[2]",
+	}
+
+	t.Run("shifted", func(t *testing.T) {
+		candidates := []*types.HTMLElement{
+			{TagName: "A", TextContent: "Home", Attributes: map[string]string{"href": "/"}, XPath: "/html/body/div[2]/nav/a[1]"},
+			{TagName: "A", TextContent: "Settings", Attributes: map[string]string{"href": "/settings"}, XPath: "/html/body/div[2]/nav/a[2]"},
+		}
+		resolved := resolveElement(candidates, target)
+		require.NotNil(t, resolved)
+		require.Equal(t, "/html/body/div[2]/nav/a[2]", resolved.XPath)
+	})
+
+	t.Run("nearest", func(t *testing.T) {
+		candidates := []*types.HTMLElement{
+			{TagName: "A", TextContent: "Settings", Attributes: map[string]string{"href": "/settings"}, XPath: "/html/body/footer/a[4]"},
+			{TagName: "A", TextContent: "Settings", Attributes: map[string]string{"href": "/settings"}, XPath: "/html/body/div[1]/nav/a[3]"},
+		}
+		resolved := resolveElement(candidates, target)
+		require.NotNil(t, resolved)
+		require.Equal(t, "/html/body/div[1]/nav/a[3]", resolved.XPath, "the candidate nearest to the old xpath should win")
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		candidates := []*types.HTMLElement{
+			{TagName: "A", TextContent: "Settings", XPath: "/html/body/main/a[1]"},
+			{TagName: "A", TextContent: "Settings", XPath: "/html/body/main/a[2]"},
+		}
+		require.Nil(t, resolveElement(candidates, target), "equally good candidates should not be guessed")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		candidates := []*types.HTMLElement{
+			{TagName: "A", TextContent: "Logout", Attributes: map[string]string{"href": "/logout"}, XPath: "/html/body/div[1]/nav/a[2]"},
+			{TagName: "BUTTON", TextContent: "Settings", XPath: "/html/body/div[1]/nav/button"},
+		}
+		require.Nil(t, resolveElement(candidates, target))
+	})
+}
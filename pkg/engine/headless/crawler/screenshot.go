@@ -0,0 +1,39 @@
+package crawler
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/diagnostics"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// actionScreenshot takes the viewport screenshot of the page compared
+// before and after an action, nil if it could not be taken
+func (c *Crawler) actionScreenshot(page *browser.BrowserPage, timings diagnostics.ActionTimings) []byte {
+	started := time.Now()
+	defer timings.Since(diagnostics.ScreenshotPhase, started)
+
+	screenshot, err := page.Screenshot(false, &proto.PageCaptureScreenshot{
+		Format: proto.PageCaptureScreenshotFormatPng,
+	})
+	if err != nil {
+		c.logger.Debug("Could not take action screenshot", slog.String("error", err.Error()))
+		return nil
+	}
+	return screenshot
+}
+
+// logActionScreenshots logs the screenshots of the page before and after
+// the action so that actions without a visible effect are flagged
+func (c *Crawler) logActionScreenshots(page *browser.BrowserPage, action *types.Action, before []byte, timings diagnostics.ActionTimings) {
+	after := c.actionScreenshot(page, timings)
+	if after == nil {
+		return
+	}
+	if err := c.diagnostics.LogActionScreenshots(action, before, after); err != nil {
+		c.logger.Error("Failed to log action screenshots", slog.String("error", err.Error()))
+	}
+}
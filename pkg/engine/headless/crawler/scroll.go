@@ -0,0 +1,75 @@
+package crawler
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// scrollSettleWait is the network and DOM idle window waited for
+// after each scroll step so that lazily loaded content can render
+const scrollSettleWait = time.Second
+
+// scrollNavigations scrolls the page a viewport at a time for up to
+// ScrollBudget steps, collecting the navigations of the content loaded
+// by infinite feeds and lazy loading. Scrolling stops once a step
+// neither grows the page nor reveals new navigations.
+func (c *Crawler) scrollNavigations(page *browser.BrowserPage, navigations []*types.Action) []*types.Action {
+	seen := make(map[string]struct{}, len(navigations))
+	for _, nav := range navigations {
+		seen[nav.Hash()] = struct{}{}
+	}
+
+	before, err := page.GetScrollPosition()
+	if err != nil {
+		c.logger.Debug("Could not get scroll position", slog.String("error", err.Error()))
+		return navigations
+	}
+	for step := 0; step < c.options.ScrollBudget; step++ {
+		if _, err := page.ScrollViewport(); err != nil {
+			c.logger.Debug("Could not scroll page", slog.String("error", err.Error()))
+			break
+		}
+		_ = page.WaitNewStable(scrollSettleWait)
+
+		after, err := page.GetScrollPosition()
+		if err != nil {
+			break
+		}
+		found, err := page.FindNavigations()
+		if err != nil {
+			c.logger.Debug("Could not extract navigations after scroll", slog.String("error", err.Error()))
+			break
+		}
+		var added int
+		navigations, added = mergeNavigations(navigations, found, seen)
+		c.logger.Debug("Scrolled page",
+			slog.Int("step", step+1),
+			slog.Float64("height", after.Height),
+			slog.Int("new_navigations", added),
+		)
+		if added == 0 && after.Height <= before.Height {
+			break
+		}
+		before = after
+	}
+	return navigations
+}
+
+// mergeNavigations appends the navigations of found whose hash is not
+// in seen to navigations, returning them and the number of added ones.
+func mergeNavigations(navigations, found []*types.Action, seen map[string]struct{}) ([]*types.Action, int) {
+	var added int
+	for _, nav := range found {
+		hash := nav.Hash()
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+		seen[hash] = struct{}{}
+		navigations = append(navigations, nav)
+		added++
+	}
+	return navigations, added
+}
@@ -0,0 +1,22 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeNavigations(t *testing.T) {
+	first := &types.Action{Type: types.ActionTypeLeftClick, Element: &types.HTMLElement{TagName: "A", Attributes: map[string]string{"href": "/post/1"}}}
+	second := &types.Action{Type: types.ActionTypeLeftClick, Element: &types.HTMLElement{TagName: "A", Attributes: map[string]string{"href": "/post/2"}}}
+	seen := map[string]struct{}{first.Hash(): {}}
+
+	merged, added := mergeNavigations([]*types.Action{first}, []*types.Action{first, second, second}, seen)
+	require.Equal(t, 1, added, "already seen navigations should not be added")
+	require.Equal(t, []*types.Action{first, second}, merged)
+
+	merged, added = mergeNavigations(merged, []*types.Action{first, second}, seen)
+	require.Zero(t, added, "a step without new content should add nothing")
+	require.Len(t, merged, 2)
+}
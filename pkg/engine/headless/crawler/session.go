@@ -0,0 +1,194 @@
+package crawler
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// maxReauthAttempts is the number of consecutive re-authentications
+// after which the session is considered lost
+const maxReauthAttempts = 3
+
+// SessionCheck verifies that an authenticated crawl is still logged in
+type SessionCheck struct {
+	// URL is the page loaded to verify the session
+	URL string
+	// Marker is the text present on the page while logged in
+	Marker string
+	// Interval is the time between session checks
+	Interval time.Duration
+}
+
+// healthy returns true if the page html shows a logged in session
+func (s *SessionCheck) healthy(html string) bool {
+	return strings.Contains(html, s.Marker)
+}
+
+// due returns true if the session should be checked again
+func (s *SessionCheck) due(lastCheck time.Time) bool {
+	return time.Since(lastCheck) >= s.Interval
+}
+
+// verifySession loads the session check page returning true if the
+// browser session is still logged in
+func (c *Crawler) verifySession(ctx context.Context) (bool, error) {
+	page, err := c.launcher.GetPageFromPool()
+	if err != nil {
+		return false, err
+	}
+	defer c.launcher.PutBrowserToPool(page)
+
+	page.Page = page.Context(ctx)
+	if err := c.restoreAuthSession(page); err != nil {
+		return false, err
+	}
+	if err := page.Timeout(c.options.ActionTimeouts.Navigation).Navigate(c.options.SessionCheck.URL); err != nil {
+		return false, errors.Wrap(err, "could not load session check url")
+	}
+	_ = page.WaitPageLoadHeurisitics()
+	html, err := page.HTML()
+	if err != nil {
+		return false, errors.Wrap(err, "could not get session check page")
+	}
+	return c.options.SessionCheck.healthy(html), nil
+}
+
+// ensureSession re-runs the auth actions when the session check shows
+// that the crawl was logged out, an error is returned if the session
+// could not be restored.
+func (c *Crawler) ensureSession(ctx context.Context) error {
+	for attempt := 0; ; attempt++ {
+		healthy, err := c.verifySession(ctx)
+		if err != nil {
+			// unreachable check pages are not a logout, the next check retries
+			c.logger.Debug("Could not verify session", slog.String("error", err.Error()))
+			return nil
+		}
+		if healthy {
+			return nil
+		}
+		if attempt == maxReauthAttempts {
+			return errors.Errorf("session lost, could not re-authenticate after %d attempts", maxReauthAttempts)
+		}
+		c.logger.Warn("Session lost, re-authenticating", slog.String("check_url", c.options.SessionCheck.URL))
+		if err := c.executeAuthActions(ctx); err != nil {
+			c.logger.Warn("Could not re-authenticate", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// ErrLoginPage is returned for the actions which reached the login
+// page, they are retried once the session is re-authenticated
+var ErrLoginPage = errors.New("kicked back to the login page")
+
+// LoginPage detects the login page the crawl is kicked back to once its
+// session expired, from the url or the content of the reached states
+type LoginPage struct {
+	// URLPatterns match the urls of the login page
+	URLPatterns []*regexp.Regexp
+	// Markers are texts present on the login page
+	Markers []string
+}
+
+// ParseLoginPage parses the url regexes and content markers of the login
+// page, nil is returned if neither are set
+func ParseLoginPage(urlPatterns, markers []string) (*LoginPage, error) {
+	if len(urlPatterns) == 0 && len(markers) == 0 {
+		return nil, nil
+	}
+	loginPage := &LoginPage{Markers: markers}
+	for _, pattern := range urlPatterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid login page url pattern %q", pattern)
+		}
+		loginPage.URLPatterns = append(loginPage.URLPatterns, compiled)
+	}
+	return loginPage, nil
+}
+
+// matches returns true if the page state is the login page
+func (l *LoginPage) matches(state *types.PageState) bool {
+	if l == nil {
+		return false
+	}
+	for _, pattern := range l.URLPatterns {
+		if pattern.MatchString(state.URL) {
+			return true
+		}
+	}
+	for _, marker := range l.Markers {
+		if marker != "" && strings.Contains(state.DOM, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLoginPage returns ErrLoginPage and schedules a re-authentication
+// when the action reached the login page, the action is requeued to be
+// retried once logged in again. Actions reaching the login page on their
+// retry lead to it whatever the session and are not retried again.
+func (c *Crawler) checkLoginPage(action *types.Action, state *types.PageState) error {
+	if c.options.LoginPage == nil {
+		return nil
+	}
+	c.reauthMu.Lock()
+	defer c.reauthMu.Unlock()
+
+	if !c.options.LoginPage.matches(state) {
+		c.reauthAttempts = 0
+		return nil
+	}
+	if len(c.options.AuthActions) == 0 {
+		c.logger.Warn("Kicked back to the login page, not crawling it without auth actions",
+			slog.String("url", state.URL),
+		)
+		return ErrLoginPage
+	}
+	actionHash := action.Hash()
+	if _, ok := c.loginRetried[actionHash]; ok {
+		c.logger.Debug("Action leads to the login page, not retrying it",
+			slog.String("action", action.String()),
+		)
+		return ErrLoginPage
+	}
+	c.loginRetried[actionHash] = struct{}{}
+	c.reauthPending = true
+	if err := c.crawlQueue.Offer(action); err != nil {
+		return err
+	}
+	return ErrLoginPage
+}
+
+// reauthenticate re-runs the auth actions when an action was kicked
+// back to the login page, an error is returned if the session could
+// not be restored.
+func (c *Crawler) reauthenticate(ctx context.Context) error {
+	c.reauthMu.Lock()
+	pending := c.reauthPending
+	c.reauthPending = false
+	if pending {
+		c.reauthAttempts++
+	}
+	attempts := c.reauthAttempts
+	c.reauthMu.Unlock()
+
+	if !pending {
+		return nil
+	}
+	if attempts > maxReauthAttempts {
+		return errors.Errorf("session lost, still on the login page after %d re-authentications", maxReauthAttempts)
+	}
+	c.logger.Warn("Kicked back to the login page, re-authenticating")
+	if err := c.executeAuthActions(ctx); err != nil {
+		c.logger.Warn("Could not re-authenticate", slog.String("error", err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package crawler
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionCheck(t *testing.T) {
+	check := &SessionCheck{URL: "https://example.com/account", Marker: "Sign out", Interval: time.Minute}
+	require.True(t, check.healthy(`<a href="/logout">Sign out</a>`))
+	require.False(t, check.healthy(`<form action="/login"><button>Sign in</button></form>`))
+
+	require.False(t, check.due(time.Now()))
+	require.True(t, check.due(time.Now().Add(-2*time.Minute)))
+}
+
+func TestCheckLoginPage(t *testing.T) {
+	loginPage, err := ParseLoginPage([]string{`/login(\?|$)`}, []string{`name="password"`})
+	require.NoError(t, err)
+	_, err = ParseLoginPage([]string{"("}, nil)
+	require.Error(t, err)
+	empty, err := ParseLoginPage(nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, empty)
+
+	c := &Crawler{
+		logger:       slog.Default(),
+		crawlQueue:   newActionQueue(nil, nil),
+		loginRetried: make(map[string]struct{}),
+		options: Options{
+			LoginPage:   loginPage,
+			AuthActions: []*types.Action{{Type: types.ActionTypeLoadURL, Input: "https://example.com/login"}},
+		},
+	}
+	action := &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/orders"}
+	require.NoError(t, c.checkLoginPage(action, &types.PageState{URL: "https://example.com/orders", DOM: "<h1>Orders</h1>"}))
+
+	kicked := &types.PageState{URL: "https://example.com/login?next=/orders"}
+	require.ErrorIs(t, c.checkLoginPage(action, kicked), ErrLoginPage)
+	require.True(t, c.reauthPending)
+	require.Equal(t, 1, c.crawlQueue.Size(), "the action should be retried once re-authenticated")
+
+	c.reauthPending = false
+	form := &types.PageState{URL: "https://example.com/", DOM: `<input name="password">`}
+	require.ErrorIs(t, c.checkLoginPage(action, form), ErrLoginPage)
+	require.False(t, c.reauthPending, "actions should only be retried once")
+	require.Equal(t, 1, c.crawlQueue.Size())
+}
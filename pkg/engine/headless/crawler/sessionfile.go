@@ -0,0 +1,159 @@
+package crawler
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+)
+
+// playwrightState is a browser session in the Playwright storage state
+// format, so that sessions are shared with Playwright and other tools
+type playwrightState struct {
+	Cookies []playwrightCookie `json:"cookies"`
+	Origins []playwrightOrigin `json:"origins"`
+}
+
+type playwrightCookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain"`
+	Path     string  `json:"path"`
+	Expires  float64 `json:"expires"`
+	HTTPOnly bool    `json:"httpOnly"`
+	Secure   bool    `json:"secure"`
+	SameSite string  `json:"sameSite"`
+}
+
+type playwrightOrigin struct {
+	Origin       string           `json:"origin"`
+	LocalStorage []playwrightItem `json:"localStorage"`
+	// SessionStorage is not part of the Playwright format,
+	// tools loading the state ignore it
+	SessionStorage []playwrightItem `json:"sessionStorage,omitempty"`
+}
+
+type playwrightItem struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// LoadSessionFile loads a session file in the Playwright storage state
+// format returning nil if no session was saved yet
+func LoadSessionFile(path string) (*StorageState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read session file")
+	}
+	var session playwrightState
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, errors.Wrap(err, "could not decode session file")
+	}
+
+	state := &StorageState{
+		LocalStorage:   make(map[string]map[string]string),
+		SessionStorage: make(map[string]map[string]string),
+	}
+	if info, err := os.Stat(path); err == nil {
+		state.SavedAt = info.ModTime()
+	}
+	for _, cookie := range session.Cookies {
+		param := &proto.NetworkCookieParam{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			HTTPOnly: cookie.HTTPOnly,
+			Secure:   cookie.Secure,
+			SameSite: proto.NetworkCookieSameSite(cookie.SameSite),
+		}
+		// session cookies have a negative expiry
+		if cookie.Expires > 0 {
+			param.Expires = proto.TimeSinceEpoch(cookie.Expires)
+		}
+		state.Cookies = append(state.Cookies, param)
+	}
+	for _, origin := range session.Origins {
+		setOriginItems(state.LocalStorage, origin.Origin, itemsMap(origin.LocalStorage))
+		setOriginItems(state.SessionStorage, origin.Origin, itemsMap(origin.SessionStorage))
+	}
+	return state, nil
+}
+
+// SaveSessionFile writes the storage state in the Playwright storage
+// state format, the file is only readable by the user.
+func (s *StorageState) SaveSessionFile(path string) error {
+	session := playwrightState{
+		Cookies: make([]playwrightCookie, 0, len(s.Cookies)),
+		Origins: []playwrightOrigin{},
+	}
+	for _, cookie := range s.Cookies {
+		expires := float64(cookie.Expires)
+		if expires <= 0 {
+			expires = -1
+		}
+		sameSite := string(cookie.SameSite)
+		if sameSite == "" {
+			sameSite = string(proto.NetworkCookieSameSiteLax)
+		}
+		session.Cookies = append(session.Cookies, playwrightCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Expires:  expires,
+			HTTPOnly: cookie.HTTPOnly,
+			Secure:   cookie.Secure,
+			SameSite: sameSite,
+		})
+	}
+
+	origins := make(map[string]struct{})
+	for origin := range s.LocalStorage {
+		origins[origin] = struct{}{}
+	}
+	for origin := range s.SessionStorage {
+		origins[origin] = struct{}{}
+	}
+	for origin := range origins {
+		session.Origins = append(session.Origins, playwrightOrigin{
+			Origin:         origin,
+			LocalStorage:   itemsList(s.LocalStorage[origin]),
+			SessionStorage: itemsList(s.SessionStorage[origin]),
+		})
+	}
+	sort.Slice(session.Origins, func(i, j int) bool {
+		return session.Origins[i].Origin < session.Origins[j].Origin
+	})
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not encode session file")
+	}
+	return writeStateFile(path, data)
+}
+
+func itemsMap(items []playwrightItem) map[string]string {
+	values := make(map[string]string, len(items))
+	for _, item := range items {
+		values[item.Name] = item.Value
+	}
+	return values
+}
+
+// itemsList returns the storage items sorted by name
+func itemsList(values map[string]string) []playwrightItem {
+	items := make([]playwrightItem, 0, len(values))
+	for name, value := range values {
+		items = append(items, playwrightItem{Name: name, Value: value})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items
+}
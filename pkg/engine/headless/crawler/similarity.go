@@ -0,0 +1,85 @@
+package crawler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/normalizer/simhash"
+)
+
+// Similarity configures how aggressively page states with near-duplicate
+// DOMs are merged, by comparing the simhashes of their stripped DOM.
+type Similarity struct {
+	// Threshold is the maximum hamming distance between the simhashes
+	// of two page states for them to be considered the same state
+	Threshold int
+	// Shingle is the number of consecutive DOM features hashed together,
+	// larger shingles make the simhash more sensitive to reordering
+	Shingle int
+	// Weights are the weights of the kinds of DOM features
+	Weights simhash.Weights
+}
+
+// DefaultSimilarity is the default page state similarity
+var DefaultSimilarity = Similarity{
+	Threshold: 2,
+	Shingle:   3,
+	Weights:   simhash.DefaultWeights,
+}
+
+// ParseSimilarity parses similarity settings in the key=value format
+// (eg. threshold=4,shingle=2,text=0), keys that are not given keep
+// their default value.
+func ParseSimilarity(values []string) (Similarity, error) {
+	similarity := DefaultSimilarity
+	for _, value := range values {
+		key, rawNumber, ok := strings.Cut(value, "=")
+		if !ok {
+			return similarity, errors.Errorf("invalid similarity setting %q, expected key=value", value)
+		}
+		number, err := strconv.Atoi(strings.TrimSpace(rawNumber))
+		if err != nil || number < 0 {
+			return similarity, errors.Errorf("invalid value for similarity setting %q", value)
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "threshold":
+			if number > 64 {
+				return similarity, errors.Errorf("similarity threshold %q must be at most 64", value)
+			}
+			similarity.Threshold = number
+		case "shingle":
+			if number < 1 {
+				return similarity, errors.Errorf("similarity shingle %q must be at least 1", value)
+			}
+			similarity.Shingle = number
+		case "tags":
+			similarity.Weights.Tags = number
+		case "attributes", "attrs":
+			similarity.Weights.Attributes = number
+		case "text":
+			similarity.Weights.Text = number
+		case "comments":
+			similarity.Weights.Comments = number
+		default:
+			return similarity, errors.Errorf("unknown similarity setting %q (threshold, shingle, tags, attributes, text, comments)", key)
+		}
+	}
+	if similarity.Weights == (simhash.Weights{}) {
+		return similarity, errors.New("at least one kind of dom features must be weighted")
+	}
+	return similarity, nil
+}
+
+// withDefaults returns DefaultSimilarity for the zero similarity
+func (s Similarity) withDefaults() Similarity {
+	if s == (Similarity{}) {
+		return DefaultSimilarity
+	}
+	return s
+}
+
+// fingerprint returns the simhash of a stripped DOM
+func (s Similarity) fingerprint(strippedDOM string) uint64 {
+	return simhash.FingerprintWeighted(strings.NewReader(strippedDOM), s.Shingle, s.Weights)
+}
@@ -0,0 +1,31 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/normalizer/simhash"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSimilarity(t *testing.T) {
+	similarity, err := ParseSimilarity(nil)
+	require.NoError(t, err)
+	require.Equal(t, DefaultSimilarity, similarity)
+
+	similarity, err = ParseSimilarity([]string{"threshold=6", "Shingle = 2", "text=0", "attrs=3"})
+	require.NoError(t, err)
+	require.Equal(t, Similarity{
+		Threshold: 6,
+		Shingle:   2,
+		Weights:   simhash.Weights{Tags: 1, Attributes: 3, Comments: 1},
+	}, similarity)
+
+	for _, invalid := range []string{"threshold", "threshold=65", "shingle=0", "text=-1", "distance=2"} {
+		_, err = ParseSimilarity([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+	_, err = ParseSimilarity([]string{"tags=0", "attributes=0", "text=0", "comments=0"})
+	require.Error(t, err, "all the features should not be ignored")
+
+	require.Equal(t, DefaultSimilarity, Similarity{}.withDefaults())
+}
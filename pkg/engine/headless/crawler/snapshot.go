@@ -0,0 +1,148 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// SnapshotIndexFile is the index of the page states of a snapshot archive
+const SnapshotIndexFile = "states.jsonl"
+
+// SnapshotEntry is an index entry of a snapshot archive describing a
+// unique page state and the objects its raw and normalized DOM are
+// stored in. The origin and navigation action of the states form the
+// crawl graph.
+type SnapshotEntry struct {
+	Timestamp time.Time     `json:"timestamp"`
+	StateID   string        `json:"state_id"`
+	OriginID  string        `json:"origin_id,omitempty"`
+	URL       string        `json:"url"`
+	Title     string        `json:"title,omitempty"`
+	Depth     int           `json:"depth"`
+	Action    *types.Action `json:"action,omitempty"`
+	// DOM and NormalizedDOM are the sha256 object ids of the raw
+	// and normalized DOM of the state
+	DOM           string `json:"dom"`
+	NormalizedDOM string `json:"normalized_dom"`
+}
+
+// snapshotArchive stores the DOM of unique page states in a content
+// addressed directory. Objects are gzip compressed and stored as
+// objects/<id[:2]>/<id>.html.gz so identical DOMs are only stored once.
+type snapshotArchive struct {
+	dir string
+
+	mu    sync.Mutex
+	index *os.File
+	seen  map[string]struct{}
+}
+
+// newSnapshotArchive opens the snapshot archive in dir, the index is
+// appended to so that the states of all targets share one archive.
+func newSnapshotArchive(dir string) (*snapshotArchive, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "objects"), 0755); err != nil {
+		return nil, errors.Wrap(err, "could not create snapshot directory")
+	}
+	index, err := os.OpenFile(filepath.Join(dir, SnapshotIndexFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open snapshot index")
+	}
+	return &snapshotArchive{dir: dir, index: index, seen: make(map[string]struct{})}, nil
+}
+
+// Store stores the raw and normalized DOM of a page state once
+func (a *snapshotArchive) Store(state *types.PageState) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.seen[state.UniqueID]; ok {
+		return nil
+	}
+	a.seen[state.UniqueID] = struct{}{}
+
+	dom, err := a.writeObject(state.DOM)
+	if err != nil {
+		return err
+	}
+	normalized, err := a.writeObject(state.StrippedDOM)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(&SnapshotEntry{
+		Timestamp:     time.Now(),
+		StateID:       state.UniqueID,
+		OriginID:      state.OriginID,
+		URL:           state.URL,
+		Title:         state.Title,
+		Depth:         state.Depth,
+		Action:        state.NavigationAction,
+		DOM:           dom,
+		NormalizedDOM: normalized,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not marshal snapshot entry")
+	}
+	if _, err := a.index.Write(append(data, '\n')); err != nil {
+		return errors.Wrap(err, "could not write snapshot entry")
+	}
+	return nil
+}
+
+// writeObject writes content as a compressed object returning its id
+func (a *snapshotArchive) writeObject(content string) (string, error) {
+	sum := sha256.Sum256([]byte(content))
+	id := hex.EncodeToString(sum[:])
+	path := SnapshotObjectPath(a.dir, id)
+	if _, err := os.Stat(path); err == nil {
+		return id, nil
+	}
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		return "", errors.Wrap(err, "could not compress snapshot")
+	}
+	if err := writer.Close(); err != nil {
+		return "", errors.Wrap(err, "could not compress snapshot")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", errors.Wrap(err, "could not create snapshot object directory")
+	}
+	// objects are renamed into place so readers never see partial files
+	tmp, err := os.CreateTemp(filepath.Dir(path), id+".*.tmp")
+	if err != nil {
+		return "", errors.Wrap(err, "could not write snapshot object")
+	}
+	_, err = tmp.Write(buffer.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", errors.Wrap(err, "could not write snapshot object")
+	}
+	return id, nil
+}
+
+// Close closes the snapshot index
+func (a *snapshotArchive) Close() error {
+	return a.index.Close()
+}
+
+// SnapshotObjectPath returns the path of an object of a snapshot archive
+func SnapshotObjectPath(dir, id string) string {
+	return filepath.Join(dir, "objects", id[:2], id+".html.gz")
+}
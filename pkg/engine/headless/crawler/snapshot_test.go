@@ -0,0 +1,65 @@
+package crawler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive, err := newSnapshotArchive(dir)
+	require.NoError(t, err)
+
+	state := &types.PageState{
+		UniqueID:         "state-1",
+		OriginID:         "origin",
+		URL:              "https://example.com/",
+		DOM:              "<html><body><a href=\"/a\">a</a></body></html>",
+		StrippedDOM:      "<body><a href=\"/a\">a</a></body>",
+		NavigationAction: &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com/"},
+	}
+	require.NoError(t, archive.Store(state))
+	require.NoError(t, archive.Store(state), "states should be archived once")
+	// a different state with the same dom shares its objects
+	require.NoError(t, archive.Store(&types.PageState{UniqueID: "state-2", DOM: state.DOM, StrippedDOM: state.StrippedDOM}))
+	require.NoError(t, archive.Close())
+
+	index, err := os.Open(filepath.Join(dir, SnapshotIndexFile))
+	require.NoError(t, err)
+	defer func() {
+		_ = index.Close()
+	}()
+	var entries []SnapshotEntry
+	scanner := bufio.NewScanner(index)
+	for scanner.Scan() {
+		var entry SnapshotEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, 2)
+	require.Equal(t, "origin", entries[0].OriginID)
+	require.Equal(t, entries[0].DOM, entries[1].DOM)
+
+	objects, err := filepath.Glob(filepath.Join(dir, "objects", "*", "*.html.gz"))
+	require.NoError(t, err)
+	require.Len(t, objects, 2, "identical doms should be stored once")
+
+	object, err := os.Open(SnapshotObjectPath(dir, entries[0].NormalizedDOM))
+	require.NoError(t, err)
+	defer func() {
+		_ = object.Close()
+	}()
+	reader, err := gzip.NewReader(object)
+	require.NoError(t, err)
+	normalized, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, state.StrippedDOM, string(normalized))
+}
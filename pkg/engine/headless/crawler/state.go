@@ -5,22 +5,18 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log/slog"
-	"strings"
 
 	graphlib "github.com/dominikbraun/graph"
 	"github.com/pkg/errors"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/diagnostics"
-	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/normalizer/simhash"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
 )
 
 var emptyPageHash = sha256Hash("")
 
-const simhashThreshold = 2 // Allow up to 2 bits difference
-
 func (c *Crawler) isCorrectNavigation(page *browser.BrowserPage, action *types.Action) (string, *types.PageState, error) {
-	currentPageHash, pageState, err := getPageHash(page)
+	currentPageHash, pageState, err := c.getPageHash(page)
 	if err != nil {
 		return "", nil, err
 	}
@@ -29,19 +25,17 @@ func (c *Crawler) isCorrectNavigation(page *browser.BrowserPage, action *types.A
 		return currentPageHash, pageState, nil
 	}
 
-	// Get the origin page state to compare SimHash
+	// Get the origin page state to compare it with the state hasher
 	originPageState, err := c.crawlGraph.GetPageState(action.OriginID)
 	if err != nil {
 		return "", pageState, fmt.Errorf("failed to get origin page state: %w", err)
 	}
 
 	if pageState != nil && originPageState != nil {
-		distance := simhash.Distance(pageState.SimHash, originPageState.SimHash)
-		if distance <= simhashThreshold {
+		if c.stateHasher.Equivalent(pageState, originPageState) {
 			c.logger.Debug("Page is similar enough to origin, proceeding",
 				slog.String("current_hash", currentPageHash),
 				slog.String("origin_hash", action.OriginID),
-				slog.Uint64("simhash_distance", uint64(distance)),
 			)
 			// Treat this page as the origin state to avoid creating a new vertex
 			return originPageState.UniqueID, pageState, nil
@@ -51,8 +45,8 @@ func (c *Crawler) isCorrectNavigation(page *browser.BrowserPage, action *types.A
 	return "", pageState, fmt.Errorf("failed to navigate back to origin page: %s != %s", currentPageHash, action.OriginID)
 }
 
-func getPageHash(page *browser.BrowserPage) (string, *types.PageState, error) {
-	pageState, err := newPageState(page, nil)
+func (c *Crawler) getPageHash(page *browser.BrowserPage) (string, *types.PageState, error) {
+	pageState, err := c.newPageState(page, nil)
 	if err == ErrEmptyPage {
 		return emptyPageHash, nil, nil
 	}
@@ -64,7 +58,7 @@ func getPageHash(page *browser.BrowserPage) (string, *types.PageState, error) {
 
 var ErrEmptyPage = errors.New("page is empty")
 
-func newPageState(page *browser.BrowserPage, action *types.Action) (*types.PageState, error) {
+func (c *Crawler) newPageState(page *browser.BrowserPage, action *types.Action) (*types.PageState, error) {
 	pageInfo, err := page.Info()
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get page info")
@@ -87,15 +81,15 @@ func newPageState(page *browser.BrowserPage, action *types.Action) (*types.PageS
 	if action != nil {
 		state.Depth = action.Depth + 1
 	}
-	strippedDOM, err := getStrippedDOM(outerHTML)
-	if err != nil {
-		return nil, errors.Wrap(err, "could not get stripped dom")
+	// documents without the javascript env have no history route
+	if route, err := page.HistoryRoute(); err == nil {
+		state.Route = route
 	}
-	state.StrippedDOM = strippedDOM
 
-	// Get sha256 hash of the stripped dom
-	state.UniqueID = sha256Hash(strippedDOM)
-	state.SimHash = simhash.Fingerprint(strings.NewReader(strippedDOM), 3)
+	if err := c.stateHasher.Normalize(page, state); err != nil {
+		return nil, errors.Wrap(err, "could not normalize page state")
+	}
+	state.UniqueID = c.stateHasher.Hash(state)
 
 	return state, nil
 }
@@ -107,14 +101,6 @@ func sha256Hash(item string) string {
 	return hashItem
 }
 
-func getStrippedDOM(contents string) (string, error) {
-	normalized, err := domNormalizer.Apply(contents)
-	if err != nil {
-		return "", errors.Wrap(err, "could not normalize dom")
-	}
-	return normalized, nil
-}
-
 var ErrNoNavigationPossible = errors.New("no navigation possible")
 
 // navigateBackToStateOrigin implements the logic to navigate back to the state origin
@@ -127,8 +113,10 @@ var ErrNoNavigationPossible = errors.New("no navigation possible")
 //
 //  2. If we have browser history, and the page is in the history which was the origin
 //     of the action, then we can directly use the browser history to navigate back.
+//     This is skipped for origin states which are not addressable by their URL
+//     (open modals, multi-step flows) as it would land on a different state.
 //
-// 3. If all else fails, we have the shortest path navigation.
+//  3. If all else fails, we replay the shortest recorded action path to the origin.
 func (c *Crawler) navigateBackToStateOrigin(action *types.Action, page *browser.BrowserPage, currentPageHash string) (string, error) {
 	c.logger.Debug("Found action with different origin id",
 		slog.String("action_origin_id", action.OriginID),
@@ -154,16 +142,22 @@ func (c *Crawler) navigateBackToStateOrigin(action *types.Action, page *browser.
 	}
 
 	// Try to see if we can move back using the browser history
-	newPageHash, err := c.tryBrowserHistoryNavigation(page, originPageState, action)
-	if err != nil {
-		c.logger.Debug("Failed to navigate back using browser history", slog.String("error", err.Error()))
-	}
-	if newPageHash != "" {
-		return newPageHash, nil
+	if c.isURLAddressable(originPageState) {
+		newPageHash, err := c.tryBrowserHistoryNavigation(page, originPageState, action)
+		if err != nil {
+			c.logger.Debug("Failed to navigate back using browser history", slog.String("error", err.Error()))
+		}
+		if newPageHash != "" {
+			return newPageHash, nil
+		}
+	} else {
+		c.logger.Debug("Origin page is not addressable by url, replaying actions",
+			slog.String("action_origin_id", action.OriginID),
+		)
 	}
 
-	// Finally try Shortest path walking from root.
-	newPageHash, err = c.tryShortestPathNavigation(action, page, currentPageHash)
+	// Finally replay the shortest recorded action path to the origin.
+	newPageHash, err := c.tryShortestPathNavigation(action, page, currentPageHash)
 	if err != nil {
 		return "", err
 	}
@@ -174,6 +168,14 @@ func (c *Crawler) navigateBackToStateOrigin(action *types.Action, page *browser.
 }
 
 func (c *Crawler) tryElementNavigation(page *browser.BrowserPage, action *types.Action, currentPageHash string) (string, error) {
+	// elements inside shadow roots have no xpath to compare against
+	if action.Element.XPath == "" {
+		return "", nil
+	}
+	page, err := page.FrameAt(action.Element.FramePath)
+	if err != nil {
+		return "", err
+	}
 	element, err := page.ElementX(action.Element.XPath)
 	if err != nil {
 		return "", err
@@ -291,30 +293,71 @@ func (c *Crawler) isBackNavigationPossible(page *browser.BrowserPage, originPage
 	return false, 0, nil
 }
 
+// isURLAddressable returns true if the state can be restored by
+// loading its URL. States reached by clicks which did not change the
+// URL (open modals, tabs) or by submitting forms need their actions replayed.
+func (c *Crawler) isURLAddressable(state *types.PageState) bool {
+	var origin *types.PageState
+	if state.NavigationAction != nil && state.OriginID != "" {
+		origin, _ = c.crawlGraph.GetPageState(state.OriginID)
+	}
+	return isURLAddressableState(state, origin)
+}
+
+func isURLAddressableState(state, origin *types.PageState) bool {
+	action := state.NavigationAction
+	if state.IsRoot || action == nil {
+		return true
+	}
+	switch action.Type {
+	case types.ActionTypeLoadURL, types.ActionTypeRedirect:
+		return true
+	case types.ActionTypeFillForm:
+		return false
+	}
+	return origin != nil && origin.URL != state.URL
+}
+
+// tryShortestPathNavigation replays the shortest recorded action path
+// from the current page to the origin of the action. If the origin is not
+// reachable from the current page or replaying it lands on a different
+// state, the path is replayed from a blank page instead.
 func (c *Crawler) tryShortestPathNavigation(action *types.Action, page *browser.BrowserPage, currentPageHash string) (string, error) {
+	// Earlier attempts may have navigated away from the page we started on
+	if pageHash, _, err := c.getPageHash(page); err == nil {
+		currentPageHash = pageHash
+	}
 	c.logger.Debug("Trying Shortest path to navigate back to origin page", slog.String("action_origin_id", action.OriginID), slog.String("current_page_hash", currentPageHash))
 
-	actions, err := c.crawlGraph.ShortestPath(currentPageHash, action.OriginID)
-	if err != nil {
-		if errors.Is(err, graphlib.ErrTargetNotReachable) {
-			c.logger.Debug("Target not reachable, reaching from blank state",
-				slog.String("action_origin_id", action.OriginID),
-			)
-
-			actions, err = c.crawlGraph.ShortestPath(emptyPageHash, action.OriginID)
-			if err != nil {
-				return "", errors.Wrap(err, "could not find path to origin page")
-			}
-		} else {
-			return "", errors.Wrap(err, "failed to find shortest path")
+	if currentPageHash != emptyPageHash {
+		newPageHash, err := c.replayActionPath(action, page, currentPageHash)
+		if err == nil {
+			return newPageHash, nil
+		}
+		if !errors.Is(err, graphlib.ErrTargetNotReachable) {
+			c.logger.Debug("Failed to replay actions from current page", slog.String("error", err.Error()))
 		}
+		c.logger.Debug("Target not reachable, reaching from blank state",
+			slog.String("action_origin_id", action.OriginID),
+		)
+	}
+	return c.replayActionPath(action, page, emptyPageHash)
+}
+
+// replayActionPath executes the recorded actions on the shortest path
+// from the source state to the origin of the action and verifies the
+// page ended up in the origin state.
+func (c *Crawler) replayActionPath(action *types.Action, page *browser.BrowserPage, sourceState string) (string, error) {
+	actions, err := c.crawlGraph.ShortestPath(sourceState, action.OriginID)
+	if err != nil {
+		return "", errors.Wrap(err, "could not find path to origin page")
 	}
 	c.logger.Debug("Found actions to traverse",
 		slog.Any("actions", actions),
 	)
-	for _, action := range actions {
-		if err := c.executeCrawlStateAction(action, page); err != nil {
-			return "", err
+	for _, pathAction := range actions {
+		if err := c.executeCrawlStateAction(pathAction, page); err != nil {
+			return "", errors.Wrapf(err, "could not replay action %s", pathAction)
 		}
 	}
 	newPageHash, pageState, err := c.isCorrectNavigation(page, action)
@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/normalizer"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/normalizer/simhash"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -78,8 +80,12 @@ func TestPageFingerprint(t *testing.T) {
 		},
 	}
 
+	domNormalizer, err := normalizer.New()
+	assert.NoError(t, err)
+	hasher := &domStateHasher{normalizer: domNormalizer}
+
 	getHash := func(html string) (string, error) {
-		strippedDOM, err := getStrippedDOM(html)
+		strippedDOM, err := hasher.stripDOM(html)
 		if err != nil {
 			return "", errors.Wrap(err, "could not get stripped dom")
 		}
@@ -105,6 +111,9 @@ func TestPageFingerprint(t *testing.T) {
 }
 
 func TestSimHashSimilarity(t *testing.T) {
+	domNormalizer, err := normalizer.New()
+	assert.NoError(t, err)
+
 	tests := []struct {
 		name      string
 		html1     string
@@ -171,3 +180,24 @@ func TestSimHashSimilarity(t *testing.T) {
 		})
 	}
 }
+
+func TestIsURLAddressableState(t *testing.T) {
+	origin := &types.PageState{URL: "https://example.com/app"}
+
+	tests := []struct {
+		name        string
+		state       *types.PageState
+		addressable bool
+	}{
+		{"root", &types.PageState{URL: "https://example.com/", IsRoot: true}, true},
+		{"load url", &types.PageState{URL: "https://example.com/app", NavigationAction: &types.Action{Type: types.ActionTypeLoadURL}}, true},
+		{"link click", &types.PageState{URL: "https://example.com/app/settings", NavigationAction: &types.Action{Type: types.ActionTypeLeftClick}}, true},
+		{"modal click", &types.PageState{URL: "https://example.com/app", NavigationAction: &types.Action{Type: types.ActionTypeLeftClick}}, false},
+		{"form submit", &types.PageState{URL: "https://example.com/app/step-2", NavigationAction: &types.Action{Type: types.ActionTypeFillForm}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.addressable, isURLAddressableState(tt.state, origin))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package crawler
+
+import (
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/normalizer"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/normalizer/simhash"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// StateHasher decides which page states are the same state of the
+// application, so that the crawler can be tuned to what a distinct
+// state is for an application (eg. url-only, dom structure-only or
+// perceptual hashes of screenshots).
+type StateHasher interface {
+	// Normalize fills the normalized representation of the page state
+	// (eg. StrippedDOM) from the page, the URL, DOM and Route of the
+	// state are set when it is called.
+	Normalize(page *browser.BrowserPage, state *types.PageState) error
+	// Hash returns the unique id of the normalized page state,
+	// page states with the same id are the same state.
+	Hash(state *types.PageState) string
+	// Equivalent returns true if the page states with different ids
+	// are near-duplicates to be treated as the same state.
+	Equivalent(a, b *types.PageState) bool
+}
+
+// domStateHasher is the default state hasher, hashing the DOM stripped
+// of its dynamic content and comparing the simhashes of the states
+type domStateHasher struct {
+	normalizer *normalizer.Normalizer
+	similarity Similarity
+}
+
+func (h *domStateHasher) Normalize(_ *browser.BrowserPage, state *types.PageState) error {
+	strippedDOM, err := h.stripDOM(state.DOM)
+	if err != nil {
+		return err
+	}
+	state.StrippedDOM = strippedDOM
+	state.SimHash = h.similarity.fingerprint(strippedDOM)
+	return nil
+}
+
+// Hash returns the sha256 hash of the stripped DOM, routes of single
+// page apps rendering the same DOM are distinct states
+func (h *domStateHasher) Hash(state *types.PageState) string {
+	if state.Route != "" {
+		return sha256Hash(state.URL + "\n" + state.StrippedDOM)
+	}
+	return sha256Hash(state.StrippedDOM)
+}
+
+func (h *domStateHasher) Equivalent(a, b *types.PageState) bool {
+	return int(simhash.Distance(a.SimHash, b.SimHash)) <= h.similarity.Threshold
+}
+
+func (h *domStateHasher) stripDOM(contents string) (string, error) {
+	normalized, err := h.normalizer.Apply(contents)
+	if err != nil {
+		return "", errors.Wrap(err, "could not normalize dom")
+	}
+	return normalized, nil
+}
+
+// URLStateHasher is a state hasher considering the pages
+// with the same url to be the same state, whatever their DOM
+type URLStateHasher struct{}
+
+func (URLStateHasher) Normalize(*browser.BrowserPage, *types.PageState) error {
+	return nil
+}
+
+func (URLStateHasher) Hash(state *types.PageState) string {
+	return sha256Hash(state.URL)
+}
+
+func (URLStateHasher) Equivalent(*types.PageState, *types.PageState) bool {
+	return false
+}
@@ -0,0 +1,36 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/normalizer"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDOMStateHasher(t *testing.T) {
+	domNormalizer, err := normalizer.New()
+	require.NoError(t, err)
+	hasher := &domStateHasher{normalizer: domNormalizer, similarity: DefaultSimilarity}
+
+	home := &types.PageState{URL: "https://example.com/#/home", DOM: `<html><body><nav><a href="/a">A</a></nav><h2>Welcome John!</h2></body></html>`}
+	other := &types.PageState{URL: "https://example.com/#/other", DOM: `<html><body><nav><a href="/a">A</a></nav><h2>Welcome Jane!</h2></body></html>`}
+	require.NoError(t, hasher.Normalize(nil, home))
+	require.NoError(t, hasher.Normalize(nil, other))
+	require.NotEmpty(t, home.StrippedDOM)
+	require.Equal(t, hasher.Hash(home), hasher.Hash(other), "dynamic content should not change the state")
+	require.True(t, hasher.Equivalent(home, other))
+
+	other.Route = "pushState"
+	require.NotEqual(t, hasher.Hash(home), hasher.Hash(other), "routes rendering the same dom should be distinct states")
+}
+
+func TestURLStateHasher(t *testing.T) {
+	var hasher StateHasher = URLStateHasher{}
+	first := &types.PageState{URL: "https://example.com/feed", DOM: "<p>1</p>"}
+	second := &types.PageState{URL: "https://example.com/feed", DOM: "<p>2</p>"}
+	require.NoError(t, hasher.Normalize(nil, first))
+	require.Equal(t, hasher.Hash(first), hasher.Hash(second))
+	require.NotEqual(t, hasher.Hash(first), hasher.Hash(&types.PageState{URL: "https://example.com/"}))
+	require.False(t, hasher.Equivalent(first, second))
+}
@@ -0,0 +1,240 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/graph"
+)
+
+// StorageState is the browser session of a target persisted across
+// runs so that authenticated sessions survive between crawls.
+type StorageState struct {
+	SavedAt time.Time                   `json:"saved_at"`
+	Cookies []*proto.NetworkCookieParam `json:"cookies,omitempty"`
+	// LocalStorage are the local storage items keyed by origin
+	LocalStorage map[string]map[string]string `json:"local_storage,omitempty"`
+	// SessionStorage are the session storage items keyed by origin
+	SessionStorage map[string]map[string]string `json:"session_storage,omitempty"`
+}
+
+var storageFileSanitizer = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// StorageStatePath returns the storage state file of a target in the directory
+func StorageStatePath(dir, URL string) string {
+	return filepath.Join(dir, targetFileName(URL)+".json")
+}
+
+// GraphExportPath returns the crawl graph export file of a target in the directory
+func GraphExportPath(dir, URL string, format graph.ExportFormat) string {
+	return filepath.Join(dir, targetFileName(URL)+"-crawl-graph."+string(format))
+}
+
+// targetFileName returns a file name identifying the origin of a target
+func targetFileName(URL string) string {
+	name := URL
+	if parsed, err := url.Parse(URL); err == nil && parsed.Host != "" {
+		name = parsed.Scheme + "_" + parsed.Host
+	}
+	return storageFileSanitizer.ReplaceAllString(name, "_")
+}
+
+// LoadStorageState loads a storage state file returning nil
+// if no state was saved yet
+func LoadStorageState(path string) (*StorageState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read storage state")
+	}
+	state := &StorageState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, errors.Wrap(err, "could not decode storage state")
+	}
+	return state, nil
+}
+
+// Save writes the storage state file, the file is only
+// readable by the user as it contains session secrets.
+func (s *StorageState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not encode storage state")
+	}
+	return writeStateFile(path, data)
+}
+
+// writeStateFile atomically writes a file only readable by the user
+func writeStateFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrap(err, "could not create storage state directory")
+	}
+	// concurrent crawls may save the same file
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "could not create storage state")
+	}
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return errors.Wrap(err, "could not write storage state")
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
+
+// restoreStorageState loads the storage state saved to path and adds it
+// to the session of the crawl returning true if a saved session was restored
+func (c *Crawler) restoreStorageState(path string, load func(string) (*StorageState, error)) bool {
+	state, err := load(path)
+	if err != nil {
+		c.logger.Warn("Could not load storage state", slog.String("error", err.Error()))
+	}
+	if state == nil {
+		return false
+	}
+	c.storageMu.Lock()
+	defer c.storageMu.Unlock()
+
+	for origin, items := range state.LocalStorage {
+		c.localStorage[origin] = items
+	}
+	for origin, items := range state.SessionStorage {
+		c.sessionStorage[origin] = items
+	}
+	c.authCookies = append(c.authCookies, state.Cookies...)
+	c.logger.Debug("Restored storage state",
+		slog.String("path", path),
+		slog.Int("cookies", len(state.Cookies)),
+		slog.Int("origins", len(state.LocalStorage)),
+	)
+	return true
+}
+
+// saveStorageState saves the cookies and storage of the crawl to path
+func (c *Crawler) saveStorageState(path string, save func(*StorageState, string) error) {
+	page, err := c.launcher.GetPageFromPool()
+	if err != nil {
+		c.logger.Warn("Could not save storage state", slog.String("error", err.Error()))
+		return
+	}
+	cookies, err := page.Browser.GetCookies()
+	c.launcher.PutBrowserToPool(page)
+	if err != nil {
+		c.logger.Warn("Could not save storage state", slog.String("error", err.Error()))
+		return
+	}
+
+	c.storageMu.Lock()
+	state := &StorageState{
+		SavedAt:        time.Now(),
+		Cookies:        proto.CookiesToParams(cookies),
+		LocalStorage:   c.localStorage,
+		SessionStorage: c.sessionStorage,
+	}
+	err = save(state, path)
+	c.storageMu.Unlock()
+	if err != nil {
+		c.logger.Warn("Could not save storage state", slog.String("error", err.Error()))
+	}
+}
+
+// persistsStorage returns true if the storage of the crawl is saved
+func (c *Crawler) persistsStorage() bool {
+	return c.options.StorageStatePath != "" || c.options.SessionFile != ""
+}
+
+// captureStorage records the local and session storage of the page origin
+func (c *Crawler) captureStorage(page *browser.BrowserPage) {
+	object, err := page.Eval(`() => {
+		try {
+			return {
+				origin: location.origin,
+				local: Object.assign({}, localStorage),
+				session: Object.assign({}, sessionStorage),
+			};
+		} catch (e) {
+			return null;
+		}
+	}`)
+	if err != nil || object.Value.Nil() {
+		return
+	}
+	var storage struct {
+		Origin  string            `json:"origin"`
+		Local   map[string]string `json:"local"`
+		Session map[string]string `json:"session"`
+	}
+	if err := object.Value.Unmarshal(&storage); err != nil || storage.Origin == "" || storage.Origin == "null" {
+		return
+	}
+
+	c.storageMu.Lock()
+	defer c.storageMu.Unlock()
+	setOriginItems(c.localStorage, storage.Origin, storage.Local)
+	setOriginItems(c.sessionStorage, storage.Origin, storage.Session)
+}
+
+// setOriginItems sets the storage items of an origin, removing
+// the origin when it has no items
+func setOriginItems(storage map[string]map[string]string, origin string, items map[string]string) {
+	if len(items) == 0 {
+		delete(storage, origin)
+		return
+	}
+	storage[origin] = items
+}
+
+// restoreStorage installs the restored local and session storage items
+// on the page, they are set on documents of their origin before any
+// page script runs unless the page already set them.
+func (c *Crawler) restoreStorage(page *browser.BrowserPage) error {
+	c.storageMu.Lock()
+	defer c.storageMu.Unlock()
+
+	if len(c.localStorage) == 0 && len(c.sessionStorage) == 0 {
+		return nil
+	}
+	if _, ok := c.storageRestored[page]; ok {
+		return nil
+	}
+	localItems, err := json.Marshal(c.localStorage)
+	if err != nil {
+		return err
+	}
+	sessionItems, err := json.Marshal(c.sessionStorage)
+	if err != nil {
+		return err
+	}
+	script := fmt.Sprintf(`(() => {
+		const restore = (storage, items) => {
+			for (const [key, value] of Object.entries(items[location.origin] || {})) {
+				if (storage.getItem(key) === null) {
+					storage.setItem(key, value);
+				}
+			}
+		};
+		try {
+			restore(localStorage, %s);
+			restore(sessionStorage, %s);
+		} catch (e) {}
+	})()`, localItems, sessionItems)
+	if _, err := page.EvalOnNewDocument(script); err != nil {
+		return err
+	}
+	c.storageRestored[page] = struct{}{}
+	return nil
+}
@@ -0,0 +1,78 @@
+package crawler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageStatePath(t *testing.T) {
+	require.Equal(t, filepath.Join("state", "https_example.com_8443.json"), StorageStatePath("state", "https://example.com:8443/login?next=/"))
+	require.Equal(t, filepath.Join("state", "http__2001_db8_1_.json"), StorageStatePath("state", "http://[2001:db8::1]/"))
+}
+
+func TestGraphExportPath(t *testing.T) {
+	require.Equal(t, filepath.Join("graphs", "https_example.com-crawl-graph.graphml"), GraphExportPath("graphs", "https://example.com/app", graph.ExportFormatGraphML))
+}
+
+func TestStorageStateRoundtrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "https_example.com.json")
+
+	state, err := LoadStorageState(path)
+	require.NoError(t, err)
+	require.Nil(t, state, "missing state should not be restored")
+
+	saved := &StorageState{
+		Cookies:      []*proto.NetworkCookieParam{{Name: "session", Value: "secret", Domain: "example.com", Path: "/", HTTPOnly: true}},
+		LocalStorage: map[string]map[string]string{"https://example.com": {"token": "jwt"}},
+	}
+	require.NoError(t, saved.Save(path))
+
+	state, err = LoadStorageState(path)
+	require.NoError(t, err)
+	require.Equal(t, saved.Cookies, state.Cookies)
+	require.Equal(t, saved.LocalStorage, state.LocalStorage)
+}
+
+func TestSessionFileRoundtrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	state, err := LoadSessionFile(path)
+	require.NoError(t, err)
+	require.Nil(t, state, "missing session should not be restored")
+
+	saved := &StorageState{
+		Cookies: []*proto.NetworkCookieParam{
+			{Name: "session", Value: "secret", Domain: "example.com", Path: "/", HTTPOnly: true, Secure: true, SameSite: proto.NetworkCookieSameSiteStrict, Expires: -1},
+			{Name: "remember", Value: "1", Domain: ".example.com", Path: "/", SameSite: proto.NetworkCookieSameSiteLax, Expires: 1893456000},
+		},
+		LocalStorage:   map[string]map[string]string{"https://example.com": {"token": "jwt", "theme": "dark"}},
+		SessionStorage: map[string]map[string]string{"https://app.example.com": {"tab": "orders"}},
+	}
+	require.NoError(t, saved.SaveSessionFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"cookies": [
+			{"name": "session", "value": "secret", "domain": "example.com", "path": "/", "expires": -1, "httpOnly": true, "secure": true, "sameSite": "Strict"},
+			{"name": "remember", "value": "1", "domain": ".example.com", "path": "/", "expires": 1893456000, "httpOnly": false, "secure": false, "sameSite": "Lax"}
+		],
+		"origins": [
+			{"origin": "https://app.example.com", "localStorage": [], "sessionStorage": [{"name": "tab", "value": "orders"}]},
+			{"origin": "https://example.com", "localStorage": [{"name": "theme", "value": "dark"}, {"name": "token", "value": "jwt"}]}
+		]
+	}`, string(data))
+
+	state, err = LoadSessionFile(path)
+	require.NoError(t, err)
+	require.Len(t, state.Cookies, 2)
+	require.Zero(t, state.Cookies[0].Expires, "session cookies should have no expiry")
+	require.Equal(t, saved.Cookies[1], state.Cookies[1])
+	require.Equal(t, saved.LocalStorage, state.LocalStorage)
+	require.Equal(t, saved.SessionStorage, state.SessionStorage)
+}
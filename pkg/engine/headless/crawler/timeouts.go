@@ -0,0 +1,70 @@
+package crawler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ActionTimeouts are the time budgets of the different kinds of
+// actions, slow initial loads and fast in-page clicks need very
+// different budgets.
+type ActionTimeouts struct {
+	// Navigation bounds loading a URL
+	Navigation time.Duration
+	// ClickSettle bounds waiting for the page to settle after a click
+	ClickSettle time.Duration
+	// FormSubmit bounds submitting a form and loading its response
+	FormSubmit time.Duration
+	// Scroll bounds scrolling an element into view
+	Scroll time.Duration
+}
+
+// DefaultActionTimeouts are the default action timeouts
+var DefaultActionTimeouts = ActionTimeouts{
+	Navigation:  30 * time.Second,
+	ClickSettle: 10 * time.Second,
+	FormSubmit:  20 * time.Second,
+	Scroll:      5 * time.Second,
+}
+
+// ParseActionTimeouts parses action timeouts in the kind=duration
+// format (eg. navigation=45s,click=5s), kinds that are not given
+// keep their default timeout.
+func ParseActionTimeouts(values []string) (ActionTimeouts, error) {
+	timeouts := DefaultActionTimeouts
+	for _, value := range values {
+		kind, rawDuration, ok := strings.Cut(value, "=")
+		if !ok {
+			return timeouts, errors.Errorf("invalid action timeout %q, expected kind=duration", value)
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(rawDuration))
+		if err != nil || duration <= 0 {
+			return timeouts, errors.Errorf("invalid duration for action timeout %q", value)
+		}
+		switch strings.ToLower(strings.TrimSpace(kind)) {
+		case "navigation", "nav":
+			timeouts.Navigation = duration
+		case "click":
+			timeouts.ClickSettle = duration
+		case "submit", "form":
+			timeouts.FormSubmit = duration
+		case "scroll":
+			timeouts.Scroll = duration
+		default:
+			return timeouts, errors.Errorf("unknown action timeout kind %q (navigation, click, submit, scroll)", kind)
+		}
+	}
+	return timeouts, nil
+}
+
+// withDefaults returns the timeouts with unset ones set to the fallback
+func (t ActionTimeouts) withDefaults(fallback time.Duration) ActionTimeouts {
+	for _, timeout := range []*time.Duration{&t.Navigation, &t.ClickSettle, &t.FormSubmit, &t.Scroll} {
+		if *timeout <= 0 {
+			*timeout = fallback
+		}
+	}
+	return t
+}
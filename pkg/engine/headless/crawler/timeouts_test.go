@@ -0,0 +1,38 @@
+package crawler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseActionTimeouts(t *testing.T) {
+	timeouts, err := ParseActionTimeouts(nil)
+	require.NoError(t, err)
+	require.Equal(t, DefaultActionTimeouts, timeouts)
+
+	timeouts, err = ParseActionTimeouts([]string{"navigation=1m", "click=2s", "Scroll = 500ms"})
+	require.NoError(t, err)
+	require.Equal(t, ActionTimeouts{
+		Navigation:  time.Minute,
+		ClickSettle: 2 * time.Second,
+		FormSubmit:  DefaultActionTimeouts.FormSubmit,
+		Scroll:      500 * time.Millisecond,
+	}, timeouts)
+
+	for _, invalid := range []string{"navigation", "click=fast", "submit=0s", "hover=1s"} {
+		_, err = ParseActionTimeouts([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestActionTimeoutsWithDefaults(t *testing.T) {
+	timeouts := ActionTimeouts{ClickSettle: time.Second}.withDefaults(30 * time.Second)
+	require.Equal(t, ActionTimeouts{
+		Navigation:  30 * time.Second,
+		ClickSettle: time.Second,
+		FormSubmit:  30 * time.Second,
+		Scroll:      30 * time.Second,
+	}, timeouts)
+}
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/diagnostics"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// PhaseTiming is the total duration of a phase over the processed actions
+type PhaseTiming struct {
+	Phase diagnostics.Phase
+	Total time.Duration
+	// Count is the number of actions the phase ran for
+	Count int
+}
+
+// Average returns the average duration of the phase per action
+func (p PhaseTiming) Average() time.Duration {
+	if p.Count == 0 {
+		return 0
+	}
+	return p.Total / time.Duration(p.Count)
+}
+
+// phaseTimings aggregates the phase timings of the processed actions.
+// It is safe for concurrent use by the crawl workers.
+type phaseTimings struct {
+	mu     sync.Mutex
+	totals map[diagnostics.Phase]*PhaseTiming
+}
+
+func (p *phaseTimings) add(timings diagnostics.ActionTimings) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.totals == nil {
+		p.totals = make(map[diagnostics.Phase]*PhaseTiming)
+	}
+	for phase, duration := range timings {
+		total, ok := p.totals[phase]
+		if !ok {
+			total = &PhaseTiming{Phase: phase}
+			p.totals[phase] = total
+		}
+		total.Total += duration
+		total.Count++
+	}
+}
+
+// list returns the timings of the phases which ran in phase order
+func (p *phaseTimings) list() []PhaseTiming {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var list []PhaseTiming
+	for _, phase := range diagnostics.Phases {
+		if total, ok := p.totals[phase]; ok {
+			list = append(list, *total)
+		}
+	}
+	return list
+}
+
+// PhaseTimings returns the total durations of the phases of processing
+// the actions, to tell the target latency from the crawler overhead
+func (c *Crawler) PhaseTimings() []PhaseTiming {
+	return c.timings.list()
+}
+
+// recordTimings adds the phase timings of an action to the totals
+// and logs them to the diagnostics
+func (c *Crawler) recordTimings(action *types.Action, timings diagnostics.ActionTimings) {
+	c.timings.add(timings)
+	if c.diagnostics == nil {
+		return
+	}
+	if err := c.diagnostics.LogTimings(action, timings); err != nil {
+		c.logger.Warn("Failed to log action timings", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,33 @@
+package crawler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler/diagnostics"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhaseTimings(t *testing.T) {
+	var timings phaseTimings
+	require.Empty(t, timings.list())
+
+	timings.add(diagnostics.ActionTimings{
+		diagnostics.HashingPhase: 10 * time.Millisecond,
+		diagnostics.ExecutePhase: 100 * time.Millisecond,
+		diagnostics.WaitPhase:    2 * time.Second,
+	})
+	timings.add(diagnostics.ActionTimings{
+		diagnostics.HashingPhase:      30 * time.Millisecond,
+		diagnostics.NavigateBackPhase: time.Second,
+	})
+
+	list := timings.list()
+	require.Len(t, list, 4)
+	require.Equal(t, diagnostics.NavigateBackPhase, list[0].Phase, "phases should be listed in the order they run")
+	require.Equal(t, diagnostics.HashingPhase, list[3].Phase)
+	require.Equal(t, 40*time.Millisecond, list[3].Total)
+	require.Equal(t, 2, list[3].Count)
+	require.Equal(t, 20*time.Millisecond, list[3].Average())
+	require.Zero(t, PhaseTiming{}.Average())
+}
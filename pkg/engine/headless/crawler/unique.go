@@ -0,0 +1,113 @@
+package crawler
+
+import (
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/hmap/store/hybrid"
+)
+
+// UniqueActionStats contains the statistics of the unique action set
+type UniqueActionStats struct {
+	// Tracked is the number of action hashes kept in memory
+	Tracked int
+	// Evicted is the number of action hashes evicted from memory
+	Evicted int
+	// Spilled is the number of action hashes written to disk
+	Spilled int
+}
+
+// actionSet tracks the hashes of the actions already queued.
+//
+// When a limit is set, the least recently seen hashes are evicted
+// from memory and optionally spilled to disk. Evicted hashes which
+// were not spilled may lead to actions being crawled again.
+// The set is safe for concurrent use by the crawl workers.
+type actionSet struct {
+	mu        sync.Mutex
+	unbounded map[string]struct{}
+	cache     *lru.Cache[string, struct{}]
+	spill     *hybrid.HybridMap
+	stats     UniqueActionStats
+}
+
+// newActionSet creates a new action set keeping at most maxEntries
+// hashes in memory. A zero maxEntries keeps all the hashes.
+func newActionSet(maxEntries int, spill bool) (*actionSet, error) {
+	set := &actionSet{}
+	if maxEntries <= 0 {
+		set.unbounded = make(map[string]struct{})
+		return set, nil
+	}
+	if spill {
+		spillMap, err := hybrid.New(hybrid.DefaultDiskOptions)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create unique actions spill")
+		}
+		set.spill = spillMap
+	}
+	cache, err := lru.NewWithEvict(maxEntries, set.evict)
+	if err != nil {
+		set.Close()
+		return nil, errors.Wrap(err, "could not create unique actions cache")
+	}
+	set.cache = cache
+	return set, nil
+}
+
+// evict is called by the cache while the set is locked in Seen
+func (s *actionSet) evict(hash string, _ struct{}) {
+	s.stats.Evicted++
+	if s.spill == nil {
+		return
+	}
+	if err := s.spill.Set(hash, nil); err == nil {
+		s.stats.Spilled++
+	}
+}
+
+// Seen returns true if the hash was already seen, adding it otherwise
+func (s *actionSet) Seen(hash string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.unbounded != nil {
+		if _, ok := s.unbounded[hash]; ok {
+			return true
+		}
+		s.unbounded[hash] = struct{}{}
+		return false
+	}
+	if _, ok := s.cache.Get(hash); ok {
+		return true
+	}
+	if s.spill != nil {
+		if _, ok := s.spill.Get(hash); ok {
+			return true
+		}
+	}
+	s.cache.Add(hash, struct{}{})
+	return false
+}
+
+// Stats returns the statistics of the set
+func (s *actionSet) Stats() UniqueActionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	if s.unbounded != nil {
+		stats.Tracked = len(s.unbounded)
+	} else {
+		stats.Tracked = s.cache.Len()
+	}
+	return stats
+}
+
+// Close releases the disk spill of the set
+func (s *actionSet) Close() {
+	if s.spill != nil {
+		_ = s.spill.Close()
+	}
+}
@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionSet(t *testing.T) {
+	t.Run("unbounded", func(t *testing.T) {
+		set, err := newActionSet(0, false)
+		require.NoError(t, err)
+		defer set.Close()
+
+		require.False(t, set.Seen("a"))
+		require.True(t, set.Seen("a"))
+		require.Equal(t, UniqueActionStats{Tracked: 1}, set.Stats())
+	})
+
+	t.Run("evict", func(t *testing.T) {
+		set, err := newActionSet(2, false)
+		require.NoError(t, err)
+		defer set.Close()
+
+		require.False(t, set.Seen("a"))
+		require.False(t, set.Seen("b"))
+		require.True(t, set.Seen("a"))
+		require.False(t, set.Seen("c"))
+
+		// b was the least recently seen hash and got evicted
+		require.False(t, set.Seen("b"))
+		require.Equal(t, UniqueActionStats{Tracked: 2, Evicted: 2}, set.Stats())
+	})
+
+	t.Run("spill", func(t *testing.T) {
+		set, err := newActionSet(1, true)
+		require.NoError(t, err)
+		defer set.Close()
+
+		require.False(t, set.Seen("a"))
+		require.False(t, set.Seen("b"))
+		require.True(t, set.Seen("a"))
+		require.Equal(t, UniqueActionStats{Tracked: 1, Evicted: 1, Spilled: 1}, set.Stats())
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		set, err := newActionSet(0, false)
+		require.NoError(t, err)
+		defer set.Close()
+
+		var (
+			wg     sync.WaitGroup
+			unseen atomic.Int32
+		)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					if !set.Seen(strconv.Itoa(j)) {
+						unseen.Add(1)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(100), unseen.Load(), "every hash should be reported unseen exactly once")
+	})
+}
@@ -1,13 +1,23 @@
 package headless
 
 import (
+	_ "embed"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler"
+	headlesstypes "github.com/projectdiscovery/katana/pkg/engine/headless/types"
 )
 
+//go:embed debugger.html
+var debuggerUI []byte
+
+// maxDebugTimings is the number of action timings kept per crawl
+const maxDebugTimings = 100
+
 // ActiveURL represents a URL currently being processed
 type ActiveURL struct {
 	URL       string    `json:"url"`
@@ -16,25 +26,76 @@ type ActiveURL struct {
 	Depth     int       `json:"depth"`
 }
 
-// CrawlDebugger tracks active URLs for debugging
+// ActionTiming is the execution time of a crawl action
+type ActionTiming struct {
+	Action   string    `json:"action"`
+	Time     time.Time `json:"time"`
+	Duration string    `json:"duration"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// QueuedAction is an action waiting in the crawl queue
+type QueuedAction struct {
+	Hash   string `json:"hash"`
+	Action string `json:"action"`
+	Depth  int    `json:"depth"`
+}
+
+// GraphNode is a page state of the crawl graph
+type GraphNode struct {
+	ID    string `json:"id"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	Depth int    `json:"depth"`
+}
+
+// GraphEdge is an action leading from a page state to another
+type GraphEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Action string `json:"action"`
+}
+
+// CrawlState is the live state of a crawl
+type CrawlState struct {
+	URL             string         `json:"url"`
+	Queue           []QueuedAction `json:"queue"`
+	Timings         []ActionTiming `json:"timings"`
+	Nodes           []GraphNode    `json:"nodes"`
+	Edges           []GraphEdge    `json:"edges"`
+	ScreenshotState string         `json:"screenshot_state,omitempty"`
+
+	screenshot []byte
+	nodes      map[string]struct{}
+	commands   []crawler.DebugCommand
+}
+
+// CrawlDebugger tracks active URLs and the live state
+// of their crawls for debugging.
 type CrawlDebugger struct {
 	mu         sync.RWMutex
 	activeURLs map[string]*ActiveURL
+	crawls     map[string]*CrawlState
 	httpServer *http.Server
 }
 
-// NewCrawlDebugger creates a new debugger instance
-func NewCrawlDebugger(httpPort int) *CrawlDebugger {
+// NewCrawlDebugger creates a new debugger instance listening on addr
+func NewCrawlDebugger(addr string) *CrawlDebugger {
 	cd := &CrawlDebugger{
 		activeURLs: make(map[string]*ActiveURL),
+		crawls:     make(map[string]*CrawlState),
 	}
 
 	mux := http.NewServeMux()
+	mux.HandleFunc("/", cd.handleUI)
 	mux.HandleFunc("/debug/active-urls", cd.handleActiveURLs)
+	mux.HandleFunc("/debug/crawls", cd.handleCrawls)
+	mux.HandleFunc("/debug/screenshot", cd.handleScreenshot)
+	mux.HandleFunc("/debug/queue", cd.handleQueue)
 	mux.HandleFunc("/debug/health", cd.handleHealth)
 
 	cd.httpServer = &http.Server{
-		Addr:              fmt.Sprintf("127.0.0.1:%d", httpPort),
+		Addr:              addr,
 		Handler:           mux,
 		ReadTimeout:       5 * time.Second,
 		ReadHeaderTimeout: 2 * time.Second,
@@ -63,6 +124,7 @@ func (cd *CrawlDebugger) StartURL(url string, depth int) {
 		StartTime: time.Now(),
 		Depth:     depth,
 	}
+	cd.crawls[url] = &CrawlState{URL: url, nodes: make(map[string]struct{})}
 	cd.mu.Unlock()
 }
 
@@ -74,9 +136,89 @@ func (cd *CrawlDebugger) EndURL(url string) {
 
 	cd.mu.Lock()
 	delete(cd.activeURLs, url)
+	delete(cd.crawls, url)
 	cd.mu.Unlock()
 }
 
+// Crawl returns the debugger receiving the state of the crawl of url
+func (cd *CrawlDebugger) Crawl(url string) crawler.Debugger {
+	if cd == nil {
+		return nil
+	}
+	return &crawlDebugger{debugger: cd, url: url}
+}
+
+// crawlDebugger records the state of a single crawl
+type crawlDebugger struct {
+	debugger *CrawlDebugger
+	url      string
+}
+
+// update calls fn with the state of the crawl if it is active
+func (d *crawlDebugger) update(fn func(state *CrawlState)) {
+	d.debugger.mu.Lock()
+	defer d.debugger.mu.Unlock()
+	if state, ok := d.debugger.crawls[d.url]; ok {
+		fn(state)
+	}
+}
+
+func (d *crawlDebugger) OnQueue(actions []*headlesstypes.Action) {
+	queue := make([]QueuedAction, 0, len(actions))
+	for _, action := range actions {
+		queue = append(queue, QueuedAction{Hash: action.Hash(), Action: action.String(), Depth: action.Depth})
+	}
+	d.update(func(state *CrawlState) {
+		state.Queue = queue
+	})
+}
+
+func (d *crawlDebugger) OnAction(action *headlesstypes.Action, duration time.Duration, err error) {
+	timing := ActionTiming{Action: action.String(), Time: time.Now(), Duration: duration.String()}
+	if err != nil {
+		timing.Error = err.Error()
+	}
+	d.update(func(state *CrawlState) {
+		state.Timings = append(state.Timings, timing)
+		if len(state.Timings) > maxDebugTimings {
+			state.Timings = state.Timings[len(state.Timings)-maxDebugTimings:]
+		}
+	})
+}
+
+func (d *crawlDebugger) OnPageState(pageState *headlesstypes.PageState, screenshot []byte) {
+	d.update(func(state *CrawlState) {
+		if len(screenshot) > 0 {
+			state.screenshot = screenshot
+			state.ScreenshotState = pageState.UniqueID
+		}
+		if _, ok := state.nodes[pageState.UniqueID]; !ok {
+			state.nodes[pageState.UniqueID] = struct{}{}
+			state.Nodes = append(state.Nodes, GraphNode{
+				ID:    pageState.UniqueID,
+				URL:   pageState.URL,
+				Title: pageState.Title,
+				Depth: pageState.Depth,
+			})
+		}
+		if pageState.NavigationAction != nil && pageState.OriginID != "" {
+			state.Edges = append(state.Edges, GraphEdge{
+				From:   pageState.OriginID,
+				To:     pageState.UniqueID,
+				Action: pageState.NavigationAction.String(),
+			})
+		}
+	})
+}
+
+func (d *crawlDebugger) Commands() []crawler.DebugCommand {
+	var commands []crawler.DebugCommand
+	d.update(func(state *CrawlState) {
+		commands, state.commands = state.commands, nil
+	})
+	return commands
+}
+
 // GetActiveURLs returns currently active URLs with durations
 func (cd *CrawlDebugger) GetActiveURLs() []ActiveURL {
 	if cd == nil {
@@ -109,6 +251,79 @@ func (cd *CrawlDebugger) handleActiveURLs(w http.ResponseWriter, r *http.Request
 	}
 }
 
+func (cd *CrawlDebugger) handleUI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(debuggerUI)
+}
+
+func (cd *CrawlDebugger) handleCrawls(w http.ResponseWriter, r *http.Request) {
+	cd.mu.RLock()
+	crawls := make([]CrawlState, 0, len(cd.crawls))
+	for _, state := range cd.crawls {
+		crawls = append(crawls, *state)
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"crawls":    crawls,
+	})
+	cd.mu.RUnlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func (cd *CrawlDebugger) handleScreenshot(w http.ResponseWriter, r *http.Request) {
+	cd.mu.RLock()
+	var screenshot []byte
+	if state, ok := cd.crawls[r.URL.Query().Get("url")]; ok {
+		screenshot = state.screenshot
+	}
+	cd.mu.RUnlock()
+	if len(screenshot) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(screenshot)
+}
+
+// handleQueue queues a skip or prioritize command for a queued action
+func (cd *CrawlDebugger) handleQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	command := crawler.DebugCommand{
+		Type:       crawler.DebugCommandType(query.Get("command")),
+		ActionHash: query.Get("action"),
+	}
+	if command.Type != crawler.DebugCommandSkip && command.Type != crawler.DebugCommandPrioritize {
+		http.Error(w, "unknown command", http.StatusBadRequest)
+		return
+	}
+
+	cd.mu.Lock()
+	state, ok := cd.crawls[query.Get("url")]
+	if ok {
+		state.commands = append(state.commands, command)
+	}
+	cd.mu.Unlock()
+	if !ok {
+		http.Error(w, "crawl not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
 func (cd *CrawlDebugger) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
@@ -0,0 +1,264 @@
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+)
+
+// ExportFormat is a format the crawl graph can be exported in
+type ExportFormat string
+
+const (
+	ExportFormatJSON    ExportFormat = "json"
+	ExportFormatGraphML ExportFormat = "graphml"
+)
+
+// ExportFormats are the supported export formats
+var ExportFormats = []ExportFormat{ExportFormatJSON, ExportFormatGraphML}
+
+// ExportedGraph is the crawl graph as exported to JSON
+type ExportedGraph struct {
+	States  []ExportedState  `json:"states"`
+	Actions []ExportedAction `json:"actions"`
+}
+
+// ExportedState is a page state of the crawl graph
+type ExportedState struct {
+	ID     string `json:"id"`
+	URL    string `json:"url"`
+	Title  string `json:"title,omitempty"`
+	Depth  int    `json:"depth"`
+	IsRoot bool   `json:"is_root,omitempty"`
+	// StatusCode is the response status of the document of the state
+	StatusCode int `json:"status_code,omitempty"`
+	// ErrorPage tags states served with an error status
+	ErrorPage bool `json:"error_page,omitempty"`
+	// Route is the history api navigation the state was reached with
+	Route string `json:"route,omitempty"`
+}
+
+// ExportedAction is an action leading from a state to another
+type ExportedAction struct {
+	Source string        `json:"source"`
+	Target string        `json:"target"`
+	Label  string        `json:"label"`
+	Depth  int           `json:"depth"`
+	Action *types.Action `json:"action,omitempty"`
+}
+
+// Export writes the graph to w in the given format
+func (g *CrawlGraph) Export(w io.Writer, format ExportFormat) error {
+	switch format {
+	case ExportFormatJSON:
+		return g.ExportJSON(w)
+	case ExportFormatGraphML:
+		return g.ExportGraphML(w)
+	default:
+		return errors.Errorf("unsupported graph export format %q", format)
+	}
+}
+
+// ExportFile writes the graph to file in the given format
+func (g *CrawlGraph) ExportFile(file string, format ExportFormat) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return errors.Wrap(err, "could not create graph export directory")
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return errors.Wrap(err, "could not create graph export file")
+	}
+	if err := g.Export(f, format); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ExportJSON writes the states and actions of the graph as JSON
+func (g *CrawlGraph) ExportJSON(w io.Writer) error {
+	exported, err := g.exportGraph()
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(exported); err != nil {
+		return errors.Wrap(err, "could not write graph json")
+	}
+	return nil
+}
+
+// graphML is the document written by ExportGraphML,
+// see http://graphml.graphdrawing.org/specification.html
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+var graphMLKeys = []graphMLKey{
+	{ID: "url", For: "node", Name: "url", Type: "string"},
+	{ID: "title", For: "node", Name: "title", Type: "string"},
+	{ID: "depth", For: "node", Name: "depth", Type: "int"},
+	{ID: "is_root", For: "node", Name: "is_root", Type: "boolean"},
+	{ID: "status_code", For: "node", Name: "status_code", Type: "int"},
+	{ID: "error_page", For: "node", Name: "error_page", Type: "boolean"},
+	{ID: "route", For: "node", Name: "route", Type: "string"},
+	{ID: "type", For: "edge", Name: "type", Type: "string"},
+	{ID: "label", For: "edge", Name: "label", Type: "string"},
+	{ID: "action_depth", For: "edge", Name: "depth", Type: "int"},
+}
+
+// ExportGraphML writes the states and actions of the graph as GraphML
+func (g *CrawlGraph) ExportGraphML(w io.Writer) error {
+	exported, err := g.exportGraph()
+	if err != nil {
+		return err
+	}
+	document := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{ID: "crawl", EdgeDefault: "directed"},
+	}
+	for _, state := range exported.States {
+		node := graphMLNode{ID: state.ID, Data: []graphMLData{
+			{Key: "url", Value: state.URL},
+			{Key: "depth", Value: strconv.Itoa(state.Depth)},
+			{Key: "is_root", Value: strconv.FormatBool(state.IsRoot)},
+		}}
+		if state.Title != "" {
+			node.Data = append(node.Data, graphMLData{Key: "title", Value: state.Title})
+		}
+		if state.Route != "" {
+			node.Data = append(node.Data, graphMLData{Key: "route", Value: state.Route})
+		}
+		if state.StatusCode != 0 {
+			node.Data = append(node.Data,
+				graphMLData{Key: "status_code", Value: strconv.Itoa(state.StatusCode)},
+				graphMLData{Key: "error_page", Value: strconv.FormatBool(state.ErrorPage)},
+			)
+		}
+		document.Graph.Nodes = append(document.Graph.Nodes, node)
+	}
+	for i, action := range exported.Actions {
+		edge := graphMLEdge{ID: "e" + strconv.Itoa(i), Source: action.Source, Target: action.Target, Data: []graphMLData{
+			{Key: "label", Value: action.Label},
+			{Key: "action_depth", Value: strconv.Itoa(action.Depth)},
+		}}
+		if action.Action != nil {
+			edge.Data = append(edge.Data, graphMLData{Key: "type", Value: string(action.Action.Type)})
+		}
+		document.Graph.Edges = append(document.Graph.Edges, edge)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Wrap(err, "could not write graphml")
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return errors.Wrap(err, "could not write graphml")
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// exportGraph returns the states and actions of the graph sorted by
+// depth so that exports of the same crawl are identical
+func (g *CrawlGraph) exportGraph() (*ExportedGraph, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	adjacencyMap, err := g.graph.AdjacencyMap()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get graph adjacency map")
+	}
+	exported := &ExportedGraph{
+		States:  make([]ExportedState, 0, len(adjacencyMap)),
+		Actions: []ExportedAction{},
+	}
+	for id, edges := range adjacencyMap {
+		state, err := g.graph.Vertex(id)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get vertex")
+		}
+		exported.States = append(exported.States, ExportedState{
+			ID:         state.UniqueID,
+			URL:        state.URL,
+			Title:      state.Title,
+			Depth:      state.Depth,
+			IsRoot:     state.IsRoot,
+			StatusCode: state.StatusCode,
+			ErrorPage:  state.IsErrorPage(),
+			Route:      state.Route,
+		})
+		for _, edge := range edges {
+			action, _ := edge.Properties.Data.(*types.Action)
+			exported.Actions = append(exported.Actions, ExportedAction{
+				Source: edge.Source,
+				Target: edge.Target,
+				Label:  edge.Properties.Attributes["label"],
+				Depth:  edge.Properties.Weight,
+				Action: action,
+			})
+		}
+	}
+	sort.Slice(exported.States, func(i, j int) bool {
+		a, b := exported.States[i], exported.States[j]
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+		return a.ID < b.ID
+	})
+	sort.Slice(exported.Actions, func(i, j int) bool {
+		a, b := exported.Actions[i], exported.Actions[j]
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Target < b.Target
+	})
+	return exported, nil
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportTestGraph(t *testing.T) *CrawlGraph {
+	g := NewCrawlGraph()
+	require.NoError(t, g.AddPageState(types.PageState{UniqueID: "root", URL: "https://example.com", IsRoot: true}))
+	require.NoError(t, g.AddPageState(types.PageState{
+		UniqueID: "login",
+		OriginID: "root",
+		URL:      "https://example.com/login",
+		Title:    "Login & Register",
+		Depth:    1,
+		NavigationAction: &types.Action{
+			Type:     types.ActionTypeLeftClick,
+			OriginID: "root",
+			Depth:    1,
+			Element:  &types.HTMLElement{TagName: "A", XPath: "/html/body/a"},
+		},
+	}))
+	require.NoError(t, g.AddPageState(types.PageState{
+		UniqueID:   "admin",
+		OriginID:   "login",
+		URL:        "https://example.com/admin",
+		Depth:      2,
+		StatusCode: 403,
+		Route:      "pushState",
+	}))
+	require.NoError(t, g.AddEdge("login", "root", &types.Action{Type: types.ActionTypeLoadURL, Input: "https://example.com", Depth: 2}))
+	return g
+}
+
+func TestExportJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, newExportTestGraph(t).ExportJSON(&buffer))
+
+	var exported ExportedGraph
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &exported))
+	require.Equal(t, []ExportedState{
+		{ID: "root", URL: "https://example.com", IsRoot: true},
+		{ID: "login", URL: "https://example.com/login", Title: "Login & Register", Depth: 1},
+		{ID: "admin", URL: "https://example.com/admin", Depth: 2, StatusCode: 403, ErrorPage: true, Route: "pushState"},
+	}, exported.States)
+	require.Len(t, exported.Actions, 2)
+	require.Equal(t, "root", exported.Actions[0].Source)
+	require.Equal(t, "login", exported.Actions[0].Target)
+	require.Equal(t, types.ActionTypeLeftClick, exported.Actions[0].Action.Type)
+	require.Equal(t, "/html/body/a", exported.Actions[0].Action.Element.XPath)
+	require.Equal(t, "login", exported.Actions[1].Source, "back edges should be exported")
+	require.Equal(t, types.ActionTypeLoadURL, exported.Actions[1].Action.Type)
+}
+
+func TestExportGraphML(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, newExportTestGraph(t).Export(&buffer, ExportFormatGraphML))
+
+	var document graphML
+	require.NoError(t, xml.Unmarshal(buffer.Bytes(), &document), "graphml should be valid xml")
+	require.Equal(t, "directed", document.Graph.EdgeDefault)
+	require.Len(t, document.Graph.Nodes, 3)
+	require.Contains(t, document.Graph.Nodes[1].Data, graphMLData{Key: "title", Value: "Login & Register"})
+	require.Contains(t, document.Graph.Nodes[2].Data, graphMLData{Key: "error_page", Value: "true"})
+	require.Contains(t, document.Graph.Nodes[2].Data, graphMLData{Key: "route", Value: "pushState"})
+	require.Len(t, document.Graph.Edges, 2)
+	require.Equal(t, "root", document.Graph.Edges[0].Source)
+	require.Contains(t, document.Graph.Edges[0].Data, graphMLData{Key: "type", Value: string(types.ActionTypeLeftClick)})
+
+	require.Error(t, newExportTestGraph(t).Export(&buffer, "dot"), "unsupported formats should fail")
+}
@@ -4,6 +4,7 @@ package graph
 
 import (
 	"os"
+	"sync"
 
 	"github.com/dominikbraun/graph"
 	"github.com/dominikbraun/graph/draw"
@@ -11,8 +12,10 @@ import (
 	"github.com/projectdiscovery/katana/pkg/engine/headless/types"
 )
 
-// CrawlGraph is a graph for storing state information during crawling
+// CrawlGraph is a graph for storing state information during crawling.
+// It is safe for concurrent use by the crawl workers.
 type CrawlGraph struct {
+	mu    sync.RWMutex
 	graph graph.Graph[string, types.PageState]
 }
 
@@ -32,6 +35,9 @@ func NewCrawlGraph() *CrawlGraph {
 }
 
 func (g *CrawlGraph) GetVertices() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
 	vertices := []string{}
 	adjacencyMap, err := g.graph.AdjacencyMap()
 	if err != nil {
@@ -45,12 +51,19 @@ func (g *CrawlGraph) GetVertices() []string {
 
 // AddNavigation adds a navigation to the graph
 func (g *CrawlGraph) AddPageState(n types.PageState) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	vertexAttrs := map[string]string{
 		"label": n.URL,
 	}
 	if n.IsRoot {
 		vertexAttrs["is_root"] = "true"
 	}
+	// error pages are highlighted when the graph is drawn
+	if n.IsErrorPage() {
+		vertexAttrs["color"] = "red"
+	}
 
 	err := g.graph.AddVertex(n, func(vp *graph.VertexProperties) {
 		vp.Weight = n.Depth
@@ -71,6 +84,7 @@ func (g *CrawlGraph) AddPageState(n types.PageState) error {
 		err = g.graph.AddEdge(n.OriginID, n.UniqueID, func(ep *graph.EdgeProperties) {
 			ep.Weight = n.Depth
 			ep.Attributes = edgeAttrs
+			ep.Data = n.NavigationAction
 		})
 		if err != nil {
 			if errors.Is(err, graph.ErrEdgeAlreadyExists) {
@@ -83,6 +97,9 @@ func (g *CrawlGraph) AddPageState(n types.PageState) error {
 }
 
 func (g *CrawlGraph) AddEdge(sourceState, targetState string, action *types.Action) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	if action == nil {
 		return errors.New("add edge: action cannot be nil")
 	}
@@ -92,6 +109,7 @@ func (g *CrawlGraph) AddEdge(sourceState, targetState string, action *types.Acti
 	err := g.graph.AddEdge(sourceState, targetState, func(ep *graph.EdgeProperties) {
 		ep.Weight = action.Depth
 		ep.Attributes = edgeAttrs
+		ep.Data = action
 	})
 	if err != nil {
 		if errors.Is(err, graph.ErrEdgeAlreadyExists) {
@@ -103,6 +121,9 @@ func (g *CrawlGraph) AddEdge(sourceState, targetState string, action *types.Acti
 }
 
 func (g *CrawlGraph) GetPageState(id string) (*types.PageState, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
 	pageVertex, err := g.graph.Vertex(id)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get vertex")
@@ -111,6 +132,9 @@ func (g *CrawlGraph) GetPageState(id string) (*types.PageState, error) {
 }
 
 func (g *CrawlGraph) ShortestPath(sourceState, targetState string) ([]*types.Action, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
 	shortestPath, err := graph.ShortestPath(g.graph, sourceState, targetState)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not find shortest path")
@@ -131,6 +155,9 @@ func (g *CrawlGraph) ShortestPath(sourceState, targetState string) ([]*types.Act
 }
 
 func (g *CrawlGraph) DrawGraph(file string) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
 	f, err := os.Create(file)
 	if err != nil {
 		return errors.Wrap(err, "could not create graph file")
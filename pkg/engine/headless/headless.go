@@ -1,21 +1,37 @@
 package headless
 
 import (
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/lmittmann/tint"
 	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/engine/common"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/authscript"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/browser"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/captcha"
 	_ "github.com/projectdiscovery/katana/pkg/engine/headless/captcha/capsolver"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/capture"
 	"github.com/projectdiscovery/katana/pkg/engine/headless/crawler"
+	"github.com/projectdiscovery/katana/pkg/engine/headless/graph"
+	headlesstypes "github.com/projectdiscovery/katana/pkg/engine/headless/types"
 	"github.com/projectdiscovery/katana/pkg/engine/parser"
+	"github.com/projectdiscovery/katana/pkg/navigation"
 	"github.com/projectdiscovery/katana/pkg/output"
 	"github.com/projectdiscovery/katana/pkg/types"
 	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/katana/pkg/utils/debugserver"
+	"github.com/projectdiscovery/katana/pkg/utils/scope"
+	"github.com/projectdiscovery/utils/errkit"
 	mapsutil "github.com/projectdiscovery/utils/maps"
 )
 
@@ -26,7 +42,27 @@ type Headless struct {
 	deduplicator *mapsutil.SyncLockMap[string, struct{}]
 	pathTrie     *utils.PathTrie
 
-	debugger *CrawlDebugger
+	debugger       *CrawlDebugger
+	authActions    []*headlesstypes.Action
+	loginPage      *crawler.LoginPage
+	logoutPatterns crawler.LogoutPatterns
+	actionTimeouts crawler.ActionTimeouts
+	similarity     crawler.Similarity
+	resourceTypes  browser.ResourceTypes
+	// clientCertificate is presented to the servers requesting one
+	clientCertificate *tls.Certificate
+
+	captureProxy       *capture.Proxy
+	captureMu          sync.RWMutex
+	captureSubscribers map[*captureSubscriber]struct{}
+}
+
+// captureSubscriber receives navigations observed by the capture
+// proxy which are in scope for a running crawl.
+type captureSubscriber struct {
+	scopeValidator browser.ScopeValidator
+	actions        chan *headlesstypes.Action
+	seen           *mapsutil.SyncLockMap[string, struct{}]
 }
 
 // New returns a new headless crawler instance
@@ -43,14 +79,175 @@ func New(options *types.CrawlerOptions) (*Headless, error) {
 		headless.pathTrie = utils.NewPathTrie(options.Options.FilterSimilarThreshold)
 	}
 
-	// Show crawl debugger if verbose is enabled
-	if options.Options.Verbose {
-		headless.debugger = NewCrawlDebugger(8089)
+	// Show crawl debugger if requested or verbose is enabled
+	if options.Options.HeadlessDebuggerAddr != "" {
+		headless.debugger = NewCrawlDebugger(options.Options.HeadlessDebuggerAddr)
+	} else if options.Options.Verbose {
+		headless.debugger = NewCrawlDebugger("127.0.0.1:8089")
+	}
+
+	if options.Options.AuthScript != "" {
+		actions, err := authscript.ParseFile(options.Options.AuthScript)
+		if err != nil {
+			return nil, errkit.Wrap(err, "headless: could not parse auth script")
+		}
+		headless.authActions = actions
+	}
+
+	loginPage, err := crawler.ParseLoginPage(options.Options.LoginPageURLs, options.Options.LoginPageMarkers)
+	if err != nil {
+		return nil, errkit.Wrap(err, "headless: could not parse login page")
+	}
+	headless.loginPage = loginPage
+
+	logoutPatterns, err := crawler.ParseLogoutPatterns(options.Options.HeadlessLogoutPatterns)
+	if err != nil {
+		return nil, errkit.Wrap(err, "headless: could not parse logout patterns")
+	}
+	headless.logoutPatterns = logoutPatterns
+
+	actionTimeouts, err := crawler.ParseActionTimeouts(options.Options.HeadlessActionTimeouts)
+	if err != nil {
+		return nil, errkit.Wrap(err, "headless: could not parse action timeouts")
+	}
+	headless.actionTimeouts = actionTimeouts
+
+	similarity, err := crawler.ParseSimilarity(options.Options.HeadlessSimilarity)
+	if err != nil {
+		return nil, errkit.Wrap(err, "headless: could not parse similarity")
+	}
+	headless.similarity = similarity
+
+	resourceTypes, err := browser.ParseResourceTypes(options.Options.HeadlessResourceTypes)
+	if err != nil {
+		return nil, errkit.Wrap(err, "headless: could not parse resource types")
+	}
+	headless.resourceTypes = resourceTypes
+
+	if options.Options.HeadlessClientCert != "" {
+		keyFile := options.Options.HeadlessClientKey
+		if keyFile == "" {
+			// the key may be in the certificate file
+			keyFile = options.Options.HeadlessClientCert
+		}
+		certificate, err := tls.LoadX509KeyPair(options.Options.HeadlessClientCert, keyFile)
+		if err != nil {
+			return nil, errkit.Wrap(err, "headless: could not load client certificate")
+		}
+		headless.clientCertificate = &certificate
+	}
+
+	if options.Options.CaptureProxy != "" {
+		if err := headless.startCaptureProxy(); err != nil {
+			return nil, err
+		}
 	}
 
 	return headless, nil
 }
 
+// startCaptureProxy starts the intercepting proxy used to feed
+// manually browsed navigations into the running crawls.
+func (h *Headless) startCaptureProxy() error {
+	homedir, err := os.UserHomeDir()
+	if err != nil {
+		return errkit.Wrap(err, "headless: could not get home directory")
+	}
+	configDir := filepath.Join(homedir, ".config", "katana")
+	certFile := filepath.Join(configDir, "capture-ca.crt")
+	authority, err := capture.LoadOrCreateAuthority(certFile, filepath.Join(configDir, "capture-ca.key"))
+	if err != nil {
+		return errkit.Wrap(err, "headless: could not load capture proxy ca")
+	}
+
+	proxy, err := capture.New(capture.Options{
+		ListenAddress: h.options.Options.CaptureProxy,
+		Authority:     authority,
+		UpstreamProxy: h.options.Options.Proxy,
+		OnRequest:     h.onCapturedRequest,
+		Logger:        h.logger,
+	})
+	if err != nil {
+		return errkit.Wrap(err, "headless: could not create capture proxy")
+	}
+	if err := proxy.Start(); err != nil {
+		return errkit.Wrap(err, "headless: could not start capture proxy")
+	}
+	h.captureProxy = proxy
+	h.captureSubscribers = make(map[*captureSubscriber]struct{})
+
+	gologger.Info().Msgf("Capture proxy listening on %s (trust %s for https interception)", proxy.Addr(), certFile)
+	return nil
+}
+
+// subscribeCapture registers a crawl for captured navigations returning
+// the channel of actions and a function to unregister it.
+func (h *Headless) subscribeCapture(scopeValidator browser.ScopeValidator) (<-chan *headlesstypes.Action, func()) {
+	subscriber := &captureSubscriber{
+		scopeValidator: scopeValidator,
+		actions:        make(chan *headlesstypes.Action, 100),
+		seen:           mapsutil.NewSyncLockMap[string, struct{}](),
+	}
+	h.captureMu.Lock()
+	h.captureSubscribers[subscriber] = struct{}{}
+	h.captureMu.Unlock()
+
+	return subscriber.actions, func() {
+		h.captureMu.Lock()
+		delete(h.captureSubscribers, subscriber)
+		h.captureMu.Unlock()
+	}
+}
+
+// onCapturedRequest dispatches a navigation observed by the capture
+// proxy to the crawls for which it is in scope. Page navigations are
+// queued as load actions. Form submissions are written to output and
+// the page which submitted them is queued so that its forms are
+// explored and added to the crawl graph by the crawler.
+func (h *Headless) onCapturedRequest(req *navigation.Request) {
+	h.captureMu.RLock()
+	defer h.captureMu.RUnlock()
+
+	target := req.URL
+	if req.Method != http.MethodGet {
+		h.writeCapturedSubmission(req)
+		target = req.Source
+	}
+	if target == "" {
+		return
+	}
+
+	for subscriber := range h.captureSubscribers {
+		if subscriber.scopeValidator != nil && !subscriber.scopeValidator(target) {
+			continue
+		}
+		if _, ok := subscriber.seen.Get(target); ok {
+			continue
+		}
+		_ = subscriber.seen.Set(target, struct{}{})
+
+		select {
+		case subscriber.actions <- &headlesstypes.Action{Type: headlesstypes.ActionTypeLoadURL, Input: target}:
+		default:
+			h.logger.Debug("capture queue full, dropping navigation", slog.String("url", target))
+		}
+	}
+}
+
+// writeCapturedSubmission writes a captured form submission
+// to output once if it is in scope for any running crawl.
+func (h *Headless) writeCapturedSubmission(req *navigation.Request) {
+	for subscriber := range h.captureSubscribers {
+		if subscriber.scopeValidator != nil && !subscriber.scopeValidator(req.URL) {
+			continue
+		}
+		if err := h.options.OutputWriter.Write(&output.Result{Timestamp: time.Now(), Request: req}); err != nil {
+			h.logger.Debug("failed to write captured request", slog.String("error", err.Error()))
+		}
+		return
+	}
+}
+
 func newLogger(options *types.CrawlerOptions) *slog.Logger {
 	if options.Logger != nil {
 		return options.Logger
@@ -72,6 +269,22 @@ func newLogger(options *types.CrawlerOptions) *slog.Logger {
 	return logger
 }
 
+// sessionCheck returns the session check of authenticated crawls
+func (h *Headless) sessionCheck() *crawler.SessionCheck {
+	if h.options.Options.SessionCheckURL == "" {
+		return nil
+	}
+	interval := h.options.Options.SessionCheckInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &crawler.SessionCheck{
+		URL:      h.options.Options.SessionCheckURL,
+		Marker:   h.options.Options.SessionCheckMarker,
+		Interval: interval,
+	}
+}
+
 func validateScopeFunc(h *Headless, URL string) browser.ScopeValidator {
 	parsedURL, err := url.Parse(URL)
 	if err != nil {
@@ -83,7 +296,8 @@ func validateScopeFunc(h *Headless, URL string) browser.ScopeValidator {
 		if h.options.ScopeManager == nil {
 			return true
 		}
-		parsed, err := url.Parse(s)
+		// links to mapped hosts are requested from their target
+		parsed, err := url.Parse(h.options.HostMap.Apply(s))
 		if err != nil {
 			return false
 		}
@@ -95,6 +309,32 @@ func validateScopeFunc(h *Headless, URL string) browser.ScopeValidator {
 	}
 }
 
+// validateHostFunc returns a validator of the hosts of urls against the
+// host scope of the url, it rejects the urls of third-party hosts
+func validateHostFunc(h *Headless, URL string) browser.ScopeValidator {
+	parsedURL, err := url.Parse(URL)
+	if err != nil || h.options.ScopeManager == nil {
+		return func(string) bool { return true }
+	}
+	rootHostname := parsedURL.Hostname()
+
+	return func(s string) bool {
+		// requests to mapped hosts are sent to their target
+		parsed, err := url.Parse(h.options.HostMap.Apply(s))
+		if err != nil {
+			return false
+		}
+		if parsed.Hostname() == "" {
+			return true
+		}
+		validated, err := h.options.ScopeManager.ValidateHost(parsed.Hostname(), rootHostname)
+		if err != nil {
+			return false
+		}
+		return validated
+	}
+}
+
 // Crawl executes the headless crawling on a given URL
 func (h *Headless) Crawl(URL string) error {
 	if h.debugger != nil {
@@ -106,24 +346,70 @@ func (h *Headless) Crawl(URL string) error {
 		}
 	}()
 
+	crawlTimeout, expired := h.options.CrawlTimeout()
+	if expired {
+		return common.ErrCrawlBudgetExhausted
+	}
+
 	scopeValidator := validateScopeFunc(h, URL)
+	var rootHostname string
+	if parsed, err := url.Parse(URL); err == nil {
+		rootHostname = parsed.Hostname()
+	}
 
 	crawlOpts := crawler.Options{
+		ControlURL:        h.options.Options.ChromeWSUrl,
 		ChromiumPath:      h.options.Options.SystemChromePath,
 		MaxDepth:          h.options.Options.MaxDepth,
 		ShowBrowser:       h.options.Options.ShowBrowser,
-		MaxCrawlDuration:  h.options.Options.CrawlDuration,
+		MaxCrawlDuration:  crawlTimeout,
+		MaxStateDuration:  h.options.Options.StateDuration,
 		MaxFailureCount:   h.options.Options.MaxFailureCount,
 		NoSandbox:         h.options.Options.HeadlessNoSandbox,
 		Proxy:             h.options.Options.Proxy,
-		MaxBrowsers:       1,
+		MaxBrowsers:       max(h.options.Options.HeadlessConcurrency, 1),
 		PageMaxTimeout:    30 * time.Second,
+		ActionTimeouts:    h.actionTimeouts,
+		Similarity:        h.similarity,
+		ReducedMotion:     h.options.Options.ReducedMotion,
+		DeterministicSeed: h.options.Options.DeterministicSeed,
+		HeaderRules:       h.options.HeaderRules,
+		HostMap:           h.options.HostMap,
+		BlockedResources:  h.options.BlockedResources,
+		FormBudget:        h.options.FormBudget,
+		RateLimit:         h.options.RateLimit,
+		Delay:             time.Duration(h.options.Options.Delay) * time.Second,
+		ResourceTypes:     h.resourceTypes,
 		ScopeValidator:    scopeValidator,
 		AutomaticFormFill: h.options.Options.AutomaticFormFill,
+		FormMarkers:       h.options.FormMarkers,
+		Debugger:          h.debugger.Crawl(URL),
 		RequestCallback: func(rr *output.Result) {
 			if rr == nil || rr.Request == nil {
 				return
 			}
+			// findings are reported regardless of the scope of the resource
+			if rr.Finding != nil {
+				if err := h.options.OutputWriter.Write(rr); err != nil {
+					h.logger.Debug("failed to write finding",
+						slog.String("url", rr.Request.URL),
+						slog.String("error", err.Error()),
+					)
+				}
+				return
+			}
+			debugserver.Requests.Add(1)
+			h.options.DomainInventory.Record(rootHostname, rr.Request.URL, rr.Request.Tag)
+			// blocked third-party requests are written although out of scope
+			if rr.Request.Tag == scope.ThirdPartyTag {
+				if err := h.options.OutputWriter.Write(rr); err != nil {
+					h.logger.Debug("failed to write blocked request",
+						slog.String("url", rr.Request.URL),
+						slog.String("error", err.Error()),
+					)
+				}
+				return
+			}
 			if scopeValidator != nil && !scopeValidator(rr.Request.URL) {
 				return
 			}
@@ -153,11 +439,81 @@ func (h *Headless) Crawl(URL string) error {
 				)
 			}
 		},
+		ErrorCallback: func(action *headlesstypes.Action, err error) {
+			endpoint := URL
+			if action.Type == headlesstypes.ActionTypeLoadURL {
+				endpoint = action.Input
+			} else if action.Element != nil && action.Element.Attributes["href"] != "" {
+				endpoint = action.Element.Attributes["href"]
+			}
+			class := output.ClassifyError(err)
+			if errors.Is(err, crawler.ErrLogoutElement) {
+				class = output.ErrorClassLogout
+			}
+			_ = h.options.OutputWriter.WriteErr(&output.Error{
+				Timestamp: time.Now(),
+				Endpoint:  endpoint,
+				Source:    URL,
+				Error:     err.Error(),
+				Class:     class,
+			})
+		},
 		Logger:              h.logger,
 		ChromeUser:          h.options.ChromeUser,
 		EnableDiagnostics:   h.options.Options.EnableDiagnostics,
 		Trace:               h.options.Options.EnableDiagnostics,
 		CookieConsentBypass: true,
+		AuthActions:         h.authActions,
+		SessionCheck:        h.sessionCheck(),
+		LoginPage:           h.loginPage,
+		LogoutPatterns:      h.logoutPatterns,
+		MinBrowsers:         h.options.Options.HeadlessMinBrowsers,
+		BrowserIdleTimeout:  h.options.Options.HeadlessBrowserIdleTimeout,
+		MaxUniqueActions:    h.options.Options.MaxUniqueActions,
+		SpillUniqueActions:  h.options.Options.SpillUniqueActions,
+		MixedContent:        h.options.Options.MixedContent,
+		SnapshotDir:         h.options.Options.DOMSnapshotDir,
+		AcceptLanguages:     h.options.Options.LanguageSweep,
+		CrossOriginFrames:   h.options.Options.CrossOriginFrames,
+		ScrollBudget:        h.options.Options.ScrollBudget,
+		ErrorPageActions:    h.options.Options.ErrorPageActions,
+		ClientCertificate:   h.clientCertificate,
+		ClickSelectors:      h.options.Options.HeadlessClickSelectors,
+		NoClickSelectors:    h.options.Options.HeadlessNoClickSelectors,
+	}
+	if h.options.Options.StorageStateDir != "" {
+		crawlOpts.StorageStatePath = crawler.StorageStatePath(h.options.Options.StorageStateDir, URL)
+	}
+	crawlOpts.SessionFile = h.options.Options.SessionFile
+	if h.options.Options.BlockThirdParty {
+		crawlOpts.HostValidator = validateHostFunc(h, URL)
+	}
+	if h.options.OnPageState != nil {
+		crawlOpts.StateCallback = func(state *headlesstypes.PageState) {
+			h.options.OnPageState(state.URL, state.Title, state.StatusCode)
+		}
+	}
+	if h.options.Options.CrawlGraphDir != "" {
+		format := graph.ExportFormat(h.options.Options.CrawlGraphFormat)
+		crawlOpts.GraphExportPath = crawler.GraphExportPath(h.options.Options.CrawlGraphDir, URL, format)
+		crawlOpts.GraphExportFormat = format
+	}
+
+	// The browser can only navigate to imported requests, so
+	// requests other than GET are skipped in headless mode.
+	var skippedSeeds int
+	for _, seed := range h.options.Seeds {
+		if !scopeValidator(seed.URL) {
+			continue
+		}
+		if seed.Method != http.MethodGet {
+			skippedSeeds++
+			continue
+		}
+		crawlOpts.SeedURLs = append(crawlOpts.SeedURLs, seed.URL)
+	}
+	if skippedSeeds > 0 {
+		gologger.Warning().Msgf("Skipped %d imported non-GET requests for %s in headless mode\n", skippedSeeds, URL)
 	}
 
 	if provider := h.options.Options.CaptchaSolverProvider; provider != "" {
@@ -170,7 +526,12 @@ func (h *Headless) Crawl(URL string) error {
 		}
 	}
 
-	// TODO: Make the crawling multi-threaded. Right now concurrency is hardcoded to 1.
+	if h.captureProxy != nil {
+		actions, unsubscribe := h.subscribeCapture(scopeValidator)
+		defer unsubscribe()
+		crawlOpts.ExternalActions = actions
+		crawlOpts.ExternalIdleTimeout = h.options.Options.CaptureIdleTimeout
+	}
 
 	headlessCrawler, err := crawler.New(crawlOpts)
 	if err != nil {
@@ -178,9 +539,63 @@ func (h *Headless) Crawl(URL string) error {
 	}
 	defer headlessCrawler.Close()
 
-	if err = headlessCrawler.Crawl(URL); err != nil {
+	err = headlessCrawler.Crawl(URL)
+
+	stats := headlessCrawler.UniqueActionStats()
+	gologger.Verbose().Msgf("Unique actions for %s: %d tracked, %d evicted, %d spilled to disk", URL, stats.Tracked, stats.Evicted, stats.Spilled)
+	pool := headlessCrawler.PoolStats()
+	gologger.Verbose().Msgf("Browser pool for %s: %d/%d peak browsers, %d opened, %d closed, %d waits for a busy browser", URL, pool.Peak, pool.Max, pool.Opened, pool.Closed, pool.Waits)
+	if timings := headlessCrawler.PhaseTimings(); len(timings) > 0 {
+		phases := make([]string, 0, len(timings))
+		for _, timing := range timings {
+			phases = append(phases, fmt.Sprintf("%s=%s (avg %s)", timing.Phase, timing.Total.Round(time.Millisecond), timing.Average().Round(time.Millisecond)))
+		}
+		gologger.Verbose().Msgf("Phase timings for %s: %s", URL, strings.Join(phases, ", "))
+	}
+	return err
+}
+
+// Replay re-executes the actions recorded in a diagnostics directory
+// printing for each action whether the recorded page state was reached.
+func (h *Headless) Replay(directory string, snapshot bool) error {
+	headlessCrawler, err := crawler.New(crawler.Options{
+		ControlURL:        h.options.Options.ChromeWSUrl,
+		ChromiumPath:      h.options.Options.SystemChromePath,
+		ShowBrowser:       h.options.Options.ShowBrowser,
+		NoSandbox:         h.options.Options.HeadlessNoSandbox,
+		Proxy:             h.options.Options.Proxy,
+		MaxBrowsers:       1,
+		PageMaxTimeout:    30 * time.Second,
+		ActionTimeouts:    h.actionTimeouts,
+		Similarity:        h.similarity,
+		ReducedMotion:     h.options.Options.ReducedMotion,
+		DeterministicSeed: h.options.Options.DeterministicSeed,
+		AutomaticFormFill: h.options.Options.AutomaticFormFill,
+		Logger:            h.logger,
+		ChromeUser:        h.options.ChromeUser,
+	})
+	if err != nil {
+		return err
+	}
+	defer headlessCrawler.Close()
+
+	var steps, reproduced int
+	err = headlessCrawler.Replay(directory, snapshot, func(step *crawler.ReplayStep) {
+		steps++
+		switch {
+		case step.Error != nil:
+			gologger.Info().Msgf("[replay] %d: %s failed: %s", step.Index, step.Action, step.Error)
+		case step.Reproduced:
+			reproduced++
+			gologger.Info().Msgf("[replay] %d: %s reached recorded state %s", step.Index, step.Action, step.StateID)
+		default:
+			gologger.Info().Msgf("[replay] %d: %s reached state %s, recorded %s", step.Index, step.Action, step.StateID, step.Action.ResultID)
+		}
+	})
+	if err != nil {
 		return err
 	}
+	gologger.Info().Msgf("Replayed %d actions, %d reached the recorded state", steps, reproduced)
 	return nil
 }
 
@@ -188,6 +603,9 @@ func (h *Headless) Close() error {
 	if h.debugger != nil {
 		h.debugger.Close()
 	}
+	if h.captureProxy != nil {
+		return h.captureProxy.Close()
+	}
 	return nil
 }
 
@@ -0,0 +1,66 @@
+package headless
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	headlesstypes "github.com/projectdiscovery/katana/pkg/engine/headless/types"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/katana/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWriter struct {
+	mu      sync.Mutex
+	results []*output.Result
+}
+
+func (m *mockWriter) Close() error { return nil }
+
+func (m *mockWriter) Write(result *output.Result) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = append(m.results, result)
+	return nil
+}
+
+func (m *mockWriter) WriteErr(*output.Error) error { return nil }
+
+func TestOnCapturedRequest(t *testing.T) {
+	writer := &mockWriter{}
+	h := &Headless{
+		logger:             slog.Default(),
+		options:            &types.CrawlerOptions{OutputWriter: writer},
+		captureSubscribers: make(map[*captureSubscriber]struct{}),
+	}
+	inScope := func(URL string) bool { return strings.HasPrefix(URL, "https://example.com") }
+
+	// two crawls of the same target must both receive captured navigations
+	first, unsubscribeFirst := h.subscribeCapture(inScope)
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := h.subscribeCapture(inScope)
+	defer unsubscribeSecond()
+
+	h.onCapturedRequest(&navigation.Request{Method: http.MethodGet, URL: "https://example.com/account"})
+	h.onCapturedRequest(&navigation.Request{Method: http.MethodGet, URL: "https://other.com/"})
+	h.onCapturedRequest(&navigation.Request{
+		Method: http.MethodPost,
+		URL:    "https://example.com/profile/update",
+		Body:   "name=katana",
+		Source: "https://example.com/profile",
+	})
+
+	for _, actions := range []<-chan *headlesstypes.Action{first, second} {
+		require.Len(t, actions, 2)
+		require.Equal(t, "https://example.com/account", (<-actions).Input)
+		action := <-actions
+		require.Equal(t, headlesstypes.ActionTypeLoadURL, action.Type)
+		require.Equal(t, "https://example.com/profile", action.Input, "form source page should be queued")
+	}
+	require.Len(t, writer.results, 1, "form submission should be written once")
+	require.Equal(t, "https://example.com/profile/update", writer.results[0].Request.URL)
+}
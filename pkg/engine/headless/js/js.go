@@ -25,3 +25,24 @@ func InitJavascriptEnv(page *rod.Page) error {
 	}
 	return nil
 }
+
+// EnsureJavascriptEnv injects the javascript code into the current
+// document of page if it is missing. Documents of cross-origin frames
+// run in another process and are not initialized by InitJavascriptEnv.
+func EnsureJavascriptEnv(page *rod.Page) error {
+	result, err := page.Eval(`() => typeof window.getAllElements === "function"`)
+	if err != nil {
+		return errors.Wrap(err, "failed to check javascript env")
+	}
+	if result.Value.Bool() {
+		return nil
+	}
+	// the bundles are self invoking so they are wrapped to be evaluated
+	if _, err := page.Eval("() => {\n" + utilsJavascriptBundle + "\n}"); err != nil {
+		return errors.Wrap(err, "failed to evaluate utils.js")
+	}
+	if _, err := page.Eval("() => {\n" + pageInitJavascriptBundle + "\n}"); err != nil {
+		return errors.Wrap(err, "failed to evaluate page-init.js")
+	}
+	return nil
+}
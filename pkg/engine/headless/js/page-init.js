@@ -11,8 +11,11 @@
 // 4. Hook form reset to prevent the form from being reset
 // 5. Hook window.close to prevent the page from being closed
 // 6. Hook history pushState and replaceState for new links
+//    Route changes are accessible via window.__historyNavigations (always recorded)
 // 7. Add event listener for hashchange to identify new navigations
-// 8. TODO: Hook inline event listeners so that layer0 event listeners can be tracked as well
+// 8. Observe the navigable elements added to the document once it was scanned
+//    These are accessible via window.__mutatedNodes (always observed)
+// 9. TODO: Hook inline event listeners so that layer0 event listeners can be tracked as well
 (function pageInitAndHook() {
     const markElementReadonlyProperties = {
       writable: false,
@@ -80,6 +83,43 @@
       Object.defineProperty(window, "fetch", { value: __wrappedFetch, writable: false, configurable: false });
     }
   
+    // hookHistoryRoutes records the route changes of the document made
+    // with the history api in window.__historyNavigations and the source
+    // of the last one in window.__historyRoute, so that routes of single
+    // page apps are distinct states. The methods are wrapped without
+    // locking them so that the navigated link sinks can hook them too.
+    function hookHistoryRoutes() {
+      window.__historyNavigations = [];
+      window.__historyRoute = "";
+      let lastLocation = document.location.href;
+      function recordRouteChange(source) {
+        const current = document.location.href;
+        if (current === lastLocation) {
+          return;
+        }
+        lastLocation = current;
+        window.__historyRoute = source;
+        window.__historyNavigations.push({ url: current, source: source });
+      }
+  
+      const __origPushState = window.history.pushState.bind(window.history);
+      const __origReplaceState = window.history.replaceState.bind(window.history);
+      window.history.pushState = function (a, b, c) {
+        const result = __origPushState(a, b, c);
+        try { recordRouteChange("pushState"); } catch (_) {}
+        return result;
+      };
+      window.history.replaceState = function (a, b, c) {
+        const result = __origReplaceState(a, b, c);
+        try { recordRouteChange("replaceState"); } catch (_) {}
+        return result;
+      };
+      // popstate is fired once the location has been updated
+      window.addEventListener("popstate", function () {
+        recordRouteChange("popstate");
+      });
+    }
+  
     // hookMiscellaneousUtilities performs miscellaneous hooks
     // on the page to prevent certain actions from happening
     // and to speed up certain actions.
@@ -163,8 +203,48 @@
       };
     }
   
+    // hookMutationObserver records the navigable elements added to the
+    // document once it was scanned for navigations, so that content
+    // appearing after delayed requests or interactions is collected
+    // without scanning the whole document again.
+    function hookMutationObserver() {
+      const selector = "a, form, div.form, button, input[type='button'], input[type='submit'], [onclick]";
+      const maxNodes = 1000;
+      window.__mutatedNodes = new Set();
+      // observing is enabled by the crawler once the document was scanned
+      window.__observingMutations = false;
+      function record(node) {
+        if (window.__mutatedNodes.size < maxNodes) {
+          window.__mutatedNodes.add(node);
+        }
+      }
+      const observer = new MutationObserver(function (mutations) {
+        if (window.__observingMutations !== true) {
+          return;
+        }
+        for (const mutation of mutations) {
+          if (mutation.type === "attributes") {
+            if (mutation.target.matches(selector)) record(mutation.target);
+            continue;
+          }
+          for (const node of mutation.addedNodes) {
+            if (node.nodeType !== Node.ELEMENT_NODE) {
+              continue;
+            }
+            if (node.matches(selector)) record(node);
+            for (const child of node.querySelectorAll(selector)) record(child);
+          }
+        }
+      });
+      observer.observe(document, { childList: true, subtree: true, attributes: true, attributeFilter: ["href"] });
+    }
+  
     // Main hook initialization part
     const __opts = window.__katanaHooksOptions || { hooked: false };
+    // route changes and mutations are recorded regardless of the
+    // hooks, before the link sinks wrap the history methods
+    try { hookHistoryRoutes(); } catch (_) {}
+    try { hookMutationObserver(); } catch (_) {}
     try { if (__opts.hooked === true) hookAddEventListener(); } catch (_) {}
     try { if (__opts.hooked === true) hookNavigatedLinkSinks(); } catch (_) {}
     try { if (__opts.hooked === true) hookMiscellaneousUtilities(); } catch (_) {}
@@ -25,15 +25,86 @@
         textContent: el.textContent.trim(),
         xpath: window.getXPath(el),
         cssSelector: window.getCssPath(el),
+        noClick: window._isNoClickElement(el),
       };
     };
+
+    // isXPathSelector returns true if a selector is an xpath expression
+    function isXPathSelector(selector) {
+      return selector.startsWith("/") || selector.startsWith("(") || selector.startsWith("./");
+    }
+
+    // querySelectorsAll returns the elements matching any of the css
+    // selectors, including in shadow roots, or xpath expressions.
+    // Invalid selectors are skipped.
+    window.querySelectorsAll = function (selectors) {
+      const nodes = new Set();
+      for (const selector of selectors) {
+        try {
+          if (isXPathSelector(selector)) {
+            const result = document.evaluate(selector, document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+            for (let i = 0; i < result.snapshotLength; i++) {
+              const node = result.snapshotItem(i);
+              if (node.nodeType === Node.ELEMENT_NODE) nodes.add(node);
+            }
+          } else {
+            for (const el of window.querySelectorAllDeep(selector)) nodes.add(el);
+          }
+        } catch (_) {}
+      }
+      return Array.from(nodes);
+    };
+
+    // getElementsBySelectors returns all the elements matching
+    // any of the css or xpath selectors on the page
+    window.getElementsBySelectors = function (selectors) {
+      return window.querySelectorsAll(selectors).map((el) => _elementDataFromElement(el));
+    };
+
+    // noClickRoots are the elements matching the never clicked selectors
+    // of window.__katanaNoClickSelectors, cached during an evaluation
+    let noClickRoots = null;
+
+    // _isNoClickElement returns true if the element or one of its
+    // ancestors, across shadow roots, is never to be clicked
+    window._isNoClickElement = function (el) {
+      const selectors = window.__katanaNoClickSelectors;
+      if (!selectors || !selectors.length) {
+        return false;
+      }
+      if (noClickRoots === null) {
+        noClickRoots = window.querySelectorsAll(selectors);
+        queueMicrotask(() => { noClickRoots = null; });
+      }
+      let node = el;
+      while (node) {
+        for (const root of noClickRoots) {
+          if (root === node || root.contains(node)) return true;
+        }
+        const rootNode = node.getRootNode();
+        node = typeof ShadowRoot !== "undefined" && rootNode instanceof ShadowRoot ? rootNode.host : null;
+      }
+      return false;
+    };
   
+    // querySelectorAllDeep returns the elements matching a query
+    // selector in the document and in all the open shadow roots
+    window.querySelectorAllDeep = function (selector, root = document) {
+      const nodes = Array.from(root.querySelectorAll(selector));
+      for (const el of root.querySelectorAll("*")) {
+        if (el.shadowRoot) {
+          nodes.push(...window.querySelectorAllDeep(selector, el.shadowRoot));
+        }
+      }
+      return nodes;
+    };
+
     // getAllElements returns all the elements for a query
-    // selector on the page
+    // selector on the page, including the ones in shadow roots
     window.getAllElements = function (selector) {
       try {
-        const nodes = document.querySelectorAll(selector);
-        return Array.from(nodes).map((el) => _elementDataFromElement(el));
+        const nodes = window.querySelectorAllDeep(selector);
+        return nodes.map((el) => _elementDataFromElement(el));
       } catch (_) {
         return [];
       }
@@ -54,7 +125,7 @@
     // on the page along with their event listeners
     // TODO: Is it optimized? or do we need to do something else?
     window.getAllElementsWithEventListeners = function () {
-      const elements = document.querySelectorAll("*");
+      const elements = window.querySelectorAllDeep("*");
       const elementsWithListeners = [];
       for (let el of elements) {
         const listeners = getEventListeners(el);
@@ -89,11 +160,17 @@
     // getAllForms returns all the forms on the page
     // along with their elements
     window.getAllForms = function () {
-      const forms = document.querySelectorAll("form");
-      const pseudoForms = document.querySelectorAll("div.form");
+      const forms = window.querySelectorAllDeep("form");
+      const pseudoForms = window.querySelectorAllDeep("div.form");
       
       const allForms = [...forms, ...pseudoForms];
-      return Array.from(allForms).map((form) => ({
+      return Array.from(allForms).map((form) => _formDataFromElement(form));
+    };
+
+    // _formDataFromElement returns the data for a form
+    // along with its elements
+    window._formDataFromElement = function (form) {
+      return {
         tagName: form.tagName,
         id: form.id,
         classes: typeof form.className === 'string' ? form.className : Array.from(form.classList).join(' '),
@@ -103,12 +180,148 @@
         method: form.method,
         xpath: window.getXPath(form),
         cssSelector: window.getCssPath(form),
+        noClick: window._isNoClickElement(form),
         elements: form.elements ? 
           Array.from(form.elements).map((el) => _elementDataFromElement(el)) :
           Array.from(form.querySelectorAll('input, select, textarea, button')).map((el) => _elementDataFromElement(el))
-      }));
+      };
+    };
+
+    // getMutatedElements returns the navigable elements added to the
+    // document since they were last taken which are still attached,
+    // grouped like the elements of a full scan of the document
+    window.getMutatedElements = function () {
+      const elements = { forms: [], buttons: [], links: [], listeners: [] };
+      if (!window.__mutatedNodes) {
+        return elements;
+      }
+      const nodes = Array.from(window.__mutatedNodes);
+      window.__mutatedNodes.clear();
+      for (const el of nodes) {
+        if (!el.isConnected) {
+          continue;
+        }
+        try {
+          if (el.matches("form, div.form")) {
+            elements.forms.push(_formDataFromElement(el));
+          } else if (el.matches("a")) {
+            elements.links.push(_elementDataFromElement(el));
+          } else if (el.matches("button, input[type='button'], input[type='submit']")) {
+            elements.buttons.push(_elementDataFromElement(el));
+          }
+          const listeners = getEventListeners(el);
+          if (listeners.length) {
+            elements.listeners.push({ element: _elementDataFromElement(el), listeners: listeners });
+          }
+        } catch (_) {}
+      }
+      return elements;
     };
   
+    // getRouterRoutes returns the paths declared in the route tables
+    // of the client-side routers (react router, vue router, angular
+    // router) found on the page, including routes no link points to.
+    window.getRouterRoutes = function () {
+      const routes = [];
+      const seen = new Set();
+      const maxNodes = 5000;
+
+      const addRoutes = (framework, table, prefix) => {
+        if (!Array.isArray(table)) return;
+        for (const route of table) {
+          if (!route || typeof route !== "object") continue;
+          let path = typeof route.path === "string" ? route.path : "";
+          if (path && !path.startsWith("/")) {
+            path = (prefix.endsWith("/") ? prefix : prefix + "/") + path;
+          } else if (!path) {
+            path = prefix;
+          }
+          const key = framework + " " + path;
+          if (path && !seen.has(key)) {
+            seen.add(key);
+            routes.push({ framework: framework, path: path });
+          }
+          addRoutes(framework, route.children, path || prefix);
+        }
+      };
+
+      // Vue 3 exposes the app on its mount element, Vue 2 on every component element
+      try {
+        for (const el of document.querySelectorAll("[data-v-app]")) {
+          const router = el.__vue_app__ && el.__vue_app__.config.globalProperties.$router;
+          if (router && typeof router.getRoutes === "function") {
+            addRoutes("vue", router.getRoutes().map((r) => ({ path: r.path })), "");
+          }
+        }
+        const all = document.querySelectorAll("*");
+        for (let i = 0; i < all.length && i < maxNodes; i++) {
+          const vm = all[i].__vue__;
+          if (vm && vm.$router && vm.$router.options) {
+            addRoutes("vue", vm.$router.options.routes, "");
+            break;
+          }
+        }
+      } catch (_) {}
+
+      // React Router data routers and framework mode manifests
+      try {
+        const dataRouter = window.__reactRouterDataRouter || window.__router;
+        if (dataRouter && dataRouter.routes) {
+          addRoutes("react", dataRouter.routes, "");
+        }
+        const manifest = window.__reactRouterManifest || window.__remixManifest;
+        if (manifest && manifest.routes) {
+          addRoutes("react", Object.values(manifest.routes).filter((r) => r.path), "");
+        }
+      } catch (_) {}
+
+      // React Router declared with <RouterProvider> or useRoutes
+      try {
+        const containers = [document.getElementById("root"), document.getElementById("app"), document.body];
+        for (const container of containers) {
+          if (!container) continue;
+          const key = Object.keys(container).find((k) => k.startsWith("__reactContainer$") || k === "_reactRootContainer");
+          if (!key) continue;
+          let root = container[key];
+          if (root && root._internalRoot) root = root._internalRoot.current;
+          if (root && root.current) root = root.current;
+          const stack = [root];
+          let visited = 0;
+          while (stack.length && visited < maxNodes) {
+            const fiber = stack.pop();
+            if (!fiber) continue;
+            visited++;
+            const props = fiber.memoizedProps;
+            if (props && props.router && Array.isArray(props.router.routes)) {
+              addRoutes("react", props.router.routes, "");
+            } else if (props && Array.isArray(props.routes)) {
+              addRoutes("react", props.routes, "");
+            }
+            stack.push(fiber.sibling, fiber.child);
+          }
+          break;
+        }
+      } catch (_) {}
+
+      // Angular exposes components through the ng debugging api
+      try {
+        if (window.ng && typeof window.ng.getComponent === "function") {
+          const hosts = document.querySelectorAll("*");
+          for (let i = 0; i < hosts.length && i < maxNodes; i++) {
+            const component = window.ng.getComponent(hosts[i]);
+            if (!component) continue;
+            for (const field of Object.values(component)) {
+              if (field && Array.isArray(field.config) && typeof field.navigateByUrl === "function") {
+                addRoutes("angular", field.config, "");
+              }
+            }
+          }
+        }
+      } catch (_) {}
+
+      return routes;
+    };
+
     // Copyright (C) Chrome Authors
     // The below code is part of the Chrome DevTools project
     // and is adapted from there.
@@ -132,12 +345,22 @@
       }
   
       steps.reverse();
-      return steps.join(" > ");
+      const path = steps.join(" > ");
+      // Elements in shadow roots are prefixed with the path of their
+      // host separated by " >>> " to pierce the shadow root.
+      const root = node.getRootNode();
+      if (typeof ShadowRoot !== "undefined" && root instanceof ShadowRoot) {
+        return window.getCssPath(root.host, optimized) + " >>> " + path;
+      }
+      return path;
     };
   
     // Utility to get the XPath for an element.
     window.getXPath = function (node, optimized = false) {
       if (node.nodeType === Node.DOCUMENT_NODE) return "/";
+      // XPath does not reach into shadow roots, their elements are
+      // located by their css path instead.
+      if (typeof ShadowRoot !== "undefined" && node.getRootNode() instanceof ShadowRoot) return "";
   
       const steps = [];
       let contextNode = node;
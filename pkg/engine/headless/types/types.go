@@ -26,11 +26,22 @@ type PageState struct {
 	StrippedDOM string `json:"stripped_dom,omitempty"`
 	Depth       int    `json:"depth,omitempty"`
 	IsRoot      bool   `json:"is_root,omitempty"`
+	// StatusCode is the response status of the document of the state
+	StatusCode int `json:"status_code,omitempty"`
+	// Route is the history api navigation the url of the state was
+	// reached with (pushState, replaceState, popstate), if any
+	Route string `json:"route,omitempty"`
 
 	// NavigationAction is actions taken to reach this state
 	NavigationAction *Action `json:"navigation_actions,omitempty"`
 }
 
+// IsErrorPage returns true if the document of the state
+// was served with a client or server error status
+func (p *PageState) IsErrorPage() bool {
+	return p.StatusCode >= 400
+}
+
 // Action is a action taken in the browser
 type Action struct {
 	OriginID string       `json:"origin_id,omitempty"`
@@ -40,24 +51,43 @@ type Action struct {
 	Form     *HTMLForm    `json:"form,omitempty"`
 	Depth    int          `json:"depth,omitempty"`
 	ResultID string       `json:"result_id,omitempty"`
+	// Language is the Accept-Language a url is loaded with
+	// when sweeping the locales of a page
+	Language string `json:"language,omitempty"`
 }
 
 func (a *Action) Hash() string {
 	if a.Element != nil {
+		// hovering, double clicking or right clicking an element
+		// reveals content clicking it may not, so all of them are kept
+		// for elements with several listeners
+		switch a.Type {
+		case ActionTypeHover, ActionTypeDoubleClick, ActionTypeRightClick:
+			return string(a.Type) + "|" + a.Element.Hash()
+		case ActionTypePressKey:
+			return string(a.Type) + "|" + a.Input + "|" + a.Element.Hash()
+		}
 		return a.Element.Hash()
 	}
 	if a.Form != nil {
 		return a.Form.Hash()
 	}
-	return string(a.Type) + "|" + a.Input + "|" + a.OriginID
+	hash := string(a.Type) + "|" + a.Input + "|" + a.OriginID
+	if a.Language != "" {
+		hash += "|" + a.Language
+	}
+	return hash
 }
 
 func (a *Action) String() string {
 	var builder strings.Builder
 	builder.WriteString(string(a.Type))
-	if a.Type == ActionTypeLoadURL {
+	if a.Type == ActionTypeLoadURL || a.Type == ActionTypePressKey {
 		fmt.Fprintf(&builder, " %s", a.Input)
 	}
+	if a.Language != "" {
+		fmt.Fprintf(&builder, " [%s]", a.Language)
+	}
 	if a.Element != nil {
 		fmt.Fprintf(&builder, " on %s", a.Element)
 	}
@@ -80,6 +110,7 @@ const (
 	ActionTypeSendKeys        ActionType = "send_keys"
 	ActionTypeKeyUp           ActionType = "key_up"
 	ActionTypeKeyDown         ActionType = "key_down"
+	ActionTypePressKey        ActionType = "press_key"
 	ActionTypeHover           ActionType = "hover"
 	ActionTypeFocus           ActionType = "focus"
 	ActionTypeBlur            ActionType = "blur"
@@ -101,10 +132,12 @@ func ActionFromEventListener(listener *EventListener) *Action {
 		actionType = ActionTypeLeftClickDown
 	case "mouseup":
 		actionType = ActionTypeLeftClickUp
-	case "keydown":
-		actionType = ActionTypeKeyDown
-	case "keyup":
-		actionType = ActionTypeKeyUp
+	case "keydown", "keyup", "keypress":
+		return &Action{
+			Type:    ActionTypePressKey,
+			Input:   KeyEnter,
+			Element: listener.Element,
+		}
 	case "focus":
 		actionType = ActionTypeFocus
 	case "blur":
@@ -115,7 +148,9 @@ func ActionFromEventListener(listener *EventListener) *Action {
 		actionType = ActionTypeDoubleClick
 	case "contextmenu":
 		actionType = ActionTypeRightClick
-	case "mouseover", "mouseout":
+	case "mouseover", "mouseenter":
+		actionType = ActionTypeHover
+	case "mouseout", "mouseleave":
 		actionType = ActionTypeMouseOverAndOut
 	case "wheel":
 		actionType = ActionTypeMouseWheel
@@ -129,6 +164,63 @@ func ActionFromEventListener(listener *EventListener) *Action {
 	}
 }
 
+// Keys pressed on elements with keyboard listeners
+const (
+	KeyEnter      = "Enter"
+	KeyEscape     = "Escape"
+	KeyTab        = "Tab"
+	KeyArrowDown  = "ArrowDown"
+	KeyArrowUp    = "ArrowUp"
+	KeyArrowLeft  = "ArrowLeft"
+	KeyArrowRight = "ArrowRight"
+)
+
+// ActionsFromEventListener returns the actions triggering the listener.
+// Keyboard listeners are triggered by pressing each of the keys the
+// widget of their element reacts to.
+func ActionsFromEventListener(listener *EventListener) []*Action {
+	action := ActionFromEventListener(listener)
+	if action.Type != ActionTypePressKey {
+		return []*Action{action}
+	}
+	keys := widgetKeys(listener.Element)
+	actions := make([]*Action, 0, len(keys))
+	for _, key := range keys {
+		actions = append(actions, &Action{
+			Type:    ActionTypePressKey,
+			Input:   key,
+			Element: listener.Element,
+		})
+	}
+	return actions
+}
+
+// widgetKeys returns the keys keyboard-navigable widgets react to
+func widgetKeys(element *HTMLElement) []string {
+	if element == nil {
+		return []string{KeyEnter}
+	}
+	attribute := func(name string) string {
+		return strings.ToLower(strings.TrimSpace(element.Attributes[name]))
+	}
+	role := attribute("role")
+	tagName := strings.ToUpper(element.TagName)
+	switch {
+	// comboboxes and search-as-you-type inputs open their suggestions
+	case role == "combobox" || role == "searchbox" || attribute("aria-autocomplete") != "" ||
+		(tagName == "INPUT" && (attribute("list") != "" || strings.EqualFold(element.Type, "search"))):
+		return []string{KeyArrowDown, KeyEnter}
+	// modal dialogs are closed or have their focus moved
+	case role == "dialog" || role == "alertdialog" || attribute("aria-modal") == "true" || tagName == "DIALOG":
+		return []string{KeyEscape, KeyTab}
+	// composite widgets move their selection with the arrow keys
+	case role == "listbox" || role == "menu" || role == "menubar" || role == "tablist" ||
+		role == "tree" || role == "grid" || role == "radiogroup":
+		return []string{KeyArrowDown, KeyArrowRight, KeyEnter}
+	}
+	return []string{KeyEnter}
+}
+
 // HTMLElement represents a DOM element
 type HTMLElement struct {
 	TagName     string            `json:"tagName,omitempty"`
@@ -143,6 +235,12 @@ type HTMLElement struct {
 	XPath       string            `json:"xpath,omitempty"`
 	TextContent string            `json:"textContent,omitempty"`
 	MD5Hash     string            `json:"md5Hash,omitempty"`
+	// FramePath are the xpaths of the frames the element is in,
+	// starting from the top document
+	FramePath []string `json:"framePath,omitempty"`
+	// NoClick is true if the element matches one of
+	// the selectors of the elements never to click
+	NoClick bool `json:"noClick,omitempty"`
 }
 
 func (e *HTMLElement) String() string {
@@ -181,6 +279,9 @@ func (e *HTMLElement) Hash() string {
 	for _, k := range stableAttrs {
 		parts = append(parts, fmt.Sprintf("%s:%s", k, e.Attributes[k]))
 	}
+	if len(e.FramePath) > 0 {
+		parts = append(parts, "frame:"+strings.Join(e.FramePath, ">"))
+	}
 
 	hashInput := strings.Join(parts, "|")
 	if IsDiagnosticEnabled {
@@ -203,6 +304,12 @@ type HTMLForm struct {
 	Elements    []*HTMLElement    `json:"elements,omitempty"`
 	CSSSelector string            `json:"cssSelector,omitempty"`
 	XPath       string            `json:"xpath,omitempty"`
+	// FramePath are the xpaths of the frames the form is in,
+	// starting from the top document
+	FramePath []string `json:"framePath,omitempty"`
+	// NoClick is true if the form matches one of
+	// the selectors of the elements never to click
+	NoClick bool `json:"noClick,omitempty"`
 }
 
 func (f *HTMLForm) Hash() string {
@@ -224,6 +331,9 @@ func (f *HTMLForm) Hash() string {
 		parts = append(parts, fmt.Sprintf("%s:%s", k, f.Attributes[k]))
 	}
 	parts = append(parts, fmt.Sprintf("action:%s", f.Action), fmt.Sprintf("method:%s", f.Method))
+	if len(f.FramePath) > 0 {
+		parts = append(parts, "frame:"+strings.Join(f.FramePath, ">"))
+	}
 
 	// Include hashes of form elements
 	for _, element := range f.Elements {
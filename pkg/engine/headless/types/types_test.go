@@ -0,0 +1,69 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionFromEventListenerHover(t *testing.T) {
+	menu := &HTMLElement{TagName: "LI", Classes: "dropdown"}
+
+	hover := ActionFromEventListener(&EventListener{Type: "mouseenter", Element: menu})
+	require.Equal(t, ActionTypeHover, hover.Type)
+	require.Equal(t, ActionTypeHover, ActionFromEventListener(&EventListener{Type: "mouseover", Element: menu}).Type)
+	require.Equal(t, ActionTypeMouseOverAndOut, ActionFromEventListener(&EventListener{Type: "mouseleave", Element: menu}).Type)
+
+	click := ActionFromEventListener(&EventListener{Type: "click", Element: menu})
+	require.Equal(t, menu.Hash(), click.Hash())
+	require.NotEqual(t, click.Hash(), hover.Hash(), "hovering and clicking an element should not be deduplicated")
+}
+
+func TestActionFromEventListenerClicks(t *testing.T) {
+	row := &HTMLElement{TagName: "TR", Attributes: map[string]string{"data-id": "42"}}
+
+	click := ActionFromEventListener(&EventListener{Type: "click", Element: row})
+	double := ActionFromEventListener(&EventListener{Type: "dblclick", Element: row})
+	context := ActionFromEventListener(&EventListener{Type: "contextmenu", Element: row})
+	require.Equal(t, ActionTypeDoubleClick, double.Type)
+	require.Equal(t, ActionTypeRightClick, context.Type)
+
+	hashes := map[string]struct{}{click.Hash(): {}, double.Hash(): {}, context.Hash(): {}}
+	require.Len(t, hashes, 3, "the click kinds of an element should not be deduplicated")
+}
+
+func TestPageStateIsErrorPage(t *testing.T) {
+	require.False(t, (&PageState{}).IsErrorPage(), "states without a recorded status should not be error pages")
+	require.False(t, (&PageState{StatusCode: 200}).IsErrorPage())
+	require.False(t, (&PageState{StatusCode: 302}).IsErrorPage())
+	for _, status := range []int{401, 403, 404, 500} {
+		require.True(t, (&PageState{StatusCode: status}).IsErrorPage())
+	}
+}
+
+func TestActionsFromEventListenerKeyboard(t *testing.T) {
+	keys := func(element *HTMLElement) []string {
+		var pressed []string
+		for _, action := range ActionsFromEventListener(&EventListener{Type: "keydown", Element: element}) {
+			require.Equal(t, ActionTypePressKey, action.Type)
+			require.Equal(t, element, action.Element)
+			pressed = append(pressed, action.Input)
+		}
+		return pressed
+	}
+	require.Equal(t, []string{KeyArrowDown, KeyEnter}, keys(&HTMLElement{TagName: "INPUT", Type: "search"}))
+	require.Equal(t, []string{KeyArrowDown, KeyEnter}, keys(&HTMLElement{TagName: "INPUT", Attributes: map[string]string{"role": "combobox"}}))
+	require.Equal(t, []string{KeyEscape, KeyTab}, keys(&HTMLElement{TagName: "DIV", Attributes: map[string]string{"role": "dialog", "aria-modal": "true"}}))
+	require.Equal(t, []string{KeyArrowDown, KeyArrowRight, KeyEnter}, keys(&HTMLElement{TagName: "UL", Attributes: map[string]string{"role": "menu"}}))
+	require.Equal(t, []string{KeyEnter}, keys(&HTMLElement{TagName: "DIV", Attributes: map[string]string{"tabindex": "0"}}))
+
+	click := ActionsFromEventListener(&EventListener{Type: "click", Element: &HTMLElement{TagName: "BUTTON"}})
+	require.Len(t, click, 1)
+	require.Equal(t, ActionTypeLeftClick, click[0].Type)
+
+	input := &HTMLElement{TagName: "INPUT", Type: "search"}
+	pressed := ActionsFromEventListener(&EventListener{Type: "keyup", Element: input})
+	require.NotEqual(t, pressed[0].Hash(), pressed[1].Hash(), "keys pressed on an element should not be deduplicated")
+	require.NotEqual(t, (&Action{Type: ActionTypeLeftClick, Element: input}).Hash(), pressed[1].Hash())
+	require.Equal(t, "press_key Enter on INPUT", pressed[1].String())
+}
@@ -18,6 +18,8 @@ import (
 	"github.com/projectdiscovery/katana/pkg/engine/common"
 	"github.com/projectdiscovery/katana/pkg/navigation"
 	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/katana/pkg/utils/fuzzlite"
+	"github.com/projectdiscovery/katana/pkg/utils/scope"
 	"github.com/projectdiscovery/retryablehttp-go"
 	"github.com/projectdiscovery/utils/errkit"
 	mapsutil "github.com/projectdiscovery/utils/maps"
@@ -31,6 +33,8 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 	response := &navigation.Response{
 		Depth:        depth,
 		RootHostname: s.Hostname,
+		KeepFragment: c.Options.Options.KeepFragments,
+		SourceChain:  request.SourceChain,
 	}
 
 	page, err := s.Browser.Page(proto.TargetCreateTarget{})
@@ -49,13 +53,69 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 		URLPattern:   "*",
 		RequestStage: proto.FetchRequestStageResponse,
 	})
+	// requests are only paused before being sent to add the headers of
+	// header rules, to rewrite the requests to mapped hosts or to block
+	// the requests to third-party hosts
+	headerRules := c.Options.HeaderRules
+	hostMap := c.Options.HostMap
+	blockThirdParty := c.Options.Options.BlockThirdParty
+	pauseRequests := len(headerRules) > 0 || len(hostMap) > 0 || blockThirdParty
+	if pauseRequests {
+		pageRouter.AddPattern(&proto.FetchRequestPattern{
+			URLPattern:   "*",
+			RequestStage: proto.FetchRequestStageRequest,
+		})
+	}
+	// blocked resources are paused before being sent to be failed
+	blockedResources := c.Options.BlockedResources
+	if !pauseRequests {
+		for _, resourceType := range blockedResources.List() {
+			pageRouter.AddPattern(&proto.FetchRequestPattern{
+				URLPattern:   "*",
+				ResourceType: proto.NetworkResourceType(resourceType),
+				RequestStage: proto.FetchRequestStageRequest,
+			})
+		}
+	}
 
 	xhrRequests := []navigation.Request{}
 	go pageRouter.Start(func(e *proto.FetchRequestPaused) error {
+		// blocked third-party requests are written although out of scope
+		if blockThirdParty && e.ResponseStatusCode == nil && e.ResponseErrorReason == "" &&
+			!c.ValidateHost(hostMap.Apply(e.Request.URL), s.Hostname) {
+			c.Options.DomainInventory.Record(s.Hostname, e.Request.URL, string(e.ResourceType))
+			c.Output(&navigation.Request{
+				Method: e.Request.Method,
+				URL:    e.Request.URL,
+				Source: request.URL,
+				Tag:    scope.ThirdPartyTag,
+			}, nil, scope.ErrThirdPartyBlocked)
+			return proto.FetchFailRequest{
+				RequestID:   e.RequestID,
+				ErrorReason: proto.NetworkErrorReasonBlockedByClient,
+			}.Call(page)
+		}
+		if e.ResponseStatusCode == nil && e.ResponseErrorReason == "" && blockedResources.Blocks(string(e.ResourceType)) {
+			return proto.FetchFailRequest{
+				RequestID:   e.RequestID,
+				ErrorReason: proto.NetworkErrorReasonBlockedByClient,
+			}.Call(page)
+		}
+		if pauseRequests && e.ResponseStatusCode == nil && e.ResponseErrorReason == "" {
+			if rewritten, ok := hostMap.Rewrite(e.Request.URL); ok {
+				return FetchContinueRequestWithURL(page, e, rewritten, headerRules.HeadersString(rewritten))
+			}
+			return FetchContinueRequestWithHeaders(page, e, headerRules.HeadersString(e.Request.URL))
+		}
 		URL, err := urlutil.Parse(e.Request.URL)
 		if err != nil {
 			return errkit.Wrap(err, "hybrid: could not parse URL")
 		}
+		c.Options.DomainInventory.Record(s.Hostname, e.Request.URL, string(e.ResourceType))
+		// subresources of the page count against the shared rate limit
+		if e.ResourceType != proto.NetworkResourceTypeDocument {
+			c.Options.RateLimit.Take(URL.Hostname())
+		}
 		body, _ := FetchGetResponseBody(page, e)
 		headers := make(map[string][]string)
 		for _, h := range e.ResponseHeaders {
@@ -128,6 +188,8 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 			Raw:           string(rawBytesResponse),
 			ContentLength: httpresp.ContentLength,
 			KnowledgeBase: c.Options.ClassifyPage(string(body)),
+			KeepFragment:  c.Options.Options.KeepFragments,
+			SourceChain:   request.SourceChain,
 		}
 		response.ContentLength = resp.ContentLength
 
@@ -324,6 +386,7 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 		if c.Options.Options.FormExtraction {
 			response.Forms = append(response.Forms, utils.ParseFormFields(response.Reader)...)
 		}
+		response.Relations = utils.ParseLinkRelations(response)
 	}
 
 	response.Reader, err = goquery.NewDocumentFromReader(strings.NewReader(response.Body))
@@ -332,6 +395,7 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 	}
 
 	response.XhrRequests = xhrRequests
+	c.enqueueXhrPermutations(s, depth, xhrRequests)
 
 	// enqueue JS-triggered navigation URLs that were detected
 	navigatedURLs.Each(func(i int, navURL string) error {
@@ -342,6 +406,7 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 					URL:          parsed.String(),
 					Depth:        depth,
 					RootHostname: s.Hostname,
+					SourceChain:  append(slices.Clip(request.SourceChain), navigation.SourceLink{URL: request.URL}),
 				}
 				c.Enqueue(s.Queue, navReq)
 				gologger.Debug().Msgf("enqueued JS navigation: %s", navURL)
@@ -353,6 +418,31 @@ func (c *Crawler) navigateRequest(s *common.CrawlSession, request *navigation.Re
 	return response, nil
 }
 
+// enqueueXhrPermutations enqueues the parameter permutations
+// of the xhr requests captured by the browser
+func (c *Crawler) enqueueXhrPermutations(s *common.CrawlSession, depth int, xhrRequests []navigation.Request) {
+	if s.XhrFuzzer == nil {
+		return
+	}
+	for _, xhrRequest := range xhrRequests {
+		s.XhrFuzzer.Observe(xhrRequest.Method, xhrRequest.URL)
+	}
+	for _, xhrRequest := range xhrRequests {
+		for _, permutation := range s.XhrFuzzer.Permutations(xhrRequest.Method, xhrRequest.URL) {
+			c.Enqueue(s.Queue, &navigation.Request{
+				Method:       http.MethodGet,
+				URL:          permutation.URL,
+				Depth:        depth,
+				Headers:      xhrRequest.Headers,
+				Tag:          fuzzlite.Tag,
+				Attribute:    permutation.Description,
+				RootHostname: s.Hostname,
+				Source:       xhrRequest.URL,
+			})
+		}
+	}
+}
+
 func (c *Crawler) addHeadersToPage(page *rod.Page) {
 	if len(c.Headers) == 0 {
 		return
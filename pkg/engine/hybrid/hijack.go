@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
+	"github.com/projectdiscovery/katana/pkg/utils/headerrules"
 )
 
 // NewHijack create hijack from page.
@@ -33,6 +34,15 @@ func (h *Hijack) SetPattern(pattern *proto.FetchRequestPattern) {
 	}
 }
 
+// AddPattern adds a pattern to the patterns set
+func (h *Hijack) AddPattern(pattern *proto.FetchRequestPattern) {
+	if h.enable == nil {
+		h.SetPattern(pattern)
+		return
+	}
+	h.enable.Patterns = append(h.enable.Patterns, pattern)
+}
+
 // Start hijack.
 func (h *Hijack) Start(handler HijackHandler) func() error {
 	if h.enable == nil {
@@ -95,3 +105,41 @@ func FetchContinueRequest(page *rod.Page, e *proto.FetchRequestPaused) error {
 	}
 	return m.Call(page)
 }
+
+// FetchContinueRequestWithHeaders continue request adding the headers
+func FetchContinueRequestWithHeaders(page *rod.Page, e *proto.FetchRequestPaused, headers map[string]string) error {
+	if len(headers) == 0 {
+		return FetchContinueRequest(page, e)
+	}
+	m := proto.FetchContinueRequest{
+		RequestID: e.RequestID,
+		Headers:   fetchHeaderEntries(e, headers),
+	}
+	return m.Call(page)
+}
+
+// FetchContinueRequestWithURL continue request sending it to the url
+// without the page observing it, adding the headers
+func FetchContinueRequestWithURL(page *rod.Page, e *proto.FetchRequestPaused, rawURL string, headers map[string]string) error {
+	m := proto.FetchContinueRequest{
+		RequestID: e.RequestID,
+		URL:       rawURL,
+	}
+	if len(headers) > 0 {
+		m.Headers = fetchHeaderEntries(e, headers)
+	}
+	return m.Call(page)
+}
+
+// fetchHeaderEntries returns the headers of the request with the headers added
+func fetchHeaderEntries(e *proto.FetchRequestPaused, headers map[string]string) []*proto.FetchHeaderEntry {
+	existing := make(map[string]string, len(e.Request.Headers))
+	for name, value := range e.Request.Headers {
+		existing[name] = value.Str()
+	}
+	var entries []*proto.FetchHeaderEntry
+	for name, value := range headerrules.Apply(existing, headers) {
+		entries = append(entries, &proto.FetchHeaderEntry{Name: name, Value: value})
+	}
+	return entries
+}
@@ -10,10 +10,13 @@ import (
 	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/katana/pkg/engine/common"
+	"github.com/projectdiscovery/katana/pkg/engine/standard"
 	"github.com/projectdiscovery/katana/pkg/navigation"
 	"github.com/projectdiscovery/katana/pkg/output"
 	"github.com/projectdiscovery/katana/pkg/types"
 	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/katana/pkg/utils/debugserver"
+	"github.com/projectdiscovery/katana/pkg/utils/fuzzlite"
 	"github.com/projectdiscovery/utils/errkit"
 	urlutil "github.com/projectdiscovery/utils/url"
 )
@@ -23,6 +26,8 @@ type Crawler struct {
 	*common.Shared
 
 	browser *rod.Browser
+	// standard executes xhr parameter permutations over http
+	standard *standard.Crawler
 	// TODO: Remove the Chrome PID kill code in favor of using Leakless(true).
 	// This change will be made if there are no complaints about zombie Chrome processes.
 	// References:
@@ -89,8 +94,9 @@ func New(options *types.CrawlerOptions) (*Crawler, error) {
 	}
 
 	crawler := &Crawler{
-		Shared:  shared,
-		browser: browser,
+		Shared:   shared,
+		browser:  browser,
+		standard: &standard.Crawler{Shared: shared},
 		// previousPIDs: previousPIDs,
 		tempDir: dataStore,
 	}
@@ -116,6 +122,9 @@ func (c *Crawler) Crawl(rootURL string) error {
 		return errkit.Wrap(err, "hybrid")
 	}
 	crawlSession.Browser = c.browser
+	if c.Options.Options.XhrFuzz {
+		crawlSession.XhrFuzzer = fuzzlite.New(fuzzlite.Options{MaxTotal: c.Options.Options.XhrFuzzLimit})
+	}
 
 	defer crawlSession.CancelFunc()
 
@@ -165,15 +174,30 @@ func (c *Crawler) Do(crawlSession *common.CrawlSession, doRequest common.DoReque
 			continue
 		}
 
-		c.Options.RateLimit.Take()
+		if !c.RobotsAllowed(crawlSession, req) {
+			continue
+		}
+
+		c.Options.RateLimit.TakeURL(req.URL)
 
 		if c.Options.Options.Delay > 0 {
 			time.Sleep(time.Duration(c.Options.Options.Delay) * time.Second)
 		}
 
-		resp, err := doRequest(crawlSession, req)
+		// parameter permutations are requested over http
+		requestFunc := doRequest
+		if req.Tag == fuzzlite.Tag {
+			requestFunc = c.standard.MakeRequest
+		}
+		debugserver.Requests.Add(1)
+		resp, err := requestFunc(crawlSession, req)
 
 		if inScope {
+			if err == nil {
+				c.DiscoverMethods(crawlSession, req, resp)
+				c.ProbeCORS(crawlSession, req, resp)
+				c.MarkSoft404(req, resp)
+			}
 			c.Output(req, resp, err)
 		}
 
@@ -184,6 +208,7 @@ func (c *Crawler) Do(crawlSession *common.CrawlSession, doRequest common.DoReque
 				Endpoint:  req.RequestURL(),
 				Source:    req.Source,
 				Error:     err.Error(),
+				Class:     output.ClassifyError(err),
 			}
 			_ = c.Options.OutputWriter.WriteErr(outputError)
 			continue
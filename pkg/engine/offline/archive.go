@@ -0,0 +1,190 @@
+package offline
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// storedIndexFile is the index of a store-response directory
+const storedIndexFile = "index.txt"
+
+// Entry is an archived request and its response
+type Entry struct {
+	Method string
+	URL    string
+	// RawRequest is the raw archived request, if available
+	RawRequest string
+	Response   *http.Response
+	Body       []byte
+}
+
+// Load loads the entries of a HAR file or a store-response directory
+func Load(path string) ([]*Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errkit.Wrap(err, "offline: could not read archive")
+	}
+	if info.IsDir() {
+		return loadStoredResponses(path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errkit.Wrap(err, "offline: could not read archive")
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return parseHAR(file)
+}
+
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+	} `json:"request"`
+	Response struct {
+		Status     int            `json:"status"`
+		StatusText string         `json:"statusText"`
+		Headers    []harNameValue `json:"headers"`
+		Content    struct {
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// parseHAR parses the entries with responses of a HTTP Archive (HAR) file
+func parseHAR(reader io.Reader) ([]*Entry, error) {
+	var har harFile
+	if err := json.NewDecoder(reader).Decode(&har); err != nil {
+		return nil, errkit.Wrap(err, "offline: could not decode har file")
+	}
+
+	entries := make([]*Entry, 0, len(har.Log.Entries))
+	for _, harEntry := range har.Log.Entries {
+		// entries without a status were never answered (eg. blocked requests)
+		if harEntry.Request.URL == "" || harEntry.Response.Status <= 0 {
+			continue
+		}
+		body := []byte(harEntry.Response.Content.Text)
+		if harEntry.Response.Content.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(harEntry.Response.Content.Text)
+			if err != nil {
+				continue
+			}
+			body = decoded
+		}
+		header := make(http.Header)
+		for _, h := range harEntry.Response.Headers {
+			// HTTP/2 pseudo headers and the encoding of the original
+			// transfer do not apply to the decoded content
+			name := http.CanonicalHeaderKey(h.Name)
+			if name == "" || name[0] == ':' || name == "Content-Encoding" || name == "Content-Length" || name == "Transfer-Encoding" {
+				continue
+			}
+			header.Add(name, h.Value)
+		}
+		status := strconv.Itoa(harEntry.Response.Status)
+		if harEntry.Response.StatusText != "" {
+			status += " " + harEntry.Response.StatusText
+		}
+		method := harEntry.Request.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		entries = append(entries, &Entry{
+			Method: method,
+			URL:    harEntry.Request.URL,
+			Response: &http.Response{
+				Status:     status,
+				StatusCode: harEntry.Response.Status,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     header,
+			},
+			Body: body,
+		})
+	}
+	return entries, nil
+}
+
+// loadStoredResponses loads the responses of a store-response directory
+func loadStoredResponses(directory string) ([]*Entry, error) {
+	var entries []*Entry
+	err := filepath.WalkDir(directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".txt" || filepath.Base(path) == storedIndexFile {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		entry, err := parseStoredResponse(data)
+		if err != nil {
+			return errkit.Wrap(err, "offline: could not parse stored response "+path)
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, errkit.Wrap(err, "offline: could not load stored responses")
+	}
+	return entries, nil
+}
+
+// parseStoredResponse parses a stored response file, it holds the url,
+// the raw request and the raw response separated by blank lines.
+func parseStoredResponse(data []byte) (*Entry, error) {
+	URL, rest, ok := bytes.Cut(data, []byte("\n\n\n"))
+	if !ok || len(URL) == 0 {
+		return nil, errkit.New("missing url")
+	}
+	index := bytes.Index(rest, []byte("\n\nHTTP/"))
+	if index < 0 {
+		return nil, errkit.New("missing response")
+	}
+	rawRequest, rawResponse := rest[:index], rest[index+2:]
+
+	entry := &Entry{Method: http.MethodGet, URL: strings.TrimSpace(string(URL)), RawRequest: string(rawRequest)}
+	if method, _, ok := strings.Cut(entry.RawRequest, " "); ok && method != "" && !strings.ContainsAny(method, "\r\n") {
+		entry.Method = method
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(rawResponse)), nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil && len(body) == 0 {
+		return nil, err
+	}
+	entry.Response = resp
+	entry.Body = body
+	return entry, nil
+}
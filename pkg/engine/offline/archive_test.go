@@ -0,0 +1,53 @@
+package offline
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHAR(t *testing.T) {
+	content := `{"log":{"entries":[
+		{"request":{"method":"GET","url":"https://example.com/"},"response":{"status":200,"statusText":"OK","headers":[{"name":":status","value":"200"},{"name":"content-type","value":"text/html"},{"name":"Content-Encoding","value":"gzip"}],"content":{"text":"<a href=\"/a\">a</a>"}}},
+		{"request":{"method":"GET","url":"https://example.com/logo"},"response":{"status":200,"headers":[],"content":{"text":"aGVsbG8=","encoding":"base64"}}},
+		{"request":{"method":"GET","url":"https://example.com/blocked"},"response":{"status":0,"headers":[],"content":{}}}
+	]}}`
+
+	entries, err := parseHAR(strings.NewReader(content))
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "unanswered entries should be skipped")
+	require.Equal(t, "https://example.com/", entries[0].URL)
+	require.Equal(t, http.MethodGet, entries[0].Method)
+	require.Equal(t, "200 OK", entries[0].Response.Status)
+	require.Equal(t, http.Header{"Content-Type": {"text/html"}}, entries[0].Response.Header)
+	require.Equal(t, `<a href="/a">a</a>`, string(entries[0].Body))
+	require.Equal(t, "hello", string(entries[1].Body))
+}
+
+func TestLoadStoredResponses(t *testing.T) {
+	directory := t.TempDir()
+	hostDirectory := filepath.Join(directory, "example.com")
+	require.NoError(t, os.MkdirAll(hostDirectory, os.ModePerm))
+
+	rawRequest := "POST /login HTTP/1.1\r\nHost: example.com\r\nContent-Length: 7\r\n\r\nuser=me"
+	rawResponse := "HTTP/1.1 200 OK\r\nContent-Type: text/html\r\nContent-Length: 22\r\n\r\n<a href=\"/home\">h</a>\n"
+	stored := "https://example.com/login\n\n\n" + rawRequest + "\n\n" + rawResponse
+	require.NoError(t, os.WriteFile(filepath.Join(hostDirectory, "a.txt"), []byte(stored), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, storedIndexFile), []byte("a.txt https://example.com/login (200 OK)\n"), 0644))
+
+	entries, err := Load(directory)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "the index should not be loaded")
+	require.Equal(t, "https://example.com/login", entries[0].URL)
+	require.Equal(t, http.MethodPost, entries[0].Method)
+	require.Equal(t, rawRequest, entries[0].RawRequest)
+	require.Equal(t, http.StatusOK, entries[0].Response.StatusCode)
+	require.Equal(t, "<a href=\"/home\">h</a>\n", string(entries[0].Body))
+
+	_, err = parseStoredResponse([]byte("https://example.com/\n\n\nno response"))
+	require.Error(t, err)
+}
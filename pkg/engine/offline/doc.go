@@ -0,0 +1,3 @@
+// Package offline implements re-parsing archived responses of a HAR
+// file or a store-response directory without any network access.
+package offline
@@ -0,0 +1,139 @@
+package offline
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/engine/common"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/types"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/utils/errkit"
+	mapsutil "github.com/projectdiscovery/utils/maps"
+)
+
+// Crawler re-parses archived responses without network access
+type Crawler struct {
+	*common.Shared
+}
+
+// New returns a new offline crawler instance
+func New(options *types.CrawlerOptions) (*Crawler, error) {
+	shared, err := common.NewShared(options)
+	if err != nil {
+		return nil, errkit.Wrap(err, "offline")
+	}
+	return &Crawler{Shared: shared}, nil
+}
+
+// Close closes the crawler process
+func (c *Crawler) Close() error {
+	return nil
+}
+
+// Crawl re-parses the responses of the HAR file or store-response
+// directory at path. Archived responses are written with the results
+// of the current extractors and endpoints discovered in them which
+// are not archived are written without a response.
+func (c *Crawler) Crawl(path string) error {
+	entries, err := Load(path)
+	if err != nil {
+		return err
+	}
+	gologger.Info().Msgf("Started offline parsing of %d archived responses from => %v", len(entries), path)
+
+	archived := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		archived[entry.URL] = struct{}{}
+		c.Options.UniqueFilter.UniqueURL(entry.URL)
+	}
+
+	for _, entry := range entries {
+		parsed, err := url.Parse(entry.URL)
+		if err != nil || parsed.Host == "" {
+			continue
+		}
+		rootHostname := parsed.Hostname()
+		if !c.Options.ValidatePath(entry.URL) || !c.ValidateScope(entry.URL, rootHostname) {
+			continue
+		}
+		req := &navigation.Request{
+			Method:       entry.Method,
+			URL:          entry.URL,
+			RootHostname: rootHostname,
+			Raw:          entry.RawRequest,
+		}
+		resp, err := c.newResponse(req, entry)
+		c.Output(req, resp, err)
+		if err != nil || resp.Reader == nil {
+			continue
+		}
+
+		for _, discovered := range c.Options.Parser.ParseResponse(resp) {
+			if discovered.URL == "" || !utils.IsURL(discovered.URL) {
+				continue
+			}
+			if _, ok := archived[discovered.URL]; ok {
+				continue
+			}
+			if !c.Options.UniqueFilter.UniqueURL(discovered.RequestURL()) {
+				continue
+			}
+			if !c.Options.ValidatePath(discovered.URL) || !c.ValidateScope(discovered.URL, rootHostname) {
+				continue
+			}
+			c.Output(discovered, nil, nil)
+		}
+	}
+	return nil
+}
+
+// newResponse creates the navigation response of an archived entry
+// the way the standard engine creates it for live responses
+func (c *Crawler) newResponse(req *navigation.Request, entry *Entry) (*navigation.Response, error) {
+	response := &navigation.Response{
+		Depth:        req.Depth + 1,
+		RootHostname: req.RootHostname,
+		KeepFragment: c.Options.Options.KeepFragments,
+	}
+	httpReq, err := http.NewRequest(req.Method, req.URL, nil)
+	if err != nil {
+		return response, err
+	}
+	resp := entry.Response
+	resp.Request = httpReq
+	resp.Body = io.NopCloser(bytes.NewReader(entry.Body))
+	resp.ContentLength = int64(len(entry.Body))
+
+	if c.Options.Wappalyzer != nil {
+		technologies := c.Options.Wappalyzer.Fingerprint(resp.Header, entry.Body)
+		response.Technologies = mapsutil.GetKeys(technologies)
+	}
+	response.KnowledgeBase = c.Options.ClassifyPage(string(entry.Body))
+	response.Body = string(entry.Body)
+	response.Resp = resp
+	response.StatusCode = resp.StatusCode
+	response.Headers = utils.FlattenHeaders(resp.Header)
+	response.ContentLength = resp.ContentLength
+
+	rawResponseBytes, _ := httputil.DumpResponse(resp, true)
+	response.Raw = string(rawResponseBytes)
+	resp.Body = io.NopCloser(bytes.NewReader(entry.Body))
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(entry.Body))
+	if err != nil {
+		return response, errkit.Wrap(err, "offline: could not make document from reader")
+	}
+	response.Reader = doc
+	response.Reader.Url, _ = url.Parse(req.URL)
+	if c.Options.Options.FormExtraction {
+		response.Forms = append(response.Forms, utils.ParseFormFields(response.Reader)...)
+	}
+	response.Relations = utils.ParseLinkRelations(response)
+	return response, nil
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+)
+
+// sniffLen is the number of body bytes used to sniff the content type
+const sniffLen = 512
+
+// contentFilter decides which response bodies are parsed
+type contentFilter struct {
+	// maxSize is the maximum size of parsed bodies, zero for no limit
+	maxSize int
+	// contentTypes are the parsed content types, type/* matches any subtype
+	contentTypes []string
+}
+
+// parseable returns true if the body of the response should be parsed.
+// Binary bodies are never parsed regardless of their declared content type.
+func (c contentFilter) parseable(resp *navigation.Response) bool {
+	if len(resp.Body) == 0 {
+		return true
+	}
+	if c.maxSize > 0 && len(resp.Body) > c.maxSize {
+		return false
+	}
+	sniffed := sniffContentType(resp.Body)
+	if len(c.contentTypes) > 0 {
+		contentType := sniffed
+		if resp.Resp != nil {
+			if declared, _, err := mime.ParseMediaType(resp.Resp.Header.Get("Content-Type")); err == nil {
+				contentType = declared
+			}
+		}
+		if !matchContentType(contentType, c.contentTypes) {
+			return false
+		}
+	}
+	return !isBinaryContentType(sniffed)
+}
+
+// sniffContentType returns the media type sniffed from the body
+func sniffContentType(body string) string {
+	sample := body
+	if len(sample) > sniffLen {
+		sample = sample[:sniffLen]
+	}
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType([]byte(sample)))
+	return sniffed
+}
+
+// isBinaryContentType returns true if the sniffed media type is not textual.
+// Bodies with binary bytes and no known signature are sniffed as octet-stream.
+func isBinaryContentType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") {
+		return false
+	}
+	for _, textual := range []string{"json", "xml", "javascript"} {
+		if strings.Contains(mediaType, textual) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchContentType returns true if the media type matches any of the patterns
+func matchContentType(mediaType string, patterns []string) bool {
+	mediaType = strings.ToLower(mediaType)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if mediaType == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package parser
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentFilter(t *testing.T) {
+	response := func(contentType, body string) *navigation.Response {
+		return &navigation.Response{Resp: &http.Response{Header: http.Header{"Content-Type": []string{contentType}}}, Body: body}
+	}
+	html := "<html><a href=/a>a</a></html>"
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+	filter := contentFilter{}
+	require.True(t, filter.parseable(response("text/html", html)))
+	require.False(t, filter.parseable(response("text/html", png)), "binary body mislabeled as text should not be parsed")
+	require.False(t, filter.parseable(response("text/plain", "PK\x03\x04\x14\x00\x00\x00")), "archive should not be parsed")
+	require.True(t, filter.parseable(response("application/octet-stream", `{"next":"/page/2"}`)), "text body mislabeled as binary should be parsed")
+
+	filter = contentFilter{maxSize: 10}
+	require.False(t, filter.parseable(response("text/html", html)))
+
+	filter = contentFilter{contentTypes: []string{"text/*", "application/json"}}
+	require.True(t, filter.parseable(response("text/html; charset=utf-8", html)))
+	require.True(t, filter.parseable(response("application/json", `{}`)))
+	require.False(t, filter.parseable(response("application/javascript", "fetch('/api')")))
+}
@@ -0,0 +1,123 @@
+package files
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/retryablehttp-go"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// KnownFileTag is the tag of the requests of custom known files
+const KnownFileTag = "known-file"
+
+// wellKnownPaths are the registered well-known uris probed for /.well-known/*
+var wellKnownPaths = []string{
+	"security.txt",
+	"openid-configuration",
+	"oauth-authorization-server",
+	"jwks.json",
+	"change-password",
+	"assetlinks.json",
+	"apple-app-site-association",
+	"host-meta",
+	"webfinger",
+	"nodeinfo",
+	"mta-sts.txt",
+}
+
+type customFilesCrawler struct {
+	httpclient *retryablehttp.Client
+	paths      []string
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func newCustomFilesCrawler(httpclient *retryablehttp.Client, paths []string) *customFilesCrawler {
+	return &customFilesCrawler{httpclient: httpclient, paths: paths, seen: make(map[string]struct{})}
+}
+
+// Visit probes the custom known files once per host returning the existing ones
+func (c *customFilesCrawler) Visit(URL string) (navigationRequests []*navigation.Request, err error) {
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return nil, errkit.Wrap(err, "customfilescrawler: could not parse url")
+	}
+	origin := parsed.Scheme + "://" + parsed.Host
+
+	c.mu.Lock()
+	_, seen := c.seen[origin]
+	c.seen[origin] = struct{}{}
+	c.mu.Unlock()
+	if seen {
+		return nil, nil
+	}
+
+	for _, pattern := range c.paths {
+		for _, path := range expandKnownFilePath(pattern) {
+			if !c.exists(origin + path) {
+				continue
+			}
+			navigationRequests = append(navigationRequests, &navigation.Request{
+				Method:    http.MethodGet,
+				URL:       origin + path,
+				Depth:     2,
+				Source:    URL,
+				Tag:       KnownFileTag,
+				Attribute: pattern,
+			})
+		}
+	}
+	return navigationRequests, nil
+}
+
+// exists returns true if the file exists or is access restricted
+func (c *customFilesCrawler) exists(requestURL string) bool {
+	req, err := retryablehttp.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("User-Agent", utils.WebUserAgent())
+
+	resp, err := c.httpclient.Do(req)
+	if err != nil {
+		gologger.Debug().Msgf("customfilescrawler: could not request %s: %s", requestURL, err)
+		return false
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			gologger.Error().Msgf("Error closing response body: %v\n", err)
+		}
+	}()
+	return resp.StatusCode < http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden
+}
+
+// expandKnownFilePath returns the paths of a known file pattern
+// expanding /.well-known/* to the registered well-known uris.
+func expandKnownFilePath(pattern string) []string {
+	path := strings.TrimSpace(pattern)
+	if path == "" {
+		return nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if prefix, ok := strings.CutSuffix(path, "/*"); ok && strings.HasSuffix(prefix, "/.well-known") {
+		paths := make([]string, 0, len(wellKnownPaths))
+		for _, name := range wellKnownPaths {
+			paths = append(paths, prefix+"/"+name)
+		}
+		return paths
+	}
+	if strings.Contains(path, "*") {
+		gologger.Warning().Msgf("Skipping known file %s, wildcards are only supported for /.well-known/*", pattern)
+		return nil
+	}
+	return []string{path}
+}
@@ -0,0 +1,46 @@
+package files
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/projectdiscovery/retryablehttp-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandKnownFilePath(t *testing.T) {
+	require.Equal(t, []string{"/security.txt"}, expandKnownFilePath("security.txt"))
+	require.Contains(t, expandKnownFilePath("/.well-known/*"), "/.well-known/openid-configuration")
+	require.Empty(t, expandKnownFilePath("/debug/*"))
+	require.Empty(t, expandKnownFilePath(" "))
+}
+
+func TestCustomFilesVisit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/package.json":
+			_, _ = w.Write([]byte("{}"))
+		case "/.well-known/security.txt":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	crawler := newCustomFilesCrawler(retryablehttp.NewClient(retryablehttp.DefaultOptionsSingle), []string{"/package.json", "/.well-known/*", "/missing"})
+	navigationRequests, err := crawler.Visit(server.URL + "/app/")
+	require.NoError(t, err)
+
+	var urls []string
+	for _, navigationRequest := range navigationRequests {
+		require.Equal(t, KnownFileTag, navigationRequest.Tag)
+		urls = append(urls, navigationRequest.URL)
+	}
+	require.ElementsMatch(t, []string{server.URL + "/package.json", server.URL + "/.well-known/security.txt"}, urls)
+
+	navigationRequests, err = crawler.Visit(server.URL + "/other")
+	require.NoError(t, err)
+	require.Empty(t, navigationRequests, "known files should be probed once per host")
+}
@@ -12,12 +12,15 @@ type KnownFiles struct {
 	httpclient *retryablehttp.Client
 }
 
-// New returns a new known files parser instance
-func New(httpclient *retryablehttp.Client, files string) *KnownFiles {
+// New returns a new known files parser instance probing
+// the custom known files paths in addition to the files
+func New(httpclient *retryablehttp.Client, files string, paths []string) *KnownFiles {
 	parser := &KnownFiles{
 		httpclient: httpclient,
 	}
 	switch files {
+	case "":
+		// only the custom known files are probed
 	case "robotstxt":
 		crawler := &robotsTxtCrawler{httpclient: httpclient}
 		parser.parsers = append(parser.parsers, crawler.Visit)
@@ -30,6 +33,10 @@ func New(httpclient *retryablehttp.Client, files string) *KnownFiles {
 		another := &sitemapXmlCrawler{httpclient: httpclient}
 		parser.parsers = append(parser.parsers, another.Visit)
 	}
+	if len(paths) > 0 {
+		crawler := newCustomFilesCrawler(httpclient, paths)
+		parser.parsers = append(parser.parsers, crawler.Visit)
+	}
 	return parser
 }
 
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+)
+
+// -------------------------------------------------------------------------
+// Begin inline handler attribute parsers
+// -------------------------------------------------------------------------
+
+var (
+	// handlerNavigationRegex matches the urls navigated to by inline handlers
+	// (eg. location.href='/a', location.assign('/a'), window.open('/a'))
+	handlerNavigationRegex = regexp.MustCompile(`(?:location(?:\.href)?\s*=\s*|location\.(?:assign|replace)\(\s*|open\(\s*)["']([^"']+)["']`)
+	// handlerPathRegex matches the quoted absolute urls and paths of inline handlers
+	handlerPathRegex = regexp.MustCompile(`["']((?:https?:)?//[^"'\s]+|\.{0,2}/[^"'\s]*)["']`)
+)
+
+// newBodyHandlerAttrParser returns a parser for the attributes of elements.
+// The urls of event handler attributes (on*) are extracted from their
+// script while other attributes are urls themselves.
+func newBodyHandlerAttrParser(attributes []string) ResponseParserFunc {
+	selectors := make([]string, 0, len(attributes))
+	for _, attribute := range attributes {
+		selectors = append(selectors, "["+attribute+"]")
+	}
+	selector := strings.Join(selectors, ",")
+
+	return func(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+		resp.Reader.Find(selector).Each(func(i int, item *goquery.Selection) {
+			tag := goquery.NodeName(item)
+			for _, attribute := range attributes {
+				value, ok := item.Attr(attribute)
+				if !ok || strings.TrimSpace(value) == "" {
+					continue
+				}
+				// button form actions are parsed by the button parser
+				if attribute == "formaction" && tag == "button" {
+					continue
+				}
+				urls := []string{strings.TrimSpace(value)}
+				if strings.HasPrefix(attribute, "on") {
+					urls = extractHandlerURLs(value)
+				}
+				for _, url := range urls {
+					navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(url, resp.Resp.Request.URL.String(), tag, attribute, resp))
+				}
+			}
+		})
+		return
+	}
+}
+
+// extractHandlerURLs returns the urls referenced by an inline handler script
+func extractHandlerURLs(script string) []string {
+	var urls []string
+	seen := make(map[string]struct{})
+	for _, re := range []*regexp.Regexp{handlerNavigationRegex, handlerPathRegex} {
+		for _, match := range re.FindAllStringSubmatch(script, -1) {
+			url := strings.TrimSpace(match[1])
+			if _, ok := seen[url]; ok || url == "" {
+				continue
+			}
+			seen[url] = struct{}{}
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
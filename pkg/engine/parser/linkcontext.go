@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+)
+
+// maxLinkTextLen is the maximum length of anchor and heading texts
+const maxLinkTextLen = 200
+
+const headingSelector = "h1, h2, h3, h4, h5, h6"
+
+// landmarkRoles maps the aria landmark roles to page regions
+var landmarkRoles = map[string]string{
+	"navigation":    "nav",
+	"banner":        "header",
+	"main":          "main",
+	"complementary": "aside",
+	"contentinfo":   "footer",
+}
+
+// linkContext returns the text, heading and page region of an anchor
+func linkContext(item *goquery.Selection) *navigation.LinkContext {
+	return &navigation.LinkContext{
+		Text:     linkText(item),
+		Heading:  linkHeading(item),
+		Location: linkLocation(item),
+	}
+}
+
+// linkText returns the text of the anchor falling back to its
+// accessible label or the alt text of its images
+func linkText(item *goquery.Selection) string {
+	if text := normalizeText(item.Text()); text != "" {
+		return text
+	}
+	for _, attribute := range []string{"aria-label", "title"} {
+		if value, ok := item.Attr(attribute); ok && strings.TrimSpace(value) != "" {
+			return normalizeText(value)
+		}
+	}
+	if alt, ok := item.Find("img[alt]").First().Attr("alt"); ok {
+		return normalizeText(alt)
+	}
+	return ""
+}
+
+// linkHeading returns the text of the nearest heading preceding
+// the anchor in its section of the document
+func linkHeading(item *goquery.Selection) string {
+	for current := item; current.Length() > 0 && !current.Is("body, html"); current = current.Parent() {
+		if current != item && current.Is(headingSelector) {
+			return normalizeText(current.Text())
+		}
+		var heading string
+		current.PrevAll().EachWithBreak(func(_ int, sibling *goquery.Selection) bool {
+			if sibling.Is(headingSelector) {
+				heading = normalizeText(sibling.Text())
+				return false
+			}
+			if nested := sibling.Find(headingSelector); nested.Length() > 0 {
+				heading = normalizeText(nested.Last().Text())
+				return false
+			}
+			return true
+		})
+		if heading != "" {
+			return heading
+		}
+	}
+	return ""
+}
+
+// linkLocation returns the innermost page region the anchor is in
+func linkLocation(item *goquery.Selection) string {
+	location := "body"
+	item.ParentsUntil("body").EachWithBreak(func(_ int, parent *goquery.Selection) bool {
+		if role, ok := landmarkRoles[strings.ToLower(parent.AttrOr("role", ""))]; ok {
+			location = role
+			return false
+		}
+		switch tag := goquery.NodeName(parent); tag {
+		case "nav", "header", "main", "aside", "footer":
+			location = tag
+			return false
+		}
+		return true
+	})
+	return location
+}
+
+// normalizeText collapses the whitespace of a text and truncates it
+func normalizeText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxLinkTextLen {
+		text = string(runes[:maxLinkTextLen])
+	}
+	return text
+}
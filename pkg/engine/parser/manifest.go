@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	stringsutil "github.com/projectdiscovery/utils/strings"
+)
+
+// -------------------------------------------------------------------------
+// Begin application shell manifest parsers
+// -------------------------------------------------------------------------
+
+// webManifest is a web application manifest (manifest.json)
+type webManifest struct {
+	StartURL      string             `json:"start_url"`
+	Scope         string             `json:"scope"`
+	Icons         []webManifestImage `json:"icons"`
+	Screenshots   []webManifestImage `json:"screenshots"`
+	ServiceWorker *webManifestImage  `json:"serviceworker"`
+	Shortcuts     []struct {
+		URL   string             `json:"url"`
+		Icons []webManifestImage `json:"icons"`
+	} `json:"shortcuts"`
+	ShareTarget *struct {
+		Action string `json:"action"`
+	} `json:"share_target"`
+	ProtocolHandlers []struct {
+		URL string `json:"url"`
+	} `json:"protocol_handlers"`
+	FileHandlers []struct {
+		Action string `json:"action"`
+	} `json:"file_handlers"`
+}
+
+type webManifestImage struct {
+	Src string `json:"src"`
+}
+
+// webManifestParser parses the urls of web application manifests
+func webManifestParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+	contentType := resp.Resp.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "manifest+json") && !isManifestPath(resp.Resp.Request.URL.Path) {
+		return
+	}
+	var manifest webManifest
+	if err := json.Unmarshal([]byte(resp.Body), &manifest); err != nil {
+		return
+	}
+
+	source := resp.Resp.Request.URL.String()
+	add := func(value, attribute string) {
+		// protocol handlers use %s as the placeholder of the url
+		value = strings.ReplaceAll(value, "%s", "")
+		if value == "" {
+			return
+		}
+		navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(value, source, "manifest", attribute, resp))
+	}
+	add(manifest.StartURL, "start_url")
+	add(manifest.Scope, "scope")
+	for _, icon := range manifest.Icons {
+		add(icon.Src, "icons")
+	}
+	for _, screenshot := range manifest.Screenshots {
+		add(screenshot.Src, "screenshots")
+	}
+	if manifest.ServiceWorker != nil {
+		add(manifest.ServiceWorker.Src, "serviceworker")
+	}
+	for _, shortcut := range manifest.Shortcuts {
+		add(shortcut.URL, "shortcuts")
+		for _, icon := range shortcut.Icons {
+			add(icon.Src, "shortcuts")
+		}
+	}
+	if manifest.ShareTarget != nil {
+		add(manifest.ShareTarget.Action, "share_target")
+	}
+	for _, handler := range manifest.ProtocolHandlers {
+		add(handler.URL, "protocol_handlers")
+	}
+	for _, handler := range manifest.FileHandlers {
+		add(handler.Action, "file_handlers")
+	}
+	return
+}
+
+// isManifestPath returns true if the path is a web application manifest
+func isManifestPath(urlPath string) bool {
+	name := strings.ToLower(path.Base(urlPath))
+	return name == "manifest.json" || name == "site.webmanifest" || strings.HasSuffix(name, ".webmanifest")
+}
+
+var (
+	// precacheMarkerRegex matches service workers using a precache manifest
+	precacheMarkerRegex = regexp.MustCompile(`precacheAndRoute|__precacheManifest|__WB_MANIFEST|precache-manifest|addAll\(`)
+	// precacheURLRegex matches the url entries of precache manifests
+	precacheURLRegex = regexp.MustCompile(`["']?url["']?\s*:\s*["']([^"']+)["']`)
+	// precacheAddAllRegex matches the urls cached with cache.addAll
+	precacheAddAllRegex = regexp.MustCompile(`addAll\(\s*\[([^\]]*)\]`)
+	// quotedStringRegex matches quoted strings
+	quotedStringRegex = regexp.MustCompile(`["']([^"']+)["']`)
+	// serviceWorkerRegisterRegex matches service worker registrations
+	serviceWorkerRegisterRegex = regexp.MustCompile(`serviceWorker\s*\.\s*register\(\s*["']([^"']+)["']`)
+)
+
+// precacheManifestParser parses the urls precached by service workers
+// (workbox precache manifests and cache.addAll calls)
+func precacheManifestParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+	contentType := resp.Resp.Header.Get("Content-Type")
+	if !stringsutil.HasSuffixAny(resp.Resp.Request.URL.Path, ".js", ".json") && !strings.Contains(contentType, "/javascript") {
+		return
+	}
+	if !precacheMarkerRegex.MatchString(resp.Body) {
+		return
+	}
+
+	source := resp.Resp.Request.URL.String()
+	for _, match := range precacheURLRegex.FindAllStringSubmatch(resp.Body, -1) {
+		navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(match[1], source, "serviceworker", "precache", resp))
+	}
+	for _, list := range precacheAddAllRegex.FindAllStringSubmatch(resp.Body, -1) {
+		for _, match := range quotedStringRegex.FindAllStringSubmatch(list[1], -1) {
+			navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(match[1], source, "serviceworker", "addAll", resp))
+		}
+	}
+	for _, match := range serviceWorkerRegisterRegex.FindAllStringSubmatch(resp.Body, -1) {
+		navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(match[1], source, "serviceworker", "register", resp))
+	}
+	return
+}
+
+// bodyServiceWorkerParser parses the service workers registered by inline scripts
+func bodyServiceWorkerParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+	resp.Reader.Find("script").Each(func(i int, item *goquery.Selection) {
+		for _, match := range serviceWorkerRegisterRegex.FindAllStringSubmatch(item.Text(), -1) {
+			navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(match[1], resp.Resp.Request.URL.String(), "script", "serviceworker", resp))
+		}
+	})
+	return
+}
+
+// browserConfigParser parses the tile images and polling urls of
+// internet explorer and edge browserconfig.xml files
+func browserConfigParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+	if !strings.EqualFold(path.Base(resp.Resp.Request.URL.Path), "browserconfig.xml") {
+		return
+	}
+
+	source := resp.Resp.Request.URL.String()
+	decoder := xml.NewDecoder(strings.NewReader(resp.Body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		for _, attr := range element.Attr {
+			if attr.Name.Local != "src" || attr.Value == "" {
+				continue
+			}
+			navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(attr.Value, source, "browserconfig", strings.ToLower(element.Name.Local), resp))
+		}
+	}
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"mime/multipart"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -19,7 +20,17 @@ import (
 // new navigation items or requests for the crawler.
 type ResponseParserFunc func(resp *navigation.Response) []*navigation.Request
 
-type Parser []responseParser
+type Parser struct {
+	parsers []responseParser
+	// content decides which response bodies are parsed
+	content contentFilter
+	// skipNonCanonical drops the links of pages which are duplicates
+	// of their canonical page and their amp and mobile alternates
+	skipNonCanonical bool
+	// skipClientRedirects drops the targets of meta refresh and
+	// script redirects when redirects are disabled
+	skipClientRedirects bool
+}
 
 type responseParserType int
 
@@ -35,7 +46,7 @@ type responseParser struct {
 }
 
 func NewResponseParser() *Parser {
-	return &Parser{
+	return &Parser{parsers: []responseParser{
 		// Header based parsers
 		{headerParser, headerContentLocationParser},
 		{headerParser, headerLinkParser},
@@ -48,6 +59,7 @@ func NewResponseParser() *Parser {
 		{bodyParser, bodyAudioTagParser},
 		{bodyParser, bodyAppletTagParser},
 		{bodyParser, bodyImgTagParser},
+		{bodyParser, bodyPictureTagParser},
 		{bodyParser, bodyObjectTagParser},
 		{bodyParser, bodySvgTagParser},
 		{bodyParser, bodyTableTagParser},
@@ -68,27 +80,93 @@ func NewResponseParser() *Parser {
 		{bodyParser, bodyHtmlManifestTagParser},
 		{bodyParser, bodyHtmlDoctypeTagParser},
 		{bodyParser, bodyHtmxAttrParser},
+		{bodyParser, bodyServiceWorkerParser},
+
+		// Application shell manifest parsers
+		{contentParser, webManifestParser},
+		{contentParser, precacheManifestParser},
+		{contentParser, browserConfigParser},
+
+		// Structured api response parsers
+		{contentParser, jsonResponseParser},
+		{contentParser, xmlResponseParser},
 
 		// custom field regex parser
 		{bodyParser, customFieldRegexParser},
-	}
+	}}
 }
 
 // parseResponse runs the response parsers on the navigation response
 func (p *Parser) ParseResponse(resp *navigation.Response) (navigationRequests []*navigation.Request) {
-	for _, parser := range *p {
+	// headers are parsed even when the body is not so that redirects are followed
+	parseBody := p.content.parseable(resp)
+	// links of the document are resolved against its base url
+	// while links of the headers are resolved against the request url
+	if parseBody && resp.Reader != nil && resp.BaseURL == nil {
+		resp.BaseURL = utils.ParseBaseURL(resp)
+	}
+	headers := resp
+	if resp.BaseURL != nil {
+		withoutBase := *resp
+		withoutBase.BaseURL = nil
+		headers = &withoutBase
+	}
+	for _, parser := range p.parsers {
 		switch {
 		case parser.parserType == headerParser && resp.Resp != nil:
+			navigationRequests = appendFiltered(navigationRequests, parser.parserFunc(headers))
+		case parser.parserType == bodyParser && resp.Reader != nil && parseBody:
 			navigationRequests = appendFiltered(navigationRequests, parser.parserFunc(resp))
-		case parser.parserType == bodyParser && resp.Reader != nil:
-			navigationRequests = appendFiltered(navigationRequests, parser.parserFunc(resp))
-		case parser.parserType == contentParser && len(resp.Body) > 0:
+		case parser.parserType == contentParser && len(resp.Body) > 0 && parseBody:
 			navigationRequests = appendFiltered(navigationRequests, parser.parserFunc(resp))
 		}
 	}
+	if p.skipNonCanonical && parseBody {
+		navigationRequests = filterNonCanonical(resp, navigationRequests)
+	}
+	if p.skipClientRedirects && parseBody {
+		navigationRequests = filterClientRedirects(resp, navigationRequests)
+	}
+	for _, req := range navigationRequests {
+		req.SourceChain = resp.SourceChainTo(req)
+	}
 	return
 }
 
+// filterNonCanonical drops the requests for the amp and mobile alternates
+// of the page. When the page declares another canonical url it is a
+// duplicate of it, so only the canonical page is crawled.
+func filterNonCanonical(resp *navigation.Response, navigationRequests []*navigation.Request) []*navigation.Request {
+	if resp.Resp == nil || resp.Resp.Request == nil {
+		return navigationRequests
+	}
+	if resp.Relations == nil {
+		resp.Relations = utils.ParseLinkRelations(resp)
+	}
+	duplicates := make(map[string]struct{})
+	for _, relation := range resp.Relations {
+		if relation.Kind == navigation.AlternateAMP || relation.Kind == navigation.AlternateMobile {
+			duplicates[relation.URL] = struct{}{}
+		}
+	}
+	canonical := resp.Canonical()
+	if canonical == resp.AbsoluteURL(resp.Resp.Request.URL.String()) || resp.IsRedirect() {
+		canonical = ""
+	}
+
+	filtered := navigationRequests[:0]
+	for _, req := range navigationRequests {
+		if _, ok := duplicates[req.URL]; ok {
+			continue
+		}
+		if canonical != "" && req.URL != canonical {
+			continue
+		}
+		filtered = append(filtered, req)
+	}
+	return filtered
+}
+
 // appendFiltered filters navigation requests and appends valid ones to the slice
 func appendFiltered(existing []*navigation.Request, new []*navigation.Request) []*navigation.Request {
 	for _, req := range new {
@@ -174,7 +252,9 @@ func bodyATagParser(resp *navigation.Response) (navigationRequests []*navigation
 	resp.Reader.Find("a").Each(func(i int, item *goquery.Selection) {
 		href, ok := item.Attr("href")
 		if ok && href != "" {
-			navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(href, resp.Resp.Request.URL.String(), "a", "href", resp))
+			req := navigation.NewNavigationRequestURLFromResponse(href, resp.Resp.Request.URL.String(), "a", "href", resp)
+			req.Link = linkContext(item)
+			navigationRequests = append(navigationRequests, req)
 		}
 		ping, ok := item.Attr("ping")
 		if ok && ping != "" {
@@ -186,15 +266,32 @@ func bodyATagParser(resp *navigation.Response) (navigationRequests []*navigation
 
 // bodyLinkHrefTagParser parses link tag from response
 func bodyLinkHrefTagParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
-	resp.Reader.Find("link[href]").Each(func(i int, item *goquery.Selection) {
+	resp.Reader.Find("link[href], link[imagesrcset]").Each(func(i int, item *goquery.Selection) {
 		href, ok := item.Attr("href")
 		if ok && href != "" {
-			navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(href, resp.Resp.Request.URL.String(), "link", "href", resp))
+			attribute := "href"
+			// fetch preloads are the api requests made by the page
+			if isFetchPreload(item) {
+				attribute = "preload-fetch"
+			}
+			navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(href, resp.Resp.Request.URL.String(), "link", attribute, resp))
+		}
+		srcSet, ok := item.Attr("imagesrcset")
+		if ok && srcSet != "" {
+			for _, value := range utils.ParseSRCSetTag(srcSet) {
+				navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(value, resp.Resp.Request.URL.String(), "link", "imagesrcset", resp))
+			}
 		}
 	})
 	return
 }
 
+// isFetchPreload returns true if the link preloads a fetch request
+func isFetchPreload(item *goquery.Selection) bool {
+	rels := strings.Fields(strings.ToLower(item.AttrOr("rel", "")))
+	return slices.Contains(rels, "preload") && strings.EqualFold(strings.TrimSpace(item.AttrOr("as", "")), "fetch")
+}
+
 // bodyEmbedTagParser parses Embed tag from response
 func bodyEmbedTagParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
 	resp.Reader.Find("embed[src]").Each(func(i int, item *goquery.Selection) {
@@ -350,6 +447,19 @@ func bodyImgTagParser(resp *navigation.Response) (navigationRequests []*navigati
 	return
 }
 
+// bodyPictureTagParser parses picture source tag from response
+func bodyPictureTagParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+	resp.Reader.Find("picture source[srcset]").Each(func(i int, item *goquery.Selection) {
+		srcSet, ok := item.Attr("srcset")
+		if ok && srcSet != "" {
+			for _, value := range utils.ParseSRCSetTag(srcSet) {
+				navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(value, resp.Resp.Request.URL.String(), "picture", "srcset", resp))
+			}
+		}
+	})
+	return
+}
+
 // bodyObjectTagParser parses object tag from response
 func bodyObjectTagParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
 	resp.Reader.Find("object").Each(func(i int, item *goquery.Selection) {
@@ -522,7 +632,19 @@ func bodyHtmlDoctypeTagParser(resp *navigation.Response) (navigationRequests []*
 }
 
 // bodyFormTagParser parses forms from response
-func bodyFormTagParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+func bodyFormTagParser(resp *navigation.Response) []*navigation.Request {
+	return parseFormTags(resp, nil)
+}
+
+// newBodyFormTagParser returns a form parser injecting
+// interaction markers into the form submissions.
+func newBodyFormTagParser(markers *utils.FormMarkers) ResponseParserFunc {
+	return func(resp *navigation.Response) []*navigation.Request {
+		return parseFormTags(resp, markers)
+	}
+}
+
+func parseFormTags(resp *navigation.Response, markers *utils.FormMarkers) (navigationRequests []*navigation.Request) {
 	resp.Reader.Find("form").Each(func(i int, item *goquery.Selection) {
 		href, _ := item.Attr("action")
 		encType, ok := item.Attr("enctype")
@@ -567,7 +689,16 @@ func bodyFormTagParser(resp *navigation.Response) (navigationRequests []*navigat
 			formFields = append(formFields, utils.ConvertGoquerySelectionToFormField(item))
 		})
 
+		source := resp.Resp.Request.URL.String()
+		submission := &utils.FormSubmission{
+			Action:      actionURL,
+			Method:      method,
+			Source:      source,
+			SourceChain: resp.SourceChainTo(&navigation.Request{Source: source, Tag: "form", Attribute: "action"}),
+		}
+
 		dataMap := utils.FormFillSuggestions(formFields)
+		markers.InjectFields(&dataMap, submission)
 		dataMap.Iterate(func(key, value string) bool {
 			if key == "" {
 				return true
@@ -596,7 +727,7 @@ func bodyFormTagParser(resp *navigation.Response) (navigationRequests []*navigat
 			RootHostname: resp.RootHostname,
 			Tag:          "form",
 			Attribute:    "action",
-			Source:       resp.Resp.Request.URL.String(),
+			Source:       source,
 		}
 		switch method {
 		case "GET":
@@ -611,6 +742,14 @@ func bodyFormTagParser(resp *navigation.Response) (navigationRequests []*navigat
 			req.Headers = make(map[string]string)
 			req.Headers["Content-Type"] = contentType
 		}
+		if headers := markers.HeaderValues(submission); len(headers) > 0 {
+			if req.Headers == nil {
+				req.Headers = make(map[string]string)
+			}
+			for k, v := range headers {
+				req.Headers[k] = v
+			}
+		}
 		navigationRequests = append(navigationRequests, req)
 	})
 	return
@@ -17,23 +17,46 @@ type Options struct {
 	ScrapeJSLuiceResponses bool
 	ScrapeJSResponses      bool
 	DisableRedirects       bool
+	// FormMarkers injects interaction markers into submitted forms
+	FormMarkers *utils.FormMarkers
+	// MaxParseSize is the maximum size of parsed response bodies
+	MaxParseSize int
+	// ParseContentTypes are the content types of parsed response bodies
+	ParseContentTypes []string
+	// SkipNonCanonical skips crawling the non-canonical duplicates of pages
+	SkipNonCanonical bool
+	// HandlerAttributes are the attributes parsed for urls, the urls of
+	// event handler attributes (on*) are extracted from their script
+	HandlerAttributes []string
+	// ClientRedirects treats meta refresh and script redirects as
+	// redirects, their targets are not followed if redirects are disabled
+	ClientRedirects bool
 }
 
 func (p *Parser) InitWithOptions(options *Options) {
+	p.content = contentFilter{maxSize: options.MaxParseSize, contentTypes: options.ParseContentTypes}
+	p.skipNonCanonical = options.SkipNonCanonical
+	p.skipClientRedirects = options.ClientRedirects && options.DisableRedirects
+	if !p.skipClientRedirects {
+		p.parsers = append(p.parsers, responseParser{bodyParser, bodyClientRedirectParser})
+	}
+	if len(options.HandlerAttributes) > 0 {
+		p.parsers = append(p.parsers, responseParser{bodyParser, newBodyHandlerAttrParser(options.HandlerAttributes)})
+	}
 	if options.AutomaticFormFill {
-		*p = append(*p, responseParser{bodyParser, bodyFormTagParser})
+		p.parsers = append(p.parsers, responseParser{bodyParser, newBodyFormTagParser(options.FormMarkers)})
 	}
 	if options.ScrapeJSLuiceResponses {
-		*p = append(*p, responseParser{bodyParser, scriptContentJsluiceParser})
-		*p = append(*p, responseParser{contentParser, scriptJSFileJsluiceParser})
+		p.parsers = append(p.parsers, responseParser{bodyParser, scriptContentJsluiceParser})
+		p.parsers = append(p.parsers, responseParser{contentParser, scriptJSFileJsluiceParser})
 	}
 	if options.ScrapeJSResponses {
-		*p = append(*p, responseParser{bodyParser, scriptContentRegexParser})
-		*p = append(*p, responseParser{contentParser, scriptJSFileRegexParser})
-		*p = append(*p, responseParser{contentParser, bodyScrapeEndpointsParser})
+		p.parsers = append(p.parsers, responseParser{bodyParser, scriptContentRegexParser})
+		p.parsers = append(p.parsers, responseParser{contentParser, scriptJSFileRegexParser})
+		p.parsers = append(p.parsers, responseParser{contentParser, bodyScrapeEndpointsParser})
 	}
 	if !options.DisableRedirects {
-		*p = append(*p, responseParser{headerParser, headerLocationParser})
+		p.parsers = append(p.parsers, responseParser{headerParser, headerLocationParser})
 	}
 }
 
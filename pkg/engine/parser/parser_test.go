@@ -52,6 +52,22 @@ func TestBodyParsers(t *testing.T) {
 		navigationRequests = bodyATagParser(resp)
 		require.Equal(t, "https://security-crawl-maze.app/test/html/body/a/ping.found", navigationRequests[0].URL, "could not get correct url")
 	})
+	t.Run("a-fragment", func(t *testing.T) {
+		documentReader, _ := goquery.NewDocumentFromReader(strings.NewReader("<a href=#/admin><a href=/app#/settings><a href=#>"))
+		resp := &navigation.Response{Resp: &http.Response{Request: &http.Request{URL: parsed.URL}}, Reader: documentReader}
+		var urls []string
+		for _, navigationRequest := range bodyATagParser(resp) {
+			urls = append(urls, navigationRequest.URL)
+		}
+		require.Equal(t, []string{"", "https://security-crawl-maze.app/app", ""}, urls, "could not strip fragments")
+
+		resp.KeepFragment = true
+		urls = nil
+		for _, navigationRequest := range bodyATagParser(resp) {
+			urls = append(urls, navigationRequest.URL)
+		}
+		require.Equal(t, []string{"https://security-crawl-maze.app/html/body/xyz/#/admin", "https://security-crawl-maze.app/app#/settings", ""}, urls, "could not keep fragments")
+	})
 	t.Run("background", func(t *testing.T) {
 		documentReader, _ := goquery.NewDocumentFromReader(strings.NewReader("<body background=\"/test/html/body/background.found\"></body>"))
 		resp := &navigation.Response{Resp: &http.Response{Request: &http.Request{URL: parsed.URL}}, Reader: documentReader}
@@ -565,3 +581,257 @@ func TestDataURIFiltering(t *testing.T) {
 		require.Equal(t, 0, len(navigationRequests), "Expected all invalid URIs to be filtered out")
 	})
 }
+
+func TestManifestParsers(t *testing.T) {
+	urls := func(navigationRequests []*navigation.Request) []string {
+		var results []string
+		for _, request := range navigationRequests {
+			results = append(results, request.URL)
+		}
+		return results
+	}
+
+	t.Run("webmanifest", func(t *testing.T) {
+		parsed, _ := urlutil.Parse("https://example.com/app/manifest.json")
+		resp := &navigation.Response{
+			Resp: &http.Response{Request: &http.Request{URL: parsed.URL}, Header: http.Header{}},
+			Body: `{"start_url":"/app/?source=pwa","icons":[{"src":"icons/192.png"}],"shortcuts":[{"url":"/app/new","icons":[{"src":"/new.png"}]}],"share_target":{"action":"/share"},"protocol_handlers":[{"url":"/handle?uri=%s"}]}`,
+		}
+		require.Equal(t, []string{
+			"https://example.com/app/?source=pwa",
+			"https://example.com/app/icons/192.png",
+			"https://example.com/app/new",
+			"https://example.com/new.png",
+			"https://example.com/share",
+			"https://example.com/handle?uri=",
+		}, urls(webManifestParser(resp)))
+	})
+	t.Run("precache", func(t *testing.T) {
+		parsed, _ := urlutil.Parse("https://example.com/sw.js")
+		resp := &navigation.Response{
+			Resp: &http.Response{Request: &http.Request{URL: parsed.URL}, Header: http.Header{}},
+			Body: `workbox.precaching.precacheAndRoute([{"revision":"1","url":"/index.html"},{url:"/static/app.js",revision:null}]);
+caches.open("v1").then(c => c.addAll(["/offline.html", '/logo.svg']));`,
+		}
+		require.Equal(t, []string{
+			"https://example.com/index.html",
+			"https://example.com/static/app.js",
+			"https://example.com/offline.html",
+			"https://example.com/logo.svg",
+		}, urls(precacheManifestParser(resp)))
+	})
+	t.Run("serviceworker", func(t *testing.T) {
+		parsed, _ := urlutil.Parse("https://example.com/")
+		documentReader, _ := goquery.NewDocumentFromReader(strings.NewReader(`<script>navigator.serviceWorker.register('/service-worker.js')</script>`))
+		resp := &navigation.Response{Resp: &http.Response{Request: &http.Request{URL: parsed.URL}}, Reader: documentReader}
+		require.Equal(t, []string{"https://example.com/service-worker.js"}, urls(bodyServiceWorkerParser(resp)))
+	})
+	t.Run("browserconfig", func(t *testing.T) {
+		parsed, _ := urlutil.Parse("https://example.com/browserconfig.xml")
+		resp := &navigation.Response{
+			Resp: &http.Response{Request: &http.Request{URL: parsed.URL}, Header: http.Header{}},
+			Body: `<?xml version="1.0" encoding="utf-8"?><browserconfig><msapplication><tile><square150x150logo src="/mstile-150x150.png"/></tile><notification><polling-uri src="/notifications/1"/></notification></msapplication></browserconfig>`,
+		}
+		require.Equal(t, []string{
+			"https://example.com/mstile-150x150.png",
+			"https://example.com/notifications/1",
+		}, urls(browserConfigParser(resp)))
+	})
+}
+
+func TestStructuredResponseParsers(t *testing.T) {
+	urls := func(navigationRequests []*navigation.Request) []string {
+		var results []string
+		for _, request := range navigationRequests {
+			results = append(results, request.URL)
+		}
+		return results
+	}
+
+	t.Run("json", func(t *testing.T) {
+		parsed, _ := urlutil.Parse("https://example.com/api/users")
+		resp := &navigation.Response{
+			Resp: &http.Response{Request: &http.Request{URL: parsed.URL}, Header: http.Header{"Content-Type": []string{"application/hal+json"}}},
+			Body: `{"_links":{"self":{"href":"/api/users?page=1"},"next":{"href":"https://example.com/api/users?page=2"}},"items":[{"name":"a b","avatar":"/static/a.png","comment":"//not a url"}],"count":2}`,
+		}
+		require.Equal(t, []string{
+			"https://example.com/api/users?page=2",
+			"https://example.com/api/users?page=1",
+			"https://example.com/static/a.png",
+		}, urls(jsonResponseParser(resp)))
+
+		resp.Resp.Header.Set("Content-Type", "text/html")
+		require.Empty(t, jsonResponseParser(resp))
+	})
+	t.Run("xml", func(t *testing.T) {
+		parsed, _ := urlutil.Parse("https://example.com/feed")
+		resp := &navigation.Response{
+			Resp: &http.Response{Request: &http.Request{URL: parsed.URL}, Header: http.Header{"Content-Type": []string{"application/atom+xml"}}},
+			Body: `<?xml version="1.0"?><feed><link rel="self" href="/feed"/><entry><title>/not/a/link</title><link href="https://example.com/posts/1"/><id>/posts/1</id></entry></feed>`,
+		}
+		require.Equal(t, []string{
+			"https://example.com/feed",
+			"https://example.com/posts/1",
+			"https://example.com/posts/1",
+		}, urls(xmlResponseParser(resp)))
+	})
+}
+
+func TestSourceChain(t *testing.T) {
+	parsed, _ := urlutil.Parse("https://example.com/docs/")
+	documentReader, _ := goquery.NewDocumentFromReader(strings.NewReader("<a href=/docs/page.found>"))
+	resp := &navigation.Response{
+		Resp:        &http.Response{Request: &http.Request{URL: parsed.URL}},
+		Reader:      documentReader,
+		SourceChain: []navigation.SourceLink{{URL: "https://example.com/", Tag: "a", Attribute: "href"}},
+	}
+	navigationRequests := NewResponseParser().ParseResponse(resp)
+	require.Len(t, navigationRequests, 1)
+	require.Equal(t, []navigation.SourceLink{
+		{URL: "https://example.com/", Tag: "a", Attribute: "href"},
+		{URL: "https://example.com/docs/", Tag: "a", Attribute: "href"},
+	}, navigationRequests[0].SourceChain)
+	require.Len(t, resp.SourceChain, 1, "source chain of the response should not be modified")
+}
+
+func TestLinkContext(t *testing.T) {
+	parsed, _ := urlutil.Parse("https://example.com/")
+	body := `<html><body>
+<nav role="navigation"><ul><li><a href="/home"> Home
+</a></li></ul></nav>
+<main>
+  <section><h2>Pricing</h2><p>See <a href="/plans">our plans</a></p></section>
+  <div><h3>Downloads</h3></div>
+  <a href="/download" aria-label="Download installer"><svg></svg></a>
+</main>
+<footer><a href="/legal"><img src="/l.png" alt="Legal"></a></footer>
+</body></html>`
+	documentReader, _ := goquery.NewDocumentFromReader(strings.NewReader(body))
+	resp := &navigation.Response{Resp: &http.Response{Request: &http.Request{URL: parsed.URL}}, Reader: documentReader}
+
+	links := make(map[string]*navigation.LinkContext)
+	for _, req := range bodyATagParser(resp) {
+		links[req.URL] = req.Link
+	}
+	require.Equal(t, &navigation.LinkContext{Text: "Home", Location: "nav"}, links["https://example.com/home"])
+	require.Equal(t, &navigation.LinkContext{Text: "our plans", Heading: "Pricing", Location: "main"}, links["https://example.com/plans"])
+	require.Equal(t, &navigation.LinkContext{Text: "Download installer", Heading: "Downloads", Location: "main"}, links["https://example.com/download"])
+	require.Equal(t, &navigation.LinkContext{Text: "Legal", Heading: "Downloads", Location: "footer"}, links["https://example.com/legal"])
+}
+
+func TestSkipNonCanonical(t *testing.T) {
+	parse := func(pageURL, body string) []string {
+		parsed, _ := urlutil.Parse(pageURL)
+		documentReader, _ := goquery.NewDocumentFromReader(strings.NewReader(body))
+		resp := &navigation.Response{Resp: &http.Response{Request: &http.Request{URL: parsed.URL}}, Reader: documentReader}
+		p := NewResponseParser()
+		p.InitWithOptions(&Options{SkipNonCanonical: true})
+		var urls []string
+		for _, req := range p.ParseResponse(resp) {
+			urls = append(urls, req.URL)
+		}
+		return urls
+	}
+
+	body := `<link rel="canonical" href="/article"><link rel="amphtml" href="/article/amp">
+<link rel="alternate" media="only screen and (max-width: 640px)" href="https://m.example.com/article">
+<link rel="alternate" type="application/rss+xml" href="/feed"><a href="/about">About</a>`
+	require.Equal(t, []string{"https://example.com/article"}, parse("https://example.com/article?utm_source=x", body), "only the canonical page of duplicates should be crawled")
+	require.Equal(t, []string{"https://example.com/about", "https://example.com/article", "https://example.com/feed"}, parse("https://example.com/article", body), "amp and mobile alternates should be skipped")
+}
+
+func TestHandlerAttrParser(t *testing.T) {
+	parsed, _ := urlutil.Parse("https://example.com/shop/")
+	body := `<div onclick="location.href='/cart'">Cart</div>
+<span onclick="window.open('details.html', '_blank')">Details</span>
+<p onmousedown="fetch('/api/rows?id=1').then(r => r.json())"></p>
+<li data-url="/category/1" data-href="https://cdn.example.com/promo"></li>
+<input type="submit" formaction="/checkout">
+<button formaction="/button">Send</button>
+<a onclick="return false">Noop</a>`
+	documentReader, _ := goquery.NewDocumentFromReader(strings.NewReader(body))
+	resp := &navigation.Response{Resp: &http.Response{Request: &http.Request{URL: parsed.URL}}, Reader: documentReader}
+
+	parse := newBodyHandlerAttrParser([]string{"onclick", "onmousedown", "data-url", "data-href", "formaction"})
+	var urls []string
+	for _, req := range parse(resp) {
+		urls = append(urls, req.Tag+" "+req.Attribute+" "+req.URL)
+	}
+	require.Equal(t, []string{
+		"div onclick https://example.com/cart",
+		"span onclick https://example.com/shop/details.html",
+		"p onmousedown https://example.com/api/rows?id=1",
+		"li data-url https://example.com/category/1",
+		"li data-href https://cdn.example.com/promo",
+		"input formaction https://example.com/checkout",
+	}, urls)
+}
+
+func TestClientRedirectParser(t *testing.T) {
+	parsed, _ := urlutil.Parse("https://example.com/old/")
+	body := `<html><head>
+<meta http-equiv="Refresh" content="0; URL='/new'">
+<meta name="description" content="url=/ignored">
+<script>if (!window.app) { window.location = "landing.html"; }</script>
+<script>location.replace('https://example.com/login?next=/old/');</script>
+<script>var location_href = "/ignored"; el.location = '/ignored';</script>
+<script src="/redirect.js"></script>
+</head></html>`
+	newResponse := func() *navigation.Response {
+		documentReader, _ := goquery.NewDocumentFromReader(strings.NewReader(body))
+		return &navigation.Response{Resp: &http.Response{Request: &http.Request{URL: parsed.URL}}, Reader: documentReader}
+	}
+
+	var urls []string
+	for _, req := range bodyClientRedirectParser(newResponse()) {
+		require.Equal(t, ClientRedirectTag, req.Tag)
+		urls = append(urls, req.Attribute+" "+req.URL)
+	}
+	require.Equal(t, []string{
+		"meta-refresh https://example.com/new",
+		"script https://example.com/old/landing.html",
+		"script https://example.com/login?next=/old/",
+	}, urls)
+
+	t.Run("disabled-redirects", func(t *testing.T) {
+		p := NewResponseParser()
+		p.InitWithOptions(&Options{DisableRedirects: true, ClientRedirects: true})
+		for _, req := range p.ParseResponse(newResponse()) {
+			require.NotEqual(t, "https://example.com/new", req.URL, "meta refresh targets should not be followed")
+			require.NotEqual(t, ClientRedirectTag, req.Tag)
+		}
+	})
+}
+
+func TestBaseHrefResolution(t *testing.T) {
+	parsed, _ := urlutil.Parse("https://example.com/app/page")
+	body := `<html><head>
+<base href="/static/v2/">
+<base href="/ignored/">
+<link rel="preload" as="fetch" href="api/config.json" crossorigin>
+<link rel="preload" as="image" href="hero.png" imagesrcset="hero-480.png 480w, hero-800.png 800w">
+</head><body>
+<a href="docs">Docs</a>
+<picture>
+<source srcset="banner.webp 1x, banner@2x.webp 2x" type="image/webp">
+<img src="banner.jpg">
+</picture>
+</body></html>`
+	documentReader, _ := goquery.NewDocumentFromReader(strings.NewReader(body))
+	resp := &navigation.Response{Resp: &http.Response{
+		Request: &http.Request{URL: parsed.URL},
+		Header:  http.Header{"Content-Type": []string{"text/html"}, "Content-Location": []string{"page.html"}},
+	}, Reader: documentReader, Body: body}
+
+	urls := make(map[string]string)
+	for _, req := range NewResponseParser().ParseResponse(resp) {
+		urls[req.URL] = req.Tag + " " + req.Attribute
+	}
+	require.Equal(t, "https://example.com/static/v2/", resp.BaseURL.String(), "the first base element should be used")
+	require.Equal(t, "header content-location", urls["https://example.com/app/page.html"], "headers should be resolved against the request url")
+	require.Equal(t, "a href", urls["https://example.com/static/v2/docs"])
+	require.Equal(t, "link preload-fetch", urls["https://example.com/static/v2/api/config.json"])
+	require.Equal(t, "link imagesrcset", urls["https://example.com/static/v2/hero-800.png"])
+	require.Equal(t, "picture srcset", urls["https://example.com/static/v2/banner@2x.webp"])
+	require.Equal(t, "img src", urls["https://example.com/static/v2/banner.jpg"])
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+)
+
+// -------------------------------------------------------------------------
+// Begin client side redirect parsers
+// -------------------------------------------------------------------------
+
+// ClientRedirectTag is the tag of the requests for client side redirects
+const ClientRedirectTag = "redirect"
+
+var (
+	// metaRefreshRegex matches the url of a meta refresh content
+	// (eg. 0; url=/next, 5;URL='/next')
+	metaRefreshRegex = regexp.MustCompile(`(?i)^\s*\d*(?:\.\d*)?\s*[;,]?\s*url\s*=\s*['"]?([^'"\s]+)`)
+	// scriptRedirectRegex matches the urls of simple script redirects
+	// (eg. window.location = '/a', location.href="/a", location.replace('/a'))
+	scriptRedirectRegex = regexp.MustCompile(`(?:^|[^\w.])(?:(?:window|document|top|self)\.)?location(?:\.href)?\s*=\s*["']([^"']+)["']|location\.(?:assign|replace)\(\s*["']([^"']+)["']`)
+)
+
+// clientRedirect is a redirect performed by the document of a response
+type clientRedirect struct {
+	url       string
+	attribute string
+}
+
+// clientRedirects returns the meta refresh and script redirects of the response
+func clientRedirects(resp *navigation.Response) []clientRedirect {
+	var redirects []clientRedirect
+	resp.Reader.Find("meta[http-equiv]").Each(func(i int, item *goquery.Selection) {
+		equiv, _ := item.Attr("http-equiv")
+		if !strings.EqualFold(strings.TrimSpace(equiv), "refresh") {
+			return
+		}
+		content, _ := item.Attr("content")
+		if match := metaRefreshRegex.FindStringSubmatch(content); match != nil {
+			redirects = append(redirects, clientRedirect{url: match[1], attribute: "meta-refresh"})
+		}
+	})
+	resp.Reader.Find("script").Each(func(i int, item *goquery.Selection) {
+		if _, ok := item.Attr("src"); ok {
+			return
+		}
+		for _, match := range scriptRedirectRegex.FindAllStringSubmatch(item.Text(), -1) {
+			url := match[1]
+			if url == "" {
+				url = match[2]
+			}
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(url)), "javascript:") {
+				continue
+			}
+			redirects = append(redirects, clientRedirect{url: url, attribute: "script"})
+		}
+	})
+	return redirects
+}
+
+// bodyClientRedirectParser parses the meta refresh and script redirects of the response
+func bodyClientRedirectParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+	for _, redirect := range clientRedirects(resp) {
+		navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(redirect.url, resp.Resp.Request.URL.String(), ClientRedirectTag, redirect.attribute, resp))
+	}
+	return
+}
+
+// filterClientRedirects drops the requests for the client side redirect
+// targets of the response, which are not followed like redirects when
+// redirects are disabled.
+func filterClientRedirects(resp *navigation.Response, navigationRequests []*navigation.Request) []*navigation.Request {
+	if resp.Reader == nil || resp.Resp == nil || resp.Resp.Request == nil {
+		return navigationRequests
+	}
+	redirects := clientRedirects(resp)
+	if len(redirects) == 0 {
+		return navigationRequests
+	}
+	targets := make(map[string]struct{}, len(redirects))
+	for _, redirect := range redirects {
+		targets[resp.AbsoluteURL(redirect.url)] = struct{}{}
+	}
+	filtered := navigationRequests[:0]
+	for _, req := range navigationRequests {
+		if _, ok := targets[req.URL]; ok {
+			continue
+		}
+		filtered = append(filtered, req)
+	}
+	return filtered
+}
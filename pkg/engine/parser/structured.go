@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"sort"
+	"strings"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+)
+
+// -------------------------------------------------------------------------
+// Begin structured api response parsers
+// -------------------------------------------------------------------------
+
+// maxStructuredNodes is the maximum number of values walked per response
+const maxStructuredNodes = 10000
+
+// isURLShaped returns true if the value looks like an absolute url
+// or an absolute path
+func isURLShaped(value string) bool {
+	if len(value) < 2 || len(value) > 2048 || strings.ContainsAny(value, " \t\r\n<>\"{}") {
+		return false
+	}
+	if lower := strings.ToLower(value); strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return true
+	}
+	// absolute paths, excluding protocol relative urls and comments
+	return value[0] == '/' && value[1] != '/' && value[1] != '*'
+}
+
+// jsonResponseParser parses url-shaped values and hateoas links of json responses
+func jsonResponseParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+	contentType := resp.Resp.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "json") && !strings.HasSuffix(resp.Resp.Request.URL.Path, ".json") {
+		return
+	}
+	var document interface{}
+	if err := json.Unmarshal([]byte(resp.Body), &document); err != nil {
+		return
+	}
+
+	source := resp.Resp.Request.URL.String()
+	nodes := 0
+	var walk func(key string, value interface{})
+	walk = func(key string, value interface{}) {
+		if nodes >= maxStructuredNodes {
+			return
+		}
+		nodes++
+		switch value := value.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(value))
+			for childKey := range value {
+				keys = append(keys, childKey)
+			}
+			// walk keys in order so extraction is deterministic
+			sort.Strings(keys)
+			for _, childKey := range keys {
+				walk(childKey, value[childKey])
+			}
+		case []interface{}:
+			for _, child := range value {
+				walk(key, child)
+			}
+		case string:
+			if isURLShaped(value) {
+				navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(value, source, "json", key, resp))
+			}
+		}
+	}
+	walk("", document)
+	return
+}
+
+// xmlURLElements are the elements whose text content is a link
+var xmlURLElements = map[string]struct{}{
+	"link": {}, "loc": {}, "url": {}, "uri": {}, "href": {}, "location": {}, "id": {}, "guid": {}, "enclosure": {},
+}
+
+// xmlResponseParser parses link attributes and url-shaped link elements of xml responses
+func xmlResponseParser(resp *navigation.Response) (navigationRequests []*navigation.Request) {
+	contentType := resp.Resp.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "xml") || strings.Contains(contentType, "html") {
+		return
+	}
+
+	source := resp.Resp.Request.URL.String()
+	decoder := xml.NewDecoder(strings.NewReader(resp.Body))
+	decoder.Strict = false
+
+	var element string
+	for nodes := 0; nodes < maxStructuredNodes; nodes++ {
+		token, err := decoder.Token()
+		if err != nil {
+			return
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			element = strings.ToLower(token.Name.Local)
+			for _, attr := range token.Attr {
+				switch strings.ToLower(attr.Name.Local) {
+				case "href", "src", "url", "uri":
+					if isURLShaped(attr.Value) {
+						navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(attr.Value, source, "xml", element, resp))
+					}
+				}
+			}
+		case xml.CharData:
+			if _, ok := xmlURLElements[element]; !ok {
+				continue
+			}
+			if value := strings.TrimSpace(string(token)); isURLShaped(value) {
+				navigationRequests = append(navigationRequests, navigation.NewNavigationRequestURLFromResponse(value, source, "xml", element, resp))
+			}
+		case xml.EndElement:
+			element = ""
+		}
+	}
+	return
+}
@@ -13,16 +13,19 @@ import (
 	"github.com/projectdiscovery/katana/pkg/engine/common"
 	"github.com/projectdiscovery/katana/pkg/navigation"
 	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/katana/pkg/utils/headerrules"
 	"github.com/projectdiscovery/retryablehttp-go"
 	"github.com/projectdiscovery/utils/errkit"
 	mapsutil "github.com/projectdiscovery/utils/maps"
 )
 
-// makeRequest makes a request to a URL returning a response interface.
-func (c *Crawler) makeRequest(s *common.CrawlSession, request *navigation.Request) (*navigation.Response, error) {
+// MakeRequest makes a request to a URL returning a response interface.
+func (c *Crawler) MakeRequest(s *common.CrawlSession, request *navigation.Request) (*navigation.Response, error) {
 	response := &navigation.Response{
 		Depth:        request.Depth + 1,
 		RootHostname: s.Hostname,
+		KeepFragment: c.Options.Options.KeepFragments,
+		SourceChain:  request.SourceChain,
 	}
 	ctx := context.WithValue(s.Ctx, navigation.Depth{}, request.Depth)
 	httpReq, err := http.NewRequestWithContext(ctx, request.Method, request.URL, nil)
@@ -36,7 +39,11 @@ func (c *Crawler) makeRequest(s *common.CrawlSession, request *navigation.Reques
 	if err != nil {
 		return response, err
 	}
-	req.Header.Set("User-Agent", utils.WebUserAgent())
+	if c.Options.BlockTracker != nil {
+		req.Header.Set("User-Agent", c.Options.BlockTracker.UserAgent(req.URL.Hostname()))
+	} else {
+		req.Header.Set("User-Agent", utils.WebUserAgent())
+	}
 
 	// Set the headers for the request.
 	for k, v := range request.Headers {
@@ -53,6 +60,14 @@ func (c *Crawler) makeRequest(s *common.CrawlSession, request *navigation.Reques
 		}
 	}
 
+	// Set the headers of the header rules matching the request.
+	for k, v := range c.Options.HeaderRules.Headers(req.Request.URL) {
+		req.Header.Set(k, headerrules.Merge(req.Header.Get(k), k, v))
+		if k == "Host" {
+			req.Host = v
+		}
+	}
+
 	// Apply cookies
 	if c.Jar != nil {
 		cookies := c.Jar.Cookies(req.Request.URL)
@@ -91,6 +106,7 @@ func (c *Crawler) makeRequest(s *common.CrawlSession, request *navigation.Reques
 	if resp.StatusCode == http.StatusSwitchingProtocols {
 		return response, nil
 	}
+	response.RedirectChain = navigation.NewRedirectChain(resp)
 
 	limitReader := io.LimitReader(resp.Body, int64(c.Options.Options.BodyReadSize))
 	data, err := io.ReadAll(limitReader)
@@ -134,6 +150,7 @@ func (c *Crawler) makeRequest(s *common.CrawlSession, request *navigation.Reques
 	if c.Options.Options.FormExtraction {
 		response.Forms = append(response.Forms, utils.ParseFormFields(response.Reader)...)
 	}
+	response.Relations = utils.ParseLinkRelations(response)
 
 	// Use the actual length of the read data as ContentLength
 	resp.ContentLength = int64(len(data))
@@ -34,7 +34,7 @@ func (c *Crawler) Crawl(rootURL string) error {
 	}
 	defer crawlSession.CancelFunc()
 	gologger.Info().Msgf("Started standard crawling for => %v", rootURL)
-	if err := c.Do(crawlSession, c.makeRequest); err != nil {
+	if err := c.Do(crawlSession, c.MakeRequest); err != nil {
 		return errkit.Wrap(err, "standard")
 	}
 	return nil
@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+type burpItems struct {
+	Items []burpItem `xml:"item"`
+}
+
+type burpItem struct {
+	URL      string      `xml:"url"`
+	Protocol string      `xml:"protocol"`
+	Method   string      `xml:"method"`
+	Request  burpPayload `xml:"request"`
+}
+
+type burpPayload struct {
+	Base64 bool   `xml:"base64,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// parseBurp parses the requests from a Burp Suite XML items export
+func parseBurp(reader io.Reader) ([]*navigation.Request, error) {
+	var items burpItems
+	if err := xml.NewDecoder(reader).Decode(&items); err != nil {
+		return nil, errkit.Wrap(err, "importer: could not decode burp export")
+	}
+
+	requests := make([]*navigation.Request, 0, len(items.Items))
+	for _, item := range items.Items {
+		raw := []byte(item.Request.Value)
+		if item.Request.Base64 {
+			decoded, err := base64.StdEncoding.DecodeString(item.Request.Value)
+			if err != nil {
+				continue
+			}
+			raw = decoded
+		}
+		if len(raw) == 0 {
+			if item.URL != "" {
+				requests = append(requests, &navigation.Request{Method: item.Method, URL: item.URL})
+			}
+			continue
+		}
+		req, err := requestFromRaw(raw, item.URL, item.Protocol)
+		if err != nil {
+			continue
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request harRequest `json:"request"`
+}
+
+type harRequest struct {
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Headers  []harNameValue  `json:"headers"`
+	PostData *harRequestData `json:"postData,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequestData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// parseHAR parses the requests from a HTTP Archive (HAR) file
+func parseHAR(reader io.Reader) ([]*navigation.Request, error) {
+	var har harFile
+	if err := json.NewDecoder(reader).Decode(&har); err != nil {
+		return nil, errkit.Wrap(err, "importer: could not decode har file")
+	}
+
+	requests := make([]*navigation.Request, 0, len(har.Log.Entries))
+	for _, entry := range har.Log.Entries {
+		if entry.Request.URL == "" {
+			continue
+		}
+		headers := make(map[string]string, len(entry.Request.Headers))
+		for _, header := range entry.Request.Headers {
+			// HTTP/2 pseudo headers (:authority, :path etc) are not reusable
+			if header.Name == "" || header.Name[0] == ':' {
+				continue
+			}
+			headers[header.Name] = header.Value
+		}
+		req := &navigation.Request{
+			Method:  entry.Request.Method,
+			URL:     entry.Request.URL,
+			Headers: filterHeaders(headers),
+		}
+		if entry.Request.PostData != nil {
+			req.Body = entry.Request.PostData.Text
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
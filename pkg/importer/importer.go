@@ -0,0 +1,161 @@
+// Package importer implements parsers for proxy history exports
+// (Burp Suite XML items, OWASP ZAP "Export Messages" text files) and
+// HAR archives which are used to seed the crawler with previously
+// observed requests. ZAP session databases are not supported, ZAP
+// sessions can be exported as HAR or messages instead.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/utils/errkit"
+	urlutil "github.com/projectdiscovery/utils/url"
+)
+
+// Format is the format of an imported file
+type Format string
+
+const (
+	FormatHAR  Format = "har"
+	FormatBurp Format = "burp"
+	FormatZAP  Format = "zap"
+)
+
+// ignoredHeaders are headers which are not reused from imported
+// requests as they are managed by the http client itself.
+var ignoredHeaders = map[string]struct{}{
+	"content-length":    {},
+	"connection":        {},
+	"accept-encoding":   {},
+	"proxy-connection":  {},
+	"transfer-encoding": {},
+	"host":              {},
+}
+
+// ParseFile parses the requests from a Burp, ZAP or HAR export.
+// The format is detected from the file extension and content.
+func ParseFile(file string) ([]*navigation.Request, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errkit.Wrap(err, "importer: could not read file")
+	}
+	format, err := DetectFormat(file, data)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bytes.NewReader(data), format)
+}
+
+// Parse parses the requests from reader in the specified format
+func Parse(reader io.Reader, format Format) ([]*navigation.Request, error) {
+	var (
+		requests []*navigation.Request
+		err      error
+	)
+	switch format {
+	case FormatHAR:
+		requests, err = parseHAR(reader)
+	case FormatBurp:
+		requests, err = parseBurp(reader)
+	case FormatZAP:
+		requests, err = parseZAP(reader)
+	default:
+		return nil, errkit.Newf("importer: unsupported format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range requests {
+		req.Tag = "import"
+		req.Attribute = string(format)
+	}
+	return requests, nil
+}
+
+// DetectFormat detects the format of the export file
+func DetectFormat(file string, data []byte) (Format, error) {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".har":
+		return FormatHAR, nil
+	case ".xml":
+		return FormatBurp, nil
+	}
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatHAR, nil
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatBurp, nil
+	case zapMessageSeparator.Match(trimmed):
+		return FormatZAP, nil
+	default:
+		return "", errkit.New("importer: unknown export format, expected burp xml, zap messages or har")
+	}
+}
+
+// RootURLs returns the unique scheme://host roots of the requests
+func RootURLs(requests []*navigation.Request) []string {
+	seen := make(map[string]struct{})
+	var roots []string
+	for _, req := range requests {
+		parsed, err := urlutil.Parse(req.URL)
+		if err != nil || parsed.Host == "" {
+			continue
+		}
+		root := parsed.Scheme + "://" + parsed.Host
+		if _, ok := seen[root]; ok {
+			continue
+		}
+		seen[root] = struct{}{}
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// requestFromRaw creates a navigation request from a raw http request.
+// The URL is used as the request target when the raw request does not
+// contain an absolute URL, otherwise it is built from the scheme and
+// the Host header of the raw request.
+func requestFromRaw(raw []byte, URL, scheme string) (*navigation.Request, error) {
+	httpReq, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		return nil, errkit.Wrap(err, "importer: could not read raw request")
+	}
+	body, _ := io.ReadAll(httpReq.Body)
+
+	if URL == "" {
+		switch {
+		case httpReq.URL.IsAbs():
+			URL = httpReq.URL.String()
+		case scheme != "":
+			URL = scheme + "://" + httpReq.Host + httpReq.URL.RequestURI()
+		default:
+			return nil, errkit.New("importer: could not determine scheme of raw request")
+		}
+	}
+	return &navigation.Request{
+		Method:  httpReq.Method,
+		URL:     URL,
+		Body:    string(body),
+		Headers: filterHeaders(utils.FlattenHeaders(httpReq.Header)),
+	}, nil
+}
+
+func filterHeaders(headers map[string]string) map[string]string {
+	filtered := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if _, ok := ignoredHeaders[strings.ToLower(k)]; ok {
+			continue
+		}
+		filtered[k] = v
+	}
+	return filtered
+}
@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHAR(t *testing.T) {
+	content := `{"log":{"entries":[
+		{"request":{"method":"GET","url":"https://example.com/a","headers":[{"name":":authority","value":"example.com"},{"name":"Cookie","value":"session=1"},{"name":"Content-Length","value":"0"}]}},
+		{"request":{"method":"POST","url":"https://example.com/login","headers":[],"postData":{"mimeType":"application/x-www-form-urlencoded","text":"user=admin"}}}
+	]}}`
+
+	requests, err := Parse(strings.NewReader(content), FormatHAR)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	require.Equal(t, "https://example.com/a", requests[0].URL)
+	require.Equal(t, map[string]string{"Cookie": "session=1"}, requests[0].Headers)
+	require.Equal(t, http.MethodPost, requests[1].Method)
+	require.Equal(t, "user=admin", requests[1].Body)
+	require.Equal(t, "import", requests[1].Tag)
+}
+
+func TestParseBurp(t *testing.T) {
+	raw := "POST /api/items HTTP/1.1\r\nHost: example.com\r\nAuthorization: Bearer token\r\nContent-Length: 7\r\n\r\nname=go"
+	content := `<?xml version="1.0"?>
+<items burpVersion="2023.1">
+  <item>
+    <url><![CDATA[https://example.com/api/items]]></url>
+    <method><![CDATA[POST]]></method>
+    <request base64="true"><![CDATA[` + base64.StdEncoding.EncodeToString([]byte(raw)) + `]]></request>
+  </item>
+</items>`
+
+	requests, err := Parse(strings.NewReader(content), FormatBurp)
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, "https://example.com/api/items", requests[0].URL)
+	require.Equal(t, http.MethodPost, requests[0].Method)
+	require.Equal(t, "name=go", requests[0].Body)
+	require.Equal(t, "Bearer token", requests[0].Headers["Authorization"])
+	require.NotContains(t, requests[0].Headers, "Content-Length")
+}
+
+func TestParseZAP(t *testing.T) {
+	content := "==== 1 ==========\n" +
+		"GET http://example.com/index.php HTTP/1.1\nHost: example.com\nCookie: PHPSESSID=abc\n\n" +
+		"HTTP/1.1 200 OK\nContent-Type: text/html\n\n<html></html>\n" +
+		"==== 2 ==========\n" +
+		"GET http://example.com/about HTTP/1.1\nHost: example.com\n\n"
+
+	requests, err := Parse(strings.NewReader(content), FormatZAP)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	require.Equal(t, "http://example.com/index.php", requests[0].URL)
+	require.Equal(t, "PHPSESSID=abc", requests[0].Headers["Cookie"])
+	require.Equal(t, "http://example.com/about", requests[1].URL)
+
+	require.ElementsMatch(t, []string{"http://example.com"}, RootURLs(requests))
+}
+
+func TestParseBurpScheme(t *testing.T) {
+	raw := "GET /account HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	content := `<?xml version="1.0"?>
+<items>
+  <item>
+    <protocol>https</protocol>
+    <method>GET</method>
+    <request base64="false"><![CDATA[` + raw + `]]></request>
+  </item>
+</items>`
+
+	requests, err := Parse(strings.NewReader(content), FormatBurp)
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, "https://example.com/account", requests[0].URL)
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		file   string
+		data   string
+		format Format
+	}{
+		{file: "session.har", format: FormatHAR},
+		{file: "history.xml", format: FormatBurp},
+		{file: "export", data: ` {"log":{}}`, format: FormatHAR},
+		{file: "export.txt", data: "==== 1 ==========\nGET http://example.com/ HTTP/1.1\n", format: FormatZAP},
+	}
+	for _, test := range tests {
+		format, err := DetectFormat(test.file, []byte(test.data))
+		require.NoError(t, err)
+		require.Equal(t, test.format, format)
+	}
+
+	_, err := DetectFormat("urls.txt", []byte("https://example.com/"))
+	require.Error(t, err)
+}
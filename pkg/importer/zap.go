@@ -0,0 +1,39 @@
+package importer
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// zapMessageSeparator matches the separator lines of a ZAP
+// "Export Messages to File" export (eg. ==== 12 ==========)
+var zapMessageSeparator = regexp.MustCompile(`(?m)^==== \d+ =+\r?$`)
+
+// parseZAP parses the requests from a ZAP messages export
+func parseZAP(reader io.Reader) ([]*navigation.Request, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errkit.Wrap(err, "importer: could not read zap export")
+	}
+
+	var requests []*navigation.Request
+	for _, message := range zapMessageSeparator.Split(string(data), -1) {
+		message := bytes.TrimLeft([]byte(message), "\r\n")
+		if len(message) == 0 {
+			continue
+		}
+		// Only the request part is parsed, the response following
+		// it is ignored by http.ReadRequest. ZAP writes absolute
+		// request targets so the scheme is taken from them.
+		req, err := requestFromRaw(message, "", "")
+		if err != nil {
+			continue
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
@@ -0,0 +1,179 @@
+// Package defectdojo writes crawl findings and classified endpoints as
+// DefectDojo "Generic Findings Import" JSON so that they can be imported
+// into vulnerability management platforms.
+package defectdojo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/utils/errkit"
+	"go.uber.org/multierr"
+)
+
+// DefaultPageTypes are the classified page types reported by default
+var DefaultPageTypes = []string{"login", "registration", "password_reset", "admin", "upload", "error"}
+
+// Report is a DefectDojo generic findings import document
+type Report struct {
+	Findings []*Finding `json:"findings"`
+}
+
+// Finding is a DefectDojo generic finding
+type Finding struct {
+	Title            string   `json:"title"`
+	Description      string   `json:"description"`
+	Severity         string   `json:"severity"`
+	Date             string   `json:"date"`
+	Endpoints        []string `json:"endpoints,omitempty"`
+	StaticFinding    bool     `json:"static_finding"`
+	DynamicFinding   bool     `json:"dynamic_finding"`
+	UniqueIDFromTool string   `json:"unique_id_from_tool"`
+	VulnIDFromTool   string   `json:"vuln_id_from_tool,omitempty"`
+}
+
+// Writer is an output writer which additionally collects the
+// findings and the classified endpoints into a DefectDojo report.
+type Writer struct {
+	output.Writer
+	file      string
+	pageTypes map[string]struct{}
+
+	mu       sync.Mutex
+	findings map[string]*Finding
+	order    []string
+}
+
+// NewWriter wraps writer writing the DefectDojo report to file on close.
+// Endpoints classified with one of pageTypes are reported as findings.
+func NewWriter(writer output.Writer, file string, pageTypes []string) *Writer {
+	w := &Writer{
+		Writer:    writer,
+		file:      file,
+		pageTypes: make(map[string]struct{}, len(pageTypes)),
+		findings:  make(map[string]*Finding),
+	}
+	for _, pageType := range pageTypes {
+		w.pageTypes[strings.ToLower(pageType)] = struct{}{}
+	}
+	return w
+}
+
+// Write writes the result and collects it when it is reportable
+func (w *Writer) Write(result *output.Result) error {
+	if err := w.Writer.Write(result); err != nil {
+		return err
+	}
+	if finding := w.convert(result); finding != nil {
+		w.mu.Lock()
+		if _, ok := w.findings[finding.UniqueIDFromTool]; !ok {
+			w.findings[finding.UniqueIDFromTool] = finding
+			w.order = append(w.order, finding.UniqueIDFromTool)
+		}
+		w.mu.Unlock()
+	}
+	return nil
+}
+
+// Close writes the report and closes the underlying writer
+func (w *Writer) Close() error {
+	return multierr.Combine(w.writeReport(), w.Writer.Close())
+}
+
+func (w *Writer) writeReport() error {
+	w.mu.Lock()
+	report := &Report{Findings: make([]*Finding, 0, len(w.order))}
+	for _, id := range w.order {
+		report.Findings = append(report.Findings, w.findings[id])
+	}
+	w.mu.Unlock()
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errkit.Wrap(err, "defectdojo: could not marshal report")
+	}
+	if err := os.WriteFile(w.file, data, 0644); err != nil {
+		return errkit.Wrap(err, "defectdojo: could not write report")
+	}
+	return nil
+}
+
+// convert converts a result to a finding returning nil
+// if the result is neither a finding nor a classified page.
+func (w *Writer) convert(result *output.Result) *Finding {
+	if result.Request == nil || result.Error != "" {
+		return nil
+	}
+	date := result.Timestamp
+	if date.IsZero() {
+		date = time.Now()
+	}
+	endpoint := result.Request.URL
+
+	if result.Finding != nil {
+		matchedAt := result.Finding.MatchedAt
+		if matchedAt == "" {
+			matchedAt = endpoint
+		}
+		title := result.Finding.Name
+		if title == "" {
+			title = result.Finding.TemplateID
+		}
+		return &Finding{
+			Title:            title,
+			Description:      fmt.Sprintf("%s (%s) reported by %s at %s", title, result.Finding.TemplateID, result.Request.Tag, matchedAt),
+			Severity:         severity(result.Finding.Severity),
+			Date:             date.Format(time.DateOnly),
+			Endpoints:        []string{matchedAt},
+			DynamicFinding:   true,
+			UniqueIDFromTool: uniqueID(result.Finding.TemplateID, matchedAt),
+			VulnIDFromTool:   result.Finding.TemplateID,
+		}
+	}
+
+	if result.Response == nil || result.Response.KnowledgeBase == nil {
+		return nil
+	}
+	pageType, _ := result.Response.KnowledgeBase["PageType"].(string)
+	if _, ok := w.pageTypes[strings.ToLower(pageType)]; !ok {
+		return nil
+	}
+	return &Finding{
+		Title:            fmt.Sprintf("Interesting endpoint: %s page", pageType),
+		Description:      fmt.Sprintf("Endpoint %s was classified as a %s page while crawling (source: %s)", endpoint, pageType, result.Request.Source),
+		Severity:         "Info",
+		Date:             date.Format(time.DateOnly),
+		Endpoints:        []string{endpoint},
+		DynamicFinding:   true,
+		UniqueIDFromTool: uniqueID("katana-page-"+pageType, endpoint),
+		VulnIDFromTool:   "katana-page-" + pageType,
+	}
+}
+
+// severity maps a finding severity to a DefectDojo severity
+func severity(value string) string {
+	switch strings.ToLower(value) {
+	case "critical":
+		return "Critical"
+	case "high":
+		return "High"
+	case "medium":
+		return "Medium"
+	case "low":
+		return "Low"
+	default:
+		return "Info"
+	}
+}
+
+func uniqueID(values ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(values, "|")))
+	return hex.EncodeToString(hash[:16])
+}
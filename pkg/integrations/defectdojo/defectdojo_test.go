@@ -0,0 +1,59 @@
+package defectdojo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWriter struct{ results int }
+
+func (m *mockWriter) Close() error                      { return nil }
+func (m *mockWriter) Write(result *output.Result) error { m.results++; return nil }
+func (m *mockWriter) WriteErr(*output.Error) error      { return nil }
+
+func TestWriter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "defectdojo.json")
+	mock := &mockWriter{}
+	writer := NewWriter(mock, file, DefaultPageTypes)
+
+	timestamp := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	finding := &output.Result{
+		Timestamp: timestamp,
+		Request:   &navigation.Request{URL: "https://example.com/.git/config", Tag: "nuclei"},
+		Finding:   &output.Finding{TemplateID: "git-config", Name: "Git Config Disclosure", Severity: "medium", MatchedAt: "https://example.com/.git/config"},
+	}
+	results := []*output.Result{
+		finding,
+		finding,
+		{Timestamp: timestamp, Request: &navigation.Request{URL: "https://example.com/login"}, Response: &navigation.Response{KnowledgeBase: map[string]any{"PageType": "login"}}},
+		{Timestamp: timestamp, Request: &navigation.Request{URL: "https://example.com/blog"}, Response: &navigation.Response{KnowledgeBase: map[string]any{"PageType": "article"}}},
+		{Timestamp: timestamp, Request: &navigation.Request{URL: "https://example.com/"}},
+	}
+	for _, result := range results {
+		require.NoError(t, writer.Write(result))
+	}
+	require.NoError(t, writer.Close())
+	require.Equal(t, len(results), mock.results)
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	var report Report
+	require.NoError(t, json.Unmarshal(data, &report))
+	require.Len(t, report.Findings, 2)
+
+	require.Equal(t, "Git Config Disclosure", report.Findings[0].Title)
+	require.Equal(t, "Medium", report.Findings[0].Severity)
+	require.Equal(t, "2026-01-02", report.Findings[0].Date)
+	require.Equal(t, []string{"https://example.com/.git/config"}, report.Findings[0].Endpoints)
+	require.Equal(t, "git-config", report.Findings[0].VulnIDFromTool)
+
+	require.Equal(t, "Info", report.Findings[1].Severity)
+	require.Equal(t, []string{"https://example.com/login"}, report.Findings[1].Endpoints)
+}
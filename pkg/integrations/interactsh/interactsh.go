@@ -0,0 +1,132 @@
+// Package interactsh correlates out-of-band interactions received by an
+// interactsh server with the form submissions which triggered them.
+package interactsh
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/interactsh/pkg/client"
+	"github.com/projectdiscovery/interactsh/pkg/server"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/utils/errkit"
+	"go.uber.org/multierr"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	// defaultCooldown is the time to wait for late interactions on close
+	defaultCooldown = 5 * time.Second
+)
+
+// Options contains the configuration for the interactsh client
+type Options struct {
+	// ServerURL is the interactsh server (default: the public servers)
+	ServerURL string
+	// Token is the authentication token for protected servers
+	Token string
+	// PollInterval is the interval between interaction polls
+	PollInterval time.Duration
+	// Cooldown is the time waited for late interactions on close
+	Cooldown time.Duration
+	// OnInteraction is called for each interaction correlated to a marker
+	OnInteraction func(*Marker, *server.Interaction)
+}
+
+// Marker is an interaction payload injected into a form submission
+type Marker struct {
+	// Action is the form action the marker was submitted to
+	Action string
+	// Method is the method of the submission carrying the marker
+	Method string
+	// Field is the form field or header carrying the marker
+	Field string
+	// Source is the page the form was submitted from
+	Source string
+	// SourceChain are the hops that led to the form
+	SourceChain []navigation.SourceLink
+}
+
+// Client polls an interactsh server for the interactions of the markers
+type Client struct {
+	options Options
+	client  *client.Client
+	// url returns a new interaction url of the client
+	url func() string
+
+	markers   map[string]*Marker
+	markersMu sync.RWMutex
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// New registers a new interactsh client and starts polling
+func New(options Options) (*Client, error) {
+	if options.PollInterval <= 0 {
+		options.PollInterval = defaultPollInterval
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = defaultCooldown
+	}
+
+	clientOptions := *client.DefaultOptions
+	if options.ServerURL != "" {
+		clientOptions.ServerURL = options.ServerURL
+	}
+	clientOptions.Token = options.Token
+	interactshClient, err := client.New(&clientOptions)
+	if err != nil {
+		return nil, errkit.Wrap(err, "interactsh: could not create client")
+	}
+
+	c := &Client{
+		options: options,
+		client:  interactshClient,
+		url:     interactshClient.URL,
+		markers: make(map[string]*Marker),
+	}
+	if err := interactshClient.StartPolling(options.PollInterval, c.handleInteraction); err != nil {
+		_ = interactshClient.Close()
+		return nil, errkit.Wrap(err, "interactsh: could not start polling")
+	}
+	return c, nil
+}
+
+// Marker returns a new interaction domain for the field of the submission
+func (c *Client) Marker(submission *utils.FormSubmission, field string) string {
+	domain := c.url()
+	uniqueID, _, _ := strings.Cut(domain, ".")
+
+	c.markersMu.Lock()
+	c.markers[strings.ToLower(uniqueID)] = &Marker{
+		Action:      submission.Action,
+		Method:      submission.Method,
+		Field:       field,
+		Source:      submission.Source,
+		SourceChain: submission.SourceChain,
+	}
+	c.markersMu.Unlock()
+	return domain
+}
+
+// Close waits for late interactions, stops polling and deregisters the
+// client. Calling it more than once returns the error of the first call.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		time.Sleep(c.options.Cooldown)
+		c.closeErr = multierr.Combine(c.client.StopPolling(), c.client.Close())
+	})
+	return c.closeErr
+}
+
+func (c *Client) handleInteraction(interaction *server.Interaction) {
+	c.markersMu.RLock()
+	marker, ok := c.markers[strings.ToLower(interaction.UniqueID)]
+	c.markersMu.RUnlock()
+	if ok && c.options.OnInteraction != nil {
+		c.options.OnInteraction(marker, interaction)
+	}
+}
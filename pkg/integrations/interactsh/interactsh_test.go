@@ -0,0 +1,47 @@
+package interactsh
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/interactsh/pkg/server"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarker(t *testing.T) {
+	var (
+		correlated *Marker
+		received   *server.Interaction
+	)
+	client := &Client{
+		options: Options{OnInteraction: func(marker *Marker, interaction *server.Interaction) {
+			correlated, received = marker, interaction
+		}},
+		url:     func() string { return "cn1ctq8h9gv8v4bl2q0gabcdefghijklm.oast.pro" },
+		markers: map[string]*Marker{},
+	}
+
+	submission := &utils.FormSubmission{
+		Action:      "https://example.com/login",
+		Method:      "GET",
+		Source:      "https://example.com/",
+		SourceChain: []navigation.SourceLink{{URL: "https://example.com/", Tag: "form", Attribute: "action"}},
+	}
+	domain := client.Marker(submission, "username")
+	require.Equal(t, "cn1ctq8h9gv8v4bl2q0gabcdefghijklm.oast.pro", domain)
+
+	client.handleInteraction(&server.Interaction{Protocol: "dns", UniqueID: "unknown"})
+	require.Nil(t, correlated, "interactions of unknown markers should be ignored")
+
+	interaction := &server.Interaction{Protocol: "http", UniqueID: "CN1CTQ8H9GV8V4BL2Q0GABCDEFGHIJKLM"}
+	client.handleInteraction(interaction)
+	require.Equal(t, &Marker{
+		Action:      submission.Action,
+		Method:      submission.Method,
+		Field:       "username",
+		Source:      submission.Source,
+		SourceChain: submission.SourceChain,
+	}, correlated)
+	require.Equal(t, interaction, received)
+}
@@ -0,0 +1,61 @@
+package interactsh
+
+import (
+	"github.com/projectdiscovery/interactsh/pkg/server"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"go.uber.org/multierr"
+)
+
+// Writer is an output writer which reports the form actions
+// that triggered out-of-band interactions.
+type Writer struct {
+	output.Writer
+	client *Client
+}
+
+// NewWriter wraps writer registering an interactsh client with options
+func NewWriter(writer output.Writer, options Options) (*Writer, error) {
+	w := &Writer{Writer: writer}
+	options.OnInteraction = w.writeInteraction
+
+	client, err := New(options)
+	if err != nil {
+		return nil, err
+	}
+	w.client = client
+	return w, nil
+}
+
+// FormMarkers returns a form marker injector for the fields and
+// the headers of form submissions backed by the interactsh client.
+func (w *Writer) FormMarkers(headers []string) *utils.FormMarkers {
+	return &utils.FormMarkers{Generate: w.client.Marker, Headers: headers}
+}
+
+// Close waits for late interactions before closing the underlying writer
+func (w *Writer) Close() error {
+	return multierr.Combine(w.client.Close(), w.Writer.Close())
+}
+
+func (w *Writer) writeInteraction(marker *Marker, interaction *server.Interaction) {
+	_ = w.Writer.Write(&output.Result{
+		Timestamp: interaction.Timestamp,
+		Request: &navigation.Request{
+			Method:      marker.Method,
+			URL:         marker.Action,
+			Source:      marker.Source,
+			SourceChain: marker.SourceChain,
+			Tag:         "interactsh",
+			Attribute:   marker.Field,
+		},
+		Finding: &output.Finding{
+			TemplateID: "interactsh-" + interaction.Protocol,
+			Name:       "out-of-band " + interaction.Protocol + " interaction from " + interaction.RemoteAddress,
+			Severity:   "info",
+			Type:       interaction.Protocol,
+			MatchedAt:  marker.Action,
+		},
+	})
+}
@@ -0,0 +1,206 @@
+// Package nuclei runs nuclei templates against endpoints as they are
+// discovered during the crawl, merging the findings into katana output.
+// Templates are executed by the nuclei engine embedded through its SDK.
+package nuclei
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/output"
+	nucleisdk "github.com/projectdiscovery/nuclei/v3/lib"
+	nucleioutput "github.com/projectdiscovery/nuclei/v3/pkg/output"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+const (
+	defaultBatchSize     = 25
+	defaultFlushInterval = 10 * time.Second
+	defaultQueueSize     = 1000
+)
+
+// Options contains the configuration for the nuclei scanner
+type Options struct {
+	// Tags are the template tags to execute
+	Tags []string
+	// BatchSize is the number of endpoints scanned per nuclei execution
+	BatchSize int
+	// FlushInterval is the maximum time an endpoint waits before being scanned
+	FlushInterval time.Duration
+	// QueueSize is the number of endpoints waiting to be scanned after
+	// which new endpoints are dropped instead of slowing down the crawl
+	QueueSize int
+	// OnFinding is called for each finding reported by nuclei
+	OnFinding func(*output.Finding)
+}
+
+// executeFunc executes the templates against a batch of endpoints
+type executeFunc func(ctx context.Context, endpoints []string) error
+
+// Scanner submits discovered endpoints to nuclei in batches
+type Scanner struct {
+	options Options
+	execute executeFunc
+	release func()
+
+	// mu guards endpoints against submissions after close
+	mu        sync.RWMutex
+	closed    bool
+	endpoints chan string
+	dropped   atomic.Int64
+
+	seen   map[string]struct{}
+	seenMu sync.Mutex
+	done   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New creates a new nuclei scanner with an embedded nuclei
+// engine and starts the batching loop
+func New(options Options) (*Scanner, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	engine, err := nucleisdk.NewThreadSafeNucleiEngineCtx(ctx, nucleisdk.DisableUpdateCheck())
+	if err != nil {
+		cancel()
+		return nil, errkit.Wrap(err, "nuclei: could not create engine")
+	}
+	engine.GlobalResultCallback(func(event *nucleioutput.ResultEvent) {
+		if options.OnFinding != nil {
+			options.OnFinding(findingFromEvent(event))
+		}
+	})
+
+	filters := nucleisdk.WithTemplateFilters(nucleisdk.TemplateFilters{Tags: options.Tags})
+	execute := func(ctx context.Context, endpoints []string) error {
+		return engine.ExecuteNucleiWithOptsCtx(ctx, endpoints, filters)
+	}
+	return newScanner(ctx, cancel, options, execute, engine.Close), nil
+}
+
+func newScanner(ctx context.Context, cancel context.CancelFunc, options Options, execute executeFunc, release func()) *Scanner {
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = defaultFlushInterval
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaultQueueSize
+	}
+
+	scanner := &Scanner{
+		options:   options,
+		execute:   execute,
+		release:   release,
+		endpoints: make(chan string, options.QueueSize),
+		seen:      make(map[string]struct{}),
+		done:      make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	go scanner.loop()
+	return scanner
+}
+
+// Submit queues an endpoint for scanning without blocking. Duplicate
+// endpoints are ignored and endpoints submitted while the queue is full
+// or after the scanner was closed are dropped. It returns true if the
+// endpoint was queued.
+func (s *Scanner) Submit(endpoint string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return false
+	}
+
+	s.seenMu.Lock()
+	if _, ok := s.seen[endpoint]; ok {
+		s.seenMu.Unlock()
+		return false
+	}
+	s.seen[endpoint] = struct{}{}
+	s.seenMu.Unlock()
+
+	select {
+	case s.endpoints <- endpoint:
+		return true
+	default:
+		s.dropped.Add(1)
+		return false
+	}
+}
+
+// Close scans the pending endpoints and waits for nuclei to finish
+func (s *Scanner) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.endpoints)
+	s.mu.Unlock()
+
+	<-s.done
+	s.cancel()
+	if s.release != nil {
+		s.release()
+	}
+	if dropped := s.dropped.Load(); dropped > 0 {
+		gologger.Warning().Msgf("nuclei: %d endpoints were not scanned as the scan queue was full", dropped)
+	}
+	return nil
+}
+
+func (s *Scanner) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.options.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]string, 0, s.options.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.execute(s.ctx, batch); err != nil {
+			gologger.Warning().Msgf("nuclei scan failed: %s", err)
+		}
+		batch = make([]string, 0, s.options.BatchSize)
+	}
+
+	for {
+		select {
+		case endpoint, ok := <-s.endpoints:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, endpoint)
+			if len(batch) >= s.options.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// findingFromEvent converts a nuclei result event to a finding
+func findingFromEvent(event *nucleioutput.ResultEvent) *output.Finding {
+	matchedAt := event.Matched
+	if matchedAt == "" {
+		matchedAt = event.Host
+	}
+	return &output.Finding{
+		TemplateID: event.TemplateID,
+		Name:       event.Info.Name,
+		Severity:   event.Info.SeverityHolder.Severity.String(),
+		Type:       event.Type,
+		MatchedAt:  matchedAt,
+	}
+}
@@ -0,0 +1,151 @@
+package nuclei
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/nuclei/v3/pkg/model"
+	"github.com/projectdiscovery/nuclei/v3/pkg/model/types/severity"
+	nucleioutput "github.com/projectdiscovery/nuclei/v3/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+type mockExecutor struct {
+	mu      sync.Mutex
+	batches [][]string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m *mockExecutor) execute(ctx context.Context, endpoints []string) error {
+	if m.started != nil {
+		m.started <- struct{}{}
+	}
+	if m.release != nil {
+		<-m.release
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches = append(m.batches, append([]string(nil), endpoints...))
+	return nil
+}
+
+func newTestScanner(options Options, executor *mockExecutor) *Scanner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return newScanner(ctx, cancel, options, executor.execute, nil)
+}
+
+func TestFindingFromEvent(t *testing.T) {
+	event := &nucleioutput.ResultEvent{
+		TemplateID: "git-config",
+		Info:       model.Info{Name: "Git Config Disclosure", SeverityHolder: severity.Holder{Severity: severity.Medium}},
+		Type:       "http",
+		Host:       "https://example.com",
+		Matched:    "https://example.com/.git/config",
+	}
+	finding := findingFromEvent(event)
+	require.Equal(t, "git-config", finding.TemplateID)
+	require.Equal(t, "Git Config Disclosure", finding.Name)
+	require.Equal(t, "medium", finding.Severity)
+	require.Equal(t, "https://example.com/.git/config", finding.MatchedAt)
+
+	event.Matched = ""
+	require.Equal(t, "https://example.com", findingFromEvent(event).MatchedAt)
+}
+
+func TestScannerBatching(t *testing.T) {
+	executor := &mockExecutor{}
+	scanner := newTestScanner(Options{BatchSize: 2, FlushInterval: time.Hour}, executor)
+
+	require.True(t, scanner.Submit("https://example.com/a"))
+	require.False(t, scanner.Submit("https://example.com/a"), "duplicate endpoint should be ignored")
+	require.True(t, scanner.Submit("https://example.com/b"))
+	require.True(t, scanner.Submit("https://example.com/c"))
+	require.NoError(t, scanner.Close())
+
+	require.Equal(t, [][]string{
+		{"https://example.com/a", "https://example.com/b"},
+		{"https://example.com/c"},
+	}, executor.batches)
+
+	require.False(t, scanner.Submit("https://example.com/d"), "submit after close should be dropped")
+	require.NoError(t, scanner.Close())
+}
+
+func TestScannerFlushInterval(t *testing.T) {
+	executor := &mockExecutor{started: make(chan struct{}, 1)}
+	scanner := newTestScanner(Options{BatchSize: 10, FlushInterval: 10 * time.Millisecond}, executor)
+	defer func() {
+		_ = scanner.Close()
+	}()
+
+	require.True(t, scanner.Submit("https://example.com/a"))
+	select {
+	case <-executor.started:
+	case <-time.After(time.Second):
+		t.Fatal("pending endpoint was not flushed")
+	}
+}
+
+func TestScannerDropsWhenQueueFull(t *testing.T) {
+	executor := &mockExecutor{started: make(chan struct{}, 2), release: make(chan struct{})}
+	scanner := newTestScanner(Options{BatchSize: 1, QueueSize: 1, FlushInterval: time.Hour}, executor)
+
+	require.True(t, scanner.Submit("https://example.com/a"))
+	<-executor.started
+
+	// the scan of a is running, b fills the queue and c is dropped
+	require.True(t, scanner.Submit("https://example.com/b"))
+	require.False(t, scanner.Submit("https://example.com/c"))
+	require.Equal(t, int64(1), scanner.dropped.Load())
+
+	close(executor.release)
+	require.NoError(t, scanner.Close())
+	require.Equal(t, [][]string{{"https://example.com/a"}, {"https://example.com/b"}}, executor.batches)
+}
+
+type mockWriter struct {
+	mu      sync.Mutex
+	results []*output.Result
+}
+
+func (m *mockWriter) Close() error { return nil }
+
+func (m *mockWriter) Write(result *output.Result) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = append(m.results, result)
+	return nil
+}
+
+func (m *mockWriter) WriteErr(*output.Error) error { return nil }
+
+func TestWriter(t *testing.T) {
+	mock := &mockWriter{}
+	writer := newWriter(mock)
+	executor := &mockExecutor{}
+	writer.scanner = newTestScanner(Options{OnFinding: writer.writeFinding}, executor)
+
+	results := []*output.Result{
+		{Request: &navigation.Request{URL: "https://example.com/login"}},
+		{Request: &navigation.Request{URL: "https://example.com/app.js"}},
+		{Request: &navigation.Request{URL: "https://example.com/logo.png"}},
+		{Request: &navigation.Request{URL: "https://example.com/asset"}, Response: &navigation.Response{Headers: navigation.Headers{"Content-Type": "image/svg+xml"}}},
+		{Request: &navigation.Request{URL: "https://example.com/down"}, Error: "connection refused"},
+	}
+	for _, result := range results {
+		require.NoError(t, writer.Write(result))
+	}
+	writer.writeFinding(&output.Finding{TemplateID: "git-config", MatchedAt: "https://example.com/.git/config"})
+	require.NoError(t, writer.Close())
+
+	require.Equal(t, [][]string{{"https://example.com/login"}}, executor.batches)
+	require.Len(t, mock.results, len(results)+1)
+	finding := mock.results[len(results)]
+	require.Equal(t, "nuclei", finding.Request.Tag)
+	require.Equal(t, "git-config", finding.Finding.TemplateID)
+}
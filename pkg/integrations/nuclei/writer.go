@@ -0,0 +1,102 @@
+package nuclei
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/katana/pkg/utils/extensions"
+	"go.uber.org/multierr"
+)
+
+// staticExtensions are the extensions of static assets which are not
+// scanned in addition to the default katana extension filter list.
+var staticExtensions = []string{".js", ".mjs", ".css", ".scss", ".less"}
+
+// staticContentTypes are the content type prefixes of static assets
+var staticContentTypes = []string{"image/", "font/", "audio/", "video/", "text/css", "application/javascript", "text/javascript", "application/font"}
+
+// Writer is an output writer which submits every written
+// endpoint to nuclei and writes back the reported findings.
+type Writer struct {
+	output.Writer
+	scanner   *Scanner
+	validator *extensions.Validator
+}
+
+// NewWriter wraps writer creating a nuclei scanner with options
+func NewWriter(writer output.Writer, options Options) (*Writer, error) {
+	w := newWriter(writer)
+	options.OnFinding = w.writeFinding
+
+	scanner, err := New(options)
+	if err != nil {
+		return nil, err
+	}
+	w.scanner = scanner
+	return w, nil
+}
+
+func newWriter(writer output.Writer) *Writer {
+	return &Writer{
+		Writer:    writer,
+		validator: extensions.NewValidator(nil, staticExtensions, false),
+	}
+}
+
+// Write writes the result and submits its endpoint for scanning
+func (w *Writer) Write(result *output.Result) error {
+	if err := w.Writer.Write(result); err != nil {
+		return err
+	}
+	if w.shouldScan(result) {
+		w.scanner.Submit(result.Request.URL)
+	}
+	return nil
+}
+
+// Close waits for pending scans before closing the underlying writer
+func (w *Writer) Close() error {
+	return multierr.Combine(w.scanner.Close(), w.Writer.Close())
+}
+
+// shouldScan returns true if the result is a crawled endpoint
+// which is not a static asset.
+func (w *Writer) shouldScan(result *output.Result) bool {
+	if result.Finding != nil || result.Request == nil || result.Error != "" {
+		return false
+	}
+	if !w.validator.ValidatePath(result.Request.URL) {
+		return false
+	}
+	if result.Response == nil {
+		return true
+	}
+	for key, value := range result.Response.Headers {
+		if !strings.EqualFold(key, "Content-Type") {
+			continue
+		}
+		value = strings.ToLower(value)
+		for _, contentType := range staticContentTypes {
+			if strings.HasPrefix(value, contentType) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (w *Writer) writeFinding(finding *output.Finding) {
+	_ = w.Writer.Write(&output.Result{
+		Timestamp: time.Now(),
+		Request: &navigation.Request{
+			Method:    http.MethodGet,
+			URL:       finding.MatchedAt,
+			Tag:       "nuclei",
+			Attribute: finding.TemplateID,
+		},
+		Finding: finding,
+	})
+}
@@ -0,0 +1,305 @@
+// Package monitor implements change detection between repeated crawls
+// of the same targets. Each crawl is recorded as a snapshot of the
+// discovered endpoints, their forms and responses which is compared
+// against the snapshot of the previous crawl.
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/utils/errkit"
+	fileutil "github.com/projectdiscovery/utils/file"
+)
+
+// Snapshot is the state of a single crawl
+type Snapshot struct {
+	// Endpoints are the discovered endpoints as "METHOD URL"
+	Endpoints map[string]struct{} `json:"endpoints"`
+	// Forms are the form signatures of the endpoints having forms
+	Forms map[string]string `json:"forms"`
+	// Responses are the response summaries of the endpoints
+	Responses map[string]ResponseSummary `json:"responses,omitempty"`
+}
+
+// ResponseSummary is the tracked state of the response of an endpoint
+type ResponseSummary struct {
+	StatusCode    int    `json:"status_code,omitempty"`
+	ContentLength int64  `json:"content_length,omitempty"`
+	Title         string `json:"title,omitempty"`
+}
+
+// NewSnapshot returns an empty snapshot
+func NewSnapshot() *Snapshot {
+	return &Snapshot{
+		Endpoints: make(map[string]struct{}),
+		Forms:     make(map[string]string),
+		Responses: make(map[string]ResponseSummary),
+	}
+}
+
+// Load loads the snapshot stored in file. A nil snapshot
+// is returned if the file does not exist yet.
+func Load(file string) (*Snapshot, error) {
+	if file == "" || !fileutil.FileExists(file) {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errkit.Wrap(err, "monitor: could not read state")
+	}
+	snapshot := NewSnapshot()
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, errkit.Wrap(err, "monitor: could not parse state")
+	}
+	if snapshot.Responses == nil {
+		// states saved before responses were tracked
+		snapshot.Responses = make(map[string]ResponseSummary)
+	}
+	return snapshot, nil
+}
+
+// Save stores the snapshot in file
+func (s *Snapshot) Save(file string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return errkit.Wrap(err, "monitor: could not marshal state")
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return errkit.Wrap(err, "monitor: could not write state")
+	}
+	return nil
+}
+
+// Monitor events of the written results
+const (
+	ChangeNew     = "new"
+	ChangeChanged = "changed"
+)
+
+// DefaultLengthThreshold is the default content length delta in bytes
+// above which the response of an endpoint is considered changed
+const DefaultLengthThreshold = 100
+
+// Changes are the differences between two snapshots
+type Changes struct {
+	NewEndpoints     []string         `json:"new_endpoints,omitempty"`
+	RemovedEndpoints []string         `json:"removed_endpoints,omitempty"`
+	ChangedForms     []string         `json:"changed_forms,omitempty"`
+	ChangedEndpoints []EndpointChange `json:"changed_endpoints,omitempty"`
+}
+
+// EndpointChange is a change of the response of a known endpoint
+type EndpointChange struct {
+	Endpoint string          `json:"endpoint"`
+	Previous ResponseSummary `json:"previous"`
+	Current  ResponseSummary `json:"current"`
+	// Reasons are the changed fields (status, length, title)
+	Reasons []string `json:"reasons"`
+}
+
+// String returns a short description of the change
+func (c EndpointChange) String() string {
+	details := make([]string, 0, len(c.Reasons))
+	for _, reason := range c.Reasons {
+		switch reason {
+		case "status":
+			details = append(details, fmt.Sprintf("status %d -> %d", c.Previous.StatusCode, c.Current.StatusCode))
+		case "length":
+			details = append(details, fmt.Sprintf("length %d -> %d", c.Previous.ContentLength, c.Current.ContentLength))
+		case "title":
+			details = append(details, fmt.Sprintf("title %q -> %q", c.Previous.Title, c.Current.Title))
+		}
+	}
+	return c.Endpoint + " (" + strings.Join(details, ", ") + ")"
+}
+
+// Empty returns true if there are no changes
+func (c *Changes) Empty() bool {
+	return len(c.NewEndpoints) == 0 && len(c.RemovedEndpoints) == 0 && len(c.ChangedForms) == 0 && len(c.ChangedEndpoints) == 0
+}
+
+// Diff returns the changes of current compared to previous. The response
+// of a known endpoint is changed if its status code or title differ or
+// its content length differs by more than lengthThreshold bytes.
+func Diff(previous, current *Snapshot, lengthThreshold int64) *Changes {
+	changes := &Changes{}
+	for endpoint := range current.Endpoints {
+		if _, ok := previous.Endpoints[endpoint]; !ok {
+			changes.NewEndpoints = append(changes.NewEndpoints, endpoint)
+		}
+	}
+	for endpoint := range previous.Endpoints {
+		if _, ok := current.Endpoints[endpoint]; !ok {
+			changes.RemovedEndpoints = append(changes.RemovedEndpoints, endpoint)
+		}
+	}
+	// forms of new or removed endpoints are already reported with the endpoint
+	for endpoint, forms := range current.Forms {
+		if previousForms, ok := previous.Forms[endpoint]; ok && previousForms != forms {
+			changes.ChangedForms = append(changes.ChangedForms, endpoint)
+		}
+	}
+	for endpoint, summary := range current.Responses {
+		if previousSummary, ok := previous.Responses[endpoint]; ok {
+			if reasons := compareResponses(previousSummary, summary, lengthThreshold); len(reasons) > 0 {
+				changes.ChangedEndpoints = append(changes.ChangedEndpoints, EndpointChange{
+					Endpoint: endpoint,
+					Previous: previousSummary,
+					Current:  summary,
+					Reasons:  reasons,
+				})
+			}
+		}
+	}
+	sort.Strings(changes.NewEndpoints)
+	sort.Strings(changes.RemovedEndpoints)
+	sort.Strings(changes.ChangedForms)
+	sort.Slice(changes.ChangedEndpoints, func(i, j int) bool {
+		return changes.ChangedEndpoints[i].Endpoint < changes.ChangedEndpoints[j].Endpoint
+	})
+	return changes
+}
+
+// compareResponses returns the fields which changed between two responses
+func compareResponses(previous, current ResponseSummary, lengthThreshold int64) []string {
+	var reasons []string
+	if previous.StatusCode != current.StatusCode {
+		reasons = append(reasons, "status")
+	}
+	delta := current.ContentLength - previous.ContentLength
+	if delta < 0 {
+		delta = -delta
+	}
+	if delta > lengthThreshold {
+		reasons = append(reasons, "length")
+	}
+	if previous.Title != current.Title {
+		reasons = append(reasons, "title")
+	}
+	return reasons
+}
+
+// Recorder is an output writer recording the results of a crawl
+// into a snapshot. When a previous snapshot is set, only the results
+// of endpoints missing from it or whose response changed are written
+// to the underlying writer, marked with a new or changed event.
+type Recorder struct {
+	output.Writer
+
+	// LengthThreshold is the content length delta in bytes above which
+	// the response of a known endpoint is considered changed
+	LengthThreshold int64
+
+	mu       sync.Mutex
+	previous *Snapshot
+	current  *Snapshot
+}
+
+// NewRecorder wraps writer recording results into snapshots
+func NewRecorder(writer output.Writer) *Recorder {
+	return &Recorder{Writer: writer, LengthThreshold: DefaultLengthThreshold, current: NewSnapshot()}
+}
+
+// Start starts recording a new crawl compared against previous
+func (r *Recorder) Start(previous *Snapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.previous = previous
+	r.current = NewSnapshot()
+}
+
+// Snapshot returns the snapshot of the current crawl
+func (r *Recorder) Snapshot() *Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Write records the result writing it if the endpoint is new
+// or its response changed
+func (r *Recorder) Write(result *output.Result) error {
+	if result.Request == nil || result.Error != "" || result.Finding != nil {
+		return r.Writer.Write(result)
+	}
+	endpoint := endpointKey(result.Request)
+
+	r.mu.Lock()
+	r.current.Endpoints[endpoint] = struct{}{}
+	summary, hasSummary := summarizeResponse(result.Response)
+	if hasSummary {
+		r.current.Responses[endpoint] = summary
+	}
+	if result.Response != nil && len(result.Response.Forms) > 0 {
+		r.current.Forms[endpoint] = formsSignature(result.Response.Forms)
+	}
+	write, change := true, ""
+	if r.previous != nil {
+		_, known := r.previous.Endpoints[endpoint]
+		previousSummary, tracked := r.previous.Responses[endpoint]
+		switch {
+		case !known:
+			change = ChangeNew
+		case tracked && hasSummary && len(compareResponses(previousSummary, summary, r.LengthThreshold)) > 0:
+			change = ChangeChanged
+		default:
+			write = false
+		}
+	}
+	r.mu.Unlock()
+
+	if !write {
+		return nil
+	}
+	if change != "" {
+		marked := *result
+		marked.Change = change
+		result = &marked
+	}
+	return r.Writer.Write(result)
+}
+
+var titleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// summarizeResponse returns the tracked state of response
+func summarizeResponse(response *navigation.Response) (ResponseSummary, bool) {
+	if response == nil || response.StatusCode == 0 {
+		return ResponseSummary{}, false
+	}
+	summary := ResponseSummary{StatusCode: response.StatusCode, ContentLength: response.ContentLength}
+	if summary.ContentLength <= 0 {
+		summary.ContentLength = int64(len(response.Body))
+	}
+	if match := titleRegex.FindStringSubmatch(response.Body); len(match) == 2 {
+		summary.Title = strings.Join(strings.Fields(html.UnescapeString(match[1])), " ")
+	}
+	return summary, true
+}
+
+func endpointKey(request *navigation.Request) string {
+	method := request.Method
+	if method == "" {
+		method = "GET"
+	}
+	return method + " " + request.URL
+}
+
+// formsSignature returns an order independent signature of forms
+func formsSignature(forms []navigation.Form) string {
+	signatures := make([]string, 0, len(forms))
+	for _, form := range forms {
+		parameters := append([]string(nil), form.Parameters...)
+		sort.Strings(parameters)
+		signatures = append(signatures, strings.ToUpper(form.Method)+" "+form.Action+" "+form.Enctype+" "+strings.Join(parameters, ","))
+	}
+	sort.Strings(signatures)
+	return strings.Join(signatures, "\n")
+}
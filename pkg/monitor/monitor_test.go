@@ -0,0 +1,154 @@
+package monitor
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWriter struct{ results []*output.Result }
+
+func (m *mockWriter) Close() error { return nil }
+func (m *mockWriter) Write(result *output.Result) error {
+	m.results = append(m.results, result)
+	return nil
+}
+func (m *mockWriter) WriteErr(*output.Error) error { return nil }
+
+func result(url string, forms ...navigation.Form) *output.Result {
+	return &output.Result{
+		Request:  &navigation.Request{Method: http.MethodGet, URL: url},
+		Response: &navigation.Response{Forms: forms},
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	mock := &mockWriter{}
+	recorder := NewRecorder(mock)
+	login := navigation.Form{Method: "POST", Action: "/login", Parameters: []string{"user", "pass"}}
+
+	recorder.Start(nil)
+	require.NoError(t, recorder.Write(result("https://example.com/")))
+	require.NoError(t, recorder.Write(result("https://example.com/login", login)))
+	require.NoError(t, recorder.Write(result("https://example.com/old")))
+	first := recorder.Snapshot()
+	require.Len(t, mock.results, 3, "all results of the first crawl should be written")
+
+	file := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, first.Save(file))
+	previous, err := Load(file)
+	require.NoError(t, err)
+	require.Equal(t, first, previous)
+
+	mock.results = nil
+	login.Parameters = append(login.Parameters, "otp")
+	recorder.Start(previous)
+	require.NoError(t, recorder.Write(result("https://example.com/")))
+	require.NoError(t, recorder.Write(result("https://example.com/login", login)))
+	require.NoError(t, recorder.Write(result("https://example.com/new")))
+	require.Len(t, mock.results, 1, "only new endpoints should be written")
+	require.Equal(t, "https://example.com/new", mock.results[0].Request.URL)
+	require.Equal(t, ChangeNew, mock.results[0].Change)
+
+	changes := Diff(previous, recorder.Snapshot(), DefaultLengthThreshold)
+	require.Equal(t, []string{"GET https://example.com/new"}, changes.NewEndpoints)
+	require.Equal(t, []string{"GET https://example.com/old"}, changes.RemovedEndpoints)
+	require.Equal(t, []string{"GET https://example.com/login"}, changes.ChangedForms)
+	require.False(t, changes.Empty())
+
+	missing, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	require.NoError(t, err)
+	require.Nil(t, missing)
+}
+
+func page(url string, status int, body string) *output.Result {
+	return &output.Result{
+		Request:  &navigation.Request{Method: http.MethodGet, URL: url},
+		Response: &navigation.Response{StatusCode: status, Body: body},
+	}
+}
+
+func TestRecorderResponseChanges(t *testing.T) {
+	mock := &mockWriter{}
+	recorder := NewRecorder(mock)
+	recorder.LengthThreshold = 10
+
+	recorder.Start(nil)
+	require.NoError(t, recorder.Write(page("https://example.com/", http.StatusOK, "<title>Home</title>")))
+	require.NoError(t, recorder.Write(page("https://example.com/admin", http.StatusForbidden, "denied")))
+	require.NoError(t, recorder.Write(page("https://example.com/about", http.StatusOK, "<title> About\n us </title>")))
+	require.NoError(t, recorder.Write(page("https://example.com/news", http.StatusOK, "<title>News</title>")))
+	previous := recorder.Snapshot()
+	require.Equal(t, ResponseSummary{StatusCode: http.StatusOK, ContentLength: 26, Title: "About us"}, previous.Responses["GET https://example.com/about"])
+	for _, result := range mock.results {
+		require.Empty(t, result.Change, "baseline results should not be marked")
+	}
+
+	mock.results = nil
+	recorder.Start(previous)
+	require.NoError(t, recorder.Write(page("https://example.com/", http.StatusOK, "<title>Home</title> v2")))
+	require.NoError(t, recorder.Write(page("https://example.com/admin", http.StatusOK, "denied")))
+	require.NoError(t, recorder.Write(page("https://example.com/about", http.StatusOK, "<title>Contact</title>")))
+	require.NoError(t, recorder.Write(page("https://example.com/news", http.StatusOK, "<title>News</title>"+strings.Repeat("x", 11))))
+	require.NoError(t, recorder.Write(page("https://example.com/new", http.StatusOK, "")))
+
+	require.Len(t, mock.results, 4, "unchanged endpoints should not be written")
+	events := make(map[string]string)
+	for _, result := range mock.results {
+		events[result.Request.URL] = result.Change
+	}
+	require.Equal(t, map[string]string{
+		"https://example.com/admin": ChangeChanged,
+		"https://example.com/about": ChangeChanged,
+		"https://example.com/news":  ChangeChanged,
+		"https://example.com/new":   ChangeNew,
+	}, events)
+
+	changes := Diff(previous, recorder.Snapshot(), recorder.LengthThreshold)
+	require.Equal(t, []string{"GET https://example.com/new"}, changes.NewEndpoints)
+	require.Len(t, changes.ChangedEndpoints, 3)
+	require.Equal(t, "GET https://example.com/about", changes.ChangedEndpoints[0].Endpoint)
+	require.Equal(t, []string{"title"}, changes.ChangedEndpoints[0].Reasons)
+	require.Equal(t, []string{"status"}, changes.ChangedEndpoints[1].Reasons)
+	require.Equal(t, []string{"length"}, changes.ChangedEndpoints[2].Reasons)
+	require.Equal(t, "GET https://example.com/admin (status 403 -> 200)", changes.ChangedEndpoints[1].String())
+}
+
+func TestNotifier(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	changes := &Changes{NewEndpoints: []string{"GET https://example.com/new"}}
+
+	notifier, err := NewNotifier(server.URL, FormatJSON)
+	require.NoError(t, err)
+	require.NoError(t, notifier.Notify([]string{"https://example.com"}, changes))
+	var payload struct {
+		Targets      []string `json:"targets"`
+		NewEndpoints []string `json:"new_endpoints"`
+	}
+	require.NoError(t, json.Unmarshal(body, &payload))
+	require.Equal(t, []string{"https://example.com"}, payload.Targets)
+	require.Equal(t, changes.NewEndpoints, payload.NewEndpoints)
+
+	notifier, err = NewNotifier(server.URL, FormatSlack)
+	require.NoError(t, err)
+	require.NoError(t, notifier.Notify([]string{"https://example.com"}, changes))
+	var slack map[string]string
+	require.NoError(t, json.Unmarshal(body, &slack))
+	require.True(t, strings.Contains(slack["text"], "GET https://example.com/new"))
+
+	_, err = NewNotifier(server.URL, "xml")
+	require.Error(t, err)
+}
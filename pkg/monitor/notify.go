@@ -0,0 +1,96 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// Webhook payload formats
+const (
+	FormatJSON  = "json"
+	FormatSlack = "slack"
+)
+
+// maxSlackLines is the number of changes listed per kind in slack messages
+const maxSlackLines = 20
+
+// Notifier sends crawl changes to a webhook
+type Notifier struct {
+	URL    string
+	Format string
+	client *http.Client
+}
+
+// NewNotifier creates a notifier posting changes to url in format
+func NewNotifier(url, format string) (*Notifier, error) {
+	if format == "" {
+		format = FormatJSON
+	}
+	if format != FormatJSON && format != FormatSlack {
+		return nil, errkit.Newf("monitor: unsupported webhook format %q", format)
+	}
+	return &Notifier{URL: url, Format: format, client: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+// Notify posts the changes of the crawl of targets
+func (n *Notifier) Notify(targets []string, changes *Changes) error {
+	payload, err := n.payload(targets, changes)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return errkit.Wrap(err, "monitor: could not send webhook")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode >= 300 {
+		return errkit.Newf("monitor: webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *Notifier) payload(targets []string, changes *Changes) ([]byte, error) {
+	if n.Format == FormatSlack {
+		return json.Marshal(map[string]string{"text": slackMessage(targets, changes)})
+	}
+	return json.Marshal(struct {
+		Targets   []string  `json:"targets"`
+		Timestamp time.Time `json:"timestamp"`
+		*Changes
+	}{Targets: targets, Timestamp: time.Now(), Changes: changes})
+}
+
+func slackMessage(targets []string, changes *Changes) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "*katana*: changes detected on %s\n", strings.Join(targets, ", "))
+	writeSection := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&builder, "*%s* (%d)\n", title, len(items))
+		for i, item := range items {
+			if i == maxSlackLines {
+				fmt.Fprintf(&builder, "• … %d more\n", len(items)-maxSlackLines)
+				break
+			}
+			fmt.Fprintf(&builder, "• `%s`\n", item)
+		}
+	}
+	writeSection("New endpoints", changes.NewEndpoints)
+	writeSection("Removed endpoints", changes.RemovedEndpoints)
+	writeSection("Changed forms", changes.ChangedForms)
+	changedEndpoints := make([]string, 0, len(changes.ChangedEndpoints))
+	for _, change := range changes.ChangedEndpoints {
+		changedEndpoints = append(changedEndpoints, change.String())
+	}
+	writeSection("Changed endpoints", changedEndpoints)
+	return builder.String()
+}
@@ -22,6 +22,30 @@ type Request struct {
 	Source         string              `json:"source,omitempty"`
 	CustomFields   map[string][]string `json:"custom_fields,omitempty"`
 	Raw            string              `json:"raw,omitempty"`
+	// SourceChain are the hops that led from the seed to the request
+	SourceChain []SourceLink `json:"source_chain,omitempty"`
+	// Link is the context of the anchor the request was discovered by
+	Link *LinkContext `json:"link,omitempty"`
+}
+
+// LinkContext is the context of an anchor on its page
+type LinkContext struct {
+	// Text is the anchor text, or its label when it has no text
+	Text string `json:"text,omitempty"`
+	// Heading is the text of the heading the anchor appears under
+	Heading string `json:"heading,omitempty"`
+	// Location is the page region of the anchor (nav, header, main,
+	// aside, footer or body)
+	Location string `json:"location,omitempty"`
+}
+
+// SourceLink is a hop of the source chain of a request
+type SourceLink struct {
+	// URL is the page the next hop was discovered on
+	URL string `json:"url"`
+	// Tag and Attribute are the feature the next hop was discovered by
+	Tag       string `json:"tag,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
 }
 
 // RequestURL returns the request URL for the navigation
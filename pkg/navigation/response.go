@@ -2,6 +2,8 @@ package navigation
 
 import (
 	"net/http"
+	"net/url"
+	"slices"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -42,18 +44,131 @@ type Response struct {
 	XhrRequests        []Request         `json:"xhr_requests,omitempty"`
 	StoredResponsePath string            `json:"stored_response_path,omitempty"`
 	KnowledgeBase      map[string]any    `json:"knowledgebase,omitempty"`
+	// KeepFragment keeps url fragments so that hash routes are distinct urls
+	KeepFragment bool `json:"-"`
+	// SourceChain is the source chain of the request of the response
+	SourceChain []SourceLink `json:"-"`
+	// Soft404 is true if the page matches the response of its host
+	// to a non-existent path
+	Soft404 bool `json:"soft_404,omitempty"`
+	// AllowedMethods are the methods supported by the api endpoint
+	AllowedMethods []string `json:"allowed_methods,omitempty"`
+	// CORS is the cross-origin policy of the api endpoint
+	CORS *CORSPolicy `json:"cors,omitempty"`
+	// RedirectChain are the hops of the redirects followed to the response
+	RedirectChain []RedirectHop `json:"redirect_chain,omitempty"`
+	// WebSocket is the traffic of a websocket connection
+	WebSocket *WebSocket `json:"websocket,omitempty"`
+	// Relations are the canonical and alternate links of the page
+	Relations []LinkRelation `json:"relations,omitempty"`
+	// BaseURL is the url declared by the base element of the document
+	// relative links are resolved against, the request url if nil
+	BaseURL *url.URL `json:"-"`
+}
+
+// Kinds of alternate link relations
+const (
+	AlternateAMP      = "amp"
+	AlternateMobile   = "mobile"
+	AlternateFeed     = "feed"
+	AlternateLanguage = "language"
+)
+
+// LinkRelation is a canonical or alternate link element of a page
+type LinkRelation struct {
+	// Rel is the relationship (canonical, alternate, amphtml)
+	Rel string `json:"rel"`
+	URL string `json:"url"`
+	// Kind is the kind of alternate (amp, mobile, feed, language)
+	Kind     string `json:"kind,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Media    string `json:"media,omitempty"`
+	Hreflang string `json:"hreflang,omitempty"`
+}
+
+// Canonical returns the canonical url declared by the page
+func (n *Response) Canonical() string {
+	for _, relation := range n.Relations {
+		if relation.Rel == "canonical" {
+			return relation.URL
+		}
+	}
+	return ""
+}
+
+// WebSocket is the traffic observed on a websocket connection
+type WebSocket struct {
+	Frames []WebSocketFrame `json:"frames,omitempty"`
+	// DroppedFrames is the number of frames beyond the recorded limit
+	DroppedFrames int `json:"dropped_frames,omitempty"`
+}
+
+// WebSocketFrame is a websocket message sent or received by the page
+type WebSocketFrame struct {
+	// Direction is sent or received
+	Direction string `json:"direction"`
+	Opcode    int    `json:"opcode"`
+	// Payload is the text of text messages and base64 encoded otherwise
+	Payload   string `json:"payload,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// CORSPolicy is the cross-origin behavior of an endpoint
+// for a request sent with a crafted Origin header
+type CORSPolicy struct {
+	AllowOrigin      string `json:"allow_origin"`
+	AllowCredentials bool   `json:"allow_credentials,omitempty"`
+	// Reflected is true if the crafted origin is allowed
+	Reflected bool `json:"reflected,omitempty"`
+	// Wildcard is true if any origin is allowed
+	Wildcard bool `json:"wildcard,omitempty"`
+}
+
+// RedirectHop is a request of a redirect chain
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+// NewRedirectChain returns the hops of the redirects followed to the
+// http response, including the final one, or nil if none was followed
+func NewRedirectChain(resp *http.Response) []RedirectHop {
+	if resp == nil || resp.Request == nil || resp.Request.Response == nil {
+		return nil
+	}
+	var chain []RedirectHop
+	for current := resp; current != nil && current.Request != nil; current = current.Request.Response {
+		chain = append(chain, RedirectHop{URL: current.Request.URL.String(), StatusCode: current.StatusCode})
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+// SourceChainTo returns the source chain of a request discovered in the response
+func (n Response) SourceChainTo(req *Request) []SourceLink {
+	link := SourceLink{URL: req.Source, Tag: req.Tag, Attribute: req.Attribute}
+	if n.Resp != nil && n.Resp.Request != nil && n.Resp.Request.URL != nil {
+		link.URL = n.Resp.Request.URL.String()
+	}
+	return append(slices.Clip(n.SourceChain), link)
 }
 
 func (n Response) AbsoluteURL(path string) string {
-	if strings.HasPrefix(path, "#") {
+	if strings.HasPrefix(path, "#") && (!n.KeepFragment || len(path) == 1) {
 		return ""
 	}
 
-	absURL, err := n.Resp.Request.URL.Parse(path)
+	base := n.Resp.Request.URL
+	if n.BaseURL != nil {
+		base = n.BaseURL
+	}
+	absURL, err := base.Parse(path)
 	if err != nil {
 		return ""
 	}
-	absURL.Fragment = ""
+	if !n.KeepFragment {
+		absURL.Fragment = ""
+	}
 	if absURL.Scheme == "//" {
 		absURL.Scheme = n.Resp.Request.URL.Scheme
 	}
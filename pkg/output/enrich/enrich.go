@@ -0,0 +1,179 @@
+// Package enrich annotates the results of a crawl with the infrastructure
+// of their hosts (ips, asn/org and cdn/waf) so that the output can be
+// filtered by it without a second tool pass.
+package enrich
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/fastdialer/fastdialer"
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/mapcidr/asn"
+	urlutil "github.com/projectdiscovery/utils/url"
+)
+
+// Resolver returns the addresses of a host
+type Resolver func(host string) ([]string, error)
+
+// ASNLookup returns the asn, organization and country of an address
+type ASNLookup func(ip string) (number, org, country string, err error)
+
+// DialerResolver resolves hosts with the dialer of the crawler
+func DialerResolver(dialer *fastdialer.Dialer) Resolver {
+	return func(host string) ([]string, error) {
+		dnsData, err := dialer.GetDNSData(host)
+		if err != nil || dnsData == nil {
+			return nil, err
+		}
+		return append(append([]string{}, dnsData.A...), dnsData.AAAA...), nil
+	}
+}
+
+// LookupASN looks the asn of an address up with the asnmap client
+func LookupASN(ip string) (number, org, country string, err error) {
+	results, err := asn.DefaultClient.GetData(ip)
+	if err != nil {
+		return "", "", "", err
+	}
+	for _, result := range results {
+		if result.ASN == 0 {
+			continue
+		}
+		return "AS" + strconv.Itoa(result.ASN), result.Org, result.Country, nil
+	}
+	return "", "", "", nil
+}
+
+// Writer is an output writer which annotates the results with the
+// infrastructure of their hosts and filters the results of the hosts
+// behind the filtered cdns or wafs.
+type Writer struct {
+	output.Writer
+	resolve   Resolver
+	lookupASN ASNLookup
+	filterCDN []string
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+// host is the infrastructure of a host. The addresses are resolved
+// once, the cdn and waf are fingerprinted from its responses.
+type host struct {
+	once sync.Once
+
+	mu   sync.Mutex
+	info output.HostInfo
+}
+
+// NewWriter wraps writer annotating the results. lookupASN may be nil.
+func NewWriter(writer output.Writer, resolve Resolver, lookupASN ASNLookup, filterCDN []string) *Writer {
+	filter := make([]string, 0, len(filterCDN))
+	for _, name := range filterCDN {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			filter = append(filter, name)
+		}
+	}
+	return &Writer{
+		Writer:    writer,
+		resolve:   resolve,
+		lookupASN: lookupASN,
+		filterCDN: filter,
+		hosts:     make(map[string]*host),
+	}
+}
+
+// Write annotates the result with the infrastructure of its host and
+// writes it unless the host is behind a filtered cdn or waf
+func (w *Writer) Write(result *output.Result) error {
+	if result == nil || result.Request == nil {
+		return w.Writer.Write(result)
+	}
+	parsed, err := urlutil.Parse(result.Request.URL)
+	if err != nil || parsed.Hostname() == "" {
+		return w.Writer.Write(result)
+	}
+	info := w.hostInfo(parsed.Hostname(), result)
+	result.Host = &info
+
+	if w.isFiltered(info) {
+		return errors.New("result is filtered by cdn")
+	}
+	return w.Writer.Write(result)
+}
+
+// hostInfo returns the infrastructure of hostname, fingerprinting the
+// cdn and waf from the response of the result if not known yet
+func (w *Writer) hostInfo(hostname string, result *output.Result) output.HostInfo {
+	hostname = strings.ToLower(hostname)
+	w.mu.Lock()
+	entry, ok := w.hosts[hostname]
+	if !ok {
+		entry = &host{}
+		w.hosts[hostname] = entry
+	}
+	w.mu.Unlock()
+
+	entry.once.Do(func() {
+		info := w.resolveHost(hostname)
+		entry.mu.Lock()
+		entry.info = info
+		entry.mu.Unlock()
+	})
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if response := result.Response; response != nil && len(response.Headers) > 0 {
+		if entry.info.CDN == "" {
+			entry.info.CDN = detectCDN(response.Headers)
+		}
+		if entry.info.WAF == "" {
+			entry.info.WAF = detectWAF(response.StatusCode, response.Headers, response.Body)
+		}
+	}
+	info := entry.info
+	info.IPs = append([]string(nil), entry.info.IPs...)
+	if info.CDN == "" {
+		info.CDN = cdnFromOrg(info.Org)
+	}
+	return info
+}
+
+// resolveHost returns the addresses and the asn of the first address
+// of hostname. Failed lookups leave the host unannotated.
+func (w *Writer) resolveHost(hostname string) output.HostInfo {
+	var info output.HostInfo
+	if ip := net.ParseIP(strings.Trim(hostname, "[]")); ip != nil {
+		info.IPs = []string{ip.String()}
+	} else if w.resolve != nil {
+		ips, err := w.resolve(hostname)
+		if err != nil {
+			gologger.Debug().Msgf("enrich: could not resolve %s: %s", hostname, err)
+		}
+		info.IPs = ips
+	}
+	if len(info.IPs) == 0 || w.lookupASN == nil {
+		return info
+	}
+	number, org, country, err := w.lookupASN(info.IPs[0])
+	if err != nil {
+		gologger.Debug().Msgf("enrich: could not lookup asn of %s: %s", info.IPs[0], err)
+		return info
+	}
+	info.ASN, info.Org, info.Country = number, org, country
+	return info
+}
+
+func (w *Writer) isFiltered(info output.HostInfo) bool {
+	for _, name := range w.filterCDN {
+		if strings.EqualFold(info.CDN, name) || strings.EqualFold(info.WAF, name) {
+			return true
+		}
+	}
+	return false
+}
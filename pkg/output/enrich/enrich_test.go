@@ -0,0 +1,76 @@
+package enrich
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWriter struct{ results []*output.Result }
+
+func (m *mockWriter) Close() error { return nil }
+func (m *mockWriter) Write(result *output.Result) error {
+	m.results = append(m.results, result)
+	return nil
+}
+func (m *mockWriter) WriteErr(*output.Error) error { return nil }
+
+func TestWriter(t *testing.T) {
+	resolved := map[string]int{}
+	resolve := func(host string) ([]string, error) {
+		resolved[host]++
+		switch host {
+		case "cdn.example.com":
+			return []string{"104.16.0.1"}, nil
+		case "origin.example.com":
+			return []string{"93.184.216.34"}, nil
+		}
+		return nil, nil
+	}
+	lookupASN := func(ip string) (string, string, string, error) {
+		if ip == "104.16.0.1" {
+			return "AS13335", "CLOUDFLARENET", "US", nil
+		}
+		return "AS15133", "EDGECAST", "US", nil
+	}
+	mock := &mockWriter{}
+	writer := NewWriter(mock, resolve, lookupASN, []string{" Cloudflare "})
+
+	err := writer.Write(&output.Result{Request: &navigation.Request{URL: "https://cdn.example.com/"}})
+	require.Error(t, err, "hosts behind a filtered cdn should be filtered by their asn")
+	err = writer.Write(&output.Result{
+		Request:  &navigation.Request{URL: "https://cdn.example.com/app.js"},
+		Response: &navigation.Response{StatusCode: 200, Headers: map[string]string{"Cf-Ray": "8a1b2c3d4e5f-AMS"}},
+	})
+	require.Error(t, err)
+
+	require.NoError(t, writer.Write(&output.Result{
+		Request:  &navigation.Request{URL: "https://origin.example.com/login"},
+		Response: &navigation.Response{StatusCode: 200, Headers: map[string]string{"X-Amz-Cf-Id": "abc", "Set-Cookie": "BIGipServerpool=1"}},
+	}))
+	require.NoError(t, writer.Write(&output.Result{Request: &navigation.Request{URL: "https://origin.example.com/about"}}))
+	require.NoError(t, writer.Write(&output.Result{Request: &navigation.Request{URL: "http://10.0.0.1:8080/"}}))
+
+	require.Equal(t, 1, resolved["cdn.example.com"], "hosts should be resolved once")
+	require.Equal(t, 1, resolved["origin.example.com"])
+	require.NotContains(t, resolved, "10.0.0.1", "ip hosts should not be resolved")
+
+	require.Len(t, mock.results, 3)
+	login := mock.results[0].Host
+	require.Equal(t, []string{"93.184.216.34"}, login.IPs)
+	require.Equal(t, "AS15133", login.ASN)
+	require.Equal(t, "EDGECAST", login.Org)
+	require.Equal(t, "cloudfront", login.CDN, "cdn headers should take precedence over the asn organization")
+	require.Equal(t, "f5-bigip", login.WAF)
+	require.Equal(t, *login, *mock.results[1].Host, "fingerprints should be kept for the later results of the host")
+	require.Equal(t, []string{"10.0.0.1"}, mock.results[2].Host.IPs)
+}
+
+func TestDetectWAF(t *testing.T) {
+	require.Equal(t, "aws-waf", detectWAF(403, map[string]string{"X-Amzn-Waf-Action": "block"}, ""))
+	require.Equal(t, "sucuri", detectWAF(403, nil, "Sucuri WebSite Firewall - Access Denied"), "block pages should be fingerprinted")
+	require.Empty(t, detectWAF(429, nil, ""), "rate limits are not a waf")
+	require.Empty(t, detectCDN(map[string]string{"Server": "nginx"}))
+}
@@ -0,0 +1,104 @@
+package enrich
+
+import (
+	"strings"
+
+	"github.com/projectdiscovery/katana/pkg/utils/blockdetect"
+)
+
+// fingerprint identifies a cdn or waf by a response header
+type fingerprint struct {
+	name string
+	// header is a header name (lowercase) and an optional value substring
+	header      string
+	headerValue string
+}
+
+var cdnFingerprints = []fingerprint{
+	{name: "cloudflare", header: "cf-ray"},
+	{name: "cloudflare", header: "server", headerValue: "cloudflare"},
+	{name: "cloudfront", header: "x-amz-cf-id"},
+	{name: "cloudfront", header: "via", headerValue: "cloudfront"},
+	{name: "akamai", header: "server", headerValue: "akamaighost"},
+	{name: "akamai", header: "x-akamai-transformed"},
+	{name: "fastly", header: "x-fastly-request-id"},
+	{name: "fastly", header: "x-served-by", headerValue: "cache-"},
+	{name: "azure-front-door", header: "x-azure-ref"},
+	{name: "google-cloud-cdn", header: "via", headerValue: "google"},
+	{name: "imperva", header: "x-cdn", headerValue: "imperva"},
+	{name: "imperva", header: "x-cdn", headerValue: "incapsula"},
+	{name: "sucuri", header: "x-sucuri-id"},
+	{name: "vercel", header: "x-vercel-id"},
+	{name: "netlify", header: "x-nf-request-id"},
+	{name: "bunnycdn", header: "server", headerValue: "bunnycdn"},
+	{name: "keycdn", header: "server", headerValue: "keycdn"},
+}
+
+var wafFingerprints = []fingerprint{
+	{name: "cloudflare", header: "cf-mitigated"},
+	{name: "imperva", header: "x-iinfo"},
+	{name: "imperva", header: "set-cookie", headerValue: "incap_ses_"},
+	{name: "sucuri", header: "x-sucuri-id"},
+	{name: "aws-waf", header: "x-amzn-waf-action"},
+	{name: "datadome", header: "x-datadome"},
+	{name: "f5-bigip", header: "set-cookie", headerValue: "bigipserver"},
+	{name: "barracuda", header: "set-cookie", headerValue: "barra_counter_session"},
+}
+
+// cdnOrgs maps substrings of the asn organizations of cdns to their
+// names for the hosts whose responses carry no cdn headers
+var cdnOrgs = []struct{ org, name string }{
+	{org: "cloudflare", name: "cloudflare"},
+	{org: "akamai", name: "akamai"},
+	{org: "fastly", name: "fastly"},
+	{org: "incapsula", name: "imperva"},
+	{org: "sucuri", name: "sucuri"},
+	{org: "edgecast", name: "edgecast"},
+	{org: "stackpath", name: "stackpath"},
+}
+
+// detectCDN returns the name of the cdn serving a response
+func detectCDN(headers map[string]string) string {
+	return detect(cdnFingerprints, lowerHeaders(headers))
+}
+
+// detectWAF returns the name of the waf protecting a response, either
+// from its headers or from its block page
+func detectWAF(statusCode int, headers map[string]string, body string) string {
+	if name := detect(wafFingerprints, lowerHeaders(headers)); name != "" {
+		return name
+	}
+	if name, blocked := blockdetect.Detect(statusCode, headers, body); blocked && name != "rate-limit" {
+		return name
+	}
+	return ""
+}
+
+// cdnFromOrg returns the name of the cdn of an asn organization
+func cdnFromOrg(org string) string {
+	org = strings.ToLower(org)
+	for _, item := range cdnOrgs {
+		if strings.Contains(org, item.org) {
+			return item.name
+		}
+	}
+	return ""
+}
+
+func detect(fingerprints []fingerprint, headers map[string]string) string {
+	for _, fingerprint := range fingerprints {
+		value, ok := headers[fingerprint.header]
+		if ok && strings.Contains(value, fingerprint.headerValue) {
+			return fingerprint.name
+		}
+	}
+	return ""
+}
+
+func lowerHeaders(headers map[string]string) map[string]string {
+	lower := make(map[string]string, len(headers))
+	for key, value := range headers {
+		lower[strings.ToLower(key)] = strings.ToLower(value)
+	}
+	return lower
+}
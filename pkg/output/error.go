@@ -1,10 +1,141 @@
 package output
 
-import "time"
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
 
 type Error struct {
-	Timestamp time.Time `json:"timestamp,omitempty"`
-	Endpoint  string    `json:"endpoint,omitempty"`
-	Source    string    `json:"source,omitempty"`
-	Error     string    `json:"error,omitempty"`
+	// SchemaVersion is the version of the output schema, omitted
+	// in the legacy output
+	SchemaVersion int        `json:"schema_version,omitempty"`
+	Timestamp     time.Time  `json:"timestamp,omitempty"`
+	Endpoint      string     `json:"endpoint,omitempty"`
+	Source        string     `json:"source,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	Class         ErrorClass `json:"class,omitempty"`
+	// Session is the metadata of the run the error occurred in
+	Session *Session `json:"session,omitempty"`
+}
+
+// ErrorClass is the machine-readable class of an error
+type ErrorClass string
+
+const (
+	ErrorClassDNS          ErrorClass = "dns"
+	ErrorClassTLS          ErrorClass = "tls"
+	ErrorClassTimeout      ErrorClass = "timeout"
+	ErrorClassBlocked      ErrorClass = "blocked"
+	ErrorClassNavigation   ErrorClass = "navigation"
+	ErrorClassScope        ErrorClass = "scope"
+	ErrorClassBrowserCrash ErrorClass = "browser-crash"
+	ErrorClassLogout       ErrorClass = "logout"
+	ErrorClassUnknown      ErrorClass = "unknown"
+)
+
+// errorClassMessages are the message fragments of the error classes
+// in the order they are matched, target problems first.
+var errorClassMessages = []struct {
+	class     ErrorClass
+	fragments []string
+}{
+	{ErrorClassDNS, []string{"no such host", "server misbehaving", "dns", "err_name_not_resolved", "err_name_resolution_failed"}},
+	{ErrorClassTLS, []string{"tls:", "x509:", "certificate", "err_cert_", "err_ssl_"}},
+	{ErrorClassTimeout, []string{"timeout", "timed out", "deadline exceeded"}},
+	{ErrorClassBlocked, []string{"blocked"}},
+	{ErrorClassScope, []string{"out of scope", "scope"}},
+	{ErrorClassBrowserCrash, []string{"browser has disconnected", "target closed", "session closed", "websocket", "crashed", "failed to launch"}},
+	{ErrorClassNavigation, []string{"navigation", "net::err_"}},
+}
+
+// ClassifyError returns the class of an error
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorClassDNS
+	}
+	var (
+		unknownAuthorityErr   x509.UnknownAuthorityError
+		hostnameErr           x509.HostnameError
+		certificateInvalidErr x509.CertificateInvalidError
+		verificationErr       *tls.CertificateVerificationError
+		recordHeaderErr       tls.RecordHeaderError
+	)
+	if errors.As(err, &unknownAuthorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &certificateInvalidErr) ||
+		errors.As(err, &verificationErr) || errors.As(err, &recordHeaderErr) {
+		return ErrorClassTLS
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorClassTimeout
+	}
+	return ClassifyErrorMessage(err.Error())
+}
+
+// ClassifyErrorMessage returns the class of an error message
+func ClassifyErrorMessage(message string) ErrorClass {
+	message = strings.ToLower(message)
+	for _, item := range errorClassMessages {
+		for _, fragment := range item.fragments {
+			if strings.Contains(message, fragment) {
+				return item.class
+			}
+		}
+	}
+	return ErrorClassUnknown
+}
+
+// ErrorStats counts the written errors per class
+type ErrorStats struct {
+	mu     sync.Mutex
+	counts map[ErrorClass]int
+}
+
+// NewErrorStats returns a new error counter
+func NewErrorStats() *ErrorStats {
+	return &ErrorStats{counts: make(map[ErrorClass]int)}
+}
+
+// Record counts an error of the class
+func (e *ErrorStats) Record(class ErrorClass) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	e.counts[class]++
+	e.mu.Unlock()
+}
+
+// ErrorClassCount is the number of errors of a class
+type ErrorClassCount struct {
+	Class ErrorClass
+	Count int
+}
+
+// Counts returns the number of errors of each class sorted by class
+func (e *ErrorStats) Counts() []ErrorClassCount {
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	counts := make([]ErrorClassCount, 0, len(e.counts))
+	for class, count := range e.counts {
+		counts = append(counts, ErrorClassCount{Class: class, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].Class < counts[j].Class
+	})
+	return counts
 }
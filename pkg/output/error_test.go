@@ -0,0 +1,45 @@
+package output
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err   error
+		class ErrorClass
+	}{
+		{nil, ""},
+		{fmt.Errorf("dial: %w", &net.DNSError{Err: "no such host", Name: "example.com"}), ErrorClassDNS},
+		{fmt.Errorf("get: %w", x509.UnknownAuthorityError{}), ErrorClassTLS},
+		{fmt.Errorf("get: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{errors.New("navigation failed: net::ERR_NAME_NOT_RESOLVED"), ErrorClassDNS},
+		{errors.New("navigation failed: net::ERR_CERT_AUTHORITY_INVALID"), ErrorClassTLS},
+		{errors.New("navigation failed: net::ERR_CONNECTION_REFUSED"), ErrorClassNavigation},
+		{errors.New("websocket: close 1006 (abnormal closure)"), ErrorClassBrowserCrash},
+		{errors.New("out of scope"), ErrorClassScope},
+		{errors.New("something else"), ErrorClassUnknown},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.class, ClassifyError(test.err), fmt.Sprint(test.err))
+	}
+}
+
+func TestErrorStats(t *testing.T) {
+	stats := NewErrorStats()
+	stats.Record(ErrorClassTimeout)
+	stats.Record(ErrorClassDNS)
+	stats.Record(ErrorClassTimeout)
+	require.Equal(t, []ErrorClassCount{{Class: ErrorClassDNS, Count: 1}, {Class: ErrorClassTimeout, Count: 2}}, stats.Counts())
+
+	var disabled *ErrorStats
+	disabled.Record(ErrorClassDNS)
+	require.Nil(t, disabled.Counts())
+}
@@ -2,22 +2,39 @@ package output
 
 import (
 	"bufio"
+	"io"
 	"os"
+
+	"filippo.io/age"
 )
 
+// EncryptedFileSuffix is appended to the names of encrypted files
+const EncryptedFileSuffix = ".age"
+
 // fileWriter is a concurrent file based output writer.
 type fileWriter struct {
-	file   *os.File
-	writer *bufio.Writer
+	file *os.File
+	// encrypter encrypts the written data when the file is encrypted
+	encrypter io.WriteCloser
+	writer    *bufio.Writer
 }
 
-// NewFileOutputWriter creates a new buffered writer for a file
-func newFileOutputWriter(file string) (*fileWriter, error) {
+// NewFileOutputWriter creates a new buffered writer for a file,
+// the data is encrypted to the recipients if any are given
+func newFileOutputWriter(file string, recipients ...age.Recipient) (*fileWriter, error) {
 	output, err := os.Create(file)
 	if err != nil {
 		return nil, err
 	}
-	return &fileWriter{file: output, writer: bufio.NewWriter(output)}, nil
+	if len(recipients) == 0 {
+		return &fileWriter{file: output, writer: bufio.NewWriter(output)}, nil
+	}
+	encrypter, err := age.Encrypt(output, recipients...)
+	if err != nil {
+		_ = output.Close()
+		return nil, err
+	}
+	return &fileWriter{file: output, encrypter: encrypter, writer: bufio.NewWriter(encrypter)}, nil
 }
 
 // WriteString writes an output to the underlying file
@@ -33,7 +50,27 @@ func (w *fileWriter) Write(data []byte) error {
 // Close closes the underlying writer flushing everything to disk
 func (w *fileWriter) Close() error {
 	_ = w.writer.Flush()
+	if w.encrypter != nil {
+		// closing the encrypter writes the final encrypted chunk
+		if err := w.encrypter.Close(); err != nil {
+			_ = w.file.Close()
+			return err
+		}
+	}
 	//nolint:errcheck // we don't care whether sync failed or succeeded.
 	w.file.Sync()
 	return w.file.Close()
 }
+
+// parseRecipients parses age public keys (age1...) to encrypt files to
+func parseRecipients(values []string) ([]age.Recipient, error) {
+	var recipients []age.Recipient
+	for _, value := range values {
+		recipient, err := age.ParseX25519Recipient(value)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
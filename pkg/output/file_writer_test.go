@@ -0,0 +1,42 @@
+package output
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedFileWriter(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	recipients, err := parseRecipients([]string{identity.Recipient().String()})
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "output.txt.age")
+	writer, err := newFileOutputWriter(file, recipients...)
+	require.NoError(t, err)
+	require.NoError(t, writer.Write([]byte("https://example.com/secret")))
+	require.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "example.com", "encrypted files should not contain plaintext")
+
+	encrypted, err := os.Open(file)
+	require.NoError(t, err)
+	defer func() {
+		_ = encrypted.Close()
+	}()
+	decrypter, err := age.Decrypt(encrypted, identity)
+	require.NoError(t, err)
+	decrypted, err := io.ReadAll(decrypter)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/secret\n", string(decrypted))
+
+	_, err = parseRecipients([]string{"-----BEGIN PGP PUBLIC KEY BLOCK-----"})
+	require.Error(t, err)
+}
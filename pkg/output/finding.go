@@ -0,0 +1,11 @@
+package output
+
+// Finding is a vulnerability finding reported for a crawled endpoint
+// by an integrated scanner.
+type Finding struct {
+	TemplateID string `json:"template_id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Severity   string `json:"severity,omitempty"`
+	Type       string `json:"type,omitempty"`
+	MatchedAt  string `json:"matched_at,omitempty"`
+}
@@ -46,6 +46,14 @@ func (w *StandardWriter) formatScreen(output *Result) ([]byte, error) {
 		builder.WriteRune(']')
 	}
 
+	if output.Finding != nil {
+		builder.WriteString(" [")
+		builder.WriteString(w.aurora.Magenta(output.Finding.TemplateID).String())
+		builder.WriteString("] [")
+		builder.WriteString(w.aurora.Red(output.Finding.Severity).String())
+		builder.WriteRune(']')
+	}
+
 	if w.verbose {
 		builder.WriteRune(' ')
 		builder.WriteRune('[')
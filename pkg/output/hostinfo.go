@@ -0,0 +1,14 @@
+package output
+
+// HostInfo is the infrastructure serving the host of a result
+type HostInfo struct {
+	// IPs are the resolved addresses of the host
+	IPs     []string `json:"ip,omitempty"`
+	ASN     string   `json:"asn,omitempty"`
+	Org     string   `json:"org,omitempty"`
+	Country string   `json:"country,omitempty"`
+	// CDN is the cdn the host is served from, if any
+	CDN string `json:"cdn,omitempty"`
+	// WAF is the web application firewall protecting the host, if any
+	WAF string `json:"waf,omitempty"`
+}
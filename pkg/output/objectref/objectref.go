@@ -0,0 +1,160 @@
+// Package objectref writes the crawled urls referencing objects by
+// apparent identifiers (numeric, uuid or hash path segments) grouped
+// by pattern so that they can be reviewed for broken access control.
+package objectref
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/utils/errkit"
+	"go.uber.org/multierr"
+)
+
+// MaxExamples is the maximum number of urls and identifiers kept per pattern
+const MaxExamples = 10
+
+// Report is the object-reference report of a crawl
+type Report struct {
+	Patterns []*Pattern `json:"patterns"`
+}
+
+// Pattern is a group of urls with the same object reference pattern
+type Pattern struct {
+	// Pattern is the url with identifiers replaced by their format
+	Pattern string `json:"pattern"`
+	// Formats are the observed identifier formats, e.g. {uuid}
+	Formats []string `json:"formats"`
+	Methods []string `json:"methods"`
+	// StatusCodes are the observed response status codes
+	StatusCodes []int    `json:"status_codes,omitempty"`
+	Count       int      `json:"count"`
+	URLs        []string `json:"urls"`
+	Identifiers []string `json:"identifiers"`
+
+	urls        map[string]struct{}
+	formats     map[string]struct{}
+	methods     map[string]struct{}
+	statusCodes map[int]struct{}
+	identifiers map[string]struct{}
+}
+
+// Writer is an output writer which additionally collects the
+// urls referencing objects into an object-reference report.
+type Writer struct {
+	output.Writer
+	file string
+
+	mu       sync.Mutex
+	patterns map[string]*Pattern
+}
+
+// NewWriter wraps writer writing the report to file on close
+func NewWriter(writer output.Writer, file string) *Writer {
+	return &Writer{Writer: writer, file: file, patterns: make(map[string]*Pattern)}
+}
+
+// Write collects the url of the result and writes the result
+func (w *Writer) Write(result *output.Result) error {
+	if result != nil && result.Request != nil && result.Finding == nil {
+		w.collect(result)
+	}
+	return w.Writer.Write(result)
+}
+
+// Close writes the report and closes the underlying writer
+func (w *Writer) Close() error {
+	return multierr.Combine(w.writeReport(), w.Writer.Close())
+}
+
+func (w *Writer) collect(result *output.Result) {
+	pattern, identifiers, ok := utils.ObjectReferencePattern(result.Request.URL)
+	if !ok {
+		return
+	}
+	method := result.Request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	group, ok := w.patterns[pattern]
+	if !ok {
+		group = &Pattern{
+			Pattern:     pattern,
+			urls:        make(map[string]struct{}),
+			formats:     make(map[string]struct{}),
+			methods:     make(map[string]struct{}),
+			statusCodes: make(map[int]struct{}),
+			identifiers: make(map[string]struct{}),
+		}
+		w.patterns[pattern] = group
+	}
+	if _, seen := group.urls[result.Request.URL]; !seen {
+		group.urls[result.Request.URL] = struct{}{}
+		group.Count++
+		if len(group.URLs) < MaxExamples {
+			group.URLs = append(group.URLs, result.Request.URL)
+		}
+	}
+	group.methods[method] = struct{}{}
+	if result.Response != nil && result.Response.StatusCode != 0 {
+		group.statusCodes[result.Response.StatusCode] = struct{}{}
+	}
+	for _, identifier := range identifiers {
+		group.formats[identifier.Format] = struct{}{}
+		if _, seen := group.identifiers[identifier.Value]; !seen && len(group.identifiers) < MaxExamples {
+			group.identifiers[identifier.Value] = struct{}{}
+			group.Identifiers = append(group.Identifiers, identifier.Value)
+		}
+	}
+}
+
+// Report returns the collected object reference patterns sorted by pattern
+func (w *Writer) Report() *Report {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	report := &Report{Patterns: make([]*Pattern, 0, len(w.patterns))}
+	for _, group := range w.patterns {
+		group.Formats = sortedKeys(group.formats)
+		group.Methods = sortedKeys(group.methods)
+		group.StatusCodes = group.StatusCodes[:0]
+		for statusCode := range group.statusCodes {
+			group.StatusCodes = append(group.StatusCodes, statusCode)
+		}
+		sort.Ints(group.StatusCodes)
+		report.Patterns = append(report.Patterns, group)
+	}
+	sort.Slice(report.Patterns, func(i, j int) bool {
+		return report.Patterns[i].Pattern < report.Patterns[j].Pattern
+	})
+	return report
+}
+
+func (w *Writer) writeReport() error {
+	data, err := json.MarshalIndent(w.Report(), "", "  ")
+	if err != nil {
+		return errkit.Wrap(err, "objectref: could not marshal report")
+	}
+	if err := os.WriteFile(w.file, append(data, '\n'), 0644); err != nil {
+		return errkit.Wrap(err, "objectref: could not write report")
+	}
+	return nil
+}
+
+func sortedKeys(values map[string]struct{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,57 @@
+package objectref
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWriter struct{ results int }
+
+func (m *mockWriter) Close() error                      { return nil }
+func (m *mockWriter) Write(result *output.Result) error { m.results++; return nil }
+func (m *mockWriter) WriteErr(*output.Error) error      { return nil }
+
+func TestWriter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "object-reference.json")
+	mock := &mockWriter{}
+	writer := NewWriter(mock, file)
+
+	results := []*output.Result{
+		{Request: &navigation.Request{Method: http.MethodGet, URL: "https://app.example.com/api/users/12/orders"}, Response: &navigation.Response{StatusCode: http.StatusOK}},
+		{Request: &navigation.Request{Method: http.MethodGet, URL: "https://app.example.com/api/users/12/orders"}, Response: &navigation.Response{StatusCode: http.StatusOK}},
+		{Request: &navigation.Request{Method: http.MethodDelete, URL: "https://app.example.com/api/users/13/orders"}, Response: &navigation.Response{StatusCode: http.StatusForbidden}},
+		{Request: &navigation.Request{URL: "https://app.example.com/docs/550e8400-e29b-41d4-a716-446655440000"}},
+		{Request: &navigation.Request{Method: http.MethodGet, URL: "https://app.example.com/about"}, Response: &navigation.Response{StatusCode: http.StatusOK}},
+	}
+	for _, result := range results {
+		require.NoError(t, writer.Write(result))
+	}
+	require.Equal(t, len(results), mock.results, "all results should be written to the wrapped writer")
+	require.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	report := &Report{}
+	require.NoError(t, json.Unmarshal(data, report))
+	require.Len(t, report.Patterns, 2, "urls without identifiers should not be reported")
+
+	users := report.Patterns[0]
+	require.Equal(t, "https://app.example.com/api/users/{num}/orders", users.Pattern)
+	require.Equal(t, 2, users.Count)
+	require.Equal(t, []string{"{num}"}, users.Formats)
+	require.Equal(t, []string{http.MethodDelete, http.MethodGet}, users.Methods)
+	require.Equal(t, []int{http.StatusOK, http.StatusForbidden}, users.StatusCodes)
+	require.Equal(t, []string{"12", "13"}, users.Identifiers)
+
+	docs := report.Patterns[1]
+	require.Equal(t, "https://app.example.com/docs/{uuid}", docs.Pattern)
+	require.Equal(t, []string{http.MethodGet}, docs.Methods)
+	require.Empty(t, docs.StatusCodes)
+}
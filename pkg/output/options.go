@@ -30,4 +30,14 @@ type Options struct {
 	OutputFilterCondition string
 	ExcludeOutputFields   []string
 	FilterPageType        []string
+	// ErrorStats counts the written errors per class when set
+	ErrorStats *ErrorStats
+	// EncryptRecipients are the age public keys the output files
+	// and stored responses are encrypted to
+	EncryptRecipients []string
+	// Session is stamped into every written result and error when set
+	Session *Session
+	// SchemaVersion is stamped into every written result and error,
+	// zero writes the legacy output without the version
+	SchemaVersion int
 }
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"sync"
 
+	"filippo.io/age"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/logrusorgru/aurora"
 	"github.com/mitchellh/mapstructure"
@@ -17,6 +18,7 @@ import (
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/katana/pkg/navigation"
 	"github.com/projectdiscovery/katana/pkg/utils/extensions"
+	"github.com/projectdiscovery/katana/pkg/utils/idn"
 	"github.com/projectdiscovery/utils/errkit"
 	fileutil "github.com/projectdiscovery/utils/file"
 	"github.com/stoewer/go-strcase"
@@ -65,6 +67,15 @@ type StandardWriter struct {
 	outputFilterCondition string
 	excludeOutputFields   []string
 	filterPageType        []string
+	errorStats            *ErrorStats
+	// recipients are the age recipients files are encrypted to
+	recipients []age.Recipient
+	// indexFile is the stored responses index when it is encrypted
+	indexFile *fileWriter
+	// session is stamped into every written result and error
+	session *Session
+	// schemaVersion is stamped into every written result and error
+	schemaVersion int
 }
 
 // New returns a new output writer instance
@@ -87,8 +98,17 @@ func New(options Options) (Writer, error) {
 		outputFilterCondition: options.OutputFilterCondition,
 		excludeOutputFields:   options.ExcludeOutputFields,
 		filterPageType:        options.FilterPageType,
+		errorStats:            options.ErrorStats,
+		session:               options.Session,
+		schemaVersion:         options.SchemaVersion,
 	}
 
+	recipients, err := parseRecipients(options.EncryptRecipients)
+	if err != nil {
+		return nil, errkit.Wrap(err, "output: could not parse encryption recipients")
+	}
+	writer.recipients = recipients
+
 	if options.StoreFieldDir != "" {
 		storeFieldDir = options.StoreFieldDir
 	}
@@ -100,7 +120,7 @@ func New(options Options) (Writer, error) {
 			return nil, err
 		}
 	}
-	err := parseCustomFieldName(options.FieldConfig)
+	err = parseCustomFieldName(options.FieldConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +142,7 @@ func New(options Options) (Writer, error) {
 		writer.storeFields = append(writer.storeFields, strings.Split(options.StoreFields, ",")...)
 	}
 	if options.OutputFile != "" {
-		output, err := newFileOutputWriter(options.OutputFile)
+		output, err := newFileOutputWriter(writer.fileName(options.OutputFile), writer.recipients...)
 		if err != nil {
 			return nil, errkit.Wrap(err, "output: could not create output file")
 		}
@@ -141,13 +161,17 @@ func New(options Options) (Writer, error) {
 			_ = os.MkdirAll(writer.storeResponseDir, os.ModePerm)
 		}
 		// todo: the index file seems never used?
-		_, err := newFileOutputWriter(filepath.Join(writer.storeResponseDir, indexFile))
+		index, err := newFileOutputWriter(writer.fileName(filepath.Join(writer.storeResponseDir, indexFile)), writer.recipients...)
 		if err != nil {
 			return nil, errkit.Wrap(err, "output: could not create index file")
 		}
+		// encrypted indexes can not be appended to and are kept open
+		if len(writer.recipients) > 0 {
+			writer.indexFile = index
+		}
 	}
 	if options.ErrorLogFile != "" {
-		errorFile, err := newFileOutputWriter(options.ErrorLogFile)
+		errorFile, err := newFileOutputWriter(writer.fileName(options.ErrorLogFile), writer.recipients...)
 		if err != nil {
 			return nil, errkit.Wrap(err, "output: could not create error file")
 		}
@@ -168,6 +192,10 @@ func (w *StandardWriter) Write(result *Result) error {
 	if result == nil {
 		return errors.New("result is nil")
 	}
+	if w.session != nil && result.Session == nil {
+		result.Session = w.session
+	}
+	result.SchemaVersion = w.schemaVersion
 
 	if len(w.storeFields) > 0 {
 		storeFields(result, w.storeFields)
@@ -196,7 +224,7 @@ func (w *StandardWriter) Write(result *Result) error {
 	var err error
 
 	if w.storeResponse && result.HasResponse() {
-		if fileName, fileWriter, err := getResponseFile(w.storeResponseDir, result.Response.Resp.Request.URL.String()); err == nil {
+		if fileName, fileWriter, err := getResponseFile(w.storeResponseDir, result.Response.Resp.Request.URL.String(), w.recipients...); err == nil {
 			if absPath, err := filepath.Abs(fileName); err == nil {
 				fileName = absPath
 			}
@@ -205,7 +233,7 @@ func (w *StandardWriter) Write(result *Result) error {
 			if err != nil {
 				return errkit.Wrap(err, "output: could not store response")
 			}
-			if err := updateIndex(w.storeResponseDir, result); err != nil {
+			if err := w.updateIndex(result); err != nil {
 				return errkit.Wrap(err, "output: could not store response")
 			}
 			if err := fileWriter.Write(data); err != nil {
@@ -215,6 +243,13 @@ func (w *StandardWriter) Write(result *Result) error {
 		}
 	}
 
+	// internationalized hosts are displayed in their unicode form
+	if displayURL := idn.UnicodeURL(result.Request.URL); displayURL != result.Request.URL {
+		request := *result.Request
+		request.URL = displayURL
+		result.Request = &request
+	}
+
 	if w.omitRaw {
 		result.Request.Raw = ""
 		if result.Response != nil {
@@ -263,6 +298,15 @@ func (w *StandardWriter) Write(result *Result) error {
 }
 
 func (w *StandardWriter) WriteErr(errMessage *Error) error {
+	if w.session != nil && errMessage.Session == nil {
+		errMessage.Session = w.session
+	}
+	errMessage.SchemaVersion = w.schemaVersion
+	if errMessage.Class == "" {
+		errMessage.Class = ClassifyErrorMessage(errMessage.Error)
+	}
+	w.errorStats.Record(errMessage.Class)
+
 	data, err := jsoniter.Marshal(errMessage)
 	if err != nil {
 		return errkit.Wrap(err, "output: marshal")
@@ -295,9 +339,24 @@ func (w *StandardWriter) Close() error {
 			return err
 		}
 	}
+	if w.indexFile != nil {
+		err := w.indexFile.Close()
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// fileName returns the name of an output file, encrypted files
+// have the encrypted file suffix
+func (w *StandardWriter) fileName(file string) string {
+	if len(w.recipients) > 0 {
+		return file + EncryptedFileSuffix
+	}
+	return file
+}
+
 func createDirNameNoClobber(dir string) string {
 	if !fileutil.FolderExists(dir) {
 		return dir
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"filippo.io/age"
 	"github.com/projectdiscovery/gologger"
 	"github.com/projectdiscovery/utils/errkit"
 	urlutil "github.com/projectdiscovery/utils/url"
@@ -46,13 +47,16 @@ func createHostDir(storeResponseFolder, domain string) string {
 	return filepath.Join(storeResponseFolder, domain)
 }
 
-func getResponseFile(storeResponseFolder, URL string) (string, *fileWriter, error) {
+func getResponseFile(storeResponseFolder, URL string, recipients ...age.Recipient) (string, *fileWriter, error) {
 	domain, err := getResponseHost(URL)
 	if err != nil {
 		return "", nil, err
 	}
 	fileName := getResponseFileName(storeResponseFolder, domain, URL)
-	output, err := newFileOutputWriter(fileName)
+	if len(recipients) > 0 {
+		fileName += EncryptedFileSuffix
+	}
+	output, err := newFileOutputWriter(fileName, recipients...)
 	if err != nil {
 		return "", nil, errkit.Wrap(err, "output: could not create output file")
 	}
@@ -66,8 +70,22 @@ func getResponseFileName(storeResponseFolder, domain, URL string) string {
 	return filepath.Join(folder, file)
 }
 
-func updateIndex(storeResponseFolder string, result *Result) error {
-	index, err := os.OpenFile(filepath.Join(storeResponseFolder, indexFile), os.O_APPEND|os.O_WRONLY, 0644)
+func (w *StandardWriter) updateIndex(result *Result) error {
+	entry, err := w.indexEntry(result)
+	if err != nil {
+		return err
+	}
+	// encrypted indexes are written as a single encrypted stream
+	if w.indexFile != nil {
+		w.outputMutex.Lock()
+		defer w.outputMutex.Unlock()
+		if err := w.indexFile.Write(entry); err != nil {
+			return errkit.Wrap(err, "output: could not update index")
+		}
+		return nil
+	}
+
+	index, err := os.OpenFile(filepath.Join(w.storeResponseDir, indexFile), os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
 	}
@@ -78,23 +96,28 @@ func updateIndex(storeResponseFolder string, result *Result) error {
 		}
 	}()
 
+	entry = append(entry, '\n')
+	if _, writeErr := index.Write(entry); writeErr != nil {
+		return errkit.Wrap(writeErr, "output: could not update index")
+	}
+
+	return nil
+}
+
+// indexEntry returns the index line of a stored response
+func (w *StandardWriter) indexEntry(result *Result) ([]byte, error) {
 	builder := &bytes.Buffer{}
 
 	domain, err := getResponseHost(result.Request.URL)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	builder.WriteString(getResponseFileName(storeResponseFolder, domain, result.Request.URL))
+	builder.WriteString(w.fileName(getResponseFileName(w.storeResponseDir, domain, result.Request.URL)))
 	builder.WriteRune(' ')
 	builder.WriteString(result.Request.URL)
 	builder.WriteRune(' ')
 	builder.WriteString("(" + result.Response.Resp.Status + ")")
-	builder.WriteRune('\n')
 
-	if _, writeErr := index.Write(builder.Bytes()); writeErr != nil {
-		return errkit.Wrap(err, "output: could not update index")
-	}
-
-	return nil
+	return builder.Bytes(), nil
 }
@@ -8,10 +8,22 @@ import (
 
 // Result of the crawling
 type Result struct {
-	Timestamp time.Time            `json:"timestamp,omitempty"`
-	Request   *navigation.Request  `json:"request,omitempty"`
-	Response  *navigation.Response `json:"response,omitempty"`
-	Error     string               `json:"error,omitempty"`
+	// SchemaVersion is the version of the output schema, omitted
+	// in the legacy output
+	SchemaVersion int                  `json:"schema_version,omitempty"`
+	Timestamp     time.Time            `json:"timestamp,omitempty"`
+	Request       *navigation.Request  `json:"request,omitempty"`
+	Response      *navigation.Response `json:"response,omitempty"`
+	Error         string               `json:"error,omitempty"`
+	Finding       *Finding             `json:"finding,omitempty"`
+	// Role is the role the result was crawled as in role comparisons
+	Role string `json:"role,omitempty"`
+	// Session is the metadata of the run the result was crawled in
+	Session *Session `json:"session,omitempty"`
+	// Change is the monitor event of the result (new, changed)
+	Change string `json:"change,omitempty"`
+	// Host is the infrastructure of the host of the result when enriched
+	Host *HostInfo `json:"host,omitempty"`
 }
 
 // HasResponse checks if the result has a valid response
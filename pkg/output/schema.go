@@ -0,0 +1,139 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the schema of the json output. It is
+// increased when fields are removed or renamed or their type changes,
+// fields added to the output keep the version.
+const SchemaVersion = 1
+
+// JSONSchema returns the JSON Schema of the json output results
+// generated from the output types
+func JSONSchema() ([]byte, error) {
+	generator := &schemaGenerator{
+		names:       make(map[reflect.Type]string),
+		definitions: make(map[string]map[string]any),
+	}
+	root := generator.schemaOf(reflect.TypeOf(Result{}))
+
+	// results are versioned unless written in the legacy schema
+	properties := generator.definitions["Result"]["properties"].(map[string]any)
+	properties["schema_version"] = map[string]any{
+		"type":        "integer",
+		"const":       SchemaVersion,
+		"description": "version of the output schema, absent in the legacy output",
+	}
+
+	schema := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "katana result",
+		"description": fmt.Sprintf("result of the katana jsonl output, schema version %d", SchemaVersion),
+		"$ref":        root["$ref"],
+		"$defs":       generator.definitions,
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaGenerator generates the schemas of go types as they are
+// marshaled to json, structs are generated as definitions
+type schemaGenerator struct {
+	names       map[reflect.Type]string
+	definitions map[string]map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return map[string]any{"$ref": "#/$defs/" + g.definition(t)}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		// byte slices are marshaled as base64 strings
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	default:
+		return map[string]any{}
+	}
+}
+
+// definition generates the definition of a struct type once
+// and returns its name
+func (g *schemaGenerator) definition(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.definitions[name]; taken || name == "" {
+		name = path.Base(t.PkgPath()) + "." + name
+	}
+	g.names[t] = name
+
+	definition := map[string]any{"type": "object"}
+	// recursive types refer to the definition being generated
+	g.definitions[name] = definition
+
+	properties := make(map[string]any)
+	var required []string
+	g.addFields(t, properties, &required)
+	definition["properties"] = properties
+	if len(required) > 0 {
+		definition["required"] = required
+	}
+	return name
+}
+
+func (g *schemaGenerator) addFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		// embedded structs without a name are flattened
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(embedded, properties, required)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schemaOf(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema()
+	require.NoError(t, err)
+
+	var schema struct {
+		Ref  string `json:"$ref"`
+		Defs map[string]struct {
+			Type       string                     `json:"type"`
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+	require.Equal(t, "#/$defs/Result", schema.Ref)
+
+	result := schema.Defs["Result"]
+	require.Equal(t, "object", result.Type)
+	require.Contains(t, result.Properties, "schema_version")
+	require.JSONEq(t, `{"type":"string","format":"date-time"}`, string(result.Properties["timestamp"]))
+	require.JSONEq(t, `{"$ref":"#/$defs/Request"}`, string(result.Properties["request"]))
+	require.Empty(t, result.Required, "omitted fields should not be required")
+
+	request := schema.Defs["Request"]
+	require.Contains(t, request.Properties, "endpoint")
+	require.NotContains(t, request.Properties, "Depth", "fields not marshaled should not be in the schema")
+	require.JSONEq(t, `{"type":"object","additionalProperties":{"type":"string"}}`, string(request.Properties["headers"]))
+	require.Contains(t, schema.Defs, "Response")
+}
+
+func TestWriteSchemaVersion(t *testing.T) {
+	writer, err := New(Options{JSON: true, SchemaVersion: SchemaVersion})
+	require.NoError(t, err)
+	defer writer.Close()
+
+	errMessage := &Error{Error: "timeout"}
+	require.NoError(t, writer.WriteErr(errMessage))
+	require.Equal(t, SchemaVersion, errMessage.SchemaVersion)
+
+	legacy, err := New(Options{JSON: true})
+	require.NoError(t, err)
+	defer legacy.Close()
+
+	errMessage = &Error{Error: "timeout"}
+	require.NoError(t, legacy.WriteErr(errMessage))
+	data, err := json.Marshal(errMessage)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "schema_version", "legacy output should not be versioned")
+}
@@ -0,0 +1,63 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/projectdiscovery/utils/errkit"
+	"github.com/rs/xid"
+)
+
+// Session is the metadata of a crawl run stamped into every
+// output record so that results of concurrent pipelines can
+// be attributed once they land in shared storage.
+type Session struct {
+	Name   string            `json:"name,omitempty"`
+	RunID  string            `json:"run_id,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// NewSession creates the session metadata of a run from its name,
+// run id and key=value labels. A run id is generated for named or
+// labeled sessions without one, nil is returned if nothing is set.
+func NewSession(name, runID string, labels []string) (*Session, error) {
+	session := &Session{Name: strings.TrimSpace(name), RunID: strings.TrimSpace(runID)}
+	for _, label := range labels {
+		key, value, ok := strings.Cut(label, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, errkit.New(fmt.Sprintf("invalid label %q, expected key=value", label))
+		}
+		if session.Labels == nil {
+			session.Labels = make(map[string]string)
+		}
+		session.Labels[key] = strings.TrimSpace(value)
+	}
+	if session.Name == "" && session.RunID == "" && len(session.Labels) == 0 {
+		return nil, nil
+	}
+	if session.RunID == "" {
+		session.RunID = xid.New().String()
+	}
+	return session, nil
+}
+
+// String returns the session as `name (run id) key=value...`
+func (s *Session) String() string {
+	var builder strings.Builder
+	if s.Name != "" {
+		builder.WriteString(s.Name)
+		builder.WriteString(" ")
+	}
+	builder.WriteString("(run " + s.RunID + ")")
+	keys := make([]string, 0, len(s.Labels))
+	for key := range s.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		builder.WriteString(" " + key + "=" + s.Labels[key])
+	}
+	return builder.String()
+}
@@ -0,0 +1,26 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSession(t *testing.T) {
+	session, err := NewSession("", "", nil)
+	require.NoError(t, err)
+	require.Nil(t, session, "runs without metadata should have no session")
+
+	session, err = NewSession("nightly", "", []string{"team=red", " env = prod"})
+	require.NoError(t, err)
+	require.Equal(t, "nightly", session.Name)
+	require.NotEmpty(t, session.RunID, "named runs should get a generated run id")
+	require.Equal(t, map[string]string{"team": "red", "env": "prod"}, session.Labels)
+
+	session, err = NewSession("nightly", "build-42", []string{"team=red", "env=prod"})
+	require.NoError(t, err)
+	require.Equal(t, "nightly (run build-42) env=prod team=red", session.String())
+
+	_, err = NewSession("", "", []string{"team"})
+	require.Error(t, err)
+}
@@ -0,0 +1,122 @@
+// Package sitemap writes the in-scope urls of a crawl as a sitemap.xml
+// following the sitemaps.org protocol so that they can be fed to seo
+// tooling and other scanners.
+package sitemap
+
+import (
+	"encoding/xml"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/utils/errkit"
+	"go.uber.org/multierr"
+)
+
+// MaxURLs is the maximum number of urls of a sitemap
+const MaxURLs = 50000
+
+// URLSet is a sitemap document
+type URLSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	URLs    []URL    `xml:"url"`
+}
+
+// URL is an url of a sitemap
+type URL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Writer is an output writer which additionally collects the
+// successfully crawled urls into a sitemap.
+type Writer struct {
+	output.Writer
+	file string
+
+	mu   sync.Mutex
+	urls map[string]string
+}
+
+// NewWriter wraps writer writing the sitemap to file on close
+func NewWriter(writer output.Writer, file string) *Writer {
+	return &Writer{Writer: writer, file: file, urls: make(map[string]string)}
+}
+
+// Write collects the url of the result and writes the result
+func (w *Writer) Write(result *output.Result) error {
+	// the url is collected first as writing displays it in unicode form
+	if loc, lastMod, ok := sitemapURL(result); ok {
+		w.mu.Lock()
+		if existing, seen := w.urls[loc]; !seen || existing == "" {
+			w.urls[loc] = lastMod
+		}
+		w.mu.Unlock()
+	}
+	return w.Writer.Write(result)
+}
+
+// Close writes the sitemap and closes the underlying writer
+func (w *Writer) Close() error {
+	return multierr.Combine(w.writeSitemap(), w.Writer.Close())
+}
+
+func (w *Writer) writeSitemap() error {
+	w.mu.Lock()
+	urlSet := &URLSet{XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9", URLs: make([]URL, 0, len(w.urls))}
+	for loc, lastMod := range w.urls {
+		urlSet.URLs = append(urlSet.URLs, URL{Loc: loc, LastMod: lastMod})
+	}
+	w.mu.Unlock()
+
+	sort.Slice(urlSet.URLs, func(i, j int) bool {
+		return urlSet.URLs[i].Loc < urlSet.URLs[j].Loc
+	})
+	if len(urlSet.URLs) > MaxURLs {
+		gologger.Warning().Msgf("Sitemap is limited to %d urls, %d urls were not written", MaxURLs, len(urlSet.URLs)-MaxURLs)
+		urlSet.URLs = urlSet.URLs[:MaxURLs]
+	}
+
+	data, err := xml.MarshalIndent(urlSet, "", "  ")
+	if err != nil {
+		return errkit.Wrap(err, "sitemap: could not marshal sitemap")
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+	if err := os.WriteFile(w.file, data, 0644); err != nil {
+		return errkit.Wrap(err, "sitemap: could not write sitemap")
+	}
+	return nil
+}
+
+// sitemapURL returns the url and last modification date of a result
+// if it is a successful response to a GET request
+func sitemapURL(result *output.Result) (loc, lastMod string, ok bool) {
+	if result.Request == nil || result.Response == nil || result.Error != "" || result.Finding != nil {
+		return "", "", false
+	}
+	if result.Request.Method != "" && result.Request.Method != http.MethodGet {
+		return "", "", false
+	}
+	statusCode := result.Response.StatusCode
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+		return "", "", false
+	}
+	var lastModified string
+	if result.Response.Resp != nil {
+		lastModified = result.Response.Resp.Header.Get("Last-Modified")
+	} else {
+		lastModified = result.Response.Headers["Last-Modified"]
+	}
+	if lastModified != "" {
+		if modified, err := http.ParseTime(lastModified); err == nil {
+			lastMod = modified.UTC().Format(time.RFC3339)
+		}
+	}
+	return result.Request.URL, lastMod, true
+}
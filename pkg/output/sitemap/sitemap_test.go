@@ -0,0 +1,56 @@
+package sitemap
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWriter struct{ results int }
+
+func (m *mockWriter) Close() error                      { return nil }
+func (m *mockWriter) Write(result *output.Result) error { m.results++; return nil }
+func (m *mockWriter) WriteErr(*output.Error) error      { return nil }
+
+func TestWriter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sitemap.xml")
+	mock := &mockWriter{}
+	writer := NewWriter(mock, file)
+
+	results := []*output.Result{
+		{
+			Request:  &navigation.Request{Method: http.MethodGet, URL: "https://example.com/b?x=1&y=2"},
+			Response: &navigation.Response{StatusCode: http.StatusOK, Headers: map[string]string{"Last-Modified": "Wed, 21 Oct 2015 07:28:00 GMT"}},
+		},
+		{Request: &navigation.Request{Method: http.MethodGet, URL: "https://example.com/a"}, Response: &navigation.Response{StatusCode: http.StatusOK}},
+		{Request: &navigation.Request{Method: http.MethodGet, URL: "https://example.com/a"}, Response: &navigation.Response{StatusCode: http.StatusOK}},
+		{Request: &navigation.Request{Method: http.MethodGet, URL: "https://example.com/missing"}, Response: &navigation.Response{StatusCode: http.StatusNotFound}},
+		{Request: &navigation.Request{Method: http.MethodPost, URL: "https://example.com/login"}, Response: &navigation.Response{StatusCode: http.StatusOK}},
+		{Request: &navigation.Request{Method: http.MethodGet, URL: "https://other.com/"}, Error: "out of scope"},
+		{Request: &navigation.Request{Method: http.MethodGet, URL: "https://example.com/found"}},
+	}
+	for _, result := range results {
+		require.NoError(t, writer.Write(result))
+	}
+	require.Equal(t, len(results), mock.results, "all results should be written to the wrapped writer")
+	require.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/a</loc>
+  </url>
+  <url>
+    <loc>https://example.com/b?x=1&amp;y=2</loc>
+    <lastmod>2015-10-21T07:28:00Z</lastmod>
+  </url>
+</urlset>
+`, string(data))
+}
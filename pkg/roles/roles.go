@@ -0,0 +1,285 @@
+// Package roles implements crawling the same targets as multiple
+// roles (eg. admin, user, anonymous) and comparing the endpoints and
+// headless page states reachable by each of them for access control
+// analysis.
+package roles
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/katana/pkg/utils/headerrules"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// Role is a set of credentials the targets are crawled with
+type Role struct {
+	Name string
+	// Rules are the credential and header rules of the role,
+	// anonymous roles have none
+	Rules headerrules.Rules
+	// AuthScript is the headless login script of the role, if any
+	AuthScript string
+}
+
+// authScriptPrefix prefixes the auth script of a role
+const authScriptPrefix = "auth-script:"
+
+// Parse parses roles in the `name`, `name=host=basic:user:pass`,
+// `name=host=bearer:token`, `name=[host][/path] header: value` and
+// `name=auth-script:file` formats. Values of the same role name are
+// merged in order.
+func Parse(values []string) ([]*Role, error) {
+	var roles []*Role
+	byName := make(map[string]*Role)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		name, spec, hasSpec := strings.Cut(value, "=")
+		name = strings.TrimSpace(name)
+		if name == "" || strings.ContainsAny(name, " :/") {
+			return nil, errkit.New(fmt.Sprintf("invalid role %q, expected name[=credential|header rule]", value))
+		}
+		role, ok := byName[name]
+		if !ok {
+			role = &Role{Name: name}
+			byName[name] = role
+			roles = append(roles, role)
+		}
+		if !hasSpec {
+			continue
+		}
+		spec = strings.TrimSpace(spec)
+		if script, ok := strings.CutPrefix(spec, authScriptPrefix); ok {
+			if role.AuthScript != "" {
+				return nil, errkit.New(fmt.Sprintf("invalid role %q, only one auth script is supported", name))
+			}
+			if script = strings.TrimSpace(script); script == "" {
+				return nil, errkit.New(fmt.Sprintf("invalid role %q, auth script file is empty", name))
+			}
+			role.AuthScript = script
+			continue
+		}
+		rules, err := parseSpec(spec)
+		if err != nil {
+			return nil, errkit.Wrap(err, fmt.Sprintf("invalid role %q", name))
+		}
+		role.Rules = append(role.Rules, rules...)
+	}
+	return roles, nil
+}
+
+// parseSpec parses a credential or a header rule of a role, header
+// rules are told apart by the space between their pattern and header
+func parseSpec(spec string) (headerrules.Rules, error) {
+	pattern, _, _ := strings.Cut(spec, " ")
+	if strings.Contains(pattern, "=") {
+		return headerrules.ParseCredentials([]string{spec})
+	}
+	return headerrules.Parse([]string{spec})
+}
+
+// Endpoint is an endpoint discovered by at least one role
+type Endpoint struct {
+	// Endpoint is the endpoint as "METHOD URL"
+	Endpoint string `json:"endpoint"`
+	// StatusCodes are the response status codes of the endpoint per
+	// role, zero if the request of the role failed
+	StatusCodes map[string]int `json:"status_codes"`
+}
+
+// Reachable returns the roles which got a successful response
+func (e Endpoint) Reachable() []string {
+	return reachable(e.StatusCodes)
+}
+
+// State is a headless page state reached by at least one role
+type State struct {
+	URL string `json:"url"`
+	// Title is the title of the page when it was first reached
+	Title string `json:"title,omitempty"`
+	// StatusCodes are the document status codes of the state per role
+	StatusCodes map[string]int `json:"status_codes"`
+}
+
+// Reachable returns the roles which reached the state successfully
+func (s State) Reachable() []string {
+	return reachable(s.StatusCodes)
+}
+
+// reachable returns the roles with a successful status code
+func reachable(statusCodes map[string]int) []string {
+	var roles []string
+	for role, statusCode := range statusCodes {
+		if successful(statusCode) {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+func successful(statusCode int) bool {
+	return statusCode > 0 && statusCode < http.StatusBadRequest
+}
+
+// Comparison are the endpoints and page states discovered by the roles
+type Comparison struct {
+	Roles     []string   `json:"roles"`
+	Endpoints []Endpoint `json:"endpoints"`
+	// States are the page states reached by headless crawls
+	States []State `json:"states,omitempty"`
+}
+
+// Restricted returns the number of endpoints reachable by each role
+// which are not reachable by every role
+func (c *Comparison) Restricted() map[string]int {
+	restricted := make(map[string]int)
+	for _, endpoint := range c.Endpoints {
+		c.countRestricted(restricted, endpoint.Reachable())
+	}
+	return restricted
+}
+
+// RestrictedStates returns the number of page states reached by each
+// role which are not reached by every role
+func (c *Comparison) RestrictedStates() map[string]int {
+	restricted := make(map[string]int)
+	for _, state := range c.States {
+		c.countRestricted(restricted, state.Reachable())
+	}
+	return restricted
+}
+
+func (c *Comparison) countRestricted(restricted map[string]int, reachable []string) {
+	if len(reachable) == len(c.Roles) {
+		return
+	}
+	for _, role := range reachable {
+		restricted[role]++
+	}
+}
+
+// Save writes the comparison to file as json
+func (c *Comparison) Save(file string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errkit.Wrap(err, "roles: could not marshal comparison")
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return errkit.Wrap(err, "roles: could not write comparison")
+	}
+	return nil
+}
+
+// Recorder is an output writer recording the endpoints of the results
+// and the page states reached by each role. Results are written with
+// the role that crawled them.
+type Recorder struct {
+	output.Writer
+
+	mu        sync.Mutex
+	role      string
+	roles     []string
+	endpoints map[string]map[string]int
+	states    map[string]*State
+}
+
+// NewRecorder creates a new role recorder writing to writer
+func NewRecorder(writer output.Writer) *Recorder {
+	return &Recorder{
+		Writer:    writer,
+		endpoints: make(map[string]map[string]int),
+		states:    make(map[string]*State),
+	}
+}
+
+// SetRole sets the role of the following results
+func (r *Recorder) SetRole(role string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.role = role
+	r.roles = append(r.roles, role)
+}
+
+// Write records the endpoint of the result for the current role
+func (r *Recorder) Write(result *output.Result) error {
+	r.mu.Lock()
+	result.Role = r.role
+	if result.Request != nil && result.Finding == nil {
+		method := result.Request.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		endpoint := method + " " + result.Request.URL
+		statusCodes, ok := r.endpoints[endpoint]
+		if !ok {
+			statusCodes = make(map[string]int)
+			r.endpoints[endpoint] = statusCodes
+		}
+		if result.Response != nil && result.Error == "" {
+			statusCodes[r.role] = result.Response.StatusCode
+		} else if _, seen := statusCodes[r.role]; !seen {
+			statusCodes[r.role] = 0
+		}
+	}
+	r.mu.Unlock()
+	return r.Writer.Write(result)
+}
+
+// RecordState records a page state reached by the current role, the
+// states are compared by url so that pages rendering the user (eg. a
+// greeting) are the same state for every role.
+func (r *Recorder) RecordState(URL, title string, statusCode int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.states[URL]
+	if !ok {
+		state = &State{URL: URL, Title: title, StatusCodes: make(map[string]int)}
+		r.states[URL] = state
+	}
+	// a state reached successfully once is reachable by the role
+	if previous, seen := state.StatusCodes[r.role]; !seen || !successful(previous) {
+		state.StatusCodes[r.role] = statusCode
+	}
+}
+
+// Compare returns the endpoints and page states discovered by the
+// recorded roles
+func (r *Recorder) Compare() *Comparison {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comparison := &Comparison{Roles: append([]string(nil), r.roles...)}
+	for endpoint, statusCodes := range r.endpoints {
+		codes := make(map[string]int, len(statusCodes))
+		for role, statusCode := range statusCodes {
+			codes[role] = statusCode
+		}
+		comparison.Endpoints = append(comparison.Endpoints, Endpoint{Endpoint: endpoint, StatusCodes: codes})
+	}
+	sort.Slice(comparison.Endpoints, func(i, j int) bool {
+		return comparison.Endpoints[i].Endpoint < comparison.Endpoints[j].Endpoint
+	})
+	for _, state := range r.states {
+		copied := *state
+		copied.StatusCodes = make(map[string]int, len(state.StatusCodes))
+		for role, statusCode := range state.StatusCodes {
+			copied.StatusCodes[role] = statusCode
+		}
+		comparison.States = append(comparison.States, copied)
+	}
+	sort.Slice(comparison.States, func(i, j int) bool {
+		return comparison.States[i].URL < comparison.States[j].URL
+	})
+	return comparison
+}
@@ -0,0 +1,92 @@
+package roles
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWriter struct{ results []*output.Result }
+
+func (m *mockWriter) Close() error { return nil }
+func (m *mockWriter) Write(result *output.Result) error {
+	m.results = append(m.results, result)
+	return nil
+}
+func (m *mockWriter) WriteErr(*output.Error) error { return nil }
+
+func TestParse(t *testing.T) {
+	roles, err := Parse([]string{
+		"admin=example.com=basic:admin:secret",
+		"user=example.com=bearer:token",
+		"admin=example.com/admin X-Role: admin",
+		"anonymous",
+		"user=auth-script:user-login.yaml",
+	})
+	require.NoError(t, err)
+	require.Len(t, roles, 3)
+	require.Equal(t, "admin", roles[0].Name)
+	require.Len(t, roles[0].Rules, 2, "values of the same role should be merged")
+	require.Equal(t, map[string]string{"Authorization": "Bearer token"}, roles[1].Rules.HeadersString("https://example.com/"))
+	require.Equal(t, "user-login.yaml", roles[1].AuthScript)
+	require.Empty(t, roles[2].Rules)
+
+	for _, invalid := range []string{"=example.com=bearer:token", "admin=example.com=digest:a:b", "admin=example.com", "admin=auth-script:"} {
+		_, err := Parse([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	mock := &mockWriter{}
+	recorder := NewRecorder(mock)
+	write := func(URL string, statusCode int) {
+		_ = recorder.Write(&output.Result{
+			Request:  &navigation.Request{Method: http.MethodGet, URL: URL},
+			Response: &navigation.Response{StatusCode: statusCode},
+		})
+	}
+
+	recorder.SetRole("admin")
+	write("https://example.com/", http.StatusOK)
+	write("https://example.com/admin", http.StatusOK)
+	recorder.SetRole("user")
+	write("https://example.com/", http.StatusOK)
+	write("https://example.com/admin", http.StatusForbidden)
+
+	require.Equal(t, "user", mock.results[3].Role, "results should be written with their role")
+
+	comparison := recorder.Compare()
+	require.Equal(t, []string{"admin", "user"}, comparison.Roles)
+	require.Len(t, comparison.Endpoints, 2)
+	require.Equal(t, "GET https://example.com/admin", comparison.Endpoints[1].Endpoint)
+	require.Equal(t, []string{"admin"}, comparison.Endpoints[1].Reachable())
+	require.Equal(t, map[string]int{"admin": 1}, comparison.Restricted())
+}
+
+func TestRecorderStates(t *testing.T) {
+	recorder := NewRecorder(&mockWriter{})
+
+	recorder.SetRole("admin")
+	recorder.RecordState("https://example.com/", "Welcome admin", http.StatusOK)
+	recorder.RecordState("https://example.com/#/settings", "Settings", http.StatusOK)
+	recorder.SetRole("user")
+	recorder.RecordState("https://example.com/", "Welcome user", http.StatusOK)
+	recorder.RecordState("https://example.com/#/settings", "Forbidden", http.StatusForbidden)
+	recorder.RecordState("https://example.com/#/profile", "Profile", http.StatusForbidden)
+	recorder.RecordState("https://example.com/#/profile", "Profile", http.StatusOK)
+	recorder.RecordState("https://example.com/#/profile", "Profile", http.StatusForbidden)
+
+	comparison := recorder.Compare()
+	require.Len(t, comparison.States, 3)
+	require.Equal(t, "https://example.com/", comparison.States[0].URL)
+	require.Equal(t, "Welcome admin", comparison.States[0].Title, "the title of the first visit should be kept")
+	require.Equal(t, []string{"admin", "user"}, comparison.States[0].Reachable())
+	require.Equal(t, []string{"admin"}, comparison.States[2].Reachable())
+	require.Equal(t, []string{"user"}, comparison.States[1].Reachable(), "states reached once should be reachable")
+	require.Equal(t, map[string]int{"admin": 1, "user": 1}, comparison.RestrictedStates())
+	require.Empty(t, comparison.Restricted())
+}
@@ -1,19 +1,36 @@
 package types
 
 import (
-	"context"
 	"log/slog"
+	"net/url"
 	"os/user"
 	"regexp"
 	"time"
 
 	"github.com/projectdiscovery/fastdialer/fastdialer"
 	"github.com/projectdiscovery/katana/pkg/engine/parser"
+	"github.com/projectdiscovery/katana/pkg/importer"
+	"github.com/projectdiscovery/katana/pkg/integrations/defectdojo"
+	"github.com/projectdiscovery/katana/pkg/integrations/interactsh"
+	"github.com/projectdiscovery/katana/pkg/integrations/nuclei"
+	"github.com/projectdiscovery/katana/pkg/navigation"
 	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/katana/pkg/output/enrich"
+	"github.com/projectdiscovery/katana/pkg/output/objectref"
+	"github.com/projectdiscovery/katana/pkg/output/sitemap"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/katana/pkg/utils/adaptive"
+	"github.com/projectdiscovery/katana/pkg/utils/blockdetect"
+	"github.com/projectdiscovery/katana/pkg/utils/formbudget"
+	"github.com/projectdiscovery/katana/pkg/utils/headerrules"
+	"github.com/projectdiscovery/katana/pkg/utils/hostmap"
+	"github.com/projectdiscovery/katana/pkg/utils/inventory"
+	"github.com/projectdiscovery/katana/pkg/utils/resourceblock"
+	"github.com/projectdiscovery/katana/pkg/utils/debugserver"
 	"github.com/projectdiscovery/katana/pkg/utils/extensions"
 	"github.com/projectdiscovery/katana/pkg/utils/filters"
 	"github.com/projectdiscovery/katana/pkg/utils/scope"
-	"github.com/projectdiscovery/ratelimit"
+	"github.com/projectdiscovery/katana/pkg/utils/throttle"
 	"github.com/happyhackingspace/dit"
 	"github.com/projectdiscovery/utils/errkit"
 	urlutil "github.com/projectdiscovery/utils/url"
@@ -24,8 +41,18 @@ import (
 type CrawlerOptions struct {
 	// OutputWriter is the interface for writing output
 	OutputWriter output.Writer
-	// RateLimit is a mechanism for controlling request rate limit
-	RateLimit *ratelimit.Limiter
+	// HeaderRules are headers added to the requests matching their patterns
+	HeaderRules headerrules.Rules
+	// HostMap rewrites the urls of the mapped hosts to their target
+	HostMap hostmap.Map
+	// BlockedResources are the resource types of the browser requests blocked
+	BlockedResources resourceblock.Types
+	// DomainInventory aggregates the third-party domains of targets when set
+	DomainInventory *inventory.Inventory
+	// ErrorStats counts the written errors per class
+	ErrorStats *output.ErrorStats
+	// RateLimit is the request rate limit shared by all engines
+	RateLimit *throttle.Limiter
 	// Parser is a mechanism for extracting new URLS from responses
 	Parser *parser.Parser
 	// Options contains the user specified configuration options
@@ -36,6 +63,8 @@ type CrawlerOptions struct {
 	UniqueFilter filters.Filter
 	// ScopeManager is a manager for validating crawling scope
 	ScopeManager *scope.Manager
+	// ScopeWatcher reloads the scope rule files during the crawl, if any
+	ScopeWatcher *scope.Watcher
 	// Dialer is instance of the dialer for global crawler
 	Dialer *fastdialer.Dialer
 	// Wappalyzer instance for technologies detection
@@ -47,6 +76,23 @@ type CrawlerOptions struct {
 	Logger *slog.Logger
 	// ChromeUser is the user to use for chrome
 	ChromeUser *user.User
+	// Seeds are the requests imported from proxy history or HAR files
+	Seeds []*navigation.Request
+	// FormMarkers injects interaction markers into submitted forms
+	FormMarkers *utils.FormMarkers
+	// BlockTracker tracks hosts serving block pages
+	BlockTracker *blockdetect.Tracker
+	// AdaptiveConcurrency adjusts the concurrency per host
+	AdaptiveConcurrency *adaptive.Controller
+	// FormBudget limits the auto-filled forms per host and their submissions
+	FormBudget *formbudget.Budget
+	// Deadline is the end of the crawl budget of the run, zero for no budget
+	Deadline time.Time
+	// Session is the metadata of the run stamped into the output, if any
+	Session *output.Session
+	// OnPageState is called with the in scope page states reached
+	// by the headless crawler, if set
+	OnPageState func(URL, title string, statusCode int)
 }
 
 // NewCrawlerOptions creates a new crawler options structure
@@ -55,16 +101,6 @@ func NewCrawlerOptions(options *Options) (*CrawlerOptions, error) {
 	options.ConfigureOutput()
 	extensionsValidator := extensions.NewValidator(options.ExtensionsMatch, options.ExtensionFilter, options.NoDefaultExtFilter)
 
-	parserOptions := &parser.Options{
-		AutomaticFormFill:      options.AutomaticFormFill,
-		ScrapeJSLuiceResponses: options.ScrapeJSLuiceResponses,
-		ScrapeJSResponses:      options.ScrapeJSResponses,
-		DisableRedirects:       options.DisableRedirects,
-	}
-
-	responseParser := parser.NewResponseParser()
-	responseParser.InitWithOptions(parserOptions)
-
 	dialerOpts := fastdialer.DefaultOptions
 	if len(options.Resolvers) > 0 {
 		dialerOpts.BaseResolvers = options.Resolvers
@@ -78,6 +114,13 @@ func NewCrawlerOptions(options *Options) (*CrawlerOptions, error) {
 	if err != nil {
 		return nil, errkit.Wrap(err, "could not create scope manager")
 	}
+	var scopeWatcher *scope.Watcher
+	if options.ScopeFile != "" || options.OutOfScopeFile != "" {
+		scopeWatcher, err = scope.NewWatcher(scopeManager, options.Scope, options.OutOfScope, options.ScopeFile, options.OutOfScopeFile)
+		if err != nil {
+			return nil, errkit.Wrap(err, "could not load scope rule files")
+		}
+	}
 	itemFilter, err := filters.NewSimple()
 	if err != nil {
 		return nil, errkit.Wrap(err, "could not create filter")
@@ -98,6 +141,7 @@ func NewCrawlerOptions(options *Options) (*CrawlerOptions, error) {
 		OmitBody:              options.OmitBody,
 		FieldConfig:           options.FieldConfig,
 		ErrorLogFile:          options.ErrorLogFile,
+		EncryptRecipients:     options.EncryptRecipients,
 		MatchRegex:            options.MatchRegex,
 		FilterRegex:           options.FilterRegex,
 		ExtensionValidator:    extensionsValidator,
@@ -106,6 +150,7 @@ func NewCrawlerOptions(options *Options) (*CrawlerOptions, error) {
 		OutputFilterCondition: options.OutputFilterCondition,
 		ExcludeOutputFields:   options.ExcludeOutputFields,
 		FilterPageType:        options.FilterPageType,
+		SchemaVersion:         options.OutputSchemaVersion,
 	}
 
 	for _, mr := range options.OutputMatchRegex {
@@ -123,25 +168,162 @@ func NewCrawlerOptions(options *Options) (*CrawlerOptions, error) {
 		outputOptions.FilterRegex = append(outputOptions.FilterRegex, cr)
 	}
 
+	session, err := output.NewSession(options.SessionName, options.RunID, options.Labels)
+	if err != nil {
+		return nil, errkit.Wrap(err, "could not parse session labels")
+	}
+	outputOptions.Session = session
+	outputOptions.ErrorStats = output.NewErrorStats()
 	outputWriter, err := output.New(outputOptions)
 	if err != nil {
 		return nil, errkit.Wrap(err, "could not create output writer")
 	}
+	// hosts behind filtered cdns are only known once enriched
+	if len(options.FilterCDN) > 0 {
+		options.EnrichHosts = true
+	}
+	if options.EnrichHosts {
+		outputWriter = enrich.NewWriter(outputWriter, enrich.DialerResolver(fastdialerInstance), enrich.LookupASN, options.FilterCDN)
+	}
+	if options.Nuclei {
+		nucleiWriter, err := nuclei.NewWriter(outputWriter, nuclei.Options{
+			Tags: options.NucleiTags,
+		})
+		if err != nil {
+			return nil, errkit.Wrap(err, "could not create nuclei scanner")
+		}
+		outputWriter = nucleiWriter
+	}
+	var formMarkers *utils.FormMarkers
+	if options.Interactsh {
+		interactshWriter, err := interactsh.NewWriter(outputWriter, interactsh.Options{
+			ServerURL: options.InteractshServer,
+			Token:     options.InteractshToken,
+		})
+		if err != nil {
+			return nil, errkit.Wrap(err, "could not create interactsh client")
+		}
+		formMarkers = interactshWriter.FormMarkers(options.InteractshHeaders)
+		outputWriter = interactshWriter
+	}
+	if options.DefectDojoOutput != "" {
+		// classified endpoints are only reported with the knowledge base
+		if len(options.DefectDojoPageTypes) > 0 {
+			options.KnowledgeBase = true
+		}
+		outputWriter = defectdojo.NewWriter(outputWriter, options.DefectDojoOutput, options.DefectDojoPageTypes)
+	}
+	if options.SitemapOutput != "" {
+		outputWriter = sitemap.NewWriter(outputWriter, options.SitemapOutput)
+	}
+	if options.ObjectReferenceOutput != "" {
+		outputWriter = objectref.NewWriter(outputWriter, options.ObjectReferenceOutput)
+	}
+
+	if options.PprofAddr != "" || options.StatsJSON {
+		outputWriter = debugserver.NewWriter(outputWriter)
+	}
+
+	parserOptions := &parser.Options{
+		AutomaticFormFill:      options.AutomaticFormFill,
+		ScrapeJSLuiceResponses: options.ScrapeJSLuiceResponses,
+		ScrapeJSResponses:      options.ScrapeJSResponses,
+		DisableRedirects:       options.DisableRedirects,
+		FormMarkers:            formMarkers,
+		MaxParseSize:           options.MaxParseSize,
+		ParseContentTypes:      options.ParseContentTypes,
+		SkipNonCanonical:       options.SkipNonCanonical,
+		HandlerAttributes:      options.HandlerAttributes,
+		ClientRedirects:        options.ClientRedirects,
+	}
+
+	responseParser := parser.NewResponseParser()
+	responseParser.InitWithOptions(parserOptions)
 
 	crawlerOptions := &CrawlerOptions{
 		ExtensionsValidator: extensionsValidator,
 		Parser:              responseParser,
 		ScopeManager:        scopeManager,
+		ScopeWatcher:        scopeWatcher,
 		UniqueFilter:        itemFilter,
 		Options:             options,
 		Dialer:              fastdialerInstance,
 		OutputWriter:        outputWriter,
+		ErrorStats:          outputOptions.ErrorStats,
+		FormMarkers:         formMarkers,
+		Session:             session,
+	}
+
+	if options.CrawlBudget > 0 {
+		crawlerOptions.Deadline = time.Now().Add(options.CrawlBudget)
 	}
 
+	headerRules, err := headerrules.Parse(options.HeaderRules)
+	if err != nil {
+		return nil, errkit.Wrap(err, "could not parse header rules")
+	}
+	// credentials are applied before the header rules so that
+	// an explicit Authorization header rule overrides them
+	credentials, err := headerrules.ParseCredentials(options.Credentials)
+	if err != nil {
+		return nil, errkit.Wrap(err, "could not parse credentials")
+	}
+	crawlerOptions.HeaderRules = append(credentials, headerRules...)
+
+	hostMap, err := hostmap.Parse(options.HostMap)
+	if err != nil {
+		return nil, errkit.Wrap(err, "could not parse host map")
+	}
+	crawlerOptions.HostMap = hostMap
+
+	blockedResources, err := resourceblock.Parse(options.BlockResources)
+	if err != nil {
+		return nil, errkit.Wrap(err, "could not parse blocked resources")
+	}
+	crawlerOptions.BlockedResources = blockedResources
+
+	if options.DomainInventory {
+		crawlerOptions.DomainInventory = inventory.New()
+	}
+
+	hostLimits, err := throttle.ParseHostLimits(options.RateLimitHosts)
+	if err != nil {
+		return nil, errkit.Wrap(err, "could not parse host rate limits")
+	}
 	if options.RateLimit > 0 {
-		crawlerOptions.RateLimit = ratelimit.New(context.Background(), uint(options.RateLimit), time.Second)
+		crawlerOptions.RateLimit = throttle.New(uint(options.RateLimit), time.Second, hostLimits)
 	} else if options.RateLimitMinute > 0 {
-		crawlerOptions.RateLimit = ratelimit.New(context.Background(), uint(options.RateLimitMinute), time.Minute)
+		crawlerOptions.RateLimit = throttle.New(uint(options.RateLimitMinute), time.Minute, hostLimits)
+	} else if len(hostLimits) > 0 {
+		crawlerOptions.RateLimit = throttle.New(0, time.Second, hostLimits)
+	}
+
+	if options.BlockDetection {
+		var proxies []*url.URL
+		for _, proxy := range options.BlockRotateProxy {
+			proxyURL, err := url.Parse(proxy)
+			if err != nil {
+				return nil, errkit.Wrap(err, "invalid value for block rotate proxy option")
+			}
+			proxies = append(proxies, proxyURL)
+		}
+		crawlerOptions.BlockTracker = blockdetect.NewTracker(blockdetect.Options{
+			Threshold:       options.BlockThreshold,
+			Cooldown:        options.BlockCooldown,
+			RotateUserAgent: options.BlockRotateUserAgent,
+			Proxies:         proxies,
+		})
+	}
+
+	if options.MaxForms > 0 || options.MaxFormSubmissions > 0 {
+		crawlerOptions.FormBudget = formbudget.New(options.MaxForms, options.MaxFormSubmissions)
+	}
+
+	if options.AdaptiveConcurrency {
+		crawlerOptions.AdaptiveConcurrency = adaptive.New(adaptive.Options{
+			Max:           options.Concurrency,
+			TargetLatency: options.AdaptiveLatency,
+		})
 	}
 
 	if options.TechDetect {
@@ -163,19 +345,69 @@ func NewCrawlerOptions(options *Options) (*CrawlerOptions, error) {
 		crawlerOptions.DitClassifier = classifier
 	}
 
+	if options.ImportFile != "" {
+		seeds, err := importer.ParseFile(options.ImportFile)
+		if err != nil {
+			return nil, errkit.Wrap(err, "could not import seed requests")
+		}
+		for _, seed := range seeds {
+			if !options.ImportHeaders {
+				seed.Headers = nil
+			}
+			seed.URL = crawlerOptions.HostMap.Apply(seed.URL)
+		}
+		crawlerOptions.Seeds = seeds
+	}
+
 	if options.MaxOnclickLinks <= 0 {
 		options.MaxOnclickLinks = 10
 	}
 
+	if scopeWatcher != nil {
+		scopeWatcher.Start(scope.DefaultWatchInterval)
+	}
 	return crawlerOptions, nil
 }
 
+// CrawlTimeout returns the maximum duration of the crawl of a target,
+// the crawl duration bounded by the remaining crawl budget of the run,
+// or zero for no limit. expired is true if the budget is exhausted.
+func (c *CrawlerOptions) CrawlTimeout() (timeout time.Duration, expired bool) {
+	timeout = c.Options.CrawlDuration
+	if c.Deadline.IsZero() {
+		return timeout, false
+	}
+	remaining := time.Until(c.Deadline)
+	if remaining <= 0 {
+		return 0, true
+	}
+	if timeout <= 0 || remaining < timeout {
+		timeout = remaining
+	}
+	return timeout, false
+}
+
 // Close closes the crawler options resources
 func (c *CrawlerOptions) Close() error {
+	if c.ScopeWatcher != nil {
+		c.ScopeWatcher.Close()
+	}
 	c.UniqueFilter.Close()
 	return c.OutputWriter.Close()
 }
 
+// ResetUniqueFilter replaces the deduplication filter so that
+// already crawled items are crawled again
+func (c *CrawlerOptions) ResetUniqueFilter() error {
+	itemFilter, err := filters.NewSimple()
+	if err != nil {
+		return errkit.Wrap(err, "could not create filter")
+	}
+	c.UniqueFilter.Close()
+	c.UniqueFilter = itemFilter
+	return nil
+}
+
 func (c *CrawlerOptions) ValidatePath(path string) bool {
 	if c.ExtensionsValidator != nil {
 		return c.ExtensionsValidator.ValidatePath(path)
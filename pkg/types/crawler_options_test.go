@@ -0,0 +1,29 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrawlTimeout(t *testing.T) {
+	options := &CrawlerOptions{Options: &Options{CrawlDuration: time.Minute}}
+	timeout, expired := options.CrawlTimeout()
+	require.False(t, expired)
+	require.Equal(t, time.Minute, timeout, "crawl duration should be used without budget")
+
+	options.Deadline = time.Now().Add(10 * time.Second)
+	timeout, expired = options.CrawlTimeout()
+	require.False(t, expired)
+	require.LessOrEqual(t, timeout, 10*time.Second, "crawl duration should be bounded by the remaining budget")
+	require.Greater(t, timeout, time.Duration(0))
+
+	options.Options.CrawlDuration = 0
+	timeout, _ = options.CrawlTimeout()
+	require.Greater(t, timeout, time.Duration(0), "remaining budget should bound unlimited crawls")
+
+	options.Deadline = time.Now().Add(-time.Second)
+	_, expired = options.CrawlTimeout()
+	require.True(t, expired)
+}
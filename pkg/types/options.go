@@ -30,6 +30,10 @@ type Options struct {
 	Scope goflags.StringSlice
 	// OutOfScope contains a list of regexes for out-scope URLS
 	OutOfScope goflags.StringSlice
+	// ScopeFile is a file of in-scope url regexes reloaded on change
+	ScopeFile string
+	// OutOfScopeFile is a file of out-of-scope url regexes reloaded on change
+	OutOfScopeFile string
 	// NoScope disables host based default scope
 	NoScope bool
 	// DisplayOutScope displays out of scope items in results
@@ -48,14 +52,51 @@ type Options struct {
 	MaxDepth int
 	// BodyReadSize is the maximum size of response body to read
 	BodyReadSize int
+	// MaxParseSize is the maximum size of response body to parse
+	MaxParseSize int
+	// ParseContentTypes are the content types of responses to parse
+	ParseContentTypes goflags.StringSlice
+	// SkipNonCanonical skips crawling the links of pages declaring another
+	// canonical url and the amp and mobile alternates of pages
+	SkipNonCanonical bool
+	// HandlerAttributes are the element attributes parsed for urls, inline
+	// event handlers (on*) are parsed for the urls they navigate to
+	HandlerAttributes goflags.StringSlice
 	// Timeout is the time to wait for request in seconds
 	Timeout int
 	// TimeStable is the time to wait until the page is stable
 	TimeStable int
 	// CrawlDuration is the duration in seconds to crawl target from
 	CrawlDuration time.Duration
+	// CrawlBudget is the maximum wall-clock duration of the whole
+	// run, targets are not crawled once it is exhausted
+	CrawlBudget time.Duration
+	// StateDuration is the maximum duration spent on a single
+	// page state in headless mode
+	StateDuration time.Duration
 	// MaxFailureCount is the maximum number of consecutive failures before stopping
 	MaxFailureCount int
+	// ReducedMotion emulates prefers-reduced-motion and suppresses animations in headless mode
+	ReducedMotion bool
+	// DeterministicSeed seeds headless page randomness and clock when not zero
+	DeterministicSeed int
+	// HeadlessActionTimeouts are the timeouts per headless action kind (eg. navigation=45s,click=5s)
+	HeadlessActionTimeouts goflags.StringSlice
+	// HeadlessSimilarity are the settings of the headless near-duplicate
+	// page state detection (eg. threshold=4,shingle=2,text=0)
+	HeadlessSimilarity goflags.StringSlice
+	// HeadlessResourceTypes are the resource types of browser requests reported in headless mode (eg. xhr,fetch,document)
+	HeadlessResourceTypes goflags.StringSlice
+	// BlockResources are the resource types of browser requests blocked in
+	// headless and hybrid mode (eg. static or image,font,media,stylesheet)
+	BlockResources goflags.StringSlice
+	// BlockThirdParty blocks the browser requests to out of scope hosts in
+	// headless and hybrid mode, the blocked urls are written to the output
+	BlockThirdParty bool
+	// HeadlessClientCert is the PEM client certificate presented to servers requesting one in headless mode
+	HeadlessClientCert string
+	// HeadlessClientKey is the PEM key of the client certificate, read from the certificate file if empty
+	HeadlessClientKey string
 	// Delay is the delay between each crawl requests in seconds
 	Delay int
 	// RateLimit is the maximum number of requests to send per second
@@ -64,6 +105,10 @@ type Options struct {
 	Retries int
 	// RateLimitMinute is the maximum number of requests to send per minute
 	RateLimitMinute int
+	// RespectRobots skips paths disallowed by robots.txt and honors crawl-delay
+	RespectRobots bool
+	// RateLimitHosts are hosts with their own requests per second limit (host=rps)
+	RateLimitHosts goflags.StringSlice
 	// Concurrency is the number of concurrent crawling goroutines
 	Concurrency int
 	// Parallelism is the number of urls processing goroutines
@@ -72,14 +117,26 @@ type Options struct {
 	FormConfig string
 	// Proxy is the URL for the proxy server
 	Proxy string
+	// SSHTunnel is the user@host[:port] jump host the traffic is tunneled through
+	SSHTunnel string
+	// SSHKey is the private key used to authenticate to the jump host
+	SSHKey string
+	// SSHKnownHosts is the known hosts file verifying the jump host key
+	SSHKnownHosts string
 	// Strategy is the crawling strategy. depth-first or breadth-first
 	Strategy string
 	// FieldScope is the scope field for default DNS scope
 	FieldScope string
+	// DomainInventory prints the third-party domains contacted by the pages of targets
+	DomainInventory bool
+	// EnrichHosts annotates results with the ips, asn and cdn/waf of their hosts
+	EnrichHosts bool
 	// OutputFile is the file to write output to
 	OutputFile string
 	// KnownFiles enables crawling of knows files like robots.txt, sitemap.xml, etc
 	KnownFiles string
+	// KnownFilesList are custom known files paths probed once per host
+	KnownFilesList goflags.StringSlice
 	// Fields is the fields to format in output
 	Fields string
 	// StoreFields is the fields to store in separate per-host files
@@ -94,6 +151,11 @@ type Options struct {
 	ExcludeOutputFields goflags.StringSlice
 	// ListOutputFields is the list of fields
 	ListOutputFields bool
+	// JSONSchema prints the JSON Schema of the jsonl output
+	JSONSchema bool
+	// OutputSchemaVersion is the version of the jsonl output schema,
+	// zero for the legacy output without the schema_version field
+	OutputSchemaVersion int
 	// Silent shows only output
 	Silent bool
 	// Verbose specifies showing verbose output
@@ -102,6 +164,10 @@ type Options struct {
 	TechDetect bool
 	// EnableDiagnostics enables diagnostics
 	EnableDiagnostics bool
+	// ReplayDiagnostics is the diagnostics directory whose actions are replayed
+	ReplayDiagnostics string
+	// ReplaySnapshot replays actions on the recorded page snapshots
+	ReplaySnapshot bool
 	// Version enables showing of crawler version
 	Version bool
 	// ScrapeJSResponses enables scraping of relative endpoints from javascript
@@ -110,12 +176,23 @@ type Options struct {
 	ScrapeJSLuiceResponses bool
 	// CustomHeaders is a list of custom headers to add to request
 	CustomHeaders goflags.StringSlice
+	// HeaderRules are headers sent only to matching hosts and paths
+	HeaderRules goflags.StringSlice
+	// Credentials are basic auth or bearer token credentials per host
+	Credentials goflags.StringSlice
+	// HostMap are host mappings in the from=to format, the urls of the mapped
+	// hosts are rewritten to their target (eg. prod.example.com=staging.example.com)
+	HostMap goflags.StringSlice
 	// Headless enables headless scraping
 	Headless bool
 	// HeadlessHybrid enables headless hybrid scraping
 	HeadlessHybrid bool
 	// AutomaticFormFill enables optional automatic form filling and submission
 	AutomaticFormFill bool
+	// MaxForms is the maximum number of distinct forms auto-filled per host (0 = unlimited)
+	MaxForms int
+	// MaxFormSubmissions is the maximum number of submissions of each form (0 = unlimited)
+	MaxFormSubmissions int
 	// FormExtraction enables extraction of form, input, textarea & select elements
 	FormExtraction bool
 	// UseInstalledChrome skips chrome install and use local instance
@@ -128,6 +205,54 @@ type Options struct {
 	HeadlessNoSandbox bool
 	// SystemChromePath : Specify the chrome binary path for headless crawling
 	SystemChromePath string
+	// HeadlessConcurrency is the number of browsers executing the
+	// actions of a headless crawl in parallel
+	HeadlessConcurrency int
+	// HeadlessMinBrowsers is the number of browsers kept open
+	// while the actions of a headless crawl are not backed up
+	HeadlessMinBrowsers int
+	// HeadlessBrowserIdleTimeout is the time after which the idle
+	// browsers above HeadlessMinBrowsers are closed
+	HeadlessBrowserIdleTimeout time.Duration
+	// MaxUniqueActions is the maximum number of headless action hashes kept in memory
+	MaxUniqueActions int
+	// SpillUniqueActions writes evicted headless action hashes to disk
+	SpillUniqueActions bool
+	// StorageStateDir is the directory the headless cookies and local
+	// storage of each target are saved to and restored from
+	StorageStateDir string
+	// SessionFile is the Playwright storage state file the headless
+	// session is loaded from before crawls and saved to after them
+	SessionFile string
+	// MixedContent reports http subresources and form actions of https pages in headless mode
+	MixedContent bool
+	// DOMSnapshotDir is the directory the raw and normalized DOM of
+	// each unique headless page state is archived to
+	DOMSnapshotDir string
+	// LanguageSweep are the Accept-Language values the key pages are
+	// re-requested with in headless mode to discover locale specific routes
+	LanguageSweep goflags.StringSlice
+	// CrossOriginFrames walks cross-origin frames for navigations in headless mode
+	CrossOriginFrames bool
+	// ScrollBudget is the maximum number of scroll steps per headless page
+	// state to load infinite feeds and lazy content (0 = disabled)
+	ScrollBudget int
+	// ErrorPageActions generates actions from 4xx/5xx pages in headless mode
+	ErrorPageActions bool
+	// HeadlessClickSelectors are css or xpath selectors of elements clicked
+	// in headless mode in addition to the ones found by the heuristics
+	HeadlessClickSelectors goflags.StringSlice
+	// HeadlessNoClickSelectors are css or xpath selectors of elements never clicked in headless mode
+	HeadlessNoClickSelectors goflags.StringSlice
+	// HeadlessLogoutPatterns replace (or with a + prefix add to) the patterns
+	// of the logout elements skipped in headless mode, none disables them
+	HeadlessLogoutPatterns goflags.StringSlice
+	// CrawlGraphDir is the directory the headless crawl graph of
+	// each target is exported to in CrawlGraphFormat
+	CrawlGraphDir    string
+	CrawlGraphFormat string
+	// HeadlessDebuggerAddr is the address of the live crawl debugger ui
+	HeadlessDebuggerAddr string
 	// ChromeWSUrl : Specify the Chrome debugger websocket url for a running Chrome instance to attach to
 	ChromeWSUrl string
 	// OnResult allows callback function on a result
@@ -146,6 +271,19 @@ type Options struct {
 	OmitRaw bool
 	// OmitBody omits the response body from the output
 	OmitBody bool
+	// SitemapOutput is the file to write the crawled urls to as sitemap.xml
+	SitemapOutput string
+	// ObjectReferenceOutput is the file to write the urls referencing
+	// objects by identifiers to grouped by pattern
+	ObjectReferenceOutput string
+	// EncryptRecipients are the age public keys output files and stored responses are encrypted to
+	EncryptRecipients goflags.StringSlice
+	// SessionName is the name of the run stamped into every output record
+	SessionName string
+	// RunID is the id of the run stamped into every output record
+	RunID string
+	// Labels are key=value labels stamped into every output record
+	Labels goflags.StringSlice
 	// ChromeDataDir : 	Specify the --user-data-dir to chrome binary to preserve sessions
 	ChromeDataDir string
 	// HeadlessNoIncognito specifies if chrome should be started without incognito mode
@@ -156,10 +294,25 @@ type Options struct {
 	HealthCheck bool
 	// PprofServer enables pprof server
 	PprofServer bool
+	// Bench is the stored responses directory replayed to benchmark the crawl pipeline
+	Bench string
+	// BenchIterations is the number of times the stored responses are replayed
+	BenchIterations int
+	// PprofAddr is the address of the runtime diagnostics listener
+	PprofAddr string
+	// FrontierDump is the file the pending requests of the crawl
+	// are written to on SIGUSR1 and interrupt
+	FrontierDump string
+	// StatsJSON periodically prints the crawl progress as json to stderr
+	StatsJSON bool
+	// StatsInterval is the interval of the json progress records
+	StatsInterval time.Duration
 	// ErrorLogFile specifies a file to write with the errors of all requests
 	ErrorLogFile string
 	// Resolvers contains custom resolvers
 	Resolvers goflags.StringSlice
+	// IPVersion is the preferred ip version (4 or 6) of dual-stack hosts
+	IPVersion string
 	// OutputTemplate enables custom output template
 	OutputTemplate string
 	// OutputMatchRegex is the regex to match output url
@@ -174,6 +327,8 @@ type Options struct {
 	DisableUpdateCheck bool
 	//IgnoreQueryParams ignore crawling same path with different query-param values
 	IgnoreQueryParams bool
+	// KeepFragments treats urls with different fragments as distinct urls
+	KeepFragments bool
 	// FilterSimilar filters crawling of similar looking URLs
 	// by normalizing variable path segments (IDs, UUIDs, hashes, dates)
 	FilterSimilar bool
@@ -186,6 +341,23 @@ type Options struct {
 	TlsImpersonate bool
 	// DisableRedirects disables the following of redirects
 	DisableRedirects bool
+	// ClientRedirects treats meta refresh and script redirects as redirects
+	// which are not followed when redirects are disabled
+	ClientRedirects bool
+	// MaxRedirects is the maximum number of redirects followed per request (default 10)
+	MaxRedirects int
+	// Soft404 probes hosts with a non-existent path and marks
+	// the responses matching it as soft 404 pages
+	Soft404 bool
+	// MethodDiscovery requests api endpoints with OPTIONS to
+	// record the methods they support
+	MethodDiscovery bool
+	// CORSProbe requests api endpoints with a crafted Origin to
+	// record their cross-origin policy
+	CORSProbe bool
+	// RedirectScope stops following redirects to other hosts, their
+	// targets are crawled as discovered urls when in scope
+	RedirectScope bool
 	// PathClimb enables path expansion (auto crawl discovered paths)
 	PathClimb bool
 	// DisableUniqueFilter disables duplicate content filtering
@@ -198,6 +370,85 @@ type Options struct {
 	KnowledgeBase bool
 	// FilterPageType filters results by page type
 	FilterPageType goflags.StringSlice
+	// FilterCDN filters results of hosts behind the cdns or wafs
+	FilterCDN goflags.StringSlice
+	// ImportFile is a Burp, ZAP or HAR export whose requests are used as crawl seeds
+	ImportFile string
+	// ImportHeaders reuses the headers and cookies of imported requests.
+	// It is not supported in headless mode.
+	ImportHeaders bool
+	// Reparse is a HAR file or store-response directory whose responses
+	// are parsed again without network access
+	Reparse string
+	// AuthScript is a Playwright script or Selenium IDE project used
+	// to authenticate the headless browser before crawling
+	AuthScript string
+	// SessionCheckURL is the page loaded to verify the authenticated session
+	SessionCheckURL string
+	// SessionCheckMarker is the text present on the session check page while logged in
+	SessionCheckMarker string
+	// SessionCheckInterval is the time between session checks
+	SessionCheckInterval time.Duration
+	// LoginPageURLs are regexes matching the url of the login page
+	// the crawl is kicked back to once its session expired
+	LoginPageURLs goflags.StringSlice
+	// LoginPageMarkers are texts present on the login page
+	LoginPageMarkers goflags.StringSlice
+	// CaptureProxy is the listen address of the intercepting proxy whose
+	// observed navigations are fed into the headless crawl queue
+	CaptureProxy string
+	// CaptureIdleTimeout stops waiting for captured navigations of a
+	// target once none were received for the duration
+	CaptureIdleTimeout time.Duration
+	// Nuclei enables scanning of discovered endpoints with nuclei templates
+	Nuclei bool
+	// NucleiTags are the nuclei template tags executed on discovered endpoints
+	NucleiTags goflags.StringSlice
+	// Interactsh enables out-of-band interaction markers in submitted forms
+	Interactsh bool
+	// InteractshServer is the interactsh server used for interaction markers
+	InteractshServer string
+	// InteractshToken is the authentication token of the interactsh server
+	InteractshToken string
+	// InteractshHeaders are the request headers injected with interaction markers
+	InteractshHeaders goflags.StringSlice
+	// DefectDojoOutput is the file to write DefectDojo generic findings to
+	DefectDojoOutput string
+	// DefectDojoPageTypes are the classified page types reported as DefectDojo findings
+	DefectDojoPageTypes goflags.StringSlice
+	// MonitorInterval re-crawls the targets on the interval reporting changes
+	MonitorInterval time.Duration
+	// MonitorState is the file storing the last crawl snapshot between runs
+	MonitorState string
+	// MonitorWebhook is the webhook url receiving the detected changes
+	MonitorWebhook string
+	// MonitorWebhookFormat is the payload format of the webhook (json, slack)
+	MonitorWebhookFormat string
+	// MonitorLengthThreshold is the content length delta in bytes above
+	// which a monitored endpoint is reported as changed
+	MonitorLengthThreshold int
+	// Roles are the roles the targets are crawled as for comparison
+	Roles goflags.StringSlice
+	// RoleReport is the file the comparison of the endpoints and page states of the roles is written to
+	RoleReport string
+	// BlockDetection detects block pages and cools blocked hosts down
+	BlockDetection bool
+	// BlockThreshold is the number of consecutive blocked responses before a cooldown
+	BlockThreshold int
+	// BlockCooldown is the initial cooldown duration of blocked hosts
+	BlockCooldown time.Duration
+	// BlockRotateUserAgent rotates the user agent of hosts after a cooldown
+	BlockRotateUserAgent bool
+	// BlockRotateProxy are the proxies rotated through for hosts after a cooldown
+	BlockRotateProxy goflags.StringSlice
+	// XhrFuzz requests captured xhr endpoints with parameter permutations
+	XhrFuzz bool
+	// XhrFuzzLimit is the maximum number of xhr parameter permutations per target
+	XhrFuzzLimit int
+	// AdaptiveConcurrency adjusts the concurrency per host from response times and errors
+	AdaptiveConcurrency bool
+	// AdaptiveLatency is the 95th percentile response time above which the concurrency is decreased
+	AdaptiveLatency time.Duration
 }
 
 func (options *Options) ParseCustomHeaders() map[string]string {
@@ -0,0 +1,196 @@
+// Package adaptive implements an AIMD controller adjusting the
+// number of concurrent requests per host based on the observed
+// response times and error rates.
+package adaptive
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWindow         = 20
+	defaultTargetLatency  = 2 * time.Second
+	defaultMaxErrorRate   = 0.1
+	defaultDecreaseFactor = 0.5
+)
+
+// Options contains the configuration of the controller
+type Options struct {
+	// Min is the minimum concurrency of a host
+	Min int
+	// Max is the maximum concurrency of a host
+	Max int
+	// TargetLatency is the 95th percentile response time above
+	// which the concurrency of a host is decreased
+	TargetLatency time.Duration
+	// MaxErrorRate is the ratio of failed requests above which
+	// the concurrency of a host is decreased
+	MaxErrorRate float64
+	// Window is the number of responses evaluated per adjustment
+	Window int
+}
+
+// HostStats are the concurrency statistics of a host
+type HostStats struct {
+	Host     string
+	Limit    int
+	Requests int
+	Errors   int
+	// Decreases is the number of times the concurrency was decreased
+	Decreases int
+}
+
+type hostState struct {
+	HostStats
+	limit     float64
+	inflight  int
+	latencies []time.Duration
+	failures  int
+	// released is closed and replaced when a request completes
+	released chan struct{}
+}
+
+// Controller adjusts the concurrency of hosts with additive
+// increase and multiplicative decrease.
+type Controller struct {
+	options Options
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// New creates a new adaptive concurrency controller
+func New(options Options) *Controller {
+	if options.Min <= 0 {
+		options.Min = 1
+	}
+	if options.Max < options.Min {
+		options.Max = options.Min
+	}
+	if options.TargetLatency <= 0 {
+		options.TargetLatency = defaultTargetLatency
+	}
+	if options.MaxErrorRate <= 0 {
+		options.MaxErrorRate = defaultMaxErrorRate
+	}
+	if options.Window <= 0 {
+		options.Window = defaultWindow
+	}
+	return &Controller{options: options, hosts: make(map[string]*hostState)}
+}
+
+func (c *Controller) host(host string) *hostState {
+	state, ok := c.hosts[host]
+	if !ok {
+		state = &hostState{
+			HostStats: HostStats{Host: host},
+			limit:     float64(c.options.Min),
+			released:  make(chan struct{}),
+		}
+		c.hosts[host] = state
+	}
+	return state
+}
+
+// Acquire waits until a request to host is allowed by its
+// concurrency limit or the context is done.
+func (c *Controller) Acquire(ctx context.Context, host string) error {
+	for {
+		c.mu.Lock()
+		state := c.host(host)
+		if state.inflight < int(state.limit) {
+			state.inflight++
+			c.mu.Unlock()
+			return nil
+		}
+		released := state.released
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+// Release records the response time of a request to host and
+// whether it failed, adjusting the concurrency limit of the host
+// once enough responses were observed.
+func (c *Controller) Release(host string, latency time.Duration, failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.host(host)
+	if state.inflight > 0 {
+		state.inflight--
+	}
+	state.Requests++
+	state.latencies = append(state.latencies, latency)
+	if failed {
+		state.Errors++
+		state.failures++
+	}
+	if len(state.latencies) >= c.options.Window {
+		c.adjust(state)
+	}
+
+	close(state.released)
+	state.released = make(chan struct{})
+}
+
+// adjust updates the limit of a host from its observed window
+func (c *Controller) adjust(state *hostState) {
+	errorRate := float64(state.failures) / float64(len(state.latencies))
+	if errorRate > c.options.MaxErrorRate || percentile(state.latencies, 0.95) > c.options.TargetLatency {
+		state.limit *= defaultDecreaseFactor
+		state.Decreases++
+	} else {
+		state.limit++
+	}
+	if state.limit < float64(c.options.Min) {
+		state.limit = float64(c.options.Min)
+	}
+	if state.limit > float64(c.options.Max) {
+		state.limit = float64(c.options.Max)
+	}
+	state.latencies = state.latencies[:0]
+	state.failures = 0
+}
+
+// Limit returns the current concurrency limit of host
+func (c *Controller) Limit(host string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int(c.host(host).limit)
+}
+
+// Stats returns the statistics of the hosts sorted by host
+func (c *Controller) Stats() []HostStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := make([]HostStats, 0, len(c.hosts))
+	for _, state := range c.hosts {
+		hostStats := state.HostStats
+		hostStats.Limit = int(state.limit)
+		stats = append(stats, hostStats)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Host < stats[j].Host
+	})
+	return stats
+}
+
+// percentile returns the p percentile of latencies
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	return sorted[int(p*float64(len(sorted)-1))]
+}
@@ -0,0 +1,60 @@
+package adaptive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestController(t *testing.T) {
+	controller := New(Options{Min: 1, Max: 4, TargetLatency: 100 * time.Millisecond, Window: 2})
+	ctx := context.Background()
+
+	release := func(latency time.Duration, failed bool) {
+		require.NoError(t, controller.Acquire(ctx, "example.com"))
+		controller.Release("example.com", latency, failed)
+	}
+
+	t.Run("increase", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			release(10*time.Millisecond, false)
+		}
+		require.Equal(t, 4, controller.Limit("example.com"))
+	})
+
+	t.Run("decrease on latency", func(t *testing.T) {
+		release(time.Second, false)
+		release(time.Second, false)
+		require.Equal(t, 2, controller.Limit("example.com"))
+	})
+
+	t.Run("decrease on errors", func(t *testing.T) {
+		release(10*time.Millisecond, true)
+		release(10*time.Millisecond, false)
+		require.Equal(t, 1, controller.Limit("example.com"))
+	})
+
+	stats := controller.Stats()
+	require.Len(t, stats, 1)
+	require.Equal(t, 14, stats[0].Requests)
+	require.Equal(t, 1, stats[0].Errors)
+	require.Equal(t, 2, stats[0].Decreases)
+}
+
+func TestControllerAcquire(t *testing.T) {
+	controller := New(Options{Min: 1, Max: 1})
+	require.NoError(t, controller.Acquire(context.Background(), "example.com"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, controller.Acquire(ctx, "example.com"), context.DeadlineExceeded)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- controller.Acquire(context.Background(), "example.com")
+	}()
+	controller.Release("example.com", time.Millisecond, false)
+	require.NoError(t, <-done)
+}
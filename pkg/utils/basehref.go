@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/projectdiscovery/katana/pkg/navigation"
+)
+
+// ParseBaseURL returns the url of the base element of the response
+// document resolved against the request url, or nil if none is declared.
+// As in browsers only the first base element with a href is used.
+func ParseBaseURL(resp *navigation.Response) *url.URL {
+	if resp.Reader == nil || resp.Resp == nil || resp.Resp.Request == nil || resp.Resp.Request.URL == nil {
+		return nil
+	}
+	href := strings.TrimSpace(resp.Reader.Find("base[href]").First().AttrOr("href", ""))
+	if href == "" {
+		return nil
+	}
+	base, err := resp.Resp.Request.URL.Parse(href)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil
+	}
+	return base
+}
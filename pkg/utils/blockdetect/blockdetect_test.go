@@ -0,0 +1,76 @@
+package blockdetect
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		headers    map[string]string
+		body       string
+		protection string
+	}{
+		{name: "cloudflare challenge header", status: 403, headers: map[string]string{"Cf-Mitigated": "challenge"}, protection: "cloudflare"},
+		{name: "cloudflare block page", status: 403, body: "<title>Attention Required! | Cloudflare</title>", protection: "cloudflare"},
+		{name: "imperva", status: 200, body: "Request unsuccessful. Incapsula incident ID: 123", protection: "imperva"},
+		{name: "rate limit", status: 429, protection: "rate-limit"},
+		{name: "cloudflare text on normal page", status: 200, body: "Attention Required! | Cloudflare"},
+		{name: "plain forbidden", status: 403, body: "<h1>Forbidden</h1>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			protection, blocked := Detect(test.status, test.headers, test.body)
+			require.Equal(t, test.protection != "", blocked)
+			require.Equal(t, test.protection, protection)
+		})
+	}
+}
+
+func TestTracker(t *testing.T) {
+	proxy, _ := url.Parse("http://127.0.0.1:8080")
+	tracker := NewTracker(Options{Threshold: 2, Cooldown: 50 * time.Millisecond, RotateUserAgent: true, Proxies: []*url.URL{proxy}})
+	request, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+	proxyFunc := tracker.Proxy(nil)
+
+	firstAgent := tracker.UserAgent("example.com")
+	noProxy, err := proxyFunc(request)
+	require.NoError(t, err)
+	require.Nil(t, noProxy)
+
+	require.False(t, tracker.Record("example.com", "cloudflare", true))
+	require.False(t, tracker.Record("example.com", "", false), "allowed response should reset consecutive blocks")
+	require.False(t, tracker.Record("example.com", "cloudflare", true))
+	require.True(t, tracker.Record("example.com", "cloudflare", true))
+	require.True(t, tracker.Blocked("example.com"))
+	require.False(t, tracker.Blocked("other.com"))
+
+	started := time.Now()
+	require.NoError(t, tracker.Wait(context.Background(), "example.com"))
+	require.GreaterOrEqual(t, time.Since(started), 40*time.Millisecond)
+
+	require.NotEqual(t, firstAgent, tracker.UserAgent("example.com"))
+	rotated, err := proxyFunc(request)
+	require.NoError(t, err)
+	require.Equal(t, proxy, rotated)
+
+	stats := tracker.Stats()
+	require.Len(t, stats, 1)
+	require.Equal(t, 4, stats[0].Requests)
+	require.Equal(t, 3, stats[0].Blocked)
+	require.Equal(t, "cloudflare", stats[0].Protection)
+	require.InDelta(t, 0.75, stats[0].Ratio(), 0.001)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tracker.Record("example.com", "cloudflare", true)
+	tracker.Record("example.com", "cloudflare", true)
+	cancel()
+	require.ErrorIs(t, tracker.Wait(ctx, "example.com"), context.Canceled)
+}
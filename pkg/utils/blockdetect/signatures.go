@@ -0,0 +1,86 @@
+// Package blockdetect detects block pages and challenge responses of
+// web application firewalls and bot protections, and tracks blocked
+// hosts to cool the crawl of them down before resuming.
+package blockdetect
+
+import (
+	"net/http"
+	"strings"
+)
+
+// signature identifies the block page of a protection
+type signature struct {
+	name string
+	// status are the status codes of the block page, any if empty
+	status []int
+	// header is a header name (lowercase) and an optional value substring
+	header      string
+	headerValue string
+	// body are substrings of which any must be present in the body
+	body []string
+}
+
+var signatures = []signature{
+	{name: "cloudflare", header: "cf-mitigated", headerValue: "challenge"},
+	{name: "cloudflare", status: []int{403, 429, 503}, body: []string{"Attention Required! | Cloudflare", "cf-chl-bypass", "challenges.cloudflare.com/cdn-cgi/challenge-platform", "<title>Just a moment...</title>"}},
+	{name: "akamai", status: []int{403}, header: "server", headerValue: "akamaighost", body: []string{"Access Denied", "Reference&#32;&#35;"}},
+	{name: "imperva", body: []string{"Incapsula incident ID", "_Incapsula_Resource"}},
+	{name: "aws-waf", status: []int{403, 405}, header: "x-amzn-waf-action"},
+	{name: "aws-waf", status: []int{403}, header: "server", headerValue: "awselb", body: []string{"Request blocked", "<h1>403 Forbidden</h1>"}},
+	{name: "sucuri", status: []int{403}, body: []string{"Sucuri WebSite Firewall - Access Denied", "sucuri.net/privacy-policy"}},
+	{name: "datadome", header: "x-datadome"},
+	{name: "datadome", status: []int{403}, body: []string{"geo.captcha-delivery.com", "ct.captcha-delivery.com"}},
+	{name: "perimeterx", status: []int{403}, body: []string{"px-captcha", "_pxCaptcha", "Please verify you are a human"}},
+	{name: "f5-asm", body: []string{"The requested URL was rejected. Please consult with your administrator."}},
+	{name: "modsecurity", status: []int{403, 406, 501}, body: []string{"Mod_Security", "This error was generated by Mod_Security", "NOYB"}},
+	{name: "barracuda", body: []string{"You have been blocked by the Barracuda", "barra_counter_session"}},
+	{name: "wordfence", status: []int{403, 503}, body: []string{"Generated by Wordfence", "Your access to this site has been limited"}},
+}
+
+// Detect returns the name of the protection whose block page or
+// challenge the response is, and false if the response is not blocked.
+func Detect(statusCode int, headers map[string]string, body string) (string, bool) {
+	lowerHeaders := make(map[string]string, len(headers))
+	for key, value := range headers {
+		lowerHeaders[strings.ToLower(key)] = strings.ToLower(value)
+	}
+	for _, signature := range signatures {
+		if signature.matches(statusCode, lowerHeaders, body) {
+			return signature.name, true
+		}
+	}
+	if statusCode == http.StatusTooManyRequests {
+		return "rate-limit", true
+	}
+	return "", false
+}
+
+func (s signature) matches(statusCode int, headers map[string]string, body string) bool {
+	if len(s.status) > 0 && !containsStatus(s.status, statusCode) {
+		return false
+	}
+	if s.header != "" {
+		value, ok := headers[s.header]
+		if !ok || !strings.Contains(value, s.headerValue) {
+			return false
+		}
+	}
+	if len(s.body) == 0 {
+		return s.header != ""
+	}
+	for _, item := range s.body {
+		if strings.Contains(body, item) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStatus(status []int, statusCode int) bool {
+	for _, item := range status {
+		if item == statusCode {
+			return true
+		}
+	}
+	return false
+}
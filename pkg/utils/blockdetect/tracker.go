@@ -0,0 +1,194 @@
+package blockdetect
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultThreshold   = 3
+	defaultCooldown    = 30 * time.Second
+	defaultMaxCooldown = 10 * time.Minute
+)
+
+// userAgents are rotated through for hosts which were cooled down
+var userAgents = []string{
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/113.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_4_1) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Safari/605.1.15",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+}
+
+// Options contains the configuration of the block tracker
+type Options struct {
+	// Threshold is the number of consecutive blocked responses
+	// of a host after which it is cooled down
+	Threshold int
+	// Cooldown is the initial cooldown duration which is
+	// doubled on each subsequent cooldown of a host
+	Cooldown time.Duration
+	// MaxCooldown is the maximum cooldown duration
+	MaxCooldown time.Duration
+	// RotateUserAgent rotates the user agent of a host after a cooldown
+	RotateUserAgent bool
+	// Proxies are rotated through for a host after each cooldown
+	Proxies []*url.URL
+}
+
+// HostStats are the block statistics of a host
+type HostStats struct {
+	Host      string
+	Requests  int
+	Blocked   int
+	Cooldowns int
+	// Protection is the last detected protection of the host
+	Protection string
+}
+
+// Ratio returns the ratio of blocked requests
+func (h HostStats) Ratio() float64 {
+	if h.Requests == 0 {
+		return 0
+	}
+	return float64(h.Blocked) / float64(h.Requests)
+}
+
+type hostState struct {
+	HostStats
+	consecutive   int
+	cooldownUntil time.Time
+}
+
+// Tracker tracks blocked responses per host
+type Tracker struct {
+	options Options
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// NewTracker creates a new block tracker with options
+func NewTracker(options Options) *Tracker {
+	if options.Threshold <= 0 {
+		options.Threshold = defaultThreshold
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = defaultCooldown
+	}
+	if options.MaxCooldown < options.Cooldown {
+		options.MaxCooldown = max(defaultMaxCooldown, options.Cooldown)
+	}
+	return &Tracker{options: options, hosts: make(map[string]*hostState)}
+}
+
+func (t *Tracker) state(host string) *hostState {
+	state, ok := t.hosts[host]
+	if !ok {
+		state = &hostState{HostStats: HostStats{Host: host}}
+		t.hosts[host] = state
+	}
+	return state
+}
+
+// Record records a response of host detected as blocked by protection.
+// It returns true if the host is cooled down after the response.
+func (t *Tracker) Record(host, protection string, blocked bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := t.state(host)
+	state.Requests++
+	if !blocked {
+		state.consecutive = 0
+		return false
+	}
+	state.Blocked++
+	state.Protection = protection
+	state.consecutive++
+	if state.consecutive < t.options.Threshold || time.Now().Before(state.cooldownUntil) {
+		return false
+	}
+
+	cooldown := t.options.Cooldown << min(state.Cooldowns, 16)
+	if cooldown > t.options.MaxCooldown || cooldown <= 0 {
+		cooldown = t.options.MaxCooldown
+	}
+	state.Cooldowns++
+	state.consecutive = 0
+	state.cooldownUntil = time.Now().Add(cooldown)
+	return true
+}
+
+// Wait waits until the cooldown of host is over or ctx is done
+func (t *Tracker) Wait(ctx context.Context, host string) error {
+	for {
+		t.mu.Lock()
+		remaining := time.Until(t.state(host).cooldownUntil)
+		t.mu.Unlock()
+		if remaining <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(remaining):
+		}
+	}
+}
+
+// Blocked returns true if host was cooled down at least once
+func (t *Tracker) Blocked(host string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.hosts[host]
+	return ok && state.Cooldowns > 0
+}
+
+// UserAgent returns the user agent to use for host
+func (t *Tracker) UserAgent(host string) string {
+	if !t.options.RotateUserAgent {
+		return userAgents[0]
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return userAgents[t.state(host).Cooldowns%len(userAgents)]
+}
+
+// Proxy returns a proxy function rotating the proxies of hosts after
+// each cooldown and using fallback until the first cooldown.
+func (t *Tracker) Proxy(fallback func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	if len(t.options.Proxies) == 0 {
+		return fallback
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		t.mu.Lock()
+		cooldowns := t.state(req.URL.Hostname()).Cooldowns
+		t.mu.Unlock()
+		if cooldowns == 0 {
+			if fallback == nil {
+				return nil, nil
+			}
+			return fallback(req)
+		}
+		return t.options.Proxies[(cooldowns-1)%len(t.options.Proxies)], nil
+	}
+}
+
+// Stats returns the statistics of hosts with blocked responses
+func (t *Tracker) Stats() []HostStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var stats []HostStats
+	for _, state := range t.hosts {
+		if state.Blocked > 0 {
+			stats = append(stats, state.HostStats)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Host < stats[j].Host })
+	return stats
+}
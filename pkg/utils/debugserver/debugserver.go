@@ -0,0 +1,143 @@
+// Package debugserver exposes pprof profiles, expvar counters and
+// goroutine dumps of the crawl process over http.
+package debugserver
+
+import (
+	"encoding/json"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	"github.com/projectdiscovery/katana/pkg/output"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+var (
+	// Requests is the number of requests sent by the crawler
+	Requests = expvar.NewInt("katana_requests")
+	// InFlight is the number of requests currently in flight
+	InFlight = expvar.NewInt("katana_requests_in_flight")
+	// Results is the number of results written to the output
+	Results = expvar.NewInt("katana_results")
+	// Errors is the number of errors written to the output
+	Errors = expvar.NewInt("katana_errors")
+	// Browsers is the number of browsers open in the headless pools
+	Browsers = expvar.NewInt("katana_browsers")
+	// BrowsersBusy is the number of browsers of the headless
+	// pools executing actions
+	BrowsersBusy = expvar.NewInt("katana_browsers_busy")
+)
+
+// frontier returns the pending requests of the crawl when set
+var frontier atomic.Pointer[func() any]
+
+// SetFrontier sets the function returning the pending requests
+// of the crawl served on /debug/frontier
+func SetFrontier(fn func() any) {
+	frontier.Store(&fn)
+}
+
+func init() {
+	expvar.Publish("katana_goroutines", expvar.Func(func() any {
+		return runtime.NumGoroutine()
+	}))
+}
+
+// Server is a runtime diagnostics http server
+type Server struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// New creates a new diagnostics server for addr
+func New(addr string) *Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/goroutines", handleGoroutines)
+	mux.HandleFunc("/debug/frontier", handleFrontier)
+
+	return &Server{
+		server: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// Start starts listening on the server address and serves
+// requests in the background.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return errkit.Wrap(err, "could not listen on pprof address")
+	}
+	s.listener = listener
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+	return nil
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return s.server.Addr
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop stops the server
+func (s *Server) Stop() {
+	_ = s.server.Close()
+}
+
+// handleGoroutines writes the stack traces of all goroutines
+func handleGoroutines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// handleFrontier writes the pending requests of the crawl as json
+func handleFrontier(w http.ResponseWriter, r *http.Request) {
+	var entries any = []any{}
+	if fn := frontier.Load(); fn != nil {
+		entries = (*fn)()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Writer counts the results and errors written to the output
+type Writer struct {
+	output.Writer
+}
+
+// NewWriter wraps writer with result and error counters
+func NewWriter(writer output.Writer) *Writer {
+	return &Writer{Writer: writer}
+}
+
+// Write counts and writes the result
+func (w *Writer) Write(result *output.Result) error {
+	Results.Add(1)
+	return w.Writer.Write(result)
+}
+
+// WriteErr counts and writes the error
+func (w *Writer) WriteErr(err *output.Error) error {
+	Errors.Add(1)
+	return w.Writer.WriteErr(err)
+}
@@ -0,0 +1,70 @@
+package debugserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	server := New("127.0.0.1:0")
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	Requests.Add(1)
+
+	resp, err := http.Get("http://" + server.Addr() + "/debug/vars")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	vars := make(map[string]any)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&vars))
+	require.Contains(t, vars, "katana_requests")
+	require.Contains(t, vars, "katana_goroutines")
+
+	resp, err = http.Get("http://" + server.Addr() + "/debug/goroutines")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.True(t, strings.Contains(string(body), "goroutine"))
+
+	SetFrontier(func() any {
+		return []map[string]any{{"url": "https://example.com/a", "depth": 1}}
+	})
+	resp, err = http.Get("http://" + server.Addr() + "/debug/frontier")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var entries []map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&entries))
+	require.Len(t, entries, 1)
+	require.Equal(t, "https://example.com/a", entries[0]["url"])
+}
+
+func TestStatsReporter(t *testing.T) {
+	var buffer bytes.Buffer
+	reporter := NewStatsReporter(&buffer, time.Hour, func() int { return 3 })
+	reporter.Start()
+	Requests.Add(2)
+	Errors.Add(1)
+	reporter.Stop()
+	reporter.Stop()
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, 1, "stopping should write a single final record")
+
+	var stats Stats
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &stats))
+	require.Equal(t, int64(3), stats.QueueSize)
+	require.Equal(t, Requests.Value(), stats.Processed)
+	require.Equal(t, Errors.Value(), stats.Errors)
+	require.Greater(t, stats.RPS, 0.0)
+}
@@ -0,0 +1,103 @@
+package debugserver
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// Stats is a machine readable progress record of the crawl
+type Stats struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Elapsed is the number of seconds since the crawl started
+	Elapsed   int64 `json:"elapsed"`
+	QueueSize int64 `json:"queue_size"`
+	Processed int64 `json:"processed"`
+	Results   int64 `json:"results"`
+	Errors    int64 `json:"errors"`
+	// RPS is the number of requests per second since the last record
+	RPS float64 `json:"rps"`
+}
+
+// StatsReporter periodically writes the crawl progress as single line json
+type StatsReporter struct {
+	writer    io.Writer
+	interval  time.Duration
+	queueSize func() int
+
+	started       time.Time
+	lastReport    time.Time
+	lastProcessed int64
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewStatsReporter creates a new stats reporter writing to writer on
+// interval, queueSize returns the number of requests waiting in the queues
+func NewStatsReporter(writer io.Writer, interval time.Duration, queueSize func() int) *StatsReporter {
+	return &StatsReporter{
+		writer:    writer,
+		interval:  interval,
+		queueSize: queueSize,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start starts reporting the progress in the background
+func (r *StatsReporter) Start() {
+	r.started = time.Now()
+	r.lastReport = r.started
+	r.lastProcessed = Requests.Value()
+
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.report()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops reporting writing a final progress record
+func (r *StatsReporter) Stop() {
+	r.once.Do(func() {
+		close(r.stop)
+		<-r.done
+		r.report()
+	})
+}
+
+// report writes a progress record
+func (r *StatsReporter) report() {
+	now := time.Now()
+	stats := Stats{
+		Timestamp: now,
+		Elapsed:   int64(now.Sub(r.started).Seconds()),
+		QueueSize: int64(r.queueSize()),
+		Processed: Requests.Value(),
+		Results:   Results.Value(),
+		Errors:    Errors.Value(),
+	}
+	if seconds := now.Sub(r.lastReport).Seconds(); seconds > 0 {
+		stats.RPS = math.Round(float64(stats.Processed-r.lastProcessed)/seconds*100) / 100
+	}
+	r.lastReport = now
+	r.lastProcessed = stats.Processed
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return
+	}
+	_, _ = r.writer.Write(append(data, '\n'))
+}
@@ -0,0 +1,105 @@
+// Package formbudget limits the number of distinct forms auto-filled
+// per host and the number of submissions of each form, so that form
+// heavy sites (eg. search filters) don't dominate the crawl.
+package formbudget
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HostStats are the form statistics of a host
+type HostStats struct {
+	Host string
+	// Forms is the number of distinct forms submitted
+	Forms int
+	// Submissions is the number of form submissions
+	Submissions int
+	// Skipped is the number of submissions skipped over the limits
+	Skipped int
+}
+
+type hostState struct {
+	HostStats
+	submissions map[string]int
+}
+
+// Budget tracks the form submissions of hosts against the limits
+type Budget struct {
+	maxForms       int
+	maxSubmissions int
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// New creates a new form budget allowing maxForms distinct forms per
+// host and maxSubmissions submissions per form, zero for no limit.
+func New(maxForms, maxSubmissions int) *Budget {
+	return &Budget{
+		maxForms:       maxForms,
+		maxSubmissions: maxSubmissions,
+		hosts:          make(map[string]*hostState),
+	}
+}
+
+// Key returns the key identifying a form by its method and action
+// url, submissions of a form with other values share the key.
+func Key(method, action string) string {
+	if parsed, err := url.Parse(action); err == nil {
+		parsed.RawQuery = ""
+		parsed.Fragment = ""
+		action = parsed.String()
+	}
+	return strings.ToUpper(method) + " " + action
+}
+
+// Allow records a submission of the form identified by key on the host
+// and returns true if it is within the limits. A nil budget allows all
+// the submissions.
+func (b *Budget) Allow(host, key string) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.hosts[host]
+	if !ok {
+		state = &hostState{HostStats: HostStats{Host: host}, submissions: make(map[string]int)}
+		b.hosts[host] = state
+	}
+	submissions, seen := state.submissions[key]
+	if !seen && b.maxForms > 0 && len(state.submissions) >= b.maxForms {
+		state.Skipped++
+		return false
+	}
+	if b.maxSubmissions > 0 && submissions >= b.maxSubmissions {
+		state.Skipped++
+		return false
+	}
+	state.submissions[key] = submissions + 1
+	state.Forms = len(state.submissions)
+	state.Submissions++
+	return true
+}
+
+// Stats returns the form statistics of the hosts sorted by host
+func (b *Budget) Stats() []HostStats {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := make([]HostStats, 0, len(b.hosts))
+	for _, state := range b.hosts {
+		stats = append(stats, state.HostStats)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Host < stats[j].Host
+	})
+	return stats
+}
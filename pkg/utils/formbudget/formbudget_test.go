@@ -0,0 +1,32 @@
+package formbudget
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget(t *testing.T) {
+	budget := New(2, 2)
+
+	search := Key("get", "https://example.com/search?q=foo")
+	require.Equal(t, "GET https://example.com/search", search)
+	require.Equal(t, search, Key("GET", "https://example.com/search?q=bar#results"))
+
+	require.True(t, budget.Allow("example.com", search))
+	require.True(t, budget.Allow("example.com", search))
+	require.False(t, budget.Allow("example.com", search), "submissions over the limit should be skipped")
+
+	require.True(t, budget.Allow("example.com", Key("POST", "https://example.com/login")))
+	require.False(t, budget.Allow("example.com", Key("POST", "https://example.com/signup")), "forms over the limit should be skipped")
+	require.True(t, budget.Allow("api.example.com", Key("POST", "https://api.example.com/signup")))
+
+	require.Equal(t, []HostStats{
+		{Host: "api.example.com", Forms: 1, Submissions: 1},
+		{Host: "example.com", Forms: 2, Submissions: 3, Skipped: 2},
+	}, budget.Stats())
+
+	var unlimited *Budget
+	require.True(t, unlimited.Allow("example.com", search))
+	require.Nil(t, unlimited.Stats())
+}
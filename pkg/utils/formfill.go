@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/projectdiscovery/katana/pkg/navigation"
 	mapsutil "github.com/projectdiscovery/utils/maps"
 	"github.com/rs/xid"
 )
@@ -289,3 +290,50 @@ func ConvertGoquerySelectionToFormField(item *goquery.Selection) interface{} {
 
 	return nil
 }
+
+// FormSubmission is a form submission carrying interaction markers
+type FormSubmission struct {
+	// Action is the url the form is submitted to
+	Action string
+	// Method is the method the form is submitted with
+	Method string
+	// Source is the page the form was submitted from
+	Source string
+	// SourceChain are the hops that led from the seed to the form, the
+	// actions on the path to the page state of the form in headless mode
+	SourceChain []navigation.SourceLink
+}
+
+// FormMarkers injects out-of-band interaction markers into form submissions
+type FormMarkers struct {
+	// Generate returns a marker for a field or header of the submission
+	Generate func(submission *FormSubmission, field string) string
+	// Headers are the request headers injected with markers
+	Headers []string
+}
+
+// InjectFields replaces the placeholder values of free-text fields
+// in data with interaction markers for the form submission.
+func (m *FormMarkers) InjectFields(data *mapsutil.OrderedMap[string, string], submission *FormSubmission) {
+	if m == nil || m.Generate == nil {
+		return
+	}
+	data.Iterate(func(key, value string) bool {
+		if key != "" && value == FormData.Placeholder {
+			data.Set(key, m.Generate(submission, key))
+		}
+		return true
+	})
+}
+
+// HeaderValues returns the marked headers for the form submission
+func (m *FormMarkers) HeaderValues(submission *FormSubmission) map[string]string {
+	if m == nil || m.Generate == nil || len(m.Headers) == 0 {
+		return nil
+	}
+	headers := make(map[string]string, len(m.Headers))
+	for _, header := range m.Headers {
+		headers[header] = m.Generate(submission, header)
+	}
+	return headers
+}
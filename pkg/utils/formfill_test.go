@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/PuerkitoBio/goquery"
+	mapsutil "github.com/projectdiscovery/utils/maps"
 	"github.com/stretchr/testify/require"
 )
 
@@ -85,3 +86,28 @@ func TestFormInputFillSuggestions(t *testing.T) {
 		require.Equal(t, "Startdate=katana&color=green&country=india&firstname=katana&food=pasta&message=katana&num=51&password=katana&sport1=cricket&sport2=tennis&sport3=football&telephone=katanaP%40assw0rd1&upclick=%23a52a2a", value, "could not get correct encoded form")
 	})
 }
+
+func TestFormMarkers(t *testing.T) {
+	data := mapsutil.NewOrderedMap[string, string]()
+	data.Set("username", FormData.Placeholder)
+	data.Set("email", FormData.Email)
+
+	submission := &FormSubmission{Action: "https://example.com/login", Method: "GET", Source: "https://example.com/"}
+
+	var disabled *FormMarkers
+	disabled.InjectFields(&data, submission)
+	require.Nil(t, disabled.HeaderValues(submission))
+
+	markers := &FormMarkers{
+		Generate: func(submission *FormSubmission, field string) string {
+			return strings.ToLower(submission.Method) + "-" + field + ".oast.pro"
+		},
+		Headers: []string{"Referer"},
+	}
+	markers.InjectFields(&data, submission)
+	username, _ := data.Get("username")
+	email, _ := data.Get("email")
+	require.Equal(t, "get-username.oast.pro", username)
+	require.Equal(t, FormData.Email, email, "non free-text fields should not be marked")
+	require.Equal(t, map[string]string{"Referer": "get-Referer.oast.pro"}, markers.HeaderValues(submission))
+}
@@ -0,0 +1,201 @@
+// Package fuzzlite generates minimal parameter permutations of api
+// endpoints captured during the crawl to reveal additional responses.
+//
+// Only GET requests are permuted and the number of permutations is
+// bounded per endpoint and per crawl so that targets are not flooded.
+package fuzzlite
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Tag is the tag of the requests of parameter permutations
+const Tag = "xhr-fuzz"
+
+const (
+	defaultMaxPerEndpoint = 8
+	defaultMaxTotal       = 200
+	defaultMaxSamples     = 3
+)
+
+// Options contains the limits of the permutations
+type Options struct {
+	// MaxPerEndpoint is the maximum number of permutations of an endpoint
+	MaxPerEndpoint int
+	// MaxTotal is the maximum number of permutations of a crawl
+	MaxTotal int
+	// MaxSamples is the maximum number of sample values kept per parameter
+	MaxSamples int
+}
+
+// Permutation is a parameter permutation of an endpoint
+type Permutation struct {
+	URL string
+	// Description describes the permutation (eg. absent:id)
+	Description string
+}
+
+// Fuzzer records the parameters observed in the traffic
+// and generates permutations of the endpoints
+type Fuzzer struct {
+	options Options
+
+	mu sync.Mutex
+	// samples are the observed values of the parameters
+	samples map[string][]string
+	// parameters are the observed parameters of the endpoint paths
+	parameters map[string]map[string]struct{}
+	// permuted are the endpoints which were already permuted
+	permuted  map[string]struct{}
+	generated int
+}
+
+// New creates a new fuzzer with options
+func New(options Options) *Fuzzer {
+	if options.MaxPerEndpoint <= 0 {
+		options.MaxPerEndpoint = defaultMaxPerEndpoint
+	}
+	if options.MaxTotal <= 0 {
+		options.MaxTotal = defaultMaxTotal
+	}
+	if options.MaxSamples <= 0 {
+		options.MaxSamples = defaultMaxSamples
+	}
+	return &Fuzzer{
+		options:    options,
+		samples:    make(map[string][]string),
+		parameters: make(map[string]map[string]struct{}),
+		permuted:   make(map[string]struct{}),
+	}
+}
+
+// Observe records the parameters and values of a captured request
+func (f *Fuzzer) Observe(method, rawURL string) {
+	if method != http.MethodGet {
+		return
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := endpointPath(parsed)
+	if f.parameters[path] == nil {
+		f.parameters[path] = make(map[string]struct{})
+	}
+	for name, values := range parsed.Query() {
+		f.parameters[path][name] = struct{}{}
+		for _, value := range values {
+			f.addSample(name, value)
+		}
+	}
+}
+
+func (f *Fuzzer) addSample(name, value string) {
+	samples := f.samples[name]
+	if len(samples) >= f.options.MaxSamples {
+		return
+	}
+	for _, sample := range samples {
+		if sample == value {
+			return
+		}
+	}
+	f.samples[name] = append(samples, value)
+}
+
+// Permutations returns the permutations of a captured request, with
+// each present parameter removed or set to an observed sample value
+// and each parameter observed on the endpoint but absent added.
+//
+// An endpoint is permuted only once for a set of parameter names.
+func (f *Fuzzer) Permutations(method, rawURL string) []Permutation {
+	if method != http.MethodGet {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	query := parsed.Query()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := endpointPath(parsed)
+	key := path + "?" + strings.Join(sortedKeys(query), "&")
+	if _, ok := f.permuted[key]; ok {
+		return nil
+	}
+	f.permuted[key] = struct{}{}
+
+	var permutations []Permutation
+	add := func(values url.Values, description string) bool {
+		if len(permutations) >= f.options.MaxPerEndpoint || f.generated >= f.options.MaxTotal {
+			return false
+		}
+		permuted := *parsed
+		permuted.RawQuery = values.Encode()
+		permutations = append(permutations, Permutation{URL: permuted.String(), Description: description})
+		f.generated++
+		return true
+	}
+
+	for _, name := range sortedKeys(query) {
+		absent := cloneValues(query)
+		absent.Del(name)
+		if !add(absent, "absent:"+name) {
+			return permutations
+		}
+		for _, sample := range f.samples[name] {
+			if sample == query.Get(name) {
+				continue
+			}
+			sampled := cloneValues(query)
+			sampled.Set(name, sample)
+			if !add(sampled, "value:"+name) {
+				return permutations
+			}
+		}
+	}
+	for _, name := range sortedKeys(f.parameters[path]) {
+		if query.Has(name) || len(f.samples[name]) == 0 {
+			continue
+		}
+		present := cloneValues(query)
+		present.Set(name, f.samples[name][0])
+		if !add(present, "present:"+name) {
+			return permutations
+		}
+	}
+	return permutations
+}
+
+// endpointPath returns the endpoint of a url without its query
+func endpointPath(parsed *url.URL) string {
+	return parsed.Scheme + "://" + parsed.Host + parsed.Path
+}
+
+func cloneValues(values url.Values) url.Values {
+	cloned := make(url.Values, len(values))
+	for name, value := range values {
+		cloned[name] = append([]string(nil), value...)
+	}
+	return cloned
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
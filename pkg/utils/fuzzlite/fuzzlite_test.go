@@ -0,0 +1,35 @@
+package fuzzlite
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermutations(t *testing.T) {
+	fuzzer := New(Options{})
+	fuzzer.Observe(http.MethodGet, "https://example.com/api/items?page=2&sort=name")
+	fuzzer.Observe(http.MethodGet, "https://example.com/api/items?page=1")
+	fuzzer.Observe(http.MethodPost, "https://example.com/api/items?debug=1")
+
+	permutations := fuzzer.Permutations(http.MethodGet, "https://example.com/api/items?page=1")
+	require.Equal(t, []Permutation{
+		{URL: "https://example.com/api/items", Description: "absent:page"},
+		{URL: "https://example.com/api/items?page=2", Description: "value:page"},
+		{URL: "https://example.com/api/items?page=1&sort=name", Description: "present:sort"},
+	}, permutations)
+
+	// an endpoint is only permuted once
+	require.Empty(t, fuzzer.Permutations(http.MethodGet, "https://example.com/api/items?page=3"))
+	// unsafe methods are never permuted
+	require.Empty(t, fuzzer.Permutations(http.MethodPost, "https://example.com/api/other?id=1"))
+}
+
+func TestPermutationsLimits(t *testing.T) {
+	fuzzer := New(Options{MaxPerEndpoint: 2, MaxTotal: 3})
+
+	require.Len(t, fuzzer.Permutations(http.MethodGet, "https://example.com/a?x=1&y=1&z=1"), 2)
+	require.Len(t, fuzzer.Permutations(http.MethodGet, "https://example.com/b?x=1&y=1&z=1"), 1)
+	require.Empty(t, fuzzer.Permutations(http.MethodGet, "https://example.com/c?x=1"))
+}
@@ -0,0 +1,47 @@
+package headerrules
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// ParseCredentials parses host credentials in the `host=basic:user:pass`
+// and `host=bearer:token` formats into Authorization header rules.
+// host is a hostname glob pattern, * matches any host.
+func ParseCredentials(values []string) (Rules, error) {
+	var rules Rules
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		host, credential, ok := strings.Cut(value, "=")
+		scheme, secret, hasSecret := strings.Cut(credential, ":")
+		host = strings.ToLower(strings.TrimSpace(host))
+		if !ok || !hasSecret || host == "" || secret == "" {
+			return nil, errkit.New(fmt.Sprintf("invalid credential for %q, expected host=basic:user:pass or host=bearer:token", host))
+		}
+		if _, err := path.Match(host, ""); err != nil {
+			return nil, errkit.Wrap(err, fmt.Sprintf("invalid host pattern of credential for %q", host))
+		}
+
+		rule := &Rule{Host: host, Path: "/", Name: "Authorization"}
+		switch strings.ToLower(scheme) {
+		case "basic":
+			if !strings.Contains(secret, ":") {
+				return nil, errkit.New(fmt.Sprintf("invalid basic credential for %q, expected user:pass", host))
+			}
+			rule.Value = "Basic " + base64.StdEncoding.EncodeToString([]byte(secret))
+		case "bearer":
+			rule.Value = "Bearer " + secret
+		default:
+			return nil, errkit.New(fmt.Sprintf("unknown credential type %q for %q (basic, bearer)", scheme, host))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
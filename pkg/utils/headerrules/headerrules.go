@@ -0,0 +1,138 @@
+// Package headerrules implements headers sent only
+// to the urls matching a host and path pattern.
+package headerrules
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// Rule is a header sent to the urls matching its host and path patterns
+type Rule struct {
+	// Host is the hostname glob pattern, * matches any host
+	Host string
+	// Path is matched as a prefix of the url path, or as a
+	// glob pattern where * matches any characters when it has one
+	Path  string
+	Name  string
+	Value string
+
+	pathPattern *regexp.Regexp
+}
+
+// Rules are the header rules applied in order, later rules
+// overriding the headers of earlier ones
+type Rules []*Rule
+
+// Parse parses header rules in the `[host][/path] name: value` format
+func Parse(values []string) (Rules, error) {
+	var rules Rules
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		pattern, header, ok := strings.Cut(value, " ")
+		name, headerValue, hasValue := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !ok || !hasValue || name == "" {
+			return nil, errkit.New(fmt.Sprintf("invalid header rule %q, expected [host][/path] name: value", value))
+		}
+
+		rule := &Rule{Host: "*", Path: "/", Name: name, Value: strings.TrimSpace(headerValue)}
+		host, rulePath, hasPath := strings.Cut(pattern, "/")
+		if host != "" {
+			rule.Host = strings.ToLower(host)
+		}
+		if _, err := path.Match(rule.Host, ""); err != nil {
+			return nil, errkit.Wrap(err, fmt.Sprintf("invalid host pattern of header rule %q", value))
+		}
+		if hasPath {
+			rule.Path = "/" + rulePath
+		}
+		if strings.Contains(rule.Path, "*") {
+			parts := strings.Split(rule.Path, "*")
+			for i, part := range parts {
+				parts[i] = regexp.QuoteMeta(part)
+			}
+			rule.pathPattern = regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Matches returns true if the rule applies to the url
+func (r *Rule) Matches(u *url.URL) bool {
+	if matched, _ := path.Match(r.Host, strings.ToLower(u.Hostname())); !matched {
+		return false
+	}
+	requestPath := u.EscapedPath()
+	if requestPath == "" {
+		requestPath = "/"
+	}
+	if r.pathPattern != nil {
+		return r.pathPattern.MatchString(requestPath)
+	}
+	return strings.HasPrefix(requestPath, r.Path)
+}
+
+// Headers returns the headers of the rules matching the url
+func (r Rules) Headers(u *url.URL) map[string]string {
+	if len(r) == 0 || u == nil {
+		return nil
+	}
+	headers := make(map[string]string)
+	for _, rule := range r {
+		if rule.Matches(u) {
+			headers[rule.Name] = rule.Value
+		}
+	}
+	return headers
+}
+
+// HeadersString returns the headers of the rules matching the raw url
+func (r Rules) HeadersString(rawURL string) map[string]string {
+	if len(r) == 0 {
+		return nil
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	return r.Headers(parsed)
+}
+
+// Merge merges a header into existing headers appending
+// cookies to existing ones instead of replacing them
+func Merge(existing, name, value string) string {
+	if existing != "" && strings.EqualFold(name, "Cookie") {
+		return existing + "; " + value
+	}
+	return value
+}
+
+// Apply returns the existing headers with the headers merged
+// into them matching the header names case-insensitively
+func Apply(existing, headers map[string]string) map[string]string {
+	merged := make(map[string]string, len(existing)+len(headers))
+	for name, value := range existing {
+		merged[name] = value
+	}
+	for name, value := range headers {
+		for existingName, existingValue := range merged {
+			if strings.EqualFold(existingName, name) {
+				delete(merged, existingName)
+				value = Merge(existingValue, name, value)
+				break
+			}
+		}
+		merged[name] = value
+	}
+	return merged
+}
@@ -0,0 +1,60 @@
+package headerrules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	rules, err := Parse([]string{
+		"api.example.com X-Tenant: foo",
+		"/admin Cookie: role=admin",
+		"*.example.com/v*/users X-Version: users",
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	require.Equal(t, "*", rules[1].Host)
+	require.Equal(t, "/admin", rules[1].Path)
+
+	for _, invalid := range []string{"api.example.com", "api.example.com X-Tenant", "[ X-Tenant: foo"} {
+		_, err := Parse([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	rules, err := Parse([]string{
+		"api.example.com X-Tenant: foo",
+		"/admin Cookie: role=admin",
+		"*.example.com/v*/users X-Version: users",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{"X-Tenant": "foo"}, rules.HeadersString("https://API.example.com/"))
+	require.Equal(t, map[string]string{"Cookie": "role=admin"}, rules.HeadersString("https://www.example.com/admin/users"))
+	require.Equal(t, map[string]string{"X-Tenant": "foo", "X-Version": "users"}, rules.HeadersString("https://api.example.com/v2/users"))
+	require.Empty(t, rules.HeadersString("https://example.com/v2/users"))
+	require.Nil(t, Rules(nil).HeadersString("https://example.com/"))
+}
+
+func TestApply(t *testing.T) {
+	merged := Apply(map[string]string{"cookie": "session=1", "Accept": "*/*"}, map[string]string{"Cookie": "role=admin", "accept": "text/html"})
+	require.Equal(t, map[string]string{"Cookie": "session=1; role=admin", "accept": "text/html"}, merged)
+}
+
+func TestParseCredentials(t *testing.T) {
+	rules, err := ParseCredentials([]string{
+		"api.example.com=basic:admin:p:ss",
+		"*.internal.example.com=bearer:token",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"Authorization": "Basic YWRtaW46cDpzcw=="}, rules.HeadersString("https://api.example.com/users"))
+	require.Equal(t, map[string]string{"Authorization": "Bearer token"}, rules.HeadersString("https://app.internal.example.com/"))
+	require.Empty(t, rules.HeadersString("https://example.com/"))
+
+	for _, invalid := range []string{"api.example.com", "api.example.com=basic:admin", "api.example.com=digest:a:b", "=bearer:token"} {
+		_, err := ParseCredentials([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,80 @@
+// Package hostmap implements the rewriting of the hosts of urls, so that
+// a crawl designed against production hosts is executed against staging.
+package hostmap
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// Map maps lowercase hostnames to the hosts their urls are rewritten to.
+// Hosts without a port keep the port of the rewritten urls.
+type Map map[string]string
+
+// Parse parses host mappings in the `from=to` format
+// (eg. prod.example.com=staging.example.com:8443)
+func Parse(values []string) (Map, error) {
+	var hostMap Map
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(value, "=")
+		from = strings.ToLower(strings.TrimSpace(from))
+		to = strings.ToLower(strings.TrimSpace(to))
+		if !ok || from == "" || to == "" {
+			return nil, errkit.New(fmt.Sprintf("invalid host mapping %q, expected from=to", value))
+		}
+		if strings.ContainsAny(from, ":/") {
+			return nil, errkit.New(fmt.Sprintf("invalid host mapping %q, the mapped host must be a hostname", value))
+		}
+		if strings.Contains(to, "/") {
+			return nil, errkit.New(fmt.Sprintf("invalid host mapping %q, the target must be a host", value))
+		}
+		if _, port, err := net.SplitHostPort(to); err == nil && port == "" {
+			return nil, errkit.New(fmt.Sprintf("invalid host mapping %q, the target port is empty", value))
+		}
+		if hostMap == nil {
+			hostMap = make(Map)
+		}
+		hostMap[from] = to
+	}
+	return hostMap, nil
+}
+
+// Rewrite returns the url with its host rewritten and
+// true if the hostname of the url is mapped
+func (m Map) Rewrite(rawURL string) (string, bool) {
+	if len(m) == 0 {
+		return rawURL, false
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL, false
+	}
+	to, ok := m[strings.ToLower(parsed.Hostname())]
+	if !ok {
+		return rawURL, false
+	}
+	if _, _, err := net.SplitHostPort(to); err == nil {
+		parsed.Host = to
+	} else if port := parsed.Port(); port != "" {
+		parsed.Host = net.JoinHostPort(to, port)
+	} else if strings.Contains(to, ":") {
+		parsed.Host = "[" + to + "]"
+	} else {
+		parsed.Host = to
+	}
+	return parsed.String(), true
+}
+
+// Apply returns the url with its host rewritten if it is mapped
+func (m Map) Apply(rawURL string) string {
+	rewritten, _ := m.Rewrite(rawURL)
+	return rewritten
+}
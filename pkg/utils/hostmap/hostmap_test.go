@@ -0,0 +1,52 @@
+package hostmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	hostMap, err := Parse([]string{"Prod.example.com=staging.example.com", " api.example.com = staging-api.example.com:8443 ", ""})
+	require.NoError(t, err)
+	require.Equal(t, Map{
+		"prod.example.com": "staging.example.com",
+		"api.example.com":  "staging-api.example.com:8443",
+	}, hostMap)
+
+	for _, invalid := range []string{"prod.example.com", "=staging.example.com", "prod.example.com:443=staging.example.com", "prod.example.com=staging.example.com/app", "prod.example.com=staging.example.com:"} {
+		_, err := Parse([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+
+	hostMap, err = Parse(nil)
+	require.NoError(t, err)
+	require.Nil(t, hostMap)
+}
+
+func TestRewrite(t *testing.T) {
+	hostMap := Map{
+		"prod.example.com": "staging.example.com",
+		"api.example.com":  "staging-api.example.com:8443",
+		"v6.example.com":   "::1",
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"https://prod.example.com/login?next=/home#top", "https://staging.example.com/login?next=/home#top", true},
+		{"https://PROD.example.com:8080/", "https://staging.example.com:8080/", true},
+		{"https://api.example.com:443/v1/users", "https://staging-api.example.com:8443/v1/users", true},
+		{"http://v6.example.com/", "http://[::1]/", true},
+		{"https://www.example.com/", "https://www.example.com/", false},
+		{"/relative/path", "/relative/path", false},
+	}
+	for _, test := range tests {
+		rewritten, ok := hostMap.Rewrite(test.input)
+		require.Equal(t, test.ok, ok, test.input)
+		require.Equal(t, test.expected, rewritten, test.input)
+	}
+	require.Equal(t, "https://prod.example.com/", Map(nil).Apply("https://prod.example.com/"))
+}
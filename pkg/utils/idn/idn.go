@@ -0,0 +1,118 @@
+// Package idn normalizes internationalized domain names.
+//
+// Hosts are compared in their punycode (ASCII) form so that the
+// unicode, punycode and percent-encoded spellings of the same host
+// are scoped and deduplicated identically, and are displayed in
+// their unicode form.
+package idn
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// ToASCII returns the lowercase punycode form of a hostname.
+// Hostnames that cannot be converted are only lowercased.
+func ToASCII(host string) string {
+	if isASCII(host) {
+		return strings.ToLower(host)
+	}
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		return ascii
+	}
+	// non-conforming labels (eg: underscores) are still encoded
+	if ascii, err := idna.Punycode.ToASCII(strings.ToLower(host)); err == nil {
+		return ascii
+	}
+	return strings.ToLower(host)
+}
+
+// ToUnicode returns the unicode form of a punycode hostname.
+// Hostnames that are not valid punycode are returned as is.
+func ToUnicode(host string) string {
+	if !strings.Contains(strings.ToLower(host), "xn--") {
+		return host
+	}
+	if unicode, err := idna.Display.ToUnicode(host); err == nil {
+		return unicode
+	}
+	return host
+}
+
+// IsIDN returns true if the hostname is internationalized
+// in either its unicode or punycode form.
+func IsIDN(host string) bool {
+	return !isASCII(host) || strings.Contains(strings.ToLower(host), "xn--")
+}
+
+// ASCIIURL returns the URL with its hostname in punycode form.
+// URLs without an internationalized hostname are returned as is.
+func ASCIIURL(rawURL string) string {
+	parsed, host, ok := parseIDN(rawURL)
+	if !ok {
+		return rawURL
+	}
+	parsed.Host = joinHost(ToASCII(host), parsed.Port())
+	return parsed.String()
+}
+
+// UnicodeURL returns the URL with its hostname in unicode form.
+// URLs without an internationalized hostname are returned as is.
+func UnicodeURL(rawURL string) string {
+	parsed, host, ok := parseIDN(rawURL)
+	if !ok {
+		return rawURL
+	}
+	ascii := ToASCII(host)
+	unicode := ToUnicode(ascii)
+	if unicode == ascii {
+		return rawURL
+	}
+	// url.URL escapes non-ascii hosts so the unicode
+	// hostname is substituted in the serialized URL
+	parsed.Host = joinHost(ascii, parsed.Port())
+	serialized := parsed.String()
+	authority := strings.Index(serialized, "//")
+	if authority == -1 {
+		return rawURL
+	}
+	authority += 2
+	index := strings.Index(serialized[authority:], ascii)
+	if index == -1 {
+		return rawURL
+	}
+	index += authority
+	return serialized[:index] + unicode + serialized[index+len(ascii):]
+}
+
+// parseIDN parses the URL returning its hostname if it is internationalized
+func parseIDN(rawURL string) (*url.URL, string, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return nil, "", false
+	}
+	host := parsed.Hostname()
+	if net.ParseIP(host) != nil || !IsIDN(host) {
+		return nil, "", false
+	}
+	return parsed, host, true
+}
+
+func joinHost(host, port string) string {
+	if port == "" {
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
+
+func isASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package idn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostConversion(t *testing.T) {
+	require.Equal(t, "xn--bcher-kva.example", ToASCII("Bücher.example"))
+	require.Equal(t, "xn--bcher-kva.example", ToASCII("XN--BCHER-KVA.example"))
+	require.Equal(t, "example.com", ToASCII("Example.com"))
+	require.Equal(t, "bücher.example", ToUnicode("xn--bcher-kva.example"))
+	require.Equal(t, "example.com", ToUnicode("example.com"))
+	require.True(t, IsIDN("bücher.example"))
+	require.True(t, IsIDN("xn--bcher-kva.example"))
+	require.False(t, IsIDN("example.com"))
+}
+
+func TestURLConversion(t *testing.T) {
+	for _, rawURL := range []string{
+		"https://bücher.example:8443/path?q=1",
+		"https://xn--bcher-kva.example:8443/path?q=1",
+		"https://b%C3%BCcher.example:8443/path?q=1",
+	} {
+		require.Equal(t, "https://xn--bcher-kva.example:8443/path?q=1", ASCIIURL(rawURL), rawURL)
+		require.Equal(t, "https://bücher.example:8443/path?q=1", UnicodeURL(rawURL), rawURL)
+	}
+	require.Equal(t, "https://Example.com/A%2fb", ASCIIURL("https://Example.com/A%2fb"))
+	require.Equal(t, "http://[::1]:80/", UnicodeURL("http://[::1]:80/"))
+}
@@ -0,0 +1,152 @@
+// Package inventory aggregates the third-party domains
+// contacted by the crawled pages of each target.
+package inventory
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// kinds maps the tags of discovered requests and the resource types
+// of browser requests to the kind of contact with a domain. Tags of
+// links which are not contacted by the page (eg. a) are not mapped.
+var kinds = map[string]string{
+	"script":      "script",
+	"xhr":         "xhr",
+	"fetch":       "xhr",
+	"eventsource": "xhr",
+	"websocket":   "websocket",
+	"ping":        "beacon",
+	"iframe":      "frame",
+	"frame":       "frame",
+	"document":    "frame",
+	"img":         "image",
+	"image":       "image",
+	"link":        "link",
+	"stylesheet":  "stylesheet",
+	"font":        "font",
+	"video":       "media",
+	"audio":       "media",
+	"source":      "media",
+	"track":       "media",
+	"media":       "media",
+	"embed":       "object",
+	"object":      "object",
+	"manifest":    "manifest",
+}
+
+// Domain is a third-party domain contacted by the pages of a target
+type Domain struct {
+	Domain string `json:"domain"`
+	// Requests is the number of requests to the domain
+	Requests int `json:"requests"`
+	// Kinds are the kinds of contact with the domain (eg. script, xhr)
+	Kinds []string `json:"kinds"`
+}
+
+// Target are the third-party domains of a target
+type Target struct {
+	Target  string   `json:"target"`
+	Domains []Domain `json:"domains"`
+}
+
+type domainState struct {
+	requests int
+	kinds    map[string]struct{}
+}
+
+// Inventory aggregates third-party domains per target
+type Inventory struct {
+	mu      sync.Mutex
+	targets map[string]map[string]*domainState
+}
+
+// New creates a new domain inventory
+func New() *Inventory {
+	return &Inventory{targets: make(map[string]map[string]*domainState)}
+}
+
+// Record records a request of the target page to the URL when it is a
+// third-party domain. kind is the tag of the request or the resource
+// type of the browser request.
+func (i *Inventory) Record(target, requestURL, kind string) {
+	if i == nil || target == "" {
+		return
+	}
+	kind, ok := kinds[strings.ToLower(kind)]
+	if !ok {
+		return
+	}
+	parsed, err := url.Parse(requestURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https" && parsed.Scheme != "ws" && parsed.Scheme != "wss") {
+		return
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" || registrableDomain(host) == registrableDomain(strings.ToLower(target)) {
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	domains, ok := i.targets[target]
+	if !ok {
+		domains = make(map[string]*domainState)
+		i.targets[target] = domains
+	}
+	state, ok := domains[host]
+	if !ok {
+		state = &domainState{kinds: make(map[string]struct{})}
+		domains[host] = state
+	}
+	state.requests++
+	state.kinds[kind] = struct{}{}
+}
+
+// Report returns the third-party domains of the targets sorted
+// by target and by decreasing number of requests
+func (i *Inventory) Report() []Target {
+	if i == nil {
+		return nil
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	report := make([]Target, 0, len(i.targets))
+	for target, domains := range i.targets {
+		item := Target{Target: target}
+		for domain, state := range domains {
+			kinds := make([]string, 0, len(state.kinds))
+			for kind := range state.kinds {
+				kinds = append(kinds, kind)
+			}
+			sort.Strings(kinds)
+			item.Domains = append(item.Domains, Domain{Domain: domain, Requests: state.requests, Kinds: kinds})
+		}
+		sort.Slice(item.Domains, func(a, b int) bool {
+			if item.Domains[a].Requests != item.Domains[b].Requests {
+				return item.Domains[a].Requests > item.Domains[b].Requests
+			}
+			return item.Domains[a].Domain < item.Domains[b].Domain
+		})
+		report = append(report, item)
+	}
+	sort.Slice(report, func(a, b int) bool {
+		return report[a].Target < report[b].Target
+	})
+	return report
+}
+
+// registrableDomain returns the registrable domain (eTLD+1) of the host
+// or the host itself for ip addresses and single label hosts
+func registrableDomain(host string) string {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
@@ -0,0 +1,29 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventory(t *testing.T) {
+	inventory := New()
+	inventory.Record("www.example.com", "https://cdn.example.com/app.js", "script")
+	inventory.Record("www.example.com", "https://cdn.thirdparty.com/lib.js", "script")
+	inventory.Record("www.example.com", "https://cdn.thirdparty.com/api", "fetch")
+	inventory.Record("www.example.com", "https://analytics.tracker.io/collect", "ping")
+	inventory.Record("www.example.com", "https://other.com/page", "a")
+	inventory.Record("www.example.com", "mailto:admin@other.com", "script")
+
+	require.Equal(t, []Target{{
+		Target: "www.example.com",
+		Domains: []Domain{
+			{Domain: "cdn.thirdparty.com", Requests: 2, Kinds: []string{"script", "xhr"}},
+			{Domain: "analytics.tracker.io", Requests: 1, Kinds: []string{"beacon"}},
+		},
+	}}, inventory.Report())
+
+	var disabled *Inventory
+	disabled.Record("www.example.com", "https://cdn.thirdparty.com/lib.js", "script")
+	require.Nil(t, disabled.Report())
+}
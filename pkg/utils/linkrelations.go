@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+)
+
+// ParseLinkRelations returns the canonical, amphtml and alternate
+// link elements of the response document
+func ParseLinkRelations(resp *navigation.Response) []navigation.LinkRelation {
+	if resp.Reader == nil || resp.Resp == nil || resp.Resp.Request == nil {
+		return nil
+	}
+	var relations []navigation.LinkRelation
+	resp.Reader.Find("link[rel][href]").Each(func(_ int, item *goquery.Selection) {
+		href := strings.TrimSpace(item.AttrOr("href", ""))
+		if href == "" {
+			return
+		}
+		relation := navigation.LinkRelation{
+			Type:     strings.ToLower(strings.TrimSpace(item.AttrOr("type", ""))),
+			Media:    strings.TrimSpace(item.AttrOr("media", "")),
+			Hreflang: strings.TrimSpace(item.AttrOr("hreflang", "")),
+		}
+		rels := strings.Fields(strings.ToLower(item.AttrOr("rel", "")))
+		switch {
+		case slices.Contains(rels, "canonical"):
+			relation.Rel = "canonical"
+		case slices.Contains(rels, "amphtml"):
+			relation.Rel = "amphtml"
+			relation.Kind = navigation.AlternateAMP
+		case slices.Contains(rels, "alternate"):
+			relation.Rel = "alternate"
+			relation.Kind = alternateKind(relation)
+		default:
+			return
+		}
+		if relation.URL = resp.AbsoluteURL(href); relation.URL == "" {
+			return
+		}
+		relations = append(relations, relation)
+	})
+	return relations
+}
+
+// alternateKind classifies an alternate link by its attributes
+func alternateKind(relation navigation.LinkRelation) string {
+	switch {
+	case strings.Contains(relation.Type, "rss") || strings.Contains(relation.Type, "atom"):
+		return navigation.AlternateFeed
+	case relation.Hreflang != "":
+		return navigation.AlternateLanguage
+	case relation.Media != "":
+		// separate mobile urls are declared with a media query
+		return navigation.AlternateMobile
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/projectdiscovery/katana/pkg/navigation"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLinkRelations(t *testing.T) {
+	body := `<html><head>
+<link rel="canonical" href="/article#top">
+<link rel="amphtml" href="https://example.com/article/amp">
+<link rel="alternate" media="only screen and (max-width: 640px)" href="https://m.example.com/article">
+<link rel="alternate" type="application/rss+xml" title="Feed" href="/feed.xml">
+<link rel="alternate" hreflang="de" href="/de/article">
+<link rel="stylesheet" href="/style.css">
+</head></html>`
+	document, err := goquery.NewDocumentFromReader(strings.NewReader(body))
+	require.NoError(t, err)
+	pageURL, _ := url.Parse("https://example.com/article?ref=home")
+	resp := &navigation.Response{Resp: &http.Response{Request: &http.Request{URL: pageURL}}, Reader: document}
+
+	relations := ParseLinkRelations(resp)
+	require.Equal(t, []navigation.LinkRelation{
+		{Rel: "canonical", URL: "https://example.com/article"},
+		{Rel: "amphtml", URL: "https://example.com/article/amp", Kind: navigation.AlternateAMP},
+		{Rel: "alternate", URL: "https://m.example.com/article", Kind: navigation.AlternateMobile, Media: "only screen and (max-width: 640px)"},
+		{Rel: "alternate", URL: "https://example.com/feed.xml", Kind: navigation.AlternateFeed, Type: "application/rss+xml"},
+		{Rel: "alternate", URL: "https://example.com/de/article", Kind: navigation.AlternateLanguage, Hreflang: "de"},
+	}, relations)
+
+	resp.Relations = relations
+	require.Equal(t, "https://example.com/article", resp.Canonical())
+}
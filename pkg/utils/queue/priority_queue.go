@@ -2,6 +2,7 @@ package queue
 
 import (
 	"container/heap"
+	"sort"
 )
 
 type priorityQueue struct {
@@ -33,6 +34,20 @@ func (p *priorityQueue) Pop() interface{} {
 	return item.value
 }
 
+// Items returns the values of the queue ordered by priority
+func (p *priorityQueue) Items() []interface{} {
+	items := make([]*item, len(*p.itemHeap))
+	copy(items, *p.itemHeap)
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].priority < items[j].priority
+	})
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		values[i] = item.value
+	}
+	return values
+}
+
 type itemHeap []*item
 
 type item struct {
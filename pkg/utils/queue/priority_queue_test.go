@@ -12,6 +12,8 @@ func TestPriorityQueue(t *testing.T) {
 	queue.Push("higher", 4)
 	queue.Push("lowest", 1)
 
+	require.Equal(t, []interface{}{"lowest", "lower", "higher"}, queue.Items(), "could not list values in pop order")
+	require.Equal(t, 3, queue.Len(), "listing values should not remove them")
 	require.Equal(t, "lowest", queue.Pop(), "could not pop lowest priority first")
 	require.Equal(t, "lower", queue.Pop(), "could not pop lower priority first")
 	require.Equal(t, "higher", queue.Pop(), "could not pop higher priority first")
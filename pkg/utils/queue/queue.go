@@ -69,6 +69,21 @@ func (q *Queue) Push(x interface{}, priority int) {
 	}
 }
 
+// Snapshot returns the items waiting in the queue in
+// the order they will be popped without removing them.
+func (q *Queue) Snapshot() []interface{} {
+	q.Lock()
+	defer q.Unlock()
+
+	switch q.Strategy {
+	case BreadthFirst:
+		return q.priorityQueue.Items()
+	case DepthFirst:
+		return q.stack.Items()
+	}
+	return nil
+}
+
 // Pop pops an element from the queue. Result can be nil if no more
 // elements are present in the queue.
 func (q *Queue) Pop() chan interface{} {
@@ -21,6 +21,15 @@ func (s *stack) Len() int {
 	return s.ll.Len()
 }
 
+// Items returns the values of the stack from the top
+func (s *stack) Items() []interface{} {
+	values := make([]interface{}, 0, s.ll.Len())
+	for element := s.ll.Back(); element != nil; element = element.Prev() {
+		values = append(values, element.Value)
+	}
+	return values
+}
+
 func (s *stack) Pop() interface{} {
 	if s.ll.Len() == 0 {
 		return nil
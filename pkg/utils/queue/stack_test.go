@@ -12,6 +12,7 @@ func TestStack(t *testing.T) {
 	queue.Push("higher")
 	queue.Push("lowest")
 
+	require.Equal(t, []interface{}{"lowest", "higher", "lower"}, queue.Items(), "could not list values in pop order")
 	require.Equal(t, "lowest", queue.Pop(), "could not pop correct value")
 	require.Equal(t, "higher", queue.Pop(), "could not pop correct value")
 	require.Equal(t, "lower", queue.Pop(), "could not pop correct value")
@@ -0,0 +1,75 @@
+// Package resourceblock implements the blocking of the static resources
+// requested by browsers, so that crawls spend no time loading assets
+// which are not needed to discover endpoints.
+package resourceblock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// blockable are the lowercase names of the resource types which can be
+// blocked mapped to their devtools protocol names. Documents, scripts and
+// api requests are not blockable as they drive the crawl.
+var blockable = map[string]string{
+	"stylesheet": "Stylesheet",
+	"image":      "Image",
+	"media":      "Media",
+	"font":       "Font",
+	"texttrack":  "TextTrack",
+	"prefetch":   "Prefetch",
+	"manifest":   "Manifest",
+	"ping":       "Ping",
+}
+
+// static are the resource types blocked by the static shorthand
+var static = []string{"image", "font", "media", "stylesheet"}
+
+// Types are the devtools protocol resource types of the blocked requests
+type Types map[string]struct{}
+
+// Parse parses the names of the resource types to block (eg. image,font).
+// The static shorthand blocks images, fonts, media and stylesheets.
+func Parse(values []string) (Types, error) {
+	var types Types
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		names := []string{value}
+		if value == "static" {
+			names = static
+		}
+		for _, name := range names {
+			resourceType, ok := blockable[name]
+			if !ok {
+				return nil, errkit.New(fmt.Sprintf("resource type %q can not be blocked (eg. static, image, font, media, stylesheet, texttrack, prefetch, manifest, ping)", value))
+			}
+			if types == nil {
+				types = make(Types)
+			}
+			types[resourceType] = struct{}{}
+		}
+	}
+	return types, nil
+}
+
+// Blocks returns true if the requests of the resource type are blocked
+func (t Types) Blocks(resourceType string) bool {
+	_, ok := t[resourceType]
+	return ok
+}
+
+// List returns the blocked resource types sorted by name
+func (t Types) List() []string {
+	list := make([]string, 0, len(t))
+	for resourceType := range t {
+		list = append(list, resourceType)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,27 @@
+package resourceblock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	types, err := Parse([]string{"static", " Prefetch ", ""})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Font", "Image", "Media", "Prefetch", "Stylesheet"}, types.List())
+	require.True(t, types.Blocks("Image"))
+	require.False(t, types.Blocks("Document"))
+	require.False(t, types.Blocks("Script"))
+
+	for _, invalid := range []string{"document", "script", "xhr", "unknown"} {
+		_, err := Parse([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+
+	types, err = Parse(nil)
+	require.NoError(t, err)
+	require.Nil(t, types)
+	require.False(t, types.Blocks("Image"))
+	require.Empty(t, types.List())
+}
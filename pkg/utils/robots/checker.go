@@ -0,0 +1,124 @@
+package robots
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+	"github.com/projectdiscovery/katana/pkg/utils"
+	"github.com/projectdiscovery/retryablehttp-go"
+)
+
+type hostState struct {
+	once  sync.Once
+	rules *Rules
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// Checker fetches the robots.txt of hosts once and checks
+// urls against it honoring the crawl-delay of the hosts.
+type Checker struct {
+	httpclient *retryablehttp.Client
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// NewChecker creates a new robots.txt checker
+func NewChecker(httpclient *retryablehttp.Client) *Checker {
+	return &Checker{httpclient: httpclient, hosts: make(map[string]*hostState)}
+}
+
+// Allowed returns true if the robots.txt of the host allows the URL
+func (c *Checker) Allowed(URL string) bool {
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return true
+	}
+	return c.host(parsed).rules.Allowed(parsed.RequestURI())
+}
+
+// Wait blocks until the crawl-delay of the host of the URL has passed
+func (c *Checker) Wait(ctx context.Context, URL string) error {
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return nil
+	}
+	state := c.host(parsed)
+	if state.rules.CrawlDelay <= 0 {
+		return nil
+	}
+
+	state.mu.Lock()
+	now := time.Now()
+	start := state.next
+	if start.Before(now) {
+		start = now
+	}
+	state.next = start.Add(state.rules.CrawlDelay)
+	state.mu.Unlock()
+
+	timer := time.NewTimer(start.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// host returns the state of the host fetching its robots.txt on first use
+func (c *Checker) host(parsed *url.URL) *hostState {
+	origin := parsed.Scheme + "://" + parsed.Host
+
+	c.mu.Lock()
+	state, ok := c.hosts[origin]
+	if !ok {
+		state = &hostState{}
+		c.hosts[origin] = state
+	}
+	c.mu.Unlock()
+
+	state.once.Do(func() {
+		state.rules = c.fetch(origin)
+	})
+	return state
+}
+
+// fetch fetches the rules of the origin. Unavailable robots.txt files
+// allow everything while unreachable ones disallow everything.
+func (c *Checker) fetch(origin string) *Rules {
+	req, err := retryablehttp.NewRequest(http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return AllowAll()
+	}
+	req.Header.Set("User-Agent", utils.WebUserAgent())
+
+	resp, err := c.httpclient.Do(req)
+	if err != nil {
+		gologger.Warning().Msgf("Could not fetch robots.txt of %s, disallowing all paths: %s", origin, err)
+		return DisallowAll()
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch {
+	case resp.StatusCode >= 500:
+		gologger.Warning().Msgf("robots.txt of %s is unreachable (%d), disallowing all paths", origin, resp.StatusCode)
+		return DisallowAll()
+	case resp.StatusCode >= 400:
+		return AllowAll()
+	}
+	rules := Parse(resp.Body, Agent)
+	if rules.CrawlDelay > 0 {
+		gologger.Verbose().Msgf("Honoring crawl-delay of %s for %s", rules.CrawlDelay, origin)
+	}
+	return rules
+}
@@ -0,0 +1,154 @@
+// Package robots implements the robots.txt compliance of the crawler
+// as described in RFC 9309 along with the crawl-delay extension.
+package robots
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRobotsSize is the maximum size of a parsed robots.txt
+const maxRobotsSize = 500 * 1024
+
+// Agent is the product token of the crawler matched against user-agent lines
+const Agent = "katana"
+
+type rule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// Rules are the rules of a robots.txt for the crawler
+type Rules struct {
+	rules []rule
+	// CrawlDelay is the delay between requests to the host
+	CrawlDelay time.Duration
+}
+
+// AllowAll returns rules allowing every path
+func AllowAll() *Rules {
+	return &Rules{}
+}
+
+// DisallowAll returns rules disallowing every path
+func DisallowAll() *Rules {
+	return &Rules{rules: []rule{{allow: false, length: 1, pattern: regexp.MustCompile("^/")}}}
+}
+
+type group struct {
+	agents     []string
+	rules      []rule
+	crawlDelay time.Duration
+}
+
+// Parse parses a robots.txt for the agent. The groups of the agent
+// are used when present, otherwise the groups of the * wildcard.
+func Parse(reader io.Reader, agent string) *Rules {
+	var (
+		groups     []*group
+		current    *group
+		inAgents   bool
+		scanner    = bufio.NewScanner(io.LimitReader(reader, maxRobotsSize))
+		lowerAgent = strings.ToLower(agent)
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index != -1 {
+			line = line[:index]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = &group{}
+				groups = append(groups, current)
+				inAgents = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			continue
+		case "allow", "disallow":
+			if current != nil && value != "" {
+				current.rules = append(current.rules, rule{allow: key == "allow", length: len(value), pattern: compilePattern(value)})
+			}
+		case "crawl-delay":
+			if current != nil {
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					current.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+		inAgents = false
+	}
+
+	matched := matchingGroups(groups, lowerAgent)
+	if len(matched) == 0 {
+		matched = matchingGroups(groups, "*")
+	}
+	rules := &Rules{}
+	for _, group := range matched {
+		rules.rules = append(rules.rules, group.rules...)
+		if group.crawlDelay > rules.CrawlDelay {
+			rules.CrawlDelay = group.crawlDelay
+		}
+	}
+	return rules
+}
+
+func matchingGroups(groups []*group, agent string) []*group {
+	var matched []*group
+	for _, group := range groups {
+		for _, groupAgent := range group.agents {
+			if groupAgent == agent {
+				matched = append(matched, group)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// compilePattern compiles a path pattern supporting the *
+// wildcard and the $ end of path anchor
+func compilePattern(value string) *regexp.Regexp {
+	anchored := strings.HasSuffix(value, "$")
+	value = strings.TrimSuffix(value, "$")
+
+	parts := strings.Split(value, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expression := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expression += "$"
+	}
+	return regexp.MustCompile(expression)
+}
+
+// Allowed returns true if the path (with query) is allowed.
+// The longest matching rule wins, allow rules win ties.
+func (r *Rules) Allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	allowed, length := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > length || (rule.length == length && rule.allow) {
+			allowed, length = rule.allow, rule.length
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,51 @@
+package robots
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	robots := `# example
+User-agent: googlebot
+Disallow: /
+
+User-agent: *
+User-agent: other
+Disallow: /admin
+Allow: /admin/public
+Disallow: /*.php$
+Disallow: /search?
+Crawl-delay: 1.5
+Sitemap: https://example.com/sitemap.xml
+`
+	rules := Parse(strings.NewReader(robots), Agent)
+	require.Equal(t, 1500*time.Millisecond, rules.CrawlDelay)
+
+	tests := map[string]bool{
+		"/":                    true,
+		"/admin":               false,
+		"/admin/users":         false,
+		"/admin/public/a.html": true,
+		"/index.php":           false,
+		"/index.php?x=1":       true,
+		"/search?q=1":          false,
+		"/search":              true,
+	}
+	for path, allowed := range tests {
+		require.Equal(t, allowed, rules.Allowed(path), path)
+	}
+
+	rules = Parse(strings.NewReader("User-agent: Katana\nDisallow: /private\n\nUser-agent: *\nDisallow: /\n"), Agent)
+	require.True(t, rules.Allowed("/public"), "agent group should take precedence over wildcard")
+	require.False(t, rules.Allowed("/private/a"))
+}
+
+func TestAllowDisallowAll(t *testing.T) {
+	require.True(t, AllowAll().Allowed("/admin"))
+	require.False(t, DisallowAll().Allowed("/"))
+	require.True(t, Parse(strings.NewReader("User-agent: *\nDisallow:\n"), Agent).Allowed("/admin"), "empty disallow should allow everything")
+}
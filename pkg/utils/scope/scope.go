@@ -1,17 +1,29 @@
 package scope
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 
+	"github.com/projectdiscovery/katana/pkg/utils/idn"
 	"golang.org/x/net/publicsuffix"
 )
 
+// ThirdPartyTag is the tag of the browser requests blocked because
+// their host is out of the host scope of the crawl
+const ThirdPartyTag = "third-party"
+
+// ErrThirdPartyBlocked is reported for the blocked third-party requests
+var ErrThirdPartyBlocked = errors.New("blocked third-party request")
+
 // Manager manages scope for crawling process
 type Manager struct {
+	// mu guards the url rules which may be replaced during the crawl
+	mu                sync.RWMutex
 	inScope           []*regexp.Regexp
 	outOfScope        []*regexp.Regexp
 	noScope           bool
@@ -50,37 +62,65 @@ func NewManager(inScope, outOfScope []string, fieldScope string, noScope bool) (
 	} else {
 		manager.fieldScope = scopeValue
 	}
-	for _, regex := range inScope {
-		if compiled, err := regexp.Compile(regex); err != nil {
-			return nil, fmt.Errorf("could not compile regex %s: %s", regex, err)
-		} else {
-			manager.inScope = append(manager.inScope, compiled)
-		}
+	if err := manager.SetRules(inScope, outOfScope); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// SetRules replaces the in-scope and out-of-scope url rules of the manager.
+// The rules are only replaced if all of them compile, the host based scope
+// is kept as is. URLs validated afterwards are checked against the new rules.
+func (m *Manager) SetRules(inScope, outOfScope []string) error {
+	compiledInScope, err := compileRules(inScope)
+	if err != nil {
+		return err
+	}
+	compiledOutOfScope, err := compileRules(outOfScope)
+	if err != nil {
+		return err
 	}
-	for _, regex := range outOfScope {
-		if compiled, err := regexp.Compile(regex); err != nil {
+	m.mu.Lock()
+	m.inScope, m.outOfScope = compiledInScope, compiledOutOfScope
+	m.mu.Unlock()
+	return nil
+}
+
+func compileRules(rules []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, regex := range rules {
+		item, err := regexp.Compile(regex)
+		if err != nil {
 			return nil, fmt.Errorf("could not compile regex %s: %s", regex, err)
-		} else {
-			manager.outOfScope = append(manager.outOfScope, compiled)
 		}
+		compiled = append(compiled, item)
 	}
-	return manager, nil
+	return compiled, nil
 }
 
 // Validate returns true if the URL matches scope rules.
 // When noScope is true, DNS validation is skipped but URL-based scope rules still apply.
+//
+// Internationalized hostnames are compared in their punycode form while URL
+// patterns are matched against both the punycode and the unicode form.
 func (m *Manager) Validate(URL *url.URL, rootHostname string) (bool, error) {
 	if !m.noScope {
 		// Only validate DNS if scope is enabled
-		hostname := URL.Hostname()
-		dnsValidated, err := m.validateDNS(hostname, rootHostname)
+		hostname := idn.ToASCII(URL.Hostname())
+		dnsValidated, err := m.validateDNS(hostname, idn.ToASCII(rootHostname))
 		if err != nil || !dnsValidated {
 			return false, err
 		}
 	}
 
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	if len(m.inScope) > 0 || len(m.outOfScope) > 0 {
-		urlValidated, err := m.validateURL(URL.String())
+		URLs := []string{URL.String()}
+		if idn.IsIDN(URL.Hostname()) {
+			URLs = []string{idn.ASCIIURL(URLs[0]), idn.UnicodeURL(URLs[0])}
+		}
+		urlValidated, err := m.validateURL(URLs...)
 		if err != nil || !urlValidated {
 			return false, err
 		}
@@ -89,28 +129,42 @@ func (m *Manager) Validate(URL *url.URL, rootHostname string) (bool, error) {
 	return true, nil
 }
 
+// ValidateHost checks whether the hostname is within the host scope of the
+// root hostname, the url patterns are not matched so that requests to in
+// scope hosts are told apart from the requests to third-party hosts.
+func (m *Manager) ValidateHost(hostname, rootHostname string) (bool, error) {
+	if m.noScope {
+		return true, nil
+	}
+	return m.validateDNS(idn.ToASCII(hostname), idn.ToASCII(rootHostname))
+}
+
 // validateURL checks whether the given URL matches the configured inScope and outOfScope patterns.
 // It returns true if the URL is allowed (matches inScope and doesn't match outOfScope),
 // false if rejected, and an error if pattern matching fails.
 // When both inScope and outOfScope are empty, it returns true with no error.
-func (m *Manager) validateURL(URL string) (bool, error) {
+// Multiple spellings of the same URL may be given, any of them matching a pattern counts.
+// The caller must hold the read lock of the rules.
+func (m *Manager) validateURL(URLs ...string) (bool, error) {
 	for _, item := range m.outOfScope {
-		if item.MatchString(URL) {
-			return false, nil
+		for _, URL := range URLs {
+			if item.MatchString(URL) {
+				return false, nil
+			}
 		}
 	}
 	if len(m.inScope) == 0 {
 		return true, nil
 	}
 
-	var inScopeMatched bool
 	for _, item := range m.inScope {
-		if item.MatchString(URL) {
-			inScopeMatched = true
-			break
+		for _, URL := range URLs {
+			if item.MatchString(URL) {
+				return true, nil
+			}
 		}
 	}
-	return inScopeMatched, nil
+	return false, nil
 }
 
 // validateDNS performs DNS-based scope validation by checking if the URL's hostname
@@ -121,11 +175,15 @@ func (m *Manager) validateDNS(hostname, rootHostname string) (bool, error) {
 	parsed := net.ParseIP(hostname)
 	if m.fieldScope == customDNSScopeField {
 		// If we have a custom regex, we need to match it against the full hostname
-		if m.fieldScopePattern.MatchString(hostname) {
+		if m.fieldScopePattern.MatchString(hostname) || m.fieldScopePattern.MatchString(idn.ToUnicode(hostname)) {
 			return true, nil
 		}
 	}
-	if m.fieldScope == fqdnDNSScopeField || parsed != nil {
+	// ip literals only match the same address in any notation (eg. ::1 and 0:0::1)
+	if rootIP := net.ParseIP(rootHostname); parsed != nil || rootIP != nil {
+		return parsed != nil && parsed.Equal(rootIP), nil
+	}
+	if m.fieldScope == fqdnDNSScopeField {
 		matched := strings.EqualFold(hostname, rootHostname)
 		return matched, nil
 	}
@@ -43,6 +43,37 @@ func TestManagerValidate(t *testing.T) {
 			require.NoError(t, err, "could not validate host")
 			require.True(t, validated, "could not get correct in-scope validation")
 		})
+		t.Run("idn", func(t *testing.T) {
+			manager, err := NewManager(nil, nil, "rdn", false)
+			require.NoError(t, err, "could not create scope manager")
+
+			parsed, _ := urlutil.Parse("https://shop.xn--bcher-kva.example/index.php")
+			validated, err := manager.Validate(parsed.URL, "bücher.example")
+			require.NoError(t, err, "could not validate host")
+			require.True(t, validated, "could not get correct in-scope validation")
+
+			manager, err = NewManager([]string{`bücher\.example/books`}, nil, "fqdn", false)
+			require.NoError(t, err, "could not create scope manager")
+
+			parsed, _ = urlutil.Parse("https://xn--bcher-kva.example/books/1")
+			validated, err = manager.Validate(parsed.URL, "BÜCHER.example")
+			require.NoError(t, err, "could not validate host")
+			require.True(t, validated, "could not get correct in-scope validation")
+		})
+		t.Run("ipv6", func(t *testing.T) {
+			manager, err := NewManager(nil, nil, "rdn", false)
+			require.NoError(t, err, "could not create scope manager")
+
+			parsed, _ := urlutil.Parse("http://[2001:db8:0:0::1]:8080/admin")
+			validated, err := manager.Validate(parsed.URL, "2001:db8::1")
+			require.NoError(t, err, "could not validate host")
+			require.True(t, validated, "could not get correct in-scope validation")
+
+			parsed, _ = urlutil.Parse("http://example.com/admin")
+			validated, err = manager.Validate(parsed.URL, "2001:db8::1")
+			require.NoError(t, err, "could not validate host")
+			require.False(t, validated, "could not get correct out-scope validation")
+		})
 		t.Run("localhost", func(t *testing.T) {
 			manager, err := NewManager(nil, nil, "rdn", false)
 			require.NoError(t, err, "could not create scope manager")
@@ -74,6 +105,27 @@ func TestManagerValidate(t *testing.T) {
 	})
 }
 
+// TestManagerValidateHost verifies that hosts are validated against the
+// host scope only, the url patterns do not affect them.
+func TestManagerValidateHost(t *testing.T) {
+	manager, err := NewManager([]string{`/app/`}, []string{`cdn`}, "rdn", false)
+	require.NoError(t, err, "could not create scope manager")
+
+	validated, err := manager.ValidateHost("cdn.example.com", "www.example.com")
+	require.NoError(t, err, "could not validate host")
+	require.True(t, validated, "url patterns should not apply to hosts")
+
+	validated, err = manager.ValidateHost("www.google-analytics.com", "www.example.com")
+	require.NoError(t, err, "could not validate host")
+	require.False(t, validated, "third-party hosts should be out of scope")
+
+	manager, err = NewManager(nil, nil, "rdn", true)
+	require.NoError(t, err, "could not create scope manager")
+	validated, err = manager.ValidateHost("www.google-analytics.com", "www.example.com")
+	require.NoError(t, err, "could not validate host")
+	require.True(t, validated, "every host should be in scope without scope")
+}
+
 // TestGetDomainRDNandDN verifies the extraction of root domain name (RDN) and
 // effective top-level domain plus one label (eTLD+1) from a hostname.
 func TestGetDomainRDNandDN(t *testing.T) {
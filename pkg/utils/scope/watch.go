@@ -0,0 +1,176 @@
+package scope
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/gologger"
+)
+
+// DefaultWatchInterval is the interval rule files are checked for changes
+const DefaultWatchInterval = 5 * time.Second
+
+// ruleFile is a file of url rules combined with the rules given
+// on the command line
+type ruleFile struct {
+	path    string
+	static  []string
+	rules   []string
+	modTime time.Time
+	size    int64
+}
+
+// Watcher watches in-scope and out-of-scope rule files for changes and
+// applies them to a scope manager during the crawl. Queued items are
+// validated when they are processed so updates affect the items which
+// were not yet processed.
+type Watcher struct {
+	manager    *Manager
+	inScope    *ruleFile
+	outOfScope *ruleFile
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewWatcher loads the rule files and applies them to the manager
+// combined with the static in-scope and out-of-scope rules. Rule files
+// contain one regex per line, empty lines and lines starting with #
+// are ignored. An empty path disables the rule file.
+func NewWatcher(manager *Manager, inScope, outOfScope []string, inScopeFile, outOfScopeFile string) (*Watcher, error) {
+	watcher := &Watcher{
+		manager:    manager,
+		inScope:    &ruleFile{path: inScopeFile, static: inScope},
+		outOfScope: &ruleFile{path: outOfScopeFile, static: outOfScope},
+	}
+	for _, file := range []*ruleFile{watcher.inScope, watcher.outOfScope} {
+		if file.path == "" {
+			continue
+		}
+		if _, err := file.load(); err != nil {
+			return nil, err
+		}
+	}
+	if err := watcher.apply(); err != nil {
+		return nil, err
+	}
+	return watcher, nil
+}
+
+// Reload reloads the rule files which changed since they were last
+// loaded, returning true if the rules of the manager were replaced.
+// Rules are kept as is if a changed file is invalid, the file is
+// loaded again on the next reload.
+func (w *Watcher) Reload() (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	inScope, outOfScope := *w.inScope, *w.outOfScope
+	restore := func() {
+		*w.inScope, *w.outOfScope = inScope, outOfScope
+	}
+	var changed bool
+	for _, file := range []*ruleFile{w.inScope, w.outOfScope} {
+		if file.path == "" {
+			continue
+		}
+		fileChanged, err := file.load()
+		if err != nil {
+			restore()
+			return false, err
+		}
+		changed = changed || fileChanged
+	}
+	if !changed {
+		return false, nil
+	}
+	if err := w.apply(); err != nil {
+		restore()
+		return false, err
+	}
+	return true, nil
+}
+
+// Start checks the rule files for changes every interval until closed
+func (w *Watcher) Start(interval time.Duration) {
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				reloaded, err := w.Reload()
+				if err != nil {
+					gologger.Warning().Msgf("Could not reload scope rules, keeping current rules: %s", err)
+					continue
+				}
+				if reloaded {
+					gologger.Info().Msg("Reloaded scope rules, applying them to the not yet crawled urls")
+				}
+			}
+		}
+	}()
+}
+
+// Close stops watching the rule files
+func (w *Watcher) Close() {
+	if w.stop == nil {
+		return
+	}
+	close(w.stop)
+	<-w.done
+	w.stop = nil
+}
+
+func (w *Watcher) apply() error {
+	return w.manager.SetRules(w.inScope.all(), w.outOfScope.all())
+}
+
+func (f *ruleFile) all() []string {
+	return append(append([]string{}, f.static...), f.rules...)
+}
+
+// load reads the rules of the file if it changed since it was last loaded
+func (f *ruleFile) load() (bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, fmt.Errorf("could not read rule file %s: %s", f.path, err)
+	}
+	if info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return false, nil
+	}
+	file, err := os.Open(f.path)
+	if err != nil {
+		return false, fmt.Errorf("could not read rule file %s: %s", f.path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var rules []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rules = append(rules, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("could not read rule file %s: %s", f.path, err)
+	}
+	f.rules, f.modTime, f.size = rules, info.ModTime(), info.Size()
+	return true, nil
+}
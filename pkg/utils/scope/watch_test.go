@@ -0,0 +1,59 @@
+package scope
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	urlutil "github.com/projectdiscovery/utils/url"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWatcherReload verifies that changed rule files replace the url
+// rules of the manager while invalid changes keep the current rules.
+func TestWatcherReload(t *testing.T) {
+	outOfScopeFile := filepath.Join(t.TempDir(), "out-of-scope.txt")
+	writeRules := func(content string, modTime time.Time) {
+		require.NoError(t, os.WriteFile(outOfScopeFile, []byte(content), 0644))
+		require.NoError(t, os.Chtimes(outOfScopeFile, modTime, modTime))
+	}
+	validate := func(manager *Manager, URL string) bool {
+		parsed, err := urlutil.Parse(URL)
+		require.NoError(t, err)
+		validated, err := manager.Validate(parsed.URL, "example.com")
+		require.NoError(t, err, "could not validate url")
+		return validated
+	}
+	start := time.Now().Add(-time.Hour)
+	writeRules("# excluded paths\n/logout\n", start)
+
+	manager, err := NewManager(nil, []string{`\.css$`}, "rdn", false)
+	require.NoError(t, err, "could not create scope manager")
+	watcher, err := NewWatcher(manager, nil, []string{`\.css$`}, "", outOfScopeFile)
+	require.NoError(t, err, "could not create scope watcher")
+	defer watcher.Close()
+
+	require.False(t, validate(manager, "https://example.com/logout"), "rule file should be applied")
+	require.False(t, validate(manager, "https://example.com/main.css"), "static rules should be kept")
+	require.True(t, validate(manager, "https://example.com/admin"))
+
+	reloaded, err := watcher.Reload()
+	require.NoError(t, err)
+	require.False(t, reloaded, "unchanged rule file should not be reloaded")
+
+	writeRules("/logout\n/admin\n", start.Add(time.Minute))
+	reloaded, err = watcher.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded, "changed rule file should be reloaded")
+	require.False(t, validate(manager, "https://example.com/admin"), "new rule should be applied")
+	require.False(t, validate(manager, "https://example.com/main.css"), "static rules should be kept")
+
+	writeRules("/admin\n[invalid\n", start.Add(2*time.Minute))
+	_, err = watcher.Reload()
+	require.Error(t, err, "invalid rule file should not be applied")
+	require.False(t, validate(manager, "https://example.com/logout"), "current rules should be kept")
+
+	_, err = NewWatcher(manager, nil, nil, "", filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err, "missing rule file should fail")
+}
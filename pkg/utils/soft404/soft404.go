@@ -0,0 +1,103 @@
+// Package soft404 detects pages of hosts answering requests
+// to non-existent paths like existing pages (soft 404).
+package soft404
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var titleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// Fingerprint is the fingerprint of a response
+type Fingerprint struct {
+	StatusCode int
+	Location   string
+	Title      string
+	Words      int
+}
+
+// NewFingerprint returns the fingerprint of a response to a request of
+// the path, occurrences of the path are removed from the body and the
+// location so that pages reflecting the requested path match.
+func NewFingerprint(statusCode int, location, body, path string) Fingerprint {
+	if path != "" && path != "/" {
+		body = strings.ReplaceAll(body, path, "")
+		location = strings.ReplaceAll(location, path, "")
+	}
+	fingerprint := Fingerprint{StatusCode: statusCode, Location: location, Words: len(strings.Fields(body))}
+	if match := titleRegex.FindStringSubmatch(body); len(match) == 2 {
+		fingerprint.Title = strings.Join(strings.Fields(html.UnescapeString(match[1])), " ")
+	}
+	return fingerprint
+}
+
+// Matches returns true if the fingerprints are of the same page,
+// their word counts may differ by 5% for dynamic content.
+func (f Fingerprint) Matches(other Fingerprint) bool {
+	if f.StatusCode != other.StatusCode || f.Location != other.Location || f.Title != other.Title {
+		return false
+	}
+	tolerance := max(f.Words/20, 5)
+	difference := f.Words - other.Words
+	return difference >= -tolerance && difference <= tolerance
+}
+
+// ProbeFunc requests the path on the origin returning the fingerprint of the response
+type ProbeFunc func(origin, path string) (Fingerprint, error)
+
+type originState struct {
+	once        sync.Once
+	fingerprint *Fingerprint
+}
+
+// Detector detects soft 404 pages probing each origin once
+// with a random non-existent path
+type Detector struct {
+	probe ProbeFunc
+
+	mu      sync.Mutex
+	origins map[string]*originState
+}
+
+// New creates a new soft 404 detector
+func New(probe ProbeFunc) *Detector {
+	return &Detector{probe: probe, origins: make(map[string]*originState)}
+}
+
+// IsSoft404 returns true if the fingerprint of a response of the
+// origin matches the response of the origin to a non-existent path
+func (d *Detector) IsSoft404(origin string, fingerprint Fingerprint) bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	state, ok := d.origins[origin]
+	if !ok {
+		state = &originState{}
+		d.origins[origin] = state
+	}
+	d.mu.Unlock()
+
+	state.once.Do(func() {
+		notFound, err := d.probe(origin, RandomPath())
+		// hosts answering non-existent paths with 404 have no soft 404 pages
+		if err != nil || notFound.StatusCode == http.StatusNotFound || notFound.StatusCode == http.StatusGone {
+			return
+		}
+		state.fingerprint = &notFound
+	})
+	return state.fingerprint != nil && state.fingerprint.Matches(fingerprint)
+}
+
+// RandomPath returns a random path which does not exist on hosts
+func RandomPath() string {
+	token := make([]byte, 12)
+	_, _ = rand.Read(token)
+	return "/" + hex.EncodeToString(token)
+}
@@ -0,0 +1,49 @@
+package soft404
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	notFound := NewFingerprint(http.StatusOK, "", "<title>Oops</title><p>The page /abc123 was not found</p>", "/abc123")
+	require.Equal(t, "Oops", notFound.Title)
+	require.True(t, notFound.Matches(NewFingerprint(http.StatusOK, "", "<title>Oops</title><p>The page /admin/users was not found</p>", "/admin/users")), "reflected paths should be ignored")
+	require.False(t, notFound.Matches(NewFingerprint(http.StatusOK, "", "<title>Users</title><p>The users</p>", "/users")))
+	require.False(t, notFound.Matches(NewFingerprint(http.StatusNotFound, "", "<title>Oops</title><p>The page /x was not found</p>", "/x")))
+
+	redirect := NewFingerprint(http.StatusFound, "/login?next=/abc123", "", "/abc123")
+	require.True(t, redirect.Matches(NewFingerprint(http.StatusFound, "/login?next=/admin", "", "/admin")))
+	require.False(t, redirect.Matches(NewFingerprint(http.StatusFound, "/dashboard", "", "/admin")))
+
+	long := NewFingerprint(http.StatusOK, "", strings.Repeat("word ", 200), "")
+	require.True(t, long.Matches(NewFingerprint(http.StatusOK, "", strings.Repeat("word ", 208), "")), "dynamic content should be tolerated")
+	require.False(t, long.Matches(NewFingerprint(http.StatusOK, "", strings.Repeat("word ", 260), "")))
+}
+
+func TestDetector(t *testing.T) {
+	var probes atomic.Int32
+	detector := New(func(origin, path string) (Fingerprint, error) {
+		probes.Add(1)
+		switch origin {
+		case "https://soft.example.com":
+			return NewFingerprint(http.StatusOK, "", "<title>Home</title> welcome "+path, path), nil
+		case "https://hard.example.com":
+			return NewFingerprint(http.StatusNotFound, "", "<title>Home</title> welcome", path), nil
+		}
+		return Fingerprint{}, errors.New("unreachable")
+	})
+
+	home := NewFingerprint(http.StatusOK, "", "<title>Home</title> welcome /missing", "/missing")
+	require.True(t, detector.IsSoft404("https://soft.example.com", home))
+	require.False(t, detector.IsSoft404("https://soft.example.com", NewFingerprint(http.StatusOK, "", "<title>About</title>", "/about")))
+	require.False(t, detector.IsSoft404("https://hard.example.com", home), "hosts answering 404 have no soft 404 pages")
+	require.False(t, detector.IsSoft404("https://down.example.com", home))
+	require.Equal(t, int32(3), probes.Load(), "origins should be probed once")
+	require.False(t, (*Detector)(nil).IsSoft404("https://soft.example.com", home))
+}
@@ -0,0 +1,153 @@
+package sshtunnel
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// socks5 protocol constants, see RFC 1928
+const (
+	socksVersion        = 0x05
+	socksNoAuth         = 0x00
+	socksNoAcceptable   = 0xff
+	socksConnect        = 0x01
+	socksAddrIPv4       = 0x01
+	socksAddrDomain     = 0x03
+	socksAddrIPv6       = 0x04
+	socksSucceeded      = 0x00
+	socksHostFailure    = 0x04
+	socksNotSupported   = 0x07
+	socksAddrNotAllowed = 0x08
+)
+
+// dialFunc dials an address on the remote side of the tunnel
+type dialFunc func(network, addr string) (net.Conn, error)
+
+// serveSOCKS accepts socks5 CONNECT requests on the listener until it is
+// closed and forwards them to the connections returned by dial.
+func serveSOCKS(listener net.Listener, dial dialFunc) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				_ = conn.Close()
+			}()
+			remote, err := handshakeSOCKS(conn, dial)
+			if err != nil {
+				return
+			}
+			defer func() {
+				_ = remote.Close()
+			}()
+			pipe(conn, remote)
+		}()
+	}
+}
+
+// handshakeSOCKS negotiates a socks5 CONNECT request returning the
+// connection to the requested address
+func handshakeSOCKS(conn net.Conn, dial dialFunc) (net.Conn, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	if header[0] != socksVersion {
+		return nil, errors.New("unsupported socks version")
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return nil, err
+	}
+	method := byte(socksNoAcceptable)
+	for _, m := range methods {
+		if m == socksNoAuth {
+			method = socksNoAuth
+		}
+	}
+	if _, err := conn.Write([]byte{socksVersion, method}); err != nil {
+		return nil, err
+	}
+	if method == socksNoAcceptable {
+		return nil, errors.New("no acceptable socks auth method")
+	}
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return nil, err
+	}
+	if request[1] != socksConnect {
+		_ = writeSOCKSReply(conn, socksNotSupported)
+		return nil, errors.New("unsupported socks command")
+	}
+	var host string
+	switch request[3] {
+	case socksAddrIPv4, socksAddrIPv6:
+		size := net.IPv4len
+		if request[3] == socksAddrIPv6 {
+			size = net.IPv6len
+		}
+		ip := make(net.IP, size)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return nil, err
+		}
+		host = ip.String()
+	case socksAddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return nil, err
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return nil, err
+		}
+		host = string(domain)
+	default:
+		_ = writeSOCKSReply(conn, socksAddrNotAllowed)
+		return nil, errors.New("unsupported socks address type")
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return nil, err
+	}
+
+	remote, err := dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))))
+	if err != nil {
+		_ = writeSOCKSReply(conn, socksHostFailure)
+		return nil, err
+	}
+	if err := writeSOCKSReply(conn, socksSucceeded); err != nil {
+		_ = remote.Close()
+		return nil, err
+	}
+	return remote, nil
+}
+
+// writeSOCKSReply writes a reply with an unspecified bound address
+func writeSOCKSReply(conn net.Conn, status byte) error {
+	_, err := conn.Write([]byte{socksVersion, status, 0x00, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// pipe copies data between both connections until either side is done
+func pipe(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	copyConn := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		done <- struct{}{}
+	}
+	go copyConn(a, b)
+	go copyConn(b, a)
+	<-done
+}
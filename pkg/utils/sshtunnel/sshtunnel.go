@@ -0,0 +1,159 @@
+// Package sshtunnel forwards the crawl traffic through an ssh jump host
+// so that targets only reachable from the jump host can be crawled.
+//
+// The tunnel is exposed as a local socks5 proxy which is used by both
+// the http client of the standard engine and the headless browser.
+// Hostnames are resolved by the jump host.
+package sshtunnel
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projectdiscovery/utils/errkit"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// DefaultPort is the ssh port used when the target has none
+const DefaultPort = "22"
+
+// defaultKeyFiles are the private keys tried when no key file is given
+var defaultKeyFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// Options contains the options of an ssh tunnel
+type Options struct {
+	// Target is the jump host as user@host[:port]
+	Target string
+	// KeyFile is the private key used to authenticate, the default
+	// keys of the user are tried when empty
+	KeyFile string
+	// KnownHostsFile is the known hosts file used to verify the host
+	// key of the jump host, ~/.ssh/known_hosts when empty
+	KnownHostsFile string
+	// Timeout is the timeout of the ssh connection
+	Timeout time.Duration
+}
+
+// Tunnel is an ssh connection to a jump host exposed as a local socks5 proxy
+type Tunnel struct {
+	client   *ssh.Client
+	listener net.Listener
+
+	wg sync.WaitGroup
+}
+
+// New connects to the jump host and starts the local socks5 proxy
+func New(options Options) (*Tunnel, error) {
+	user, address, err := ParseTarget(options.Target)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := loadSigner(options.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, err := loadKnownHosts(options.KnownHostsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := ssh.Dial("tcp", address, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         options.Timeout,
+	})
+	if err != nil {
+		return nil, errkit.Wrap(err, "sshtunnel: could not connect to "+address)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		_ = client.Close()
+		return nil, errkit.Wrap(err, "sshtunnel: could not listen for proxy connections")
+	}
+
+	tunnel := &Tunnel{client: client, listener: listener}
+	tunnel.wg.Add(1)
+	go func() {
+		defer tunnel.wg.Done()
+		serveSOCKS(listener, client.Dial)
+	}()
+	return tunnel, nil
+}
+
+// ProxyURL returns the url of the local socks5 proxy of the tunnel
+func (t *Tunnel) ProxyURL() string {
+	return "socks5://" + t.listener.Addr().String()
+}
+
+// Close stops the local proxy and closes the ssh connection
+func (t *Tunnel) Close() error {
+	err := t.listener.Close()
+	// closing the ssh connection ends the forwarded connections
+	if closeErr := t.client.Close(); err == nil {
+		err = closeErr
+	}
+	t.wg.Wait()
+	return err
+}
+
+// ParseTarget parses a user@host[:port] jump host returning the
+// user and the address to connect to
+func ParseTarget(target string) (string, string, error) {
+	user, host, ok := strings.Cut(target, "@")
+	if !ok || user == "" || host == "" {
+		return "", "", errkit.New("sshtunnel: target must be user@host[:port]")
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), DefaultPort)
+	}
+	return user, host, nil
+}
+
+func loadSigner(keyFile string) (ssh.Signer, error) {
+	if keyFile == "" {
+		homedir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errkit.Wrap(err, "sshtunnel: could not get home directory")
+		}
+		for _, name := range defaultKeyFiles {
+			candidate := filepath.Join(homedir, ".ssh", name)
+			if _, err := os.Stat(candidate); err == nil {
+				keyFile = candidate
+				break
+			}
+		}
+		if keyFile == "" {
+			return nil, errkit.New("sshtunnel: no private key found, set one with -ssh-key")
+		}
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, errkit.Wrap(err, "sshtunnel: could not read private key")
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, errkit.Wrap(err, "sshtunnel: could not parse private key "+keyFile)
+	}
+	return signer, nil
+}
+
+func loadKnownHosts(file string) (ssh.HostKeyCallback, error) {
+	if file == "" {
+		homedir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errkit.Wrap(err, "sshtunnel: could not get home directory")
+		}
+		file = filepath.Join(homedir, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(file)
+	if err != nil {
+		return nil, errkit.Wrap(err, "sshtunnel: could not load known hosts")
+	}
+	return callback, nil
+}
@@ -0,0 +1,68 @@
+package sshtunnel
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	user, address, err := ParseTarget("deploy@bastion.internal")
+	require.NoError(t, err)
+	require.Equal(t, "deploy", user)
+	require.Equal(t, "bastion.internal:22", address, "default port should be used")
+
+	_, address, err = ParseTarget("deploy@[2001:db8::1]:2222")
+	require.NoError(t, err)
+	require.Equal(t, "[2001:db8::1]:2222", address)
+
+	_, address, err = ParseTarget("deploy@2001:db8::1")
+	require.NoError(t, err)
+	require.Equal(t, "[2001:db8::1]:22", address)
+
+	_, _, err = ParseTarget("bastion.internal")
+	require.Error(t, err, "target without user should fail")
+}
+
+func TestServeSOCKS(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("tunneled " + r.Host))
+	}))
+	defer ts.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var dialed []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// hostnames are resolved on the remote side of the tunnel
+		serveSOCKS(listener, func(network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			_, port, _ := net.SplitHostPort(addr)
+			return net.Dial(network, net.JoinHostPort("127.0.0.1", port))
+		})
+	}()
+
+	proxyURL, err := url.Parse("socks5://" + listener.Addr().String())
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+	resp, err := client.Get("http://intranet.local:" + port + "/")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	require.Equal(t, "tunneled intranet.local:"+port, string(body))
+
+	client.CloseIdleConnections()
+	require.NoError(t, listener.Close())
+	<-done
+	require.Equal(t, []string{"intranet.local:" + port}, dialed)
+}
@@ -0,0 +1,100 @@
+// Package throttle implements the process-wide request rate limit
+// shared by all the crawling engines.
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/projectdiscovery/ratelimit"
+	"github.com/projectdiscovery/utils/errkit"
+)
+
+// Limiter is a token bucket shared by every request sent to the targets.
+//
+// Hosts with a carve-out have their own bucket and do not consume
+// tokens of the shared bucket.
+type Limiter struct {
+	global *ratelimit.Limiter
+	hosts  map[string]*ratelimit.Limiter
+}
+
+// New returns a limiter allowing max requests per duration in total and
+// the given number of requests per second for each carved-out host.
+// A zero max leaves requests to other hosts unlimited.
+func New(max uint, duration time.Duration, hostLimits map[string]uint) *Limiter {
+	limiter := &Limiter{hosts: make(map[string]*ratelimit.Limiter, len(hostLimits))}
+	if max > 0 {
+		limiter.global = ratelimit.New(context.Background(), max, duration)
+	}
+	for host, limit := range hostLimits {
+		limiter.hosts[host] = ratelimit.New(context.Background(), limit, time.Second)
+	}
+	return limiter
+}
+
+// ParseHostLimits parses host carve-outs in the host=rps format
+func ParseHostLimits(values []string) (map[string]uint, error) {
+	hostLimits := make(map[string]uint, len(values))
+	for _, value := range values {
+		host, rawLimit, ok := strings.Cut(value, "=")
+		host = strings.ToLower(strings.TrimSpace(host))
+		if !ok || host == "" {
+			return nil, errkit.New(fmt.Sprintf("invalid host rate limit %q, expected host=rps", value))
+		}
+		limit, err := strconv.ParseUint(strings.TrimSpace(rawLimit), 10, 32)
+		if err != nil || limit == 0 {
+			return nil, errkit.New(fmt.Sprintf("invalid requests per second for host rate limit %q", value))
+		}
+		hostLimits[host] = uint(limit)
+	}
+	return hostLimits, nil
+}
+
+// Take blocks until a request to the host is allowed
+func (l *Limiter) Take(host string) {
+	if l == nil {
+		return
+	}
+	if limiter, ok := l.hosts[strings.ToLower(host)]; ok {
+		limiter.Take()
+		return
+	}
+	if l.global != nil {
+		l.global.Take()
+	}
+}
+
+// TakeURL blocks until a request to the host of the URL is allowed
+func (l *Limiter) TakeURL(rawURL string) {
+	if l == nil {
+		return
+	}
+	host := ""
+	if parsed, err := url.Parse(rawURL); err == nil {
+		host = parsed.Hostname()
+	}
+	l.Take(host)
+}
+
+// Transport returns a round tripper taking a token for every request
+func (l *Limiter) Transport(next http.RoundTripper) http.RoundTripper {
+	if l == nil {
+		return next
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		l.Take(req.URL.Hostname())
+		return next.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
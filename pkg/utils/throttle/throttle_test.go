@@ -0,0 +1,25 @@
+package throttle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHostLimits(t *testing.T) {
+	hostLimits, err := ParseHostLimits([]string{"API.example.com=5", " cdn.example.com = 100 "})
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint{"api.example.com": 5, "cdn.example.com": 100}, hostLimits)
+
+	for _, invalid := range []string{"example.com", "=5", "example.com=0", "example.com=fast"} {
+		_, err := ParseHostLimits([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestNilLimiter(t *testing.T) {
+	var limiter *Limiter
+	require.NotPanics(t, func() {
+		limiter.Take("example.com")
+	})
+}
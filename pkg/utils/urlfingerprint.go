@@ -101,6 +101,42 @@ func FingerprintURL(rawURL string, trie *PathTrie) string {
 	return buildFingerprint(u, fingerprintedPath)
 }
 
+// ObjectIdentifier is an apparent object identifier in an url path
+type ObjectIdentifier struct {
+	// Format is the placeholder of the identifier, e.g. {uuid}
+	Format string
+	// Value is the identifier as found in the path
+	Value string
+}
+
+// ObjectReferencePattern returns the path pattern of an url referencing
+// objects by numeric, uuid or hash identifiers along with the identifiers.
+// Dates and timestamps are not treated as object identifiers. ok is false
+// if the path contains no identifiers.
+func ObjectReferencePattern(rawURL string) (pattern string, identifiers []ObjectIdentifier, ok bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", nil, false
+	}
+	trimmed := strings.Trim(u.Path, "/")
+	if trimmed == "" {
+		return "", nil, false
+	}
+	segments := strings.Split(trimmed, "/")
+	for i, seg := range segments {
+		placeholder, matched := normalizeSegment(seg)
+		if !matched || placeholder == "{date}" || placeholder == "{ts}" {
+			continue
+		}
+		identifiers = append(identifiers, ObjectIdentifier{Format: placeholder, Value: seg})
+		segments[i] = placeholder
+	}
+	if len(identifiers) == 0 {
+		return "", nil, false
+	}
+	return u.Scheme + "://" + u.Host + "/" + strings.Join(segments, "/"), identifiers, true
+}
+
 // buildFingerprint reconstructs the URL with the fingerprinted path
 // and sorted query keys (values dropped).
 func buildFingerprint(u *url.URL, path string) string {
@@ -481,3 +481,27 @@ func TestFingerprintURL_NilTrieSameAsRegexOnly(t *testing.T) {
 		}
 	}
 }
+
+func TestObjectReferencePattern(t *testing.T) {
+	pattern, identifiers, ok := ObjectReferencePattern("https://app.example.com/api/tenants/550e8400-e29b-41d4-a716-446655440000/invoices/42?download=1")
+	if !ok {
+		t.Fatal("expected object references to be found")
+	}
+	if want := "https://app.example.com/api/tenants/{uuid}/invoices/{num}"; pattern != want {
+		t.Errorf("pattern = %q, want %q", pattern, want)
+	}
+	if len(identifiers) != 2 || identifiers[0].Format != "{uuid}" || identifiers[1].Value != "42" {
+		t.Errorf("unexpected identifiers %+v", identifiers)
+	}
+
+	for _, rawURL := range []string{
+		"https://example.com/",
+		"https://example.com/about/team",
+		"https://example.com/blog/2024-01-15/post",
+		"https://example.com/events/1700000000",
+	} {
+		if pattern, _, ok := ObjectReferencePattern(rawURL); ok {
+			t.Errorf("unexpected object reference pattern %q for %q", pattern, rawURL)
+		}
+	}
+}